@@ -50,10 +50,74 @@ func newBaseApp() *cli.App {
 			Name:  "V, version",
 			Usage: "Print version and exit",
 		},
+		cli.BoolFlag{
+			Name:  "init",
+			Usage: "Create a starter config file in the current directory and exit",
+		},
+		cli.BoolFlag{
+			Name:  "overwrite",
+			Usage: "Overwrite an existing config file with --init",
+		},
+		cli.BoolFlag{
+			Name:  "explain",
+			Usage: "Explain why the task would or would not run, without running it",
+		},
+		cli.BoolFlag{
+			Name:  "force",
+			Usage: "Run tasks even if their targets are up to date",
+		},
+		cli.BoolFlag{
+			Name:  "graceful-interrupt",
+			Usage: "On interrupt, stop tasks and run their finally clauses until interrupted again",
+		},
+		cli.StringFlag{
+			Name:  "color",
+			Usage: "Set `when` to color output (one of: auto, always, never)",
+		},
+		cli.BoolFlag{
+			Name:  "keep-going",
+			Usage: "Keep running the remaining run items of a task after one fails",
+		},
+		cli.StringFlag{
+			Name:  "log-file",
+			Usage: "Copy all output to `file`, without colors",
+		},
+		cli.BoolFlag{
+			Name:  "log-append",
+			Usage: "Append to the log file instead of overwriting it",
+		},
+		cli.BoolFlag{
+			Name:  "prefix-output",
+			Usage: "Prefix each line of command output with the task name",
+		},
+		cli.StringFlag{
+			Name:  "output",
+			Usage: "Print output in the given `format` (one of: human, json)",
+		},
+		cli.BoolFlag{
+			Name:  "profile",
+			Usage: "Print the time taken by each task and command after running",
+		},
+		cli.StringFlag{
+			Name:  "summary-file",
+			Usage: "Write a summary of the tasks and commands run to `file`",
+		},
+		cli.StringFlag{
+			Name:  "summary-format",
+			Usage: "Write the summary file in the given `format` (one of: json, junit)",
+		},
+		cli.BoolFlag{
+			Name:  "print-schema",
+			Usage: "Print the JSON schema for config files and exit",
+		},
 		cli.StringFlag{
 			Name:  "install-completion",
 			Usage: "Install tab completion for a `shell` (one of: bash, fish, zsh)",
 		},
+		cli.StringFlag{
+			Name:  "completion",
+			Usage: "Print the tab completion script for a `shell` (one of: bash, fish, zsh)",
+		},
 		cli.StringFlag{
 			Name:  "uninstall-completion",
 			Usage: "Uninstall tab completion for a `shell` (one of: bash, fish, zsh)",
@@ -70,6 +134,47 @@ func newBaseApp() *cli.App {
 			Name:  "clean-task-cache",
 			Usage: "Delete cached files related to the given task",
 		},
+		cli.BoolFlag{
+			Name:  "validate",
+			Usage: "Check the config file for problems and exit",
+		},
+		cli.BoolFlag{
+			Name:  "dump-graph",
+			Usage: "Print the sub-tasks run by each task as a Graphviz DOT graph and exit",
+		},
+		cli.BoolFlag{
+			Name:  "no-user-config",
+			Usage: "Ignore the tasks in the user-level config file",
+		},
+		cli.BoolFlag{
+			Name:  "offline",
+			Usage: "Use cached copies of remote included files without fetching them",
+		},
+		cli.StringSliceFlag{
+			Name:  "only, step",
+			Usage: "Run only the run items of the task with the given `name`",
+		},
+		cli.StringFlag{
+			Name:  "use-profile",
+			Usage: "Use the option defaults of the config profile with the given `name`",
+		},
+		cli.StringFlag{
+			Name:  "since",
+			Usage: "Only hash source files changed since `ref`, a git ref or time",
+		},
+		cli.StringSliceFlag{
+			Name:  "skip",
+			Usage: "Skip the run items of the task with the given `name`",
+		},
+		cli.IntFlag{
+			Name:  "max-task-depth",
+			Usage: "Fail if sub-tasks are nested more than `n` deep",
+			Value: 50,
+		},
+		cli.BoolFlag{
+			Name:  "yes",
+			Usage: "Confirm tasks that ask for confirmation without prompting",
+		},
 	)
 
 	sort.Sort(cli.FlagsByName(app.Flags))
@@ -86,15 +191,22 @@ func newSilentApp() *cli.App {
 }
 
 // newMetaApp creates a cli.App containing metadata, which can parse flags.
-func newMetaApp(cfgText []byte) (*cli.App, error) {
-	cfg, err := runner.Parse(cfgText)
+func newMetaApp(meta *Metadata) (*cli.App, error) {
+	cfg, err := runner.Parse(&runner.ParseConfig{
+		CfgPath:     meta.CfgPath,
+		CfgText:     meta.CfgText,
+		Offline:     meta.Offline,
+		UserCfgPath: meta.UserCfgPath,
+		UserCfgText: meta.UserCfgText,
+	})
 	if err != nil {
 		return nil, err
 	}
 
 	app := newSilentApp()
 	app.Metadata = make(map[string]any)
-	app.Metadata["tasks"] = make(map[string]*runner.Task)
+	app.Metadata["tasks"] = map[string]*runner.Task(cfg.Tasks)
+	app.Metadata["defaultTask"] = cfg.Default
 	app.Metadata["argsPassed"] = []string{}
 	app.Metadata["flagsPassed"] = make(map[string]string)
 
@@ -116,7 +228,7 @@ func helpAction(c *cli.Context) error {
 
 // NewApp creates a cli.App that executes tasks.
 func NewApp(args []string, meta *Metadata) (*cli.App, error) {
-	metaApp, err := newMetaApp(meta.CfgText)
+	metaApp, err := newMetaApp(meta)
 	if err != nil {
 		return nil, err
 	}
@@ -137,16 +249,26 @@ func NewApp(args []string, meta *Metadata) (*cli.App, error) {
 	}
 
 	cfg, err := runner.ParseComplete(&runner.ParseConfig{
-		Args:        argsPassed,
-		CfgPath:     meta.CfgPath,
-		CfgText:     meta.CfgText,
-		Flags:       flagsPassed,
-		Interpreter: meta.Interpreter,
-		TaskName:    taskName,
+		Args:         argsPassed,
+		CfgPath:      meta.CfgPath,
+		CfgText:      meta.CfgText,
+		Flags:        flagsPassed,
+		Interpreter:  meta.Interpreter,
+		MaxTaskDepth: meta.MaxTaskDepth,
+		Offline:      meta.Offline,
+		Profile:      meta.UseProfile,
+		TaskName:     taskName,
+		UserCfgPath:  meta.UserCfgPath,
+		UserCfgText:  meta.UserCfgText,
 	})
 	if err != nil {
 		return nil, err
 	}
+	if !IsCompleting(args) {
+		for _, warning := range cfg.Warnings {
+			meta.Logger.Warn(warning)
+		}
+	}
 
 	app := newBaseApp()
 	if cfg.Name != "" {
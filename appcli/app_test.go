@@ -4,7 +4,9 @@ import (
 	"bytes"
 	"errors"
 	"fmt"
+	"os"
 	"os/exec"
+	"path/filepath"
 	"syscall"
 	"testing"
 
@@ -28,7 +30,7 @@ tasks:
     run: echo ${foo}
 `)
 
-	flagApp, err := newMetaApp(cfgText)
+	flagApp, err := newMetaApp(&Metadata{CfgPath: "tusk.yml", CfgText: cfgText})
 	g.NoError(err)
 
 	err = flagApp.Run([]string{"tusk", "mytask", "--foo", "other"})
@@ -45,6 +47,138 @@ tasks:
 	g.Should(be.DeepEqual(flags, map[string]string{"foo": "other"}))
 }
 
+func TestNewFlagApp_list(t *testing.T) {
+	g := ghost.New(t)
+
+	cfgText := []byte(`options:
+  tag:
+    type: list
+    separator: ","
+
+tasks:
+  mytask:
+    options:
+      file:
+        type: list
+    run: echo ${tag} ${file}
+`)
+
+	flagApp, err := newMetaApp(&Metadata{CfgPath: "tusk.yml", CfgText: cfgText})
+	g.NoError(err)
+
+	err = flagApp.Run([]string{
+		"tusk", "mytask", "--tag", "a", "--tag", "b", "--file", "x", "--file", "y",
+	})
+	g.NoError(err)
+
+	flags, ok := flagApp.Metadata["flagsPassed"].(map[string]string)
+	g.Assert(ok)
+
+	g.Should(be.DeepEqual(flags, map[string]string{"tag": "a,b", "file": "x y"}))
+}
+
+func TestNewFlagApp_count(t *testing.T) {
+	cfgText := []byte(`tasks:
+  mytask:
+    options:
+      verbose:
+        type: count
+        short: v
+      quiet:
+        type: bool
+        short: q
+    run: echo ${verbose}
+`)
+
+	tests := []struct {
+		name string
+		args []string
+		want map[string]string
+	}{
+		{
+			name: "not passed",
+			want: map[string]string{},
+		},
+		{
+			name: "repeated",
+			args: []string{"-v", "-v", "-v"},
+			want: map[string]string{"verbose": "3"},
+		},
+		{
+			name: "stacked",
+			args: []string{"-vvv"},
+			want: map[string]string{"verbose": "3"},
+		},
+		{
+			name: "stacked with other flags",
+			args: []string{"-vqv", "-v"},
+			want: map[string]string{"verbose": "3", "quiet": "true"},
+		},
+		{
+			name: "assigned",
+			args: []string{"--verbose=2"},
+			want: map[string]string{"verbose": "2"},
+		},
+		{
+			name: "assigned higher than repeated",
+			args: []string{"-v=2", "-v"},
+			want: map[string]string{"verbose": "2"},
+		},
+		{
+			name: "assigned by long name higher than repeated",
+			args: []string{"-v", "--verbose=2"},
+			want: map[string]string{"verbose": "2"},
+		},
+		{
+			name: "assigned by long name lower than repeated",
+			args: []string{"-vvv", "--verbose=1"},
+			want: map[string]string{"verbose": "3"},
+		},
+		{
+			name: "assigned by long name before repeated",
+			args: []string{"--verbose=1", "-v", "-v"},
+			want: map[string]string{"verbose": "2"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			g := ghost.New(t)
+
+			flagApp, err := newMetaApp(&Metadata{CfgPath: "tusk.yml", CfgText: cfgText})
+			g.NoError(err)
+
+			err = flagApp.Run(append([]string{"tusk", "mytask"}, tt.args...))
+			g.NoError(err)
+
+			flags, ok := flagApp.Metadata["flagsPassed"].(map[string]string)
+			g.Assert(ok)
+
+			g.Should(be.DeepEqual(flags, tt.want))
+		})
+	}
+}
+
+func TestNewFlagApp_count_invalid(t *testing.T) {
+	g := ghost.New(t)
+
+	cfgText := []byte(`tasks:
+  mytask:
+    options:
+      verbose: {type: count}
+    run: echo ${verbose}
+`)
+
+	flagApp, err := newMetaApp(&Metadata{CfgPath: "tusk.yml", CfgText: cfgText})
+	g.NoError(err)
+
+	err = flagApp.Run([]string{"tusk", "mytask", "--verbose=-1"})
+	g.Should(be.ErrorEqual(
+		err,
+		`invalid boolean value "-1" for -verbose: count must be a non-negative integer, got "-1"`,
+	))
+}
+
 func TestNewFlagApp_no_options(t *testing.T) {
 	g := ghost.New(t)
 
@@ -53,7 +187,7 @@ func TestNewFlagApp_no_options(t *testing.T) {
     run: echo foo
 `)
 
-	flagApp, err := newMetaApp(cfgText)
+	flagApp, err := newMetaApp(&Metadata{CfgPath: "tusk.yml", CfgText: cfgText})
 	g.NoError(err)
 
 	err = flagApp.Run([]string{"tusk", "mytask"})
@@ -153,6 +287,35 @@ tasks:
 	))
 }
 
+func TestNewApp_alias(t *testing.T) {
+	g := ghost.New(t)
+
+	args := []string{"tusk", "t"}
+	cfgText := []byte(`
+tasks:
+  test:
+    aliases: t
+    run: exit 99`)
+	meta := &Metadata{
+		CfgText: cfgText,
+		Logger:  ui.Noop(),
+	}
+
+	app, err := NewApp(args, meta)
+	g.NoError(err)
+
+	g.Must(be.SliceLen(app.Commands, 1))
+	g.Should(be.DeepEqual(app.Commands[0].Names(), []string{"test", "t"}))
+
+	err = app.Run(args)
+	var exitErr *exec.ExitError
+	ok := errors.As(err, &exitErr)
+	g.Assert(ok)
+
+	exitCode := exitErr.Sys().(syscall.WaitStatus).ExitStatus()
+	g.Should(be.Equal(exitCode, 99))
+}
+
 func TestNewApp_task_not_found(t *testing.T) {
 	g := ghost.New(t)
 
@@ -202,6 +365,99 @@ tasks:
 	g.Should(be.Equal(exitCode, wantExitCode))
 }
 
+func TestNewApp_private_option(t *testing.T) {
+	g := ghost.New(t)
+
+	cfgText := []byte(`
+tasks:
+  greet:
+    options:
+      name:
+        private: true
+        default: world
+    run: echo ${name}`)
+	meta := &Metadata{
+		CfgText: cfgText,
+		Logger:  ui.Noop(),
+	}
+
+	app, err := NewApp([]string{"tusk", "greet"}, meta)
+	g.NoError(err)
+
+	g.Must(be.SliceLen(app.Commands, 1))
+	g.Should(be.SliceLen(app.Commands[0].Flags, 0))
+
+	_, err = NewApp([]string{"tusk", "greet", "--name", "someone"}, meta)
+	g.Should(be.ErrorEqual(err, "flag provided but not defined: -name"))
+}
+
+func TestNewApp_user_config(t *testing.T) {
+	g := ghost.New(t)
+
+	args := []string{"tusk", "scratch"}
+	meta := &Metadata{
+		CfgText:     []byte(`tasks: { build: { usage: Build it, run: exit 0 } }`),
+		UserCfgPath: "user.yml",
+		UserCfgText: []byte(`
+tasks:
+  build:
+    run: exit 1
+  scratch:
+    usage: Scratch pad
+    run: exit 99`),
+		Logger: ui.Noop(),
+	}
+
+	app, err := NewApp(args, meta)
+	g.NoError(err)
+
+	g.Must(be.SliceLen(app.Commands, 2))
+	g.Should(be.Equal(app.Commands[0].Usage, "Build it"))
+	g.Should(be.Equal(app.Commands[1].Usage, "Scratch pad (user)"))
+
+	err = app.Run(args)
+	var exitErr *exec.ExitError
+	ok := errors.As(err, &exitErr)
+	g.Assert(ok)
+
+	exitCode := exitErr.Sys().(syscall.WaitStatus).ExitStatus()
+	g.Should(be.Equal(exitCode, 99))
+}
+
+func TestNewApp_import_npm(t *testing.T) {
+	g := ghost.New(t)
+
+	dir := t.TempDir()
+	err := os.WriteFile(
+		filepath.Join(dir, "package.json"),
+		[]byte(`{"scripts": {"build": "tsc", "lint": "eslint ."}}`),
+		0o600,
+	)
+	g.NoError(err)
+
+	stderr := new(bytes.Buffer)
+	meta := &Metadata{
+		CfgPath: filepath.Join(dir, "tusk.yml"),
+		CfgText: []byte(`
+import: { npm: package.json }
+tasks: { build: { usage: Build it, run: exit 0 } }`),
+		Logger: ui.New(ui.Config{Stderr: stderr}),
+	}
+
+	app, err := NewApp([]string{"tusk"}, meta)
+	g.NoError(err)
+
+	g.Must(be.SliceLen(app.Commands, 2))
+	g.Should(be.Equal(app.Commands[0].Usage, "Build it"))
+	g.Should(be.Equal(app.Commands[1].Usage, "(npm)"))
+	g.Should(be.Equal(app.Commands[1].Description, "eslint ."))
+	g.Should(be.Equal(
+		stderr.String(),
+		"Warning: npm script \"build\" is not imported, "+
+			"since a task with that name is already defined\n",
+	))
+}
+
 func TestNewApp_bad_config(t *testing.T) {
 	g := ghost.New(t)
 
@@ -211,7 +467,7 @@ func TestNewApp_bad_config(t *testing.T) {
 	)
 	g.Should(be.ErrorEqual(
 		err,
-		"yaml: unmarshal errors:\n  line 1: cannot unmarshal !!str `invalid` into runner.configType",
+		"line 1: cannot unmarshal !!str `invalid` into runner.configType",
 	))
 }
 
@@ -9,27 +9,58 @@ import (
 	"github.com/rliebz/tusk/runner"
 )
 
-type commandCreator func(app *cli.App, meta *Metadata, t *runner.Task) (*cli.Command, error)
+type commandCreator func(
+	app *cli.App,
+	meta *Metadata,
+	cfg *runner.Config,
+	t *runner.Task,
+) (*cli.Command, error)
 
-func createExecuteCommand(_ *cli.App, meta *Metadata, t *runner.Task) (*cli.Command, error) {
+func createExecuteCommand(
+	_ *cli.App,
+	meta *Metadata,
+	cfg *runner.Config,
+	t *runner.Task,
+) (*cli.Command, error) {
 	return createCommand(t, func(c *cli.Context) error {
-		if len(t.Args) != len(c.Args()) {
-			return fmt.Errorf(
-				"task %q requires exactly %d args, got %d",
-				t.Name, len(t.Args), len(c.Args()),
-			)
+		if err := t.ValidateArgCount(len(c.Args())); err != nil {
+			return err
 		}
-		return t.Execute(runner.Context{
+		ctx := runner.Context{
 			CfgPath:     meta.CfgPath,
 			Logger:      meta.Logger,
 			Interpreter: meta.Interpreter,
-		})
+			Selection:   meta.Selection,
+			CacheDir:    meta.CacheDir,
+			Force:       meta.Force,
+			KeepGoing:   meta.KeepGoing,
+			Since:       meta.Since,
+			Yes:         meta.Yes,
+			Completed:   meta.Completed,
+		}
+		if meta.Completed != nil {
+			meta.Logger.PrintInvocation(t.Name)
+		}
+		if meta.Explain {
+			return t.Explain(ctx)
+		}
+		// Interrupts must be handled for the summary file to be written, but
+		// finally clauses are only run after one with --graceful-interrupt.
+		// Otherwise, they are only handled to stop background commands.
+		if meta.GracefulInterrupt || meta.SummaryFile != "" {
+			ctx.Interrupts = runner.NotifyInterrupts(meta.GracefulInterrupt)
+		} else {
+			ctx.Interrupts = runner.StopOnInterrupt()
+		}
+		defer ctx.Interrupts.Stop()
+		return runner.ExecuteTask(ctx, cfg, t)
 	}), nil
 }
 
 func createMetadataBuildCommand(
 	app *cli.App,
 	_ *Metadata,
+	cfg *runner.Config,
 	t *runner.Task,
 ) (*cli.Command, error) {
 	argsPassed, flagsPassed, err := getPassedValues(app)
@@ -43,9 +74,20 @@ func createMetadataBuildCommand(
 			argsPassed = append(argsPassed, value)
 		}
 		app.Metadata["argsPassed"] = argsPassed
-		for _, flagName := range c.FlagNames() {
-			if c.IsSet(flagName) {
-				flagsPassed[flagName] = c.String(flagName)
+		for _, flag := range c.Command.Flags {
+			flagName, _, _ := strings.Cut(flag.GetName(), ",")
+			if !c.IsSet(flagName) {
+				continue
+			}
+
+			// A count flag passed by its short name is only set under that name.
+			if alias, ok := flag.(countAlias); ok {
+				flagName = alias.Target
+			}
+
+			flagsPassed[flagName] = c.String(flagName)
+			if values := c.StringSlice(flagName); values != nil {
+				flagsPassed[flagName] = joinListValues(cfg, t, flagName, values)
 			}
 		}
 		return nil
@@ -54,16 +96,52 @@ func createMetadataBuildCommand(
 
 // createCommand creates a cli.Command from a runner.runner.
 func createCommand(t *runner.Task, actionFunc func(*cli.Context) error) *cli.Command {
+	usage := strings.TrimSpace(t.Usage)
+	if t.UserConfig != "" {
+		usage = strings.TrimSpace(usage + " (user)")
+	}
+	if t.Imported != "" {
+		usage = strings.TrimSpace(usage + " (" + t.Imported + ")")
+	}
+
 	command := &cli.Command{
 		Name:        t.Name,
-		Usage:       strings.TrimSpace(t.Usage),
+		Aliases:     t.Aliases,
+		Category:    taskCategory(t.Name),
+		Usage:       usage,
 		Description: strings.TrimSpace(t.Description),
 		Action:      actionFunc,
 	}
 
 	for _, arg := range t.Args {
-		command.ArgsUsage += fmt.Sprintf(" <%s>", arg.Name)
+		if arg.Default != nil {
+			command.ArgsUsage += fmt.Sprintf(" [<%s>]", arg.Name)
+		} else {
+			command.ArgsUsage += fmt.Sprintf(" <%s>", arg.Name)
+		}
 	}
 
 	return command
 }
+
+// taskCategory returns the namespace of a task, which is the part of its name
+// before the first colon. Tasks are grouped by namespace in help output.
+func taskCategory(name string) string {
+	category, _, ok := strings.Cut(name, ":")
+	if !ok {
+		return ""
+	}
+
+	return category
+}
+
+// joinListValues joins the values passed for a list option, which is either an
+// option of the task or a shared option.
+func joinListValues(cfg *runner.Config, t *runner.Task, name string, values []string) string {
+	opt, ok := t.Options.Lookup(name)
+	if !ok {
+		opt, _ = cfg.Options.Lookup(name)
+	}
+
+	return opt.JoinValues(values)
+}
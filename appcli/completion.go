@@ -209,7 +209,7 @@ func isCompletingFlagArg(flags []cli.Flag, arg string) bool {
 
 	for _, flag := range flags {
 		switch flag.(type) {
-		case cli.BoolFlag, cli.BoolTFlag:
+		case cli.BoolFlag, cli.BoolTFlag, countFlag, countAlias:
 			continue
 		}
 
@@ -2,12 +2,44 @@ package appcli
 
 import (
 	"errors"
+	"fmt"
+	"io"
 	"os"
 	"path/filepath"
+	"runtime"
+
+	"github.com/rliebz/tusk/internal/xdg"
 )
 
 var defaultFiles = []string{"tusk.yml", "tusk.yaml"}
 
+// stdinFile is the file passed to read the config file from stdin.
+const stdinFile = "-"
+
+// stdinName is the file name of a config file read from stdin.
+const stdinName = "<stdin>"
+
+// configInput is where a config file passed as "-" is read from. It can be
+// overwritten during tests.
+var configInput io.Reader = os.Stdin
+
+// readStdinFile reads the config file from stdin. The config is treated as a
+// file in the working directory, so that relative paths within it are resolved
+// from there.
+func readStdinFile() (fullPath string, cfgText []byte, _ error) {
+	dir, err := os.Getwd()
+	if err != nil {
+		return "", nil, err
+	}
+
+	cfgText, err = io.ReadAll(configInput)
+	if err != nil {
+		return "", nil, fmt.Errorf("reading config file from stdin: %w", err)
+	}
+
+	return filepath.Join(dir, stdinName), cfgText, nil
+}
+
 // searchForFile checks the working directory and every parent directory to
 // find a configuration file with the default name. If no file is found, an
 // empty string will be returned
@@ -54,3 +86,37 @@ func findFileInDirByName(dirPath, fileName string) (fullPath string, found bool,
 
 	return fullPath, true, nil
 }
+
+// searchForUserFile finds the user-level config file, whose tasks are available
+// in every project. If no file is found, an empty string will be returned.
+func searchForUserFile() (string, error) {
+	dirPath, err := getUserConfigDir()
+	if err != nil {
+		// Without a home directory, there is no user config to find.
+		return "", nil //nolint:nilerr
+	}
+
+	fullPath, _, err := findFileInDir(dirPath)
+	return fullPath, err
+}
+
+// getUserConfigDir gets the directory containing the user-level config file,
+// adhering to the XDG base directory specification. On Windows, the user's
+// application data directory is used unless XDG_CONFIG_HOME is set.
+func getUserConfigDir() (string, error) {
+	if runtime.GOOS == "windows" && os.Getenv("XDG_CONFIG_HOME") == "" {
+		appData, err := os.UserConfigDir()
+		if err != nil {
+			return "", err
+		}
+
+		return filepath.Join(appData, "tusk"), nil
+	}
+
+	xdgConfigHome, err := xdg.ConfigHome()
+	if err != nil {
+		return "", err
+	}
+
+	return filepath.Join(xdgConfigHome, "tusk"), nil
+}
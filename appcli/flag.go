@@ -1,8 +1,10 @@
 package appcli
 
 import (
+	"flag"
 	"fmt"
 	"sort"
+	"strconv"
 	"strings"
 
 	"github.com/urfave/cli"
@@ -57,6 +59,9 @@ func addFlag(command *cli.Command, opt *runner.Option) error {
 	}
 
 	command.Flags = append(command.Flags, newFlag)
+	if f, ok := newFlag.(countFlag); ok {
+		command.Flags = append(command.Flags, f.aliases()...)
+	}
 
 	return nil
 }
@@ -90,7 +95,113 @@ func createCLIFlag(opt *runner.Option) (cli.Flag, error) {
 			Name:  name,
 			Usage: opt.Usage,
 		}, nil
+	case "list":
+		return cli.StringSliceFlag{
+			Name:  name,
+			Usage: opt.Usage,
+		}, nil
+	case "count":
+		return countFlag{
+			Name:  name,
+			Usage: opt.Usage,
+		}, nil
 	default:
 		return nil, fmt.Errorf("unsupported flag type %q", opt.Type)
 	}
 }
+
+// countFlag is a flag that counts the number of times it is passed, so that
+// -v -v -v and -vvv are both 3. A count can also be assigned, as in -v=3, in
+// which case the higher of the two counts is used.
+type countFlag struct {
+	Name  string
+	Usage string
+}
+
+func (f countFlag) String() string {
+	return cli.FlagNamePrefixer(f.Name, "") + "\t" + f.Usage
+}
+
+// GetName returns only the first name of the flag. The cli package rejects a
+// flag that is passed under two of its names, but a count can be passed as
+// both -v and --verbose=2, so each other name is listed as a separate alias.
+func (f countFlag) GetName() string {
+	name, _, _ := strings.Cut(f.Name, ",")
+	return name
+}
+
+// Apply adds the flag to the flag set under each of its names, which share
+// the same count.
+func (f countFlag) Apply(set *flag.FlagSet) {
+	value := new(countValue)
+	for _, name := range strings.Split(f.Name, ",") {
+		set.Var(value, strings.TrimSpace(name), f.Usage)
+	}
+}
+
+// aliases returns a hidden alias for each name of the flag but the first.
+func (f countFlag) aliases() []cli.Flag {
+	var aliases []cli.Flag
+	for _, name := range strings.Split(f.Name, ",")[1:] {
+		aliases = append(aliases, countAlias{
+			Name:   strings.TrimSpace(name),
+			Target: f.GetName(),
+			Hidden: true,
+		})
+	}
+	return aliases
+}
+
+// countAlias is another name for a count flag. The name is added to the flag
+// set by the count flag itself, so the alias only lets the cli package
+// recognize the name as a flag.
+type countAlias struct {
+	Name   string
+	Target string
+	Hidden bool
+}
+
+func (f countAlias) String() string {
+	return cli.FlagNamePrefixer(f.Name, "") + "\tAlias for " + cli.FlagNamePrefixer(f.Target, "")
+}
+
+func (f countAlias) GetName() string {
+	return f.Name
+}
+
+func (f countAlias) Apply(*flag.FlagSet) {}
+
+// countValue is the number of times a count flag was passed, or the count
+// assigned to it, whichever is higher. The two are kept apart so that the
+// order of -v and --verbose=2 does not matter, since the cli package may
+// reorder flags before they are parsed.
+type countValue struct {
+	passed   int
+	assigned int
+}
+
+func (v *countValue) String() string {
+	return strconv.Itoa(max(v.passed, v.assigned))
+}
+
+// Set increments the count when the flag is passed without a value, which the
+// flag package passes as "true", or otherwise assigns the count.
+func (v *countValue) Set(s string) error {
+	if s == "true" {
+		v.passed++
+		return nil
+	}
+
+	n, err := strconv.Atoi(s)
+	if err != nil || n < 0 {
+		return fmt.Errorf("count must be a non-negative integer, got %q", s)
+	}
+
+	v.assigned = n
+	return nil
+}
+
+// IsBoolFlag allows the flag to be passed without a value.
+func (v *countValue) IsBoolFlag() bool {
+	return true
+}
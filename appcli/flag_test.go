@@ -45,3 +45,26 @@ func TestAddFlag_no_duplicates(t *testing.T) {
 
 	g.Should(be.SliceLen(command.Flags, 1))
 }
+
+func TestAddFlag_count_aliases(t *testing.T) {
+	g := ghost.New(t)
+
+	command := &cli.Command{}
+
+	opt := &runner.Option{
+		Passable: runner.Passable{
+			Name: "verbose",
+			Type: "count",
+		},
+		Short: "v",
+	}
+
+	err := addFlag(command, opt)
+	g.NoError(err)
+
+	g.Should(be.DeepEqual(command.Flags, []cli.Flag{
+		countFlag{Name: "verbose, v"},
+		countAlias{Name: "v", Target: "verbose", Hidden: true},
+	}))
+	g.Should(be.SliceLen(command.VisibleFlags(), 1))
+}
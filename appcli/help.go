@@ -54,7 +54,7 @@ Tasks:
 {{- range .VisibleCategories }}
 {{- $categoryName := .Name }}
 {{- with $categoryName }}
-   {{ . }}:
+   {{ . }}:{{ "\t" }}
 {{- end }}
 {{- range .VisibleCommands }}
    {{ if $categoryName }}  {{ end }}{{ join .Names ", " }}{{ "\t" }}{{ .Usage }}
@@ -164,6 +164,13 @@ Usage:
    {{- with .ArgsUsage }}{{ . }}{{ end }}
 {{- end }}
 
+{{- with .Aliases }}
+
+Aliases:
+   {{ join . ", " }}
+
+{{- end }}
+
 {{- with .Category }}
 
 Category:
@@ -177,7 +184,41 @@ Description:
 {{ indent 3 . }}
 
 {{- end }}%s
-`, createArgsSection(t)+createOptionsSection(command, t, dependencies))
+`, createArgsSection(t)+createOptionsSection(command, t, dependencies)+createExamplesSection(t))
+}
+
+func createExamplesSection(t *runner.Task) string {
+	tpl := template.New(t.Name + " example help")
+	tpl = template.Must(tpl.Parse(`{{- if . }}
+
+Examples:
+{{- range  . }}
+   {{ . }}
+{{- end }}
+
+{{- end }}`))
+
+	lines := make([]string, 0, len(t.Examples))
+	for _, example := range t.Examples {
+		lines = append(lines, formatExample(example))
+	}
+
+	var buf bytes.Buffer
+	if err := tpl.Execute(&buf, lines); err != nil {
+		panic(err)
+	}
+
+	return buf.String()
+}
+
+// formatExample prints the description of an example, if any, with the command
+// indented beneath it.
+func formatExample(example runner.Example) string {
+	if example.Description == "" {
+		return example.Command
+	}
+
+	return formatUsage(example.Description, 0) + "\n      " + example.Command
 }
 
 func createArgsSection(t *runner.Task) string {
@@ -211,8 +252,16 @@ Arguments:
 func formatArg(arg *runner.Arg, width int) string {
 	line := pad(arg.Name, width) + formatUsage(arg.Usage, width)
 
-	if len(arg.ValuesAllowed) > 0 {
+	if arg.Default != nil {
 		if arg.Usage != "" {
+			line += " " + fmt.Sprintf("(default: %s)", *arg.Default)
+		} else {
+			line += "Default: " + *arg.Default
+		}
+	}
+
+	if len(arg.ValuesAllowed) > 0 {
+		if arg.Usage != "" || arg.Default != nil {
 			line += "\n" + strings.Repeat(" ", width+3)
 		}
 		line += "One of: " + strings.Join(arg.ValuesAllowed, ", ")
@@ -253,12 +302,12 @@ Options:
 		if opt.Short != "" {
 			hasShortOpt = true
 		}
-		lines = append(lines, formatOpt(flag, opt, width))
+		lines = append(lines, formatOpt(flag, opt, t.ConflictingOptions(opt.Name), width))
 	}
 
 	if !hasShortOpt {
 		for i, line := range lines {
-			lines[i] = line[4:]
+			lines[i] = strings.ReplaceAll(line[4:], "\n    ", "\n")
 		}
 	}
 
@@ -270,7 +319,7 @@ Options:
 	return buf.String()
 }
 
-func formatOpt(flag cli.Flag, opt *runner.Option, width int) string {
+func formatOpt(flag cli.Flag, opt *runner.Option, conflicts []string, width int) string {
 	line := pad(flagPrefix(flag, opt), width) + formatUsage(opt.Usage, width)
 	defaultValue, hasDefault := opt.StaticDefault()
 
@@ -282,11 +331,22 @@ func formatOpt(flag cli.Flag, opt *runner.Option, width int) string {
 		}
 	}
 
+	var details []string
 	if len(opt.ValuesAllowed) > 0 {
-		if opt.Usage != "" || hasDefault {
+		details = append(details, "One of: "+strings.Join(opt.ValuesAllowed, ", "))
+	}
+	if opt.Environment != "" {
+		details = append(details, "Environment: "+opt.Environment)
+	}
+	if len(conflicts) > 0 {
+		details = append(details, "Cannot be used with: --"+strings.Join(conflicts, ", --"))
+	}
+
+	for i, detail := range details {
+		if i > 0 || opt.Usage != "" || hasDefault {
 			line += "\n" + strings.Repeat(" ", width+3)
 		}
-		line += "One of: " + strings.Join(opt.ValuesAllowed, ", ")
+		line += detail
 	}
 
 	return strings.TrimRight(line, " ")
@@ -307,7 +367,14 @@ func flagPrefix(flag cli.Flag, opt *runner.Option) string {
 	if opt.Usage != "" {
 		text, _, _ = strings.Cut(text, strings.TrimSpace(unquoteUsage(opt.Usage)))
 	}
-	return strings.TrimRight(text, " \t")
+	text = strings.TrimRight(text, " \t")
+
+	// List options are passed once for each value.
+	if strings.EqualFold(opt.Type, "list") {
+		text += "..."
+	}
+
+	return text
 }
 
 func formatUsage(usage string, width int) string {
@@ -83,6 +83,16 @@ Arguments:
    a      some usage
    aaaaa  other usage`,
 		},
+		{
+			"args with defaults",
+			"foo: {usage: 'some usage'}, bar: {usage: 'other usage', default: x}, baz: {default: z}",
+			`
+
+Arguments:
+   foo  some usage
+   bar  other usage (default: x)
+   baz  Default: z`,
+		},
 	}
 
 	for _, tt := range tests {
@@ -91,7 +101,7 @@ Arguments:
 			g := ghost.New(t)
 
 			cfgText := fmt.Sprintf("tasks: { %s: { args: {%s} } }", taskName, tt.taskCfg)
-			cfg, err := runner.Parse([]byte(cfgText))
+			cfg, err := runner.Parse(&runner.ParseConfig{CfgPath: "tusk.yml", CfgText: []byte(cfgText)})
 			g.NoError(err)
 
 			got := createArgsSection(cfg.Tasks[taskName])
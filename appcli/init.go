@@ -0,0 +1,47 @@
+package appcli
+
+import (
+	_ "embed" // starter config file
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"github.com/rliebz/tusk/ui"
+)
+
+//go:embed init/tusk.yml
+var starterConfig []byte
+
+// InitConfig writes a starter config file to the current directory.
+func InitConfig(meta *Metadata) error {
+	dir, err := os.Getwd()
+	if err != nil {
+		return err
+	}
+
+	return initConfigInDir(meta.Logger, dir, meta.Overwrite)
+}
+
+// initConfigInDir writes a starter config file to a directory. An existing
+// config file is only overwritten if overwrite is set.
+func initConfigInDir(logger *ui.Logger, dir string, overwrite bool) error {
+	target, found, err := findFileInDir(dir)
+	if err != nil {
+		return err
+	}
+
+	switch {
+	case found && !overwrite:
+		return fmt.Errorf("config file %q already exists (use --overwrite to replace it)", target)
+	case !found:
+		target = filepath.Join(dir, defaultFiles[0])
+	}
+
+	//nolint:gosec
+	if err := os.WriteFile(target, starterConfig, 0o644); err != nil {
+		return fmt.Errorf("writing config file: %w", err)
+	}
+
+	logger.Info("Config file created", target)
+	return nil
+}
@@ -0,0 +1,126 @@
+package appcli
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"runtime"
+	"testing"
+
+	"github.com/rliebz/ghost"
+	"github.com/rliebz/ghost/be"
+	"gotest.tools/v3/fs"
+
+	"github.com/rliebz/tusk/runner"
+	"github.com/rliebz/tusk/ui"
+)
+
+func TestInitConfig(t *testing.T) {
+	g := ghost.New(t)
+
+	dir := fs.NewDir(t, "project")
+
+	err := initConfigInDir(ui.Noop(), dir.Path(), false)
+	g.NoError(err)
+
+	cfgPath := filepath.Join(dir.Path(), "tusk.yml")
+	contents, err := os.ReadFile(cfgPath)
+	g.NoError(err)
+
+	g.Should(be.Equal(string(contents), string(starterConfig)))
+	g.NoError(runner.Validate(&runner.ParseConfig{CfgPath: cfgPath, CfgText: contents}))
+}
+
+func TestInitConfig_existing(t *testing.T) {
+	tests := []struct {
+		name      string
+		fileName  string
+		overwrite bool
+		wantErr   bool
+		want      string
+	}{
+		{
+			name:     "yml",
+			fileName: "tusk.yml",
+			wantErr:  true,
+			want:     "existing",
+		},
+		{
+			name:     "yaml",
+			fileName: "tusk.yaml",
+			wantErr:  true,
+			want:     "existing",
+		},
+		{
+			name:      "yml with overwrite",
+			fileName:  "tusk.yml",
+			overwrite: true,
+			want:      string(starterConfig),
+		},
+		{
+			name:      "yaml with overwrite",
+			fileName:  "tusk.yaml",
+			overwrite: true,
+			want:      string(starterConfig),
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			g := ghost.New(t)
+
+			dir := fs.NewDir(t, "project", fs.WithFile(tt.fileName, "existing"))
+			cfgPath := filepath.Join(dir.Path(), tt.fileName)
+
+			err := initConfigInDir(ui.Noop(), dir.Path(), tt.overwrite)
+			if tt.wantErr {
+				g.Should(be.ErrorEqual(err,
+					`config file "`+cfgPath+`" already exists (use --overwrite to replace it)`,
+				))
+			} else {
+				g.NoError(err)
+			}
+
+			contents, err := os.ReadFile(cfgPath)
+			g.NoError(err)
+			g.Should(be.Equal(string(contents), tt.want))
+
+			entries, err := os.ReadDir(dir.Path())
+			g.NoError(err)
+			g.Should(be.SliceLen(entries, 1))
+		})
+	}
+}
+
+func TestInitConfig_unwritable(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("directory permissions are not supported on windows")
+	}
+	if os.Geteuid() == 0 {
+		t.Skip("directory permissions are not enforced for root")
+	}
+
+	g := ghost.New(t)
+
+	dir := fs.NewDir(t, "project", fs.WithMode(0o555))
+
+	err := initConfigInDir(ui.Noop(), dir.Path(), false)
+	g.Should(be.ErrorIs(err, os.ErrPermission))
+}
+
+func TestInitConfig_logs(t *testing.T) {
+	g := ghost.New(t)
+
+	dir := fs.NewDir(t, "project")
+
+	stderr := new(bytes.Buffer)
+	logger := ui.New(ui.Config{Stderr: stderr})
+
+	err := initConfigInDir(logger, dir.Path(), false)
+	g.NoError(err)
+
+	g.Should(be.Equal(
+		stderr.String(),
+		"Info: Config file created\n => "+filepath.Join(dir.Path(), "tusk.yml")+"\n",
+	))
+}
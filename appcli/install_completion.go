@@ -5,9 +5,11 @@ import (
 	_ "embed" // completion scripts
 	"errors"
 	"fmt"
+	"io"
 	"os"
 	"path/filepath"
 	"regexp"
+	"strings"
 
 	"github.com/rliebz/tusk/internal/xdg"
 	"github.com/rliebz/tusk/ui"
@@ -61,6 +63,33 @@ func UninstallCompletion(meta *Metadata) error {
 	}
 }
 
+// PrintCompletion prints the command line tab completion script for a given
+// shell, which can be sourced instead of installed.
+func PrintCompletion(meta *Metadata) error {
+	shell := meta.PrintCompletion
+	var script string
+	switch shell {
+	case "bash":
+		script = rawBashCompletion
+	case "fish":
+		script = rawFishCompletion
+	case "zsh":
+		script = sourceableZshCompletion()
+	default:
+		return fmt.Errorf("completion target %q must be one of [bash, fish, zsh]", shell)
+	}
+
+	_, err := io.WriteString(meta.Logger.Stdout(), script)
+	return err
+}
+
+// sourceableZshCompletion wraps the zsh completion script in a function, since
+// the #compdef directive only applies to files loaded from the fpath.
+func sourceableZshCompletion() string {
+	body := strings.TrimPrefix(rawZshCompletion, "#compdef tusk\n")
+	return "_tusk() {\n" + body + "}\n\ncompdef _tusk tusk\n"
+}
+
 func installBashCompletion(logger *ui.Logger) error {
 	dir, err := getDataDir()
 	if err != nil {
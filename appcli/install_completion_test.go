@@ -1,10 +1,12 @@
 package appcli
 
 import (
+	"bytes"
 	"fmt"
 	"os"
 	"path/filepath"
 	"regexp"
+	"strings"
 	"testing"
 
 	"github.com/rliebz/ghost"
@@ -40,6 +42,54 @@ func TestUninstallCompletionUnsupported(t *testing.T) {
 	))
 }
 
+func TestPrintCompletion(t *testing.T) {
+	tests := []struct {
+		shell string
+		want  string
+	}{
+		{"bash", rawBashCompletion},
+		{"fish", rawFishCompletion},
+		{"zsh", sourceableZshCompletion()},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.shell, func(t *testing.T) {
+			g := ghost.New(t)
+
+			var stdout bytes.Buffer
+			err := PrintCompletion(&Metadata{
+				PrintCompletion: tt.shell,
+				Logger:          ui.New(ui.Config{Stdout: &stdout}),
+			})
+			g.NoError(err)
+
+			g.Should(be.Equal(stdout.String(), tt.want))
+		})
+	}
+}
+
+func TestPrintCompletionUnsupported(t *testing.T) {
+	g := ghost.New(t)
+
+	err := PrintCompletion(
+		&Metadata{
+			PrintCompletion: "fake",
+		},
+	)
+	g.Should(be.ErrorEqual(err,
+		`completion target "fake" must be one of [bash, fish, zsh]`,
+	))
+}
+
+func TestSourceableZshCompletion(t *testing.T) {
+	g := ghost.New(t)
+
+	got := sourceableZshCompletion()
+	g.Should(be.True(strings.HasPrefix(got, "_tusk() {\n")))
+	g.Should(be.True(strings.HasSuffix(got, "}\n\ncompdef _tusk tusk\n")))
+	g.Should(be.False(strings.Contains(got, "#compdef")))
+}
+
 func TestInstallBashCompletion(t *testing.T) {
 	g := ghost.New(t)
 
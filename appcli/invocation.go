@@ -0,0 +1,168 @@
+package appcli
+
+import (
+	"errors"
+	"fmt"
+	"slices"
+	"strings"
+
+	"github.com/urfave/cli"
+
+	"github.com/rliebz/tusk/runner"
+)
+
+// SplitTasks splits the command-line arguments into one set of arguments per
+// task, so that several tasks can be run in order, as in `tusk build test`.
+// Every set includes the global flags passed before the first task.
+//
+// Each task takes as many positional arguments as it has args, including args
+// with defaults. The next argument that names a task starts the arguments of
+// that task. Anything else, such as "--", ends the splitting, so the remaining
+// arguments belong to the last task. If no task is named, the default task of
+// the config file is run, if there is one.
+func SplitTasks(args []string, meta *Metadata) ([][]string, error) {
+	app, err := newMetaApp(meta)
+	if err != nil {
+		return nil, err
+	}
+
+	tasks, ok := app.Metadata["tasks"].(map[string]*runner.Task)
+	if !ok {
+		return nil, errors.New("could not read tasks from metadata")
+	}
+
+	start := nextPositional(args, 1, app.Flags)
+	if start == len(args) {
+		return [][]string{withDefaultTask(app, args)}, nil
+	}
+	if app.Command(args[start]) == nil {
+		return [][]string{args}, nil
+	}
+
+	prefix := args[:start]
+	var invocations [][]string
+	for start < len(args) {
+		command := app.Command(args[start])
+		end := invocationEnd(app, args, start, command, tasks[command.Name])
+		invocations = append(invocations, slices.Concat(prefix, args[start:end]))
+		start = end
+	}
+
+	if len(invocations) > 1 && (len(meta.Selection.Only) > 0 || len(meta.Selection.Skip) > 0) {
+		return nil, errors.New("--only and --skip cannot be used when running more than one task")
+	}
+
+	return invocations, nil
+}
+
+// GraphRoots returns the tasks named in the command-line arguments, which limit
+// the task graph to those tasks and the tasks they lead to. Aliases are
+// resolved to the tasks they refer to. Unlike running tasks, the default task
+// is not used when no task is named.
+func GraphRoots(args []string, meta *Metadata) ([]string, error) {
+	app, err := newMetaApp(meta)
+	if err != nil {
+		return nil, err
+	}
+
+	var roots []string
+	i := nextPositional(args, 1, app.Flags)
+	for ; i < len(args); i = nextPositional(args, i+1, app.Flags) {
+		command := app.Command(args[i])
+		if command == nil {
+			return nil, fmt.Errorf("task %q is not defined", args[i])
+		}
+		roots = append(roots, command.Name)
+	}
+
+	return roots, nil
+}
+
+// withDefaultTask adds the name of the default task to arguments that do not
+// name a task. Arguments after "--" are left alone, since they cannot be
+// followed by a task name.
+func withDefaultTask(app *cli.App, args []string) []string {
+	name, _ := app.Metadata["defaultTask"].(string)
+	if name == "" || slices.Contains(args, "--") {
+		return args
+	}
+
+	return slices.Concat(args, []string{name})
+}
+
+// invocationEnd returns the index of the argument after the last one for the
+// task whose name is at the start index.
+func invocationEnd(
+	app *cli.App,
+	args []string,
+	start int,
+	command *cli.Command,
+	t *runner.Task,
+) int {
+	positional := 0
+	for i := start + 1; i < len(args); i++ {
+		i = nextPositional(args, i, command.Flags)
+		switch {
+		case i == len(args):
+			return i
+		case positional < len(t.Args):
+			positional++
+		case app.Command(args[i]) != nil:
+			return i
+		default:
+			return len(args)
+		}
+	}
+
+	return len(args)
+}
+
+// nextPositional returns the index of the first positional argument at or
+// after i, skipping flags and their values. If there are none, or "--" is
+// found first, the length of args is returned.
+func nextPositional(args []string, i int, flags []cli.Flag) int {
+	for ; i < len(args); i++ {
+		arg := args[i]
+		switch {
+		case arg == "--":
+			return len(args)
+		case arg == "-" || !strings.HasPrefix(arg, "-"):
+			return i
+		case flagTakesValue(arg, flags):
+			i++
+		}
+	}
+
+	return len(args)
+}
+
+// flagTakesValue returns whether a flag argument is followed by its value as a
+// separate argument. For combined short flags such as -qf, only the last flag
+// can take a value.
+func flagTakesValue(arg string, flags []cli.Flag) bool {
+	if strings.Contains(arg, "=") {
+		return false
+	}
+
+	name := strings.TrimLeft(arg, "-")
+	if !strings.HasPrefix(arg, "--") && len(name) > 1 {
+		name = name[len(name)-1:]
+	}
+
+	for _, flag := range flags {
+		for _, flagName := range strings.Split(flag.GetName(), ",") {
+			if strings.TrimSpace(flagName) != name {
+				continue
+			}
+
+			switch flag.(type) {
+			case cli.BoolFlag, cli.BoolTFlag, countFlag, countAlias:
+				return false
+			default:
+				return true
+			}
+		}
+	}
+
+	return false
+}
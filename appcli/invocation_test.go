@@ -0,0 +1,202 @@
+package appcli
+
+import (
+	"testing"
+
+	"github.com/rliebz/ghost"
+	"github.com/rliebz/ghost/be"
+
+	"github.com/rliebz/tusk/runner"
+)
+
+func TestSplitTasks(t *testing.T) {
+	cfgText := []byte(`
+tasks:
+  build:
+    options:
+      target: {short: t}
+      release: {type: bool, short: r}
+    run: echo build
+  test:
+    aliases: [check]
+    run: echo test
+  deploy:
+    args:
+      env: {}
+    run: echo deploy
+`)
+
+	tests := []struct {
+		name string
+		args []string
+		want [][]string
+	}{
+		{
+			name: "no task",
+			args: []string{"tusk", "-q"},
+			want: [][]string{{"tusk", "-q"}},
+		},
+		{
+			name: "single task",
+			args: []string{"tusk", "build"},
+			want: [][]string{{"tusk", "build"}},
+		},
+		{
+			name: "several tasks",
+			args: []string{"tusk", "-q", "build", "test"},
+			want: [][]string{{"tusk", "-q", "build"}, {"tusk", "-q", "test"}},
+		},
+		{
+			name: "aliases",
+			args: []string{"tusk", "check", "build"},
+			want: [][]string{{"tusk", "check"}, {"tusk", "build"}},
+		},
+		{
+			name: "options with values",
+			args: []string{"tusk", "build", "-t", "test", "-r", "test"},
+			want: [][]string{{"tusk", "build", "-t", "test", "-r"}, {"tusk", "test"}},
+		},
+		{
+			name: "args",
+			args: []string{"tusk", "deploy", "build", "test"},
+			want: [][]string{{"tusk", "deploy", "build"}, {"tusk", "test"}},
+		},
+		{
+			name: "global flags with values",
+			args: []string{"tusk", "-f", "build", "test", "build"},
+			want: [][]string{{"tusk", "-f", "build", "test"}, {"tusk", "-f", "build", "build"}},
+		},
+		{
+			name: "unknown task",
+			args: []string{"tusk", "build", "unknown", "test"},
+			want: [][]string{{"tusk", "build", "unknown", "test"}},
+		},
+		{
+			name: "double dash",
+			args: []string{"tusk", "build", "--", "test"},
+			want: [][]string{{"tusk", "build", "--", "test"}},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			g := ghost.New(t)
+
+			got, err := SplitTasks(tt.args, &Metadata{CfgPath: "tusk.yml", CfgText: cfgText})
+			g.NoError(err)
+			g.Should(be.DeepEqual(got, tt.want))
+		})
+	}
+}
+
+func TestSplitTasks_default(t *testing.T) {
+	meta := &Metadata{
+		CfgPath: "tusk.yml",
+		CfgText: []byte("default: build\ntasks: {build: {run: echo}, test: {run: echo}}"),
+	}
+
+	tests := []struct {
+		name string
+		args []string
+		want [][]string
+	}{
+		{
+			name: "no task",
+			args: []string{"tusk", "-q"},
+			want: [][]string{{"tusk", "-q", "build"}},
+		},
+		{
+			name: "named task",
+			args: []string{"tusk", "test"},
+			want: [][]string{{"tusk", "test"}},
+		},
+		{
+			name: "after double dash",
+			args: []string{"tusk", "--", "test"},
+			want: [][]string{{"tusk", "--", "test"}},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			g := ghost.New(t)
+
+			got, err := SplitTasks(tt.args, meta)
+			g.NoError(err)
+
+			g.Should(be.DeepEqual(got, tt.want))
+		})
+	}
+}
+
+func TestSplitTasks_selection(t *testing.T) {
+	g := ghost.New(t)
+
+	meta := &Metadata{
+		CfgPath:   "tusk.yml",
+		CfgText:   []byte("tasks: {build: {run: echo}, test: {run: echo}}"),
+		Selection: runner.Selection{Only: []string{"build"}},
+	}
+
+	_, err := SplitTasks([]string{"tusk", "build", "test"}, meta)
+	g.Should(be.ErrorEqual(err, "--only and --skip cannot be used when running more than one task"))
+}
+
+func TestGraphRoots(t *testing.T) {
+	meta := &Metadata{
+		CfgPath: "tusk.yml",
+		CfgText: []byte(`
+default: build
+tasks:
+  build:
+    options:
+      target: {short: t}
+    run: echo build
+  test:
+    aliases: [check]
+    run: echo test
+`),
+	}
+
+	tests := []struct {
+		name    string
+		args    []string
+		want    []string
+		wantErr string
+	}{
+		{
+			name: "no task",
+			args: []string{"tusk", "--dump-graph"},
+		},
+		{
+			name: "several tasks",
+			args: []string{"tusk", "--dump-graph", "-f", "tusk.yml", "test", "build"},
+			want: []string{"test", "build"},
+		},
+		{
+			name: "aliases",
+			args: []string{"tusk", "--dump-graph", "check"},
+			want: []string{"test"},
+		},
+		{
+			name:    "undefined task",
+			args:    []string{"tusk", "--dump-graph", "lint"},
+			wantErr: `task "lint" is not defined`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			g := ghost.New(t)
+
+			got, err := GraphRoots(tt.args, meta)
+			if tt.wantErr != "" {
+				g.Should(be.ErrorEqual(err, tt.wantErr))
+				return
+			}
+			g.NoError(err)
+
+			g.Should(be.DeepEqual(got, tt.want))
+		})
+	}
+}
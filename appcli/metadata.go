@@ -1,13 +1,18 @@
 package appcli
 
 import (
+	"errors"
 	"fmt"
+	"io/fs"
 	"os"
+	"path/filepath"
+	"runtime"
 	"strings"
 
 	"github.com/urfave/cli"
 	yaml "gopkg.in/yaml.v2"
 
+	"github.com/rliebz/tusk/runner"
 	"github.com/rliebz/tusk/ui"
 )
 
@@ -15,16 +20,45 @@ import (
 type Metadata struct {
 	CfgPath     string
 	CfgText     []byte
+	UserCfgPath string
+	UserCfgText []byte
 	Interpreter []string
+	CacheDir    string
 	Logger      *ui.Logger
+	LogPath     string
+	LogAppend   bool
+	LogFile     *os.File
 
 	InstallCompletion   string
 	UninstallCompletion string
+	PrintCompletion     string
 	PrintHelp           bool
 	PrintVersion        bool
+	PrintSchema         bool
+	Init                bool
+	Overwrite           bool
+	Explain             bool
+	Profile             bool
+	SummaryFile         string
+	SummaryFormat       ui.SummaryFormat
+	Force               bool
+	KeepGoing           bool
+	GracefulInterrupt   bool
+	Offline             bool
+	Since               string
+	Yes                 bool
 	CleanCache          bool
 	CleanProjectCache   bool
 	CleanTaskCache      string
+	UseProfile          string
+	Validate            bool
+	DumpGraph           bool
+	MaxTaskDepth        int
+	Selection           runner.Selection
+
+	// Completed records the tasks that have run when several tasks are run
+	// together, so that none run twice. It is nil when running a single task.
+	Completed *runner.TaskRecord
 }
 
 // NewMetadata returns a metadata object based on global options passed.
@@ -56,7 +90,9 @@ func NewConfiglessMetadata(logger *ui.Logger) *Metadata {
 // These options will generally come from the command line.
 type optGetter interface {
 	Bool(string) bool
+	Int(string) int
 	String(string) string
+	StringSlice(string) []string
 }
 
 // set sets the metadata based on options.
@@ -72,16 +108,107 @@ func (m *Metadata) set(o optGetter) error {
 		return err
 	}
 
+	if !o.Bool("no-user-config") {
+		m.UserCfgPath, m.UserCfgText, err = getUserConfigFile(cfgPath)
+		if err != nil {
+			return err
+		}
+	}
+
+	cacheDir, err := runner.ReadCacheDir(cfgPath, cfgText)
+	if err != nil {
+		return err
+	}
+
+	maxTaskDepth := o.Int("max-task-depth")
+	if maxTaskDepth < 0 {
+		return fmt.Errorf("max task depth must not be negative, got %d", maxTaskDepth)
+	}
+
 	m.CfgPath, m.CfgText = cfgPath, cfgText
 	m.Interpreter = interpreter
+	m.CacheDir = cacheDir
 	m.InstallCompletion = o.String("install-completion")
 	m.UninstallCompletion = o.String("uninstall-completion")
+	m.PrintCompletion = o.String("completion")
 	m.PrintHelp = o.Bool("help")
 	m.PrintVersion = o.Bool("version")
+	m.PrintSchema = o.Bool("print-schema")
+	m.Init = o.Bool("init")
+	m.Overwrite = o.Bool("overwrite")
+	m.Explain = o.Bool("explain")
+	m.Profile = o.Bool("profile")
+	m.SummaryFile = o.String("summary-file")
+	m.Force = o.Bool("force")
+	m.KeepGoing = o.Bool("keep-going")
+	m.GracefulInterrupt = o.Bool("graceful-interrupt")
+	m.Offline = o.Bool("offline")
+	m.Since = o.String("since")
+	m.Yes = o.Bool("yes")
 	m.CleanCache = o.Bool("clean-cache")
 	m.CleanProjectCache = o.Bool("clean-project-cache")
 	m.CleanTaskCache = o.String("clean-task-cache")
+	m.UseProfile = o.String("use-profile")
+	m.Validate = o.Bool("validate")
+	m.DumpGraph = o.Bool("dump-graph")
+	m.MaxTaskDepth = maxTaskDepth
+	m.Selection = runner.Selection{
+		Only: o.StringSlice("only"),
+		Skip: o.StringSlice("skip"),
+	}
 	m.Logger.SetLevel(getLogLevel(o))
+
+	format, err := ui.ParseFormat(o.String("output"))
+	if err != nil {
+		return err
+	}
+	m.Logger.SetFormat(format)
+
+	m.SummaryFormat, err = ui.ParseSummaryFormat(o.String("summary-format"))
+	if err != nil {
+		return err
+	}
+
+	color, err := ui.ParseColorMode(o.String("color"))
+	if err != nil {
+		return err
+	}
+	m.Logger.SetColor(color)
+	m.Logger.SetPrefixOutput(o.Bool("prefix-output"))
+
+	m.LogPath, err = getLogPath(o, cfgPath, cfgText)
+	if err != nil {
+		return err
+	}
+	m.LogAppend = o.Bool("log-append")
+
+	return nil
+}
+
+// OpenLogFile opens the log file, if there is one, so that all output that
+// follows is copied to it. It is only opened when tasks are about to run, so
+// the file is left alone by flags such as --validate.
+//
+// The file is truncated unless appending was requested. Writes to the file are
+// not buffered, so its contents are kept even if the run is interrupted.
+func (m *Metadata) OpenLogFile() error {
+	if m.LogPath == "" || m.LogFile != nil {
+		return nil
+	}
+
+	flag := os.O_WRONLY | os.O_CREATE | os.O_TRUNC
+	if m.LogAppend {
+		flag = os.O_WRONLY | os.O_CREATE | os.O_APPEND
+	}
+
+	//nolint:gosec
+	f, err := os.OpenFile(m.LogPath, flag, 0o644)
+	if err != nil {
+		return fmt.Errorf("opening log file: %w", err)
+	}
+
+	m.LogFile = f
+	m.Logger.SetLogFile(f)
 	return nil
 }
 
@@ -103,6 +230,9 @@ func populateMetadata(app *cli.App, args []string) error {
 
 func getConfigFile(o optGetter) (fullPath string, cfgText []byte, _ error) {
 	fullPath = o.String("file")
+	if fullPath == stdinFile {
+		return readStdinFile()
+	}
 
 	if fullPath == "" {
 		var err error
@@ -113,6 +243,9 @@ func getConfigFile(o optGetter) (fullPath string, cfgText []byte, _ error) {
 	}
 
 	cfgText, err := os.ReadFile(fullPath)
+	if errors.Is(err, fs.ErrNotExist) {
+		return "", nil, fmt.Errorf("config file %q: %w", fullPath, fs.ErrNotExist)
+	}
 	if err != nil {
 		return "", nil, fmt.Errorf("reading config file %q: %w", fullPath, err)
 	}
@@ -120,25 +253,85 @@ func getConfigFile(o optGetter) (fullPath string, cfgText []byte, _ error) {
 	return fullPath, cfgText, nil
 }
 
+// getUserConfigFile reads the user-level config file, if there is one. Nothing
+// is returned if the user config is the project config, so that its tasks are
+// not defined twice.
+func getUserConfigFile(cfgPath string) (fullPath string, cfgText []byte, _ error) {
+	fullPath, err := searchForUserFile()
+	if err != nil || fullPath == "" {
+		return "", nil, err
+	}
+
+	if cfgPath != "" && isSameFile(fullPath, cfgPath) {
+		return "", nil, nil
+	}
+
+	cfgText, err = os.ReadFile(fullPath)
+	if err != nil {
+		return "", nil, fmt.Errorf("reading user config file %q: %w", fullPath, err)
+	}
+
+	return fullPath, cfgText, nil
+}
+
+// isSameFile returns whether two paths refer to the same file.
+func isSameFile(a, b string) bool {
+	aInfo, err := os.Stat(a)
+	if err != nil {
+		return false
+	}
+	bInfo, err := os.Stat(b)
+	if err != nil {
+		return false
+	}
+
+	return os.SameFile(aInfo, bInfo)
+}
+
 // getInterpreter attempts to determine the interpreter by reading the config
 // file. This should occur before full config parsing, as it may influence the
 // interpretation of option and arg resolutions.
 //
-// If no interpreter is specified, nil will be returned.
+// If no interpreter is specified for the current operating system, nil will be
+// returned.
 func getInterpreter(cfgText []byte) ([]string, error) {
 	var cfg struct {
-		Interpreter string `yaml:"interpreter"`
+		Interpreter runner.Interpreter `yaml:"interpreter"`
 	}
 
 	if err := yaml.Unmarshal(cfgText, &cfg); err != nil {
 		return nil, err
 	}
 
-	if cfg.Interpreter == "" {
+	interpreter := cfg.Interpreter.Fields(runtime.GOOS)
+	if len(interpreter) == 0 {
 		return nil, nil
 	}
 
-	return strings.Fields(cfg.Interpreter), nil
+	return interpreter, nil
+}
+
+// getLogPath returns the file that all output should be copied to, if any. The
+// file passed on the command line takes priority over the one in the config
+// file, which is relative to the config file's directory.
+func getLogPath(o optGetter, cfgPath string, cfgText []byte) (string, error) {
+	if path := o.String("log-file"); path != "" {
+		return path, nil
+	}
+
+	var cfg struct {
+		LogFile string `yaml:"log-file"`
+	}
+
+	if err := yaml.Unmarshal(cfgText, &cfg); err != nil {
+		return "", err
+	}
+
+	if cfg.LogFile == "" || filepath.IsAbs(cfg.LogFile) {
+		return cfg.LogFile, nil
+	}
+
+	return filepath.Join(filepath.Dir(cfgPath), cfg.LogFile), nil
 }
 
 func getLogLevel(c optGetter) ui.Level {
@@ -1,8 +1,11 @@
 package appcli
 
 import (
+	"maps"
 	"os"
 	"path/filepath"
+	"runtime"
+	"strings"
 	"testing"
 
 	"github.com/rliebz/ghost"
@@ -10,6 +13,7 @@ import (
 	"gotest.tools/v3/fs"
 
 	"github.com/rliebz/tusk/internal/xtesting"
+	"github.com/rliebz/tusk/runner"
 	"github.com/rliebz/tusk/ui"
 )
 
@@ -52,6 +56,24 @@ func TestNewMetadata_fileNoExist(t *testing.T) {
 
 	_, err := NewMetadata(ui.Noop(), []string{"tusk", "--file", "fakefile.yml"})
 	g.Should(be.ErrorIs(err, os.ErrNotExist))
+	g.Should(be.ErrorEqual(err, `config file "fakefile.yml": file does not exist`))
+}
+
+func TestNewMetadata_stdin(t *testing.T) {
+	g := ghost.New(t)
+
+	cfgText := "tasks: { hello: { run: echo hello } }\n"
+	t.Cleanup(func() { configInput = os.Stdin })
+	configInput = strings.NewReader(cfgText)
+
+	meta, err := NewMetadata(ui.Noop(), []string{"tusk", "--file", "-"})
+	g.NoError(err)
+
+	wd, err := os.Getwd()
+	g.NoError(err)
+
+	g.Should(be.Equal(meta.CfgPath, filepath.Join(wd, "<stdin>")))
+	g.Should(be.Equal(string(meta.CfgText), cfgText))
 }
 
 func TestNewMetadata_version(t *testing.T) {
@@ -63,6 +85,43 @@ func TestNewMetadata_version(t *testing.T) {
 	g.Check(meta.PrintVersion)
 }
 
+func TestNewMetadata_selection(t *testing.T) {
+	g := ghost.New(t)
+
+	meta, err := NewMetadata(ui.Noop(), []string{
+		"tusk", "--only", "build", "--only", "test", "--skip", "lint",
+	})
+	g.NoError(err)
+
+	g.Should(be.DeepEqual(meta.Selection, runner.Selection{
+		Only: []string{"build", "test"},
+		Skip: []string{"lint"},
+	}))
+
+	meta, err = NewMetadata(ui.Noop(), []string{"tusk", "--step", "deploy"})
+	g.NoError(err)
+
+	g.Should(be.DeepEqual(meta.Selection, runner.Selection{
+		Only: []string{"deploy"},
+		Skip: []string{},
+	}))
+}
+
+func TestNewMetadata_max_task_depth(t *testing.T) {
+	g := ghost.New(t)
+
+	meta, err := NewMetadata(ui.Noop(), []string{"tusk"})
+	g.NoError(err)
+	g.Should(be.Equal(meta.MaxTaskDepth, 50))
+
+	meta, err = NewMetadata(ui.Noop(), []string{"tusk", "--max-task-depth", "10"})
+	g.NoError(err)
+	g.Should(be.Equal(meta.MaxTaskDepth, 10))
+
+	_, err = NewMetadata(ui.Noop(), []string{"tusk", "--max-task-depth", "-1"})
+	g.Should(be.ErrorEqual(err, "max task depth must not be negative, got -1"))
+}
+
 func TestNewMetadata_log_level(t *testing.T) {
 	tests := []struct {
 		name string
@@ -125,8 +184,26 @@ func TestNewMetadata_log_level(t *testing.T) {
 
 // mockOptGetter returns opts from maps.
 type mockOptGetter struct {
-	bools   map[string]bool
-	strings map[string]string
+	bools        map[string]bool
+	ints         map[string]int
+	strings      map[string]string
+	stringSlices map[string][]string
+}
+
+func (m mockOptGetter) StringSlice(v string) []string {
+	if m.stringSlices != nil {
+		return m.stringSlices[v]
+	}
+
+	return nil
+}
+
+func (m mockOptGetter) Int(v string) int {
+	if m.ints != nil {
+		return m.ints[v]
+	}
+
+	return 0
 }
 
 func (m mockOptGetter) String(v string) string {
@@ -159,6 +236,9 @@ func TestMetadata_Set(t *testing.T) {
 	quiet := ui.New(ui.Config{Verbosity: ui.LevelQuiet})
 	verbose := ui.New(ui.Config{Verbosity: ui.LevelVerbose})
 
+	prefixed := ui.New(ui.Config{Verbosity: ui.LevelNormal})
+	prefixed.SetPrefixOutput(true)
+
 	tests := []struct {
 		name    string
 		bools   map[string]bool
@@ -224,6 +304,16 @@ func TestMetadata_Set(t *testing.T) {
 				Logger:              normal,
 			},
 		},
+		{
+			name: "completion",
+			strings: map[string]string{
+				"completion": "zsh",
+			},
+			meta: Metadata{
+				PrintCompletion: "zsh",
+				Logger:          normal,
+			},
+		},
 		{
 			name: "print-help",
 			bools: map[string]bool{
@@ -282,6 +372,15 @@ func TestMetadata_Set(t *testing.T) {
 				Logger: silent,
 			},
 		},
+		{
+			name: "prefix-output",
+			bools: map[string]bool{
+				"prefix-output": true,
+			},
+			meta: Metadata{
+				Logger: prefixed,
+			},
+		},
 	}
 
 	for _, tt := range tests {
@@ -338,12 +437,21 @@ func TestMetadata_Set_interpreter(t *testing.T) {
 			config: `interpreter: /usr/bin/env node -e`,
 			want:   []string{"/usr/bin/env", "node", "-e"},
 		},
+		{
+			name:   "operating system",
+			config: "interpreter: {" + runtime.GOOS + ": bash -c}",
+			want:   []string{"bash", "-c"},
+		},
+		{
+			name:   "other operating system",
+			config: `interpreter: {plan9: rc -c}`,
+		},
 		{
 			name:   "invalid yaml",
 			config: "🥔",
 			wantErr: `yaml: unmarshal errors:
   line 1: cannot unmarshal !!str ` + "`🥔`" +
-				` into struct { Interpreter string "yaml:\"interpreter\"" }`,
+				` into struct { Interpreter runner.Interpreter "yaml:\"interpreter\"" }`,
 		},
 	}
 
@@ -368,3 +476,100 @@ func TestMetadata_Set_interpreter(t *testing.T) {
 		})
 	}
 }
+
+func TestMetadata_Set_logFile(t *testing.T) {
+	dir := fs.NewDir(t, "log-dir", fs.WithFile("tusk.yml", "log-file: config.log"))
+	flagLog := filepath.Join(dir.Path(), "flag.log")
+
+	tests := []struct {
+		name  string
+		flags map[string]string
+		want  string
+	}{
+		{
+			name: "config relative to config file",
+			want: filepath.Join(dir.Path(), "config.log"),
+		},
+		{
+			name:  "flag overrides config",
+			flags: map[string]string{"log-file": flagLog},
+			want:  flagLog,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			g := ghost.New(t)
+
+			opts := mockOptGetter{
+				strings: map[string]string{"file": filepath.Join(dir.Path(), "tusk.yml")},
+			}
+			maps.Copy(opts.strings, tt.flags)
+
+			meta := Metadata{Logger: ui.Noop()}
+			err := meta.set(opts)
+			g.NoError(err)
+			g.Should(be.Equal(meta.LogPath, tt.want))
+			g.Should(be.Nil(meta.LogFile))
+
+			g.NoError(meta.OpenLogFile())
+			g.Must(be.True(meta.LogFile != nil))
+			t.Cleanup(func() { meta.LogFile.Close() }) //nolint:errcheck
+
+			g.Should(be.Equal(meta.LogFile.Name(), tt.want))
+		})
+	}
+}
+
+func TestMetadata_Set_userConfig(t *testing.T) {
+	userContents := "tasks: { scratch: { run: echo scratch } }"
+	configHome := fs.NewDir(t, "config-home",
+		fs.WithDir("tusk", fs.WithFile("tusk.yml", userContents)),
+	)
+	t.Setenv("XDG_CONFIG_HOME", configHome.Path())
+
+	userPath := filepath.Join(configHome.Path(), "tusk", "tusk.yml")
+	projectPath := fs.NewFile(t, "", fs.WithContent("tasks: {}")).Path()
+
+	tests := []struct {
+		name     string
+		file     string
+		bools    map[string]bool
+		wantPath string
+		wantText string
+	}{
+		{
+			name:     "found",
+			file:     projectPath,
+			wantPath: userPath,
+			wantText: userContents,
+		},
+		{
+			name:  "disabled",
+			file:  projectPath,
+			bools: map[string]bool{"no-user-config": true},
+		},
+		{
+			name: "same as project config",
+			file: userPath,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			g := ghost.New(t)
+
+			opts := mockOptGetter{
+				bools:   tt.bools,
+				strings: map[string]string{"file": tt.file},
+			}
+
+			meta := Metadata{Logger: ui.Noop()}
+			err := meta.set(opts)
+			g.NoError(err)
+
+			g.Should(be.Equal(meta.UserCfgPath, tt.wantPath))
+			g.Should(be.Equal(string(meta.UserCfgText), tt.wantText))
+		})
+	}
+}
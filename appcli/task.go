@@ -37,7 +37,7 @@ func addTask(
 		return nil
 	}
 
-	command, err := create(app, meta, t)
+	command, err := create(app, meta, cfg, t)
 	if err != nil {
 		return fmt.Errorf("could not create command %q: %w", t.Name, err)
 	}
@@ -0,0 +1,208 @@
+// Package schema generates the JSON schema for config files.
+package schema
+
+import (
+	"bytes"
+	_ "embed" // schema source
+	"encoding/json"
+	"errors"
+	"fmt"
+	"maps"
+	"reflect"
+	"slices"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+
+	"github.com/rliebz/tusk/runner"
+)
+
+// source describes everything in the schema that cannot be read from the
+// config structs, such as descriptions, examples, and the shorthand forms
+// accepted by custom unmarshalers.
+//
+//go:embed schema.yaml
+var source []byte
+
+// structs maps each definition that describes a config struct to the struct.
+// The root of the schema describes the config itself.
+var structs = map[string]reflect.Type{
+	"root":          reflect.TypeFor[runner.Config](),
+	"argClause":     reflect.TypeFor[runner.Arg](),
+	"commandItem":   reflect.TypeFor[runner.Command](),
+	"defaultItem":   reflect.TypeFor[runner.Value](),
+	"envFile":       reflect.TypeFor[runner.EnvFile](),
+	"hooks":         reflect.TypeFor[runner.Hooks](),
+	"option":        reflect.TypeFor[runner.Option](),
+	"runItem":       reflect.TypeFor[runner.Run](),
+	"subTaskClause": reflect.TypeFor[runner.SubTask](),
+	"taskItem":      reflect.TypeFor[runner.Task](),
+	"whenItem":      reflect.TypeFor[runner.When](),
+}
+
+// Generate returns the JSON schema for config files.
+//
+// The object that describes each config struct has exactly the properties
+// accepted by the struct. Properties are titled by their key unless they have
+// a title, and are typed by their field unless they have a type.
+func Generate() ([]byte, error) {
+	var schema map[string]any
+	if err := yaml.Unmarshal(source, &schema); err != nil {
+		return nil, err
+	}
+
+	if err := complete(schema, structs); err != nil {
+		return nil, err
+	}
+
+	var buf bytes.Buffer
+	enc := json.NewEncoder(&buf)
+	enc.SetIndent("", "\t")
+	if err := enc.Encode(schema); err != nil {
+		return nil, err
+	}
+
+	return buf.Bytes(), nil
+}
+
+// complete fills in the object that describes each struct, returning every
+// property that does not match a field.
+func complete(schema map[string]any, types map[string]reflect.Type) error {
+	defs, _ := schema["$defs"].(map[string]any)
+
+	var errs []error
+	for _, name := range slices.Sorted(maps.Keys(types)) {
+		node := schema
+		if name != "root" {
+			node, _ = defs[name].(map[string]any)
+		}
+
+		obj := objectNode(node)
+		if obj == nil {
+			errs = append(errs, fmt.Errorf("%s: no object with properties is defined", name))
+			continue
+		}
+
+		for _, err := range completeObject(obj, types[name]) {
+			errs = append(errs, fmt.Errorf("%s: %w", name, err))
+		}
+	}
+
+	return errors.Join(errs...)
+}
+
+// objectNode returns the node that describes an object with properties, which
+// is either the node itself or one of its oneOf branches.
+func objectNode(node map[string]any) map[string]any {
+	if _, ok := node["properties"]; ok {
+		return node
+	}
+
+	branches, _ := node["oneOf"].([]any)
+	for _, branch := range branches {
+		if m, ok := branch.(map[string]any); ok {
+			if _, ok := m["properties"]; ok {
+				return m
+			}
+		}
+	}
+
+	return nil
+}
+
+// completeObject checks that an object has a property for every field of a
+// struct and no others, then fills in what can be read from the fields.
+func completeObject(obj map[string]any, typ reflect.Type) []error {
+	props, _ := obj["properties"].(map[string]any)
+	fields := yamlFields(typ)
+
+	var errs []error
+	for _, name := range slices.Sorted(maps.Keys(fields)) {
+		prop, ok := props[name].(map[string]any)
+		if !ok {
+			errs = append(errs, fmt.Errorf("field %q has no property", name))
+			continue
+		}
+
+		if _, ok := prop["title"]; !ok {
+			prop["title"] = name
+		}
+
+		if hasType(prop) {
+			continue
+		}
+
+		jsonType, ok := jsonTypes[indirect(fields[name]).Kind()]
+		if !ok {
+			errs = append(errs, fmt.Errorf("property %q must have a type", name))
+			continue
+		}
+		prop["type"] = jsonType
+	}
+
+	for _, name := range slices.Sorted(maps.Keys(props)) {
+		if _, ok := fields[name]; !ok {
+			errs = append(errs, fmt.Errorf("property %q has no field", name))
+		}
+	}
+
+	obj["type"] = "object"
+	obj["additionalProperties"] = false
+
+	return errs
+}
+
+// jsonTypes are the JSON types of fields that are unmarshaled as is.
+var jsonTypes = map[reflect.Kind]string{
+	reflect.Bool:   "boolean",
+	reflect.Int:    "integer",
+	reflect.String: "string",
+}
+
+// hasType reports whether a property describes the type of its value.
+func hasType(prop map[string]any) bool {
+	for _, key := range []string{"type", "$ref", "oneOf", "anyOf", "enum", "const"} {
+		if _, ok := prop[key]; ok {
+			return true
+		}
+	}
+	return false
+}
+
+// indirect returns the type that a pointer type points to.
+func indirect(typ reflect.Type) reflect.Type {
+	for typ.Kind() == reflect.Pointer {
+		typ = typ.Elem()
+	}
+	return typ
+}
+
+// yamlFields returns the type of each key that a struct accepts when
+// unmarshaling YAML.
+func yamlFields(typ reflect.Type) map[string]reflect.Type {
+	fields := make(map[string]reflect.Type)
+	for i := range typ.NumField() {
+		field := typ.Field(i)
+		if !field.IsExported() {
+			continue
+		}
+
+		name, opts, _ := strings.Cut(field.Tag.Get("yaml"), ",")
+		switch {
+		case name == "-":
+			continue
+		case strings.Contains(opts, "inline") && field.Type.Kind() == reflect.Map:
+			// Inline maps hold keys such as "x-" extensions, not fields.
+			continue
+		case strings.Contains(opts, "inline"):
+			maps.Copy(fields, yamlFields(field.Type))
+			continue
+		case name == "":
+			name = strings.ToLower(field.Name)
+		}
+
+		fields[name] = field.Type
+	}
+
+	return fields
+}
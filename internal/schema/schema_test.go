@@ -0,0 +1,93 @@
+package schema
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/rliebz/ghost"
+	"github.com/rliebz/ghost/be"
+	"gopkg.in/yaml.v3"
+)
+
+func TestGenerate(t *testing.T) {
+	g := ghost.New(t)
+
+	_, err := Generate()
+	g.NoError(err)
+}
+
+type example struct {
+	Name    string            `yaml:"name"`
+	Quiet   *bool             `yaml:",omitempty"`
+	Count   int               `yaml:"count"`
+	Paths   []string          `yaml:"paths"`
+	Ignored string            `yaml:"-"`
+	Extra   map[string]string `yaml:",inline"`
+	Nested  `yaml:",inline"`
+}
+
+type Nested struct {
+	Usage string `yaml:"usage"`
+}
+
+func TestComplete(t *testing.T) {
+	g := ghost.New(t)
+
+	var schema map[string]any
+	g.NoError(yaml.Unmarshal([]byte(`
+$defs:
+  example:
+    oneOf:
+      - type: string
+      - properties:
+          name: {description: The name.}
+          quiet: {title: quiet mode}
+          count: {}
+          paths: {type: array}
+          usage: {type: [string, number]}
+`), &schema))
+
+	err := complete(schema, map[string]reflect.Type{
+		"example": reflect.TypeFor[example](),
+	})
+	g.NoError(err)
+
+	defs := schema["$defs"].(map[string]any)
+	g.Should(be.DeepEqual(defs["example"], any(map[string]any{
+		"oneOf": []any{
+			map[string]any{"type": "string"},
+			map[string]any{
+				"type":                 "object",
+				"additionalProperties": false,
+				"properties": map[string]any{
+					"name":  map[string]any{"title": "name", "type": "string", "description": "The name."},
+					"quiet": map[string]any{"title": "quiet mode", "type": "boolean"},
+					"count": map[string]any{"title": "count", "type": "integer"},
+					"paths": map[string]any{"title": "paths", "type": "array"},
+					"usage": map[string]any{"title": "usage", "type": []any{"string", "number"}},
+				},
+			},
+		},
+	})))
+}
+
+func TestComplete_mismatch(t *testing.T) {
+	g := ghost.New(t)
+
+	var schema map[string]any
+	g.NoError(yaml.Unmarshal([]byte(`
+properties:
+  name: {}
+  paths: {}
+  missing: {}
+`), &schema))
+
+	err := complete(schema, map[string]reflect.Type{
+		"root": reflect.TypeFor[example](),
+	})
+	g.Should(be.ErrorEqual(err, `root: field "count" has no property
+root: property "paths" must have a type
+root: field "quiet" has no property
+root: field "usage" has no property
+root: property "missing" has no field`))
+}
@@ -2,6 +2,7 @@ package main
 
 import (
 	"cmp"
+	_ "embed"
 	"errors"
 	"fmt"
 	"io"
@@ -19,6 +20,16 @@ import (
 
 var version string
 
+// statusInterrupted is the exit status when a task is stopped by an interrupt
+// that tusk handled, following the shell convention for SIGINT.
+const statusInterrupted = 130
+
+// schema is the JSON schema for config files, generated from the config structs.
+//
+//go:generate go run ./schemagen
+//go:embed tusk.schema.json
+var schema []byte
+
 func main() {
 	status := run(config{
 		args:   os.Args,
@@ -52,6 +63,11 @@ func run(cfg config) (status int) {
 		logError(logger, cfg.args, err)
 		return 1
 	}
+	defer func() {
+		if meta.LogFile != nil {
+			meta.LogFile.Close() //nolint:errcheck
+		}
+	}()
 
 	status, err = runMeta(meta, cfg.args)
 	if err != nil && appcli.IsCompleting(cfg.args) && meta.CfgPath != "" {
@@ -73,17 +89,61 @@ func runMeta(meta *appcli.Metadata, args []string) (exitStatus int, err error) {
 	case meta.PrintVersion:
 		printVersion(meta)
 		return 0, nil
+	case meta.PrintSchema:
+		return 0, printSchema(meta)
+	case meta.Init:
+		return 0, appcli.InitConfig(meta)
 	case meta.InstallCompletion != "":
 		return 0, appcli.InstallCompletion(meta)
 	case meta.UninstallCompletion != "":
 		return 0, appcli.UninstallCompletion(meta)
+	case meta.PrintCompletion != "":
+		return 0, appcli.PrintCompletion(meta)
 	case meta.CleanCache:
-		return 0, runner.CleanCache()
+		return 0, runner.CleanCache(meta.CacheDir)
 	case meta.CleanProjectCache:
-		return 0, runner.CleanProjectCache(meta.CfgPath)
+		return 0, runner.CleanProjectCache(meta.CfgPath, meta.CacheDir)
+	case meta.Validate:
+		return 0, runner.Validate(&runner.ParseConfig{
+			CfgPath: meta.CfgPath,
+			CfgText: meta.CfgText,
+			Offline: meta.Offline,
+		})
+	case meta.DumpGraph:
+		return 0, dumpGraph(meta, args)
+	}
+
+	invocations := [][]string{args}
+	if !appcli.IsCompleting(args) && !meta.PrintHelp && meta.CleanTaskCache == "" {
+		// The summary is written even if the config cannot be loaded, so that
+		// there is always a result to read.
+		if meta.SummaryFile != "" {
+			defer func() {
+				status := exitStatus
+				if err != nil {
+					status = cmp.Or(exitStatus, 1)
+				}
+				if serr := writeSummary(meta, status); serr != nil {
+					err = errors.Join(err, serr)
+				}
+			}()
+		}
+
+		if err := meta.OpenLogFile(); err != nil {
+			return 1, err
+		}
+
+		var err error
+		invocations, err = appcli.SplitTasks(args, meta)
+		if err != nil {
+			return 1, err
+		}
+	}
+	if len(invocations) > 1 {
+		meta.Completed = runner.NewTaskRecord()
 	}
 
-	app, err := appcli.NewApp(args, meta)
+	app, err := appcli.NewApp(invocations[0], meta)
 	if err != nil {
 		return 1, err
 	}
@@ -93,13 +153,86 @@ func runMeta(meta *appcli.Metadata, args []string) (exitStatus int, err error) {
 		appcli.ShowAppHelp(meta.Logger, app)
 		return 0, nil
 	case meta.CleanTaskCache != "":
-		if app.Command(meta.CleanTaskCache) == nil {
+		command := app.Command(meta.CleanTaskCache)
+		if command == nil {
 			return 0, fmt.Errorf("task %q is not defined", meta.CleanTaskCache)
 		}
-		return 0, runner.CleanTaskCache(meta.CfgPath, meta.CleanTaskCache)
+		// Aliases share the cache of the task they refer to.
+		return 0, runner.CleanTaskCache(meta.CfgPath, meta.CacheDir, command.Name)
 	}
 
-	return runApp(app, meta, args)
+	return runApps(app, meta, invocations)
+}
+
+// runApps runs the app for each task invoked in order, stopping at the first
+// task that fails. Each app is created just before it runs, so that option
+// defaults are computed after earlier tasks have finished.
+func runApps(
+	app *cli.App,
+	meta *appcli.Metadata,
+	invocations [][]string,
+) (status int, err error) {
+	if meta.Profile {
+		defer meta.Logger.PrintProfile()
+	} else {
+		defer meta.Logger.PrintTimingSummary()
+	}
+
+	for i, args := range invocations {
+		if i > 0 {
+			app, err = appcli.NewApp(args, meta)
+			if err != nil {
+				return 1, err
+			}
+		}
+
+		status, err = runApp(app, meta, args)
+		if errors.Is(err, runner.ErrNotConfirmed) {
+			// Declining a task stops the run without failing it.
+			meta.Logger.Info("Stopping, since the task was not confirmed")
+			return 0, nil
+		}
+		if status != 0 || err != nil {
+			return status, err
+		}
+	}
+
+	return 0, nil
+}
+
+// writeSummary writes the tasks and commands run, along with the exit status,
+// to the summary file.
+func writeSummary(meta *appcli.Metadata, status int) error {
+	f, err := os.Create(meta.SummaryFile)
+	if err != nil {
+		return fmt.Errorf("writing summary file: %w", err)
+	}
+	defer f.Close() //nolint:errcheck
+
+	if err := meta.Logger.WriteSummary(f, meta.SummaryFormat, status); err != nil {
+		return fmt.Errorf("writing summary file: %w", err)
+	}
+
+	return f.Close()
+}
+
+// dumpGraph prints the graph of the tasks named on the command line, or of
+// every task if none are named.
+func dumpGraph(meta *appcli.Metadata, args []string) error {
+	var roots []string
+	if meta.CfgPath != "" {
+		var err error
+		roots, err = appcli.GraphRoots(args, meta)
+		if err != nil {
+			return err
+		}
+	}
+
+	return runner.WriteGraph(meta.Logger.Stdout(), &runner.ParseConfig{
+		CfgPath: meta.CfgPath,
+		CfgText: meta.CfgText,
+		Offline: meta.Offline,
+	}, roots...)
 }
 
 func printVersion(meta *appcli.Metadata) {
@@ -112,8 +245,21 @@ func printVersion(meta *appcli.Metadata) {
 	meta.Logger.Println(version)
 }
 
+func printSchema(meta *appcli.Metadata) error {
+	_, err := meta.Logger.Stdout().Write(schema)
+	return err
+}
+
 func runApp(app *cli.App, meta *appcli.Metadata, args []string) (int, error) {
 	if err := app.Run(args); err != nil {
+		if errors.Is(err, runner.ErrNotConfirmed) {
+			return 0, err
+		}
+
+		if errors.Is(err, runner.ErrInterrupted) {
+			return statusInterrupted, err
+		}
+
 		var exitErr *exec.ExitError
 		if errors.As(err, &exitErr) {
 			if meta.Logger.Level() < ui.LevelVerbose {
@@ -130,7 +276,20 @@ func runApp(app *cli.App, meta *appcli.Metadata, args []string) (int, error) {
 }
 
 func logError(logger *ui.Logger, args []string, err error) {
-	if !appcli.IsCompleting(args) {
-		logger.Error(err)
+	if appcli.IsCompleting(args) {
+		return
 	}
+
+	var verrs runner.ValidationErrors
+	if errors.As(err, &verrs) {
+		messages := make([]any, 0, len(verrs)+1)
+		messages = append(messages, "invalid config file")
+		for _, verr := range verrs {
+			messages = append(messages, verr)
+		}
+		logger.Error(messages...)
+		return
+	}
+
+	logger.Error(err)
 }
@@ -2,10 +2,10 @@ package main
 
 import (
 	"bytes"
-	"fmt"
+	"encoding/json"
+	"io"
 	"os"
 	"path/filepath"
-	"runtime"
 	"slices"
 	"strings"
 	"testing"
@@ -33,6 +33,26 @@ func Test_run_printVersion(t *testing.T) {
 	g.Should(be.Equal(status, 0))
 }
 
+func Test_run_printSchema(t *testing.T) {
+	g := ghost.New(t)
+
+	stdout := new(bytes.Buffer)
+
+	args := []string{"tusk", "--print-schema"}
+	status := run(
+		config{
+			args:   args,
+			stdout: stdout,
+		},
+	)
+
+	want, err := os.ReadFile("tusk.schema.json")
+	g.NoError(err)
+
+	g.Should(be.Equal(stdout.String(), string(want)))
+	g.Should(be.Equal(status, 0))
+}
+
 func Test_run_printHelp(t *testing.T) {
 	tests := []struct {
 		args     []string
@@ -48,21 +68,50 @@ Usage:
 
 Tasks:
    hello                
-   lint                 Run static analysis
+   lint, l              Run static analysis
    print-passed-values  Print values passed
+   db:                  
+     db:migrate         Migrate the database
+     db:seed            
 
 Global Options:
        --clean-cache                   Delete all cached files
        --clean-project-cache           Delete cached files related to the current config file
        --clean-task-cache <value>      Delete cached files related to the given task
+       --color <when>                  Set when to color output (one of: auto, always, never)
+       --completion <shell>            Print the tab completion script for a shell (one of: bash, fish, zsh)
+       --dump-graph                    Print the sub-tasks run by each task as a Graphviz DOT graph and exit
+       --explain                       Explain why the task would or would not run, without running it
    -f, --file <file>                   Set file to use as the config file
+       --force                         Run tasks even if their targets are up to date
+       --graceful-interrupt            On interrupt, stop tasks and run their finally clauses until interrupted again
    -h, --help                          Show help and exit
+       --init                          Create a starter config file in the current directory and exit
        --install-completion <shell>    Install tab completion for a shell (one of: bash, fish, zsh)
+       --keep-going                    Keep running the remaining run items of a task after one fails
+       --log-append                    Append to the log file instead of overwriting it
+       --log-file <file>               Copy all output to file, without colors
+       --max-task-depth <n>            Fail if sub-tasks are nested more than n deep (default: 50)
+       --no-user-config                Ignore the tasks in the user-level config file
+       --offline                       Use cached copies of remote included files without fetching them
+       --only, --step <name>           Run only the run items of the task with the given name
+       --output <format>               Print output in the given format (one of: human, json)
+       --overwrite                     Overwrite an existing config file with --init
+       --prefix-output                 Prefix each line of command output with the task name
+       --print-schema                  Print the JSON schema for config files and exit
+       --profile                       Print the time taken by each task and command after running
    -q, --quiet                         Only print command output and application errors
    -s, --silent                        Print no output
+       --since <ref>                   Only hash source files changed since ref, a git ref or time
+       --skip <name>                   Skip the run items of the task with the given name
+       --summary-file <file>           Write a summary of the tasks and commands run to file
+       --summary-format <format>       Write the summary file in the given format (one of: json, junit)
        --uninstall-completion <shell>  Uninstall tab completion for a shell (one of: bash, fish, zsh)
+       --use-profile <name>            Use the option defaults of the config profile with the given name
    -V, --version                       Print version and exit
    -v, --verbose                       Print verbose output
+       --validate                      Check the config file for problems and exit
+       --yes                           Confirm tasks that ask for confirmation without prompting
 `,
 		},
 		{
@@ -71,6 +120,17 @@ Global Options:
 
 Usage:
    {{.}} hello
+`,
+		},
+		{
+			args: []string{"db:migrate", "--help"},
+			wantTmpl: `{{.}} db:migrate - Migrate the database
+
+Usage:
+   {{.}} db:migrate
+
+Category:
+   db
 `,
 		},
 		{
@@ -80,9 +140,20 @@ Usage:
 Usage:
    {{.}} lint [options]
 
+Aliases:
+   l
+
 Options:
    --fast     Only run fast linters
+              Cannot be used with: --slow
+   --slow     Cannot be used with: --fast
    --verbose  Run in verbose mode
+
+Examples:
+   tusk lint
+   Run only the fast linters, which skips
+   the slowest checks
+      tusk lint --fast
 `,
 		},
 		{
@@ -108,6 +179,7 @@ Arguments:
 Options:
        --bool-default-true        Boolean value (default: true)
    -b, --brief                    A brief flag
+                                  Environment: ENV_BRIEF
        --much-less-brief <value>  A much less brief flag
                                   which is multi-line
                                   One of: baz, qux
@@ -116,6 +188,7 @@ Options:
        --only-values <value>      One of: alice, bob, carol
        --option-without-usage
        --placeholder <val>        With a value named val
+       --tags <value>...          A tag to apply
        --usage-default <value>    This is the flag usage (default: 15.5)
        --values-default <value>   Default: alice
                                   One of: alice, bob, carol
@@ -193,6 +266,245 @@ exit status 5
 	g.Should(be.Equal(status, 5))
 }
 
+func Test_run_multipleTasks(t *testing.T) {
+	g := ghost.New(t)
+
+	stderr := new(bytes.Buffer)
+
+	args := []string{"tusk", "-f", "./testdata/tusk.yml", "exit", "0", "exit", "3", "exit", "0"}
+	status := run(
+		config{
+			args:   args,
+			stderr: stderr,
+		},
+	)
+
+	want := `Running: exit
+exit $ exit 0
+Running: exit
+exit $ exit 3
+exit status 3
+`
+
+	g.Should(be.Equal(stderr.String(), want))
+	g.Should(be.Equal(status, 3))
+}
+
+func Test_run_multipleTasks_subTasks(t *testing.T) {
+	cfgPath := filepath.Join(t.TempDir(), "tusk.yml")
+	ghost.New(t).NoError(os.WriteFile(cfgPath, []byte(`
+tasks:
+  clean:
+    run: echo clean
+  build:
+    run:
+      - task: clean
+      - echo build
+      - task: clean
+  other:
+    run:
+      - task: clean
+      - echo other
+`), 0o600))
+
+	tests := []struct {
+		name  string
+		tasks []string
+		want  string
+	}{
+		{
+			name:  "one task",
+			tasks: []string{"build"},
+			want:  "clean\nbuild\nclean\n",
+		},
+		{
+			name:  "several tasks",
+			tasks: []string{"build", "other"},
+			want:  "clean\nbuild\nclean\nother\n",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			g := ghost.New(t)
+
+			stdout := new(bytes.Buffer)
+			status := run(config{
+				args:   append([]string{"tusk", "-f", cfgPath}, tt.tasks...),
+				stdout: stdout,
+				stderr: new(bytes.Buffer),
+			})
+
+			g.Should(be.Equal(status, 0))
+			g.Should(be.Equal(stdout.String(), tt.want))
+		})
+	}
+}
+
+func Test_run_exitCodeNotConfirmed(t *testing.T) {
+	g := ghost.New(t)
+
+	stderr := new(bytes.Buffer)
+
+	args := []string{"tusk", "-f", "./testdata/tusk.yml", "deploy", "exit", "1"}
+	status := run(
+		config{
+			args:   args,
+			stderr: stderr,
+		},
+	)
+
+	want := `Running: deploy
+Info: Stopping, since the task was not confirmed
+`
+
+	g.Should(be.Equal(stderr.String(), want))
+	g.Should(be.Equal(status, 0))
+}
+
+func Test_run_logFile(t *testing.T) {
+	runWithLog := func(t *testing.T, logFile string, extra ...string) (stderr string, status int) {
+		t.Helper()
+
+		buf := new(bytes.Buffer)
+		args := append([]string{"tusk", "-f", "./testdata/tusk.yml", "--log-file", logFile}, extra...)
+		status = run(config{
+			args:   append(args, "exit", "5"),
+			stderr: buf,
+		})
+
+		return buf.String(), status
+	}
+
+	want := "exit $ exit 5\nexit status 5\n"
+
+	t.Run("truncate", func(t *testing.T) {
+		g := ghost.New(t)
+
+		logFile := filepath.Join(t.TempDir(), "tusk.log")
+		g.NoError(os.WriteFile(logFile, []byte("old contents\n"), 0o600))
+
+		stderr, status := runWithLog(t, logFile)
+		g.Should(be.Equal(stderr, want))
+		g.Should(be.Equal(status, 5))
+
+		got, err := os.ReadFile(logFile)
+		g.NoError(err)
+		g.Should(be.Equal(string(got), want))
+	})
+
+	t.Run("append", func(t *testing.T) {
+		g := ghost.New(t)
+
+		logFile := filepath.Join(t.TempDir(), "tusk.log")
+		g.NoError(os.WriteFile(logFile, []byte("old contents\n"), 0o600))
+
+		_, status := runWithLog(t, logFile, "--log-append")
+		g.Should(be.Equal(status, 5))
+
+		got, err := os.ReadFile(logFile)
+		g.NoError(err)
+		g.Should(be.Equal(string(got), "old contents\n"+want))
+	})
+
+	t.Run("cannot open", func(t *testing.T) {
+		g := ghost.New(t)
+
+		logFile := filepath.Join(t.TempDir(), "missing", "tusk.log")
+
+		stderr, status := runWithLog(t, logFile)
+		g.Should(be.Equal(status, 1))
+		g.Should(be.StringContaining(stderr, "Error: opening log file: "))
+	})
+
+	t.Run("no tasks run", func(t *testing.T) {
+		for _, flag := range []string{"--validate", "--dump-graph", "--help"} {
+			g := ghost.New(t)
+
+			logFile := filepath.Join(t.TempDir(), "tusk.log")
+			g.NoError(os.WriteFile(logFile, []byte("old contents\n"), 0o600))
+
+			status := run(config{
+				args:   []string{"tusk", "-f", "./testdata/tusk.yml", "--log-file", logFile, flag},
+				stdout: io.Discard,
+				stderr: io.Discard,
+			})
+			g.Should(be.Equal(status, 0))
+
+			got, err := os.ReadFile(logFile)
+			g.NoError(err)
+			g.Should(be.Equal(string(got), "old contents\n"))
+		}
+	})
+}
+
+func Test_run_summaryFile(t *testing.T) {
+	g := ghost.New(t)
+
+	summaryFile := filepath.Join(t.TempDir(), "summary.json")
+
+	status := run(config{
+		args: []string{
+			"tusk", "-f", "./testdata/tusk.yml", "--summary-file", summaryFile, "exit", "5",
+		},
+		stderr: new(bytes.Buffer),
+	})
+	g.Should(be.Equal(status, 5))
+
+	data, err := os.ReadFile(summaryFile)
+	g.NoError(err)
+
+	var summary struct {
+		ExitStatus int `json:"exit_status"`
+		Tasks      []struct {
+			Name     string `json:"name"`
+			Status   string `json:"status"`
+			Error    string `json:"error"`
+			Commands []struct {
+				Command  string `json:"command"`
+				ExitCode int    `json:"exit_code"`
+			} `json:"commands"`
+		} `json:"tasks"`
+	}
+	g.NoError(json.Unmarshal(data, &summary))
+
+	g.Should(be.Equal(summary.ExitStatus, 5))
+	g.Must(be.SliceLen(summary.Tasks, 1))
+	g.Should(be.Equal(summary.Tasks[0].Name, "exit"))
+	g.Should(be.Equal(summary.Tasks[0].Status, "failed"))
+	g.Should(be.Equal(summary.Tasks[0].Error, "exit status 5"))
+	g.Must(be.SliceLen(summary.Tasks[0].Commands, 1))
+	g.Should(be.Equal(summary.Tasks[0].Commands[0].Command, "exit 5"))
+	g.Should(be.Equal(summary.Tasks[0].Commands[0].ExitCode, 5))
+}
+
+func Test_run_summaryFile_invalidConfig(t *testing.T) {
+	g := ghost.New(t)
+
+	dir := t.TempDir()
+	cfgPath := filepath.Join(dir, "tusk.yml")
+	g.NoError(os.WriteFile(cfgPath, []byte("tasks: {foo: {unknown: true}}"), 0o600))
+	summaryFile := filepath.Join(dir, "summary.json")
+
+	status := run(config{
+		args:   []string{"tusk", "-f", cfgPath, "--summary-file", summaryFile, "foo"},
+		stderr: new(bytes.Buffer),
+	})
+	g.Should(be.Equal(status, 1))
+
+	data, err := os.ReadFile(summaryFile)
+	g.NoError(err)
+
+	var summary struct {
+		ExitStatus int   `json:"exit_status"`
+		Tasks      []any `json:"tasks"`
+	}
+	g.NoError(json.Unmarshal(data, &summary))
+
+	g.Should(be.Equal(summary.ExitStatus, 1))
+	g.Should(be.SliceLen(summary.Tasks, 0))
+}
+
 func Test_run_incorrect_usage(t *testing.T) {
 	g := ghost.New(t)
 
@@ -225,19 +537,56 @@ func Test_run_missing_config(t *testing.T) {
 		},
 	)
 
-	notFound := "no such file or directory"
-	if runtime.GOOS == "windows" {
-		notFound = "The system cannot find the file specified."
-	}
-
-	wantErr := `Error: reading config file "./testdata/does-not-exist.yml": ` +
-		fmt.Sprintf("open ./testdata/does-not-exist.yml: %s\n", notFound)
+	wantErr := `Error: config file "./testdata/does-not-exist.yml": file does not exist` + "\n"
 
 	g.Should(be.Zero(stdout.String()))
 	g.Should(be.Equal(stderr.String(), wantErr))
 	g.Should(be.Equal(status, 1))
 }
 
+func Test_run_validate(t *testing.T) {
+	t.Run("valid", func(t *testing.T) {
+		g := ghost.New(t)
+
+		stdout := new(bytes.Buffer)
+		stderr := new(bytes.Buffer)
+
+		args := []string{"tusk", "-f", "./testdata/tusk.yml", "--validate"}
+		status := run(config{
+			args:   args,
+			stdout: stdout,
+			stderr: stderr,
+		})
+
+		g.Should(be.Zero(stdout.String()))
+		g.Should(be.Zero(stderr.String()))
+		g.Should(be.Equal(status, 0))
+	})
+
+	t.Run("invalid", func(t *testing.T) {
+		g := ghost.New(t)
+
+		stdout := new(bytes.Buffer)
+		stderr := new(bytes.Buffer)
+
+		args := []string{"tusk", "-f", "./testdata/invalid.yml", "--validate"}
+		status := run(config{
+			args:   args,
+			stdout: stdout,
+			stderr: stderr,
+		})
+
+		wantErr := `Error: invalid config file
+ => invalid.yml:2: task "one": ${typo} does not refer to an arg or option
+ => invalid.yml:4: task "two": sub-task "fake" is not defined
+`
+
+		g.Should(be.Zero(stdout.String()))
+		g.Should(be.Equal(stderr.String(), wantErr))
+		g.Should(be.Equal(status, 1))
+	})
+}
+
 func Test_run_completion(t *testing.T) {
 	t.Run("unknown task", func(t *testing.T) {
 		g := ghost.New(t)
@@ -288,13 +637,40 @@ func Test_run_completion(t *testing.T) {
 --clean-cache:Delete all cached files
 --clean-project-cache:Delete cached files related to the current config file
 --clean-task-cache:Delete cached files related to the given task
+--color:Set when to color output (one of: auto, always, never)
+--completion:Print the tab completion script for a shell (one of: bash, fish, zsh)
+--dump-graph:Print the sub-tasks run by each task as a Graphviz DOT graph and exit
+--explain:Explain why the task would or would not run, without running it
+--force:Run tasks even if their targets are up to date
+--graceful-interrupt:On interrupt, stop tasks and run their finally clauses until interrupted again
 --help:Show help and exit
+--init:Create a starter config file in the current directory and exit
 --install-completion:Install tab completion for a shell (one of: bash, fish, zsh)
+--keep-going:Keep running the remaining run items of a task after one fails
+--log-append:Append to the log file instead of overwriting it
+--log-file:Copy all output to file, without colors
+--max-task-depth:Fail if sub-tasks are nested more than n deep (default: 50)
+--no-user-config:Ignore the tasks in the user-level config file
+--offline:Use cached copies of remote included files without fetching them
+--only:Run only the run items of the task with the given name
+--step:Run only the run items of the task with the given name
+--output:Print output in the given format (one of: human, json)
+--overwrite:Overwrite an existing config file with --init
+--prefix-output:Prefix each line of command output with the task name
+--print-schema:Print the JSON schema for config files and exit
+--profile:Print the time taken by each task and command after running
 --quiet:Only print command output and application errors
 --silent:Print no output
+--since:Only hash source files changed since ref, a git ref or time
+--skip:Skip the run items of the task with the given name
+--summary-file:Write a summary of the tasks and commands run to file
+--summary-format:Write the summary file in the given format (one of: json, junit)
 --uninstall-completion:Uninstall tab completion for a shell (one of: bash, fish, zsh)
+--use-profile:Use the option defaults of the config profile with the given name
 --version:Print version and exit
 --verbose:Print verbose output
+--validate:Check the config file for problems and exit
+--yes:Confirm tasks that ask for confirmation without prompting
 `))
 		g.Should(be.Zero(stderr.String()))
 	})
@@ -323,13 +699,40 @@ func Test_run_completion(t *testing.T) {
 --clean-cache:Delete all cached files
 --clean-project-cache:Delete cached files related to the current config file
 --clean-task-cache:Delete cached files related to the given task
+--color:Set when to color output (one of: auto, always, never)
+--completion:Print the tab completion script for a shell (one of: bash, fish, zsh)
+--dump-graph:Print the sub-tasks run by each task as a Graphviz DOT graph and exit
+--explain:Explain why the task would or would not run, without running it
+--force:Run tasks even if their targets are up to date
+--graceful-interrupt:On interrupt, stop tasks and run their finally clauses until interrupted again
 --help:Show help and exit
+--init:Create a starter config file in the current directory and exit
 --install-completion:Install tab completion for a shell (one of: bash, fish, zsh)
+--keep-going:Keep running the remaining run items of a task after one fails
+--log-append:Append to the log file instead of overwriting it
+--log-file:Copy all output to file, without colors
+--max-task-depth:Fail if sub-tasks are nested more than n deep (default: 50)
+--no-user-config:Ignore the tasks in the user-level config file
+--offline:Use cached copies of remote included files without fetching them
+--only:Run only the run items of the task with the given name
+--step:Run only the run items of the task with the given name
+--output:Print output in the given format (one of: human, json)
+--overwrite:Overwrite an existing config file with --init
+--prefix-output:Prefix each line of command output with the task name
+--print-schema:Print the JSON schema for config files and exit
+--profile:Print the time taken by each task and command after running
 --quiet:Only print command output and application errors
 --silent:Print no output
+--since:Only hash source files changed since ref, a git ref or time
+--skip:Skip the run items of the task with the given name
+--summary-file:Write a summary of the tasks and commands run to file
+--summary-format:Write the summary file in the given format (one of: json, junit)
 --uninstall-completion:Uninstall tab completion for a shell (one of: bash, fish, zsh)
+--use-profile:Use the option defaults of the config profile with the given name
 --version:Print version and exit
 --verbose:Print verbose output
+--validate:Check the config file for problems and exit
+--yes:Confirm tasks that ask for confirmation without prompting
 `))
 		g.Should(be.Zero(stderr.String()))
 	})
@@ -0,0 +1,163 @@
+package marshal
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+	"regexp"
+	"strings"
+
+	yaml "gopkg.in/yaml.v3"
+)
+
+// envPrefix starts a reference to an environment variable, such as
+// ${env:HOME} or ${env:PORT:-8080}.
+var envPrefix = []byte("${env:")
+
+// envPattern matches a single well-formed reference to an environment variable
+// at the start of text, capturing the variable name and the default, if any.
+var envPattern = regexp.MustCompile(`^\$\{env:([A-Za-z_][A-Za-z0-9_]*)(?:(:-)([^}]*))?\}`)
+
+// envReference is a single reference to an environment variable found in text.
+type envReference struct {
+	name       string
+	fallback   string
+	hasDefault bool
+}
+
+// value returns the value of the environment variable, or the default if the
+// variable is unset or empty.
+func (r envReference) value() string {
+	if value := os.Getenv(r.name); value != "" || !r.hasDefault {
+		return value
+	}
+
+	return r.fallback
+}
+
+// interpolateEnv replaces references to environment variables in a YAML
+// document with their values. A malformed reference is an error.
+//
+// References are replaced within each scalar of the document, which is then
+// encoded again, so that values are quoted as they need to be. Any "$" in a
+// value is escaped, so that the value is not interpolated again.
+func interpolateEnv(text []byte) ([]byte, error) {
+	if !bytes.Contains(text, envPrefix) {
+		return text, nil
+	}
+
+	var doc yaml.Node
+	if err := yaml.Unmarshal(text, &doc); err != nil {
+		return nil, err
+	}
+
+	changed, err := interpolateEnvNode(&doc)
+	if err != nil || !changed {
+		return text, err
+	}
+
+	return yaml.Marshal(&doc)
+}
+
+// interpolateEnvNode replaces references to environment variables within each
+// scalar of a node, reporting whether any were found.
+func interpolateEnvNode(node *yaml.Node) (bool, error) {
+	if node.Kind == yaml.ScalarNode {
+		value, err := interpolateEnvValue(node.Value)
+		if err != nil || value == node.Value {
+			return false, err
+		}
+
+		// The original style may not be able to hold the new value.
+		node.Value = value
+		node.Style = 0
+		return true, nil
+	}
+
+	changed := false
+	for _, child := range node.Content {
+		c, err := interpolateEnvNode(child)
+		if err != nil {
+			return false, err
+		}
+		changed = changed || c
+	}
+
+	return changed, nil
+}
+
+// interpolateEnvValue replaces references to environment variables in a single
+// value, escaping any "$" that the variables contain.
+func interpolateEnvValue(value string) (string, error) {
+	text := escapePattern([]byte(value))
+
+	var out bytes.Buffer
+	for {
+		i := bytes.Index(text, envPrefix)
+		if i < 0 {
+			break
+		}
+
+		ref, n, err := parseEnvReference(text[i:])
+		if err != nil {
+			return "", err
+		}
+
+		out.Write(text[:i])
+		out.WriteString(strings.ReplaceAll(ref.value(), "$", "$$"))
+		text = text[i+n:]
+	}
+	out.Write(text)
+
+	return string(unescapePattern(out.Bytes())), nil
+}
+
+// CheckEnv returns an error for the first malformed reference to an
+// environment variable in the text.
+func CheckEnv(text []byte) error {
+	text = escapePattern(text)
+	for {
+		i := bytes.Index(text, envPrefix)
+		if i < 0 {
+			return nil
+		}
+
+		_, n, err := parseEnvReference(text[i:])
+		if err != nil {
+			return err
+		}
+		text = text[i+n:]
+	}
+}
+
+// parseEnvReference parses the reference to an environment variable at the
+// start of text, returning it along with its length.
+func parseEnvReference(text []byte) (envReference, int, error) {
+	m := envPattern.FindSubmatch(text)
+	if m == nil {
+		return envReference{}, 0, fmt.Errorf(
+			"invalid environment variable reference %s: must be ${env:NAME} or ${env:NAME:-default}",
+			envSnippet(text),
+		)
+	}
+
+	return envReference{
+		name:       string(m[1]),
+		fallback:   string(m[3]),
+		hasDefault: len(m[2]) > 0,
+	}, len(m[0]), nil
+}
+
+// envSnippet returns the malformed reference at the start of text, up to and
+// including the closing brace, or to the end of the line if there is none.
+func envSnippet(text []byte) string {
+	end := bytes.IndexAny(text, "}\n")
+	switch {
+	case end < 0:
+		return string(text)
+	case text[end] == '}':
+		return string(text[:end+1])
+	default:
+		return string(text[:end])
+	}
+}
@@ -0,0 +1,136 @@
+package marshal
+
+import (
+	"testing"
+
+	"github.com/rliebz/ghost"
+	"github.com/rliebz/ghost/be"
+)
+
+func TestInterpolate_env(t *testing.T) {
+	t.Setenv("TUSK_TEST_SET", "set")
+	t.Setenv("TUSK_TEST_EMPTY", "")
+	t.Setenv("TUSK_TEST_DOLLARS", "a$$b ${name}")
+	t.Setenv("TUSK_TEST_LINES", "one\ntwo: three")
+
+	tests := []struct {
+		name    string
+		input   string
+		want    string
+		wantErr string
+	}{
+		{
+			name:  "set",
+			input: "${env:TUSK_TEST_SET}",
+			want:  "set",
+		},
+		{
+			name:  "unset",
+			input: "value: '${env:TUSK_TEST_UNSET}'",
+			want:  "value: ''",
+		},
+		{
+			name:  "default when set",
+			input: "${env:TUSK_TEST_SET:-fallback}",
+			want:  "set",
+		},
+		{
+			name:  "default when unset",
+			input: "${env:TUSK_TEST_UNSET:-fallback value}",
+			want:  "fallback value",
+		},
+		{
+			name:  "default when empty",
+			input: "${env:TUSK_TEST_EMPTY:-fallback}",
+			want:  "fallback",
+		},
+		{
+			name:  "empty default",
+			input: "a${env:TUSK_TEST_UNSET:-}b",
+			want:  "ab",
+		},
+		{
+			name:  "several",
+			input: "${env:TUSK_TEST_SET}-${name}-${env:TUSK_TEST_UNSET:-x}",
+			want:  "set-foo-x",
+		},
+		{
+			name:  "escaped",
+			input: "$${env:TUSK_TEST_SET}",
+			want:  "${env:TUSK_TEST_SET}",
+		},
+		{
+			name:  "value not interpolated",
+			input: "[${env:TUSK_TEST_DOLLARS}]",
+			want:  "[a$$b ${name}]",
+		},
+		{
+			name:  "value with newline",
+			input: "echo ${env:TUSK_TEST_LINES}",
+			want:  "echo one\ntwo: three",
+		},
+		{
+			name:    "missing name",
+			input:   "${env:}",
+			wantErr: "invalid environment variable reference ${env:}",
+		},
+		{
+			name:    "invalid name",
+			input:   "${env:1FOO}",
+			wantErr: "invalid environment variable reference ${env:1FOO}",
+		},
+		{
+			name:    "missing dash",
+			input:   "${env:FOO:bar}",
+			wantErr: "invalid environment variable reference ${env:FOO:bar}",
+		},
+		{
+			name:    "unterminated",
+			input:   "echo ${env:FOO",
+			wantErr: "invalid environment variable reference ${env:FOO",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			g := ghost.New(t)
+
+			input := tt.input
+			err := Interpolate(&input, map[string]string{"name": "foo"})
+			if tt.wantErr != "" {
+				g.Should(be.ErrorContaining(err, tt.wantErr))
+				return
+			}
+			g.NoError(err)
+
+			g.Should(be.Equal(input, tt.want))
+		})
+	}
+}
+
+func TestInterpolate_env_structure(t *testing.T) {
+	g := ghost.New(t)
+
+	t.Setenv("TUSK_TEST_LINES", "one\ntwo: three")
+
+	type item struct {
+		Run  string `yaml:"run"`
+		Next string `yaml:"next"`
+	}
+	input := item{Run: "echo ${env:TUSK_TEST_LINES}", Next: "${name}"}
+	err := Interpolate(&input, map[string]string{"name": "foo"})
+	g.NoError(err)
+
+	g.Should(be.Equal(input, item{Run: "echo one\ntwo: three", Next: "foo"}))
+}
+
+func TestCheckEnv(t *testing.T) {
+	g := ghost.New(t)
+
+	g.Should(be.Nil(CheckEnv([]byte("${env:FOO} ${env:BAR:-baz} $${env:}"))))
+	g.Should(be.ErrorEqual(
+		CheckEnv([]byte("${env:FOO} ${env:BAR:baz}")),
+		"invalid environment variable reference ${env:BAR:baz}: "+
+			"must be ${env:NAME} or ${env:NAME:-default}",
+	))
+}
@@ -0,0 +1,151 @@
+package marshal
+
+import (
+	"bytes"
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// function is a function that can be called during interpolation. The first
+// argument is always the value of a variable, followed by a fixed number of
+// string literals.
+type function struct {
+	args int
+	call func(value string, args []string) string
+}
+
+// functions are the functions available during interpolation by name.
+var functions = map[string]function{
+	"upper": {
+		call: func(value string, _ []string) string { return strings.ToUpper(value) },
+	},
+	"lower": {
+		call: func(value string, _ []string) string { return strings.ToLower(value) },
+	},
+	"default": {
+		args: 1,
+		call: func(value string, args []string) string {
+			if value == "" {
+				return args[0]
+			}
+			return value
+		},
+	},
+	"replace": {
+		args: 2,
+		call: func(value string, args []string) string {
+			return strings.ReplaceAll(value, args[0], args[1])
+		},
+	},
+}
+
+// callPattern matches a function call such as ${replace(name, "a", "b")},
+// capturing the function name, the variable name, and the remaining arguments.
+var callPattern = regexp.MustCompile(
+	`\$\{([\w-]+)\(\s*([\w.-]+)((?:\s*,\s*"(?:[^"\\]|\\.)*")*)\s*\)\}`,
+)
+
+// literalPattern matches a single string literal argument.
+var literalPattern = regexp.MustCompile(`"(?:[^"\\]|\\.)*"`)
+
+// interpolateFunctions replaces function calls on variables with known values.
+// Calls on unknown variables are left in place to be interpolated later, but
+// calls to unknown functions are always an error.
+func interpolateFunctions(text []byte, values map[string]string) ([]byte, error) {
+	text = escapePattern(text)
+
+	var out bytes.Buffer
+	last := 0
+	for _, m := range callPattern.FindAllSubmatchIndex(text, -1) {
+		call := parseCall(text, m)
+		result, ok, err := call.evaluate(values)
+		if err != nil {
+			return nil, err
+		}
+		if !ok {
+			continue
+		}
+
+		out.Write(text[last:m[0]])
+		out.WriteString(result)
+		last = m[1]
+	}
+	out.Write(text[last:])
+
+	return unescapePattern(out.Bytes()), nil
+}
+
+// CheckFunctions returns an error for the first function call in the text that
+// cannot be evaluated, such as a call to an unknown function.
+func CheckFunctions(text []byte) error {
+	text = escapePattern(text)
+	for _, m := range callPattern.FindAllSubmatchIndex(text, -1) {
+		call := parseCall(text, m)
+		if _, err := call.lookup(); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// functionCall is a single call to a function found in text.
+type functionCall struct {
+	text     string
+	name     string
+	variable string
+	args     []string
+}
+
+func parseCall(text []byte, m []int) functionCall {
+	return functionCall{
+		text:     string(text[m[0]:m[1]]),
+		name:     string(text[m[2]:m[3]]),
+		variable: string(text[m[4]:m[5]]),
+		args:     literalPattern.FindAllString(string(text[m[6]:m[7]]), -1),
+	}
+}
+
+// lookup returns the function being called, ensuring it is called correctly.
+func (c functionCall) lookup() (function, error) {
+	f, ok := functions[c.name]
+	if !ok {
+		return function{}, fmt.Errorf("unknown function %q in %s", c.name, c.text)
+	}
+
+	if len(c.args) != f.args {
+		return function{}, fmt.Errorf(
+			"function %q takes %d arguments but got %d in %s",
+			c.name, f.args+1, len(c.args)+1, c.text,
+		)
+	}
+
+	return f, nil
+}
+
+// evaluate returns the result of the function call. If the variable does not
+// have a value yet, false is returned.
+func (c functionCall) evaluate(values map[string]string) (string, bool, error) {
+	f, err := c.lookup()
+	if err != nil {
+		return "", false, err
+	}
+
+	value, ok := values[c.variable]
+	if !ok {
+		return "", false, nil
+	}
+
+	args := make([]string, 0, len(c.args))
+	for _, arg := range c.args {
+		unquoted, err := strconv.Unquote(arg)
+		if err != nil {
+			return "", false, fmt.Errorf("invalid argument %s in %s: %w", arg, c.text, err)
+		}
+		args = append(args, unquoted)
+	}
+
+	return f.call(value, args), true, nil
+}
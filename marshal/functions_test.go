@@ -0,0 +1,82 @@
+package marshal
+
+import (
+	"testing"
+
+	"github.com/rliebz/ghost"
+	"github.com/rliebz/ghost/be"
+)
+
+func TestInterpolateFunctions(t *testing.T) {
+	vars := map[string]string{"name": "Foo", "path": "a/b/c", "empty": "", "a.b": "dotted"}
+
+	tests := []struct {
+		input   string
+		want    string
+		wantErr string
+	}{
+		{input: "${upper(name)}", want: "FOO"},
+		{input: "${lower(name)}", want: "foo"},
+		{input: `${default(empty, "latest")}`, want: "latest"},
+		{input: `${default(name, "latest")}`, want: "Foo"},
+		{input: `${replace(path, "/", "_")}`, want: "a_b_c"},
+		{input: `${replace( path ,"/","\"")}`, want: `a"b"c`},
+		{input: "${upper(name)}-${lower(name)}", want: "FOO-foo"},
+		{input: "${upper(a.b)}", want: "DOTTED"},
+		{input: "${upper(other)}", want: "${upper(other)}"},
+		{input: "$${upper(name)}", want: "$${upper(name)}"},
+		{input: "${name}", want: "${name}"},
+		{
+			input:   "${reverse(name)}",
+			wantErr: `unknown function "reverse" in ${reverse(name)}`,
+		},
+		{
+			input:   `${upper(name, "x")}`,
+			wantErr: `function "upper" takes 1 arguments but got 2 in ${upper(name, "x")}`,
+		},
+		{
+			input:   "${default(name)}",
+			wantErr: `function "default" takes 2 arguments but got 1 in ${default(name)}`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.input, func(t *testing.T) {
+			g := ghost.New(t)
+
+			got, err := interpolateFunctions([]byte(tt.input), vars)
+			if tt.wantErr != "" {
+				g.Should(be.ErrorEqual(err, tt.wantErr))
+				return
+			}
+			g.NoError(err)
+
+			g.Should(be.Equal(string(got), tt.want))
+		})
+	}
+}
+
+func TestInterpolate_functions(t *testing.T) {
+	g := ghost.New(t)
+
+	values := map[string]string{"name": "foo", "tag": ""}
+
+	input := []string{`docker build -t ${upper(name)}:${default(tag, "latest")}`, "${name}"}
+	want := []string{"docker build -t FOO:latest", "foo"}
+
+	err := Interpolate(&input, values)
+	g.NoError(err)
+
+	g.Should(be.DeepEqual(input, want))
+}
+
+func TestCheckFunctions(t *testing.T) {
+	g := ghost.New(t)
+
+	g.NoError(CheckFunctions([]byte(`${upper(a)} ${replace(b, "x", "y")}`)))
+	g.NoError(CheckFunctions([]byte(`$${unknown(a)}`)))
+	g.Should(be.ErrorEqual(
+		CheckFunctions([]byte(`${upper(a)} ${unknown(b)}`)),
+		`unknown function "unknown" in ${unknown(b)}`,
+	))
+}
@@ -17,6 +17,16 @@ func Interpolate(i any, values map[string]string) error {
 		return err
 	}
 
+	text, err = interpolateEnv(text)
+	if err != nil {
+		return err
+	}
+
+	text, err = interpolateFunctions(text, values)
+	if err != nil {
+		return err
+	}
+
 	text, err = mapInterpolate(text, values)
 	if err != nil {
 		return err
@@ -27,15 +37,17 @@ func Interpolate(i any, values map[string]string) error {
 	return yaml.UnmarshalStrict(text, i)
 }
 
-// FindPotentialVariables returns a list of potential interpolation target names.
+// FindPotentialVariables returns a list of potential interpolation target names,
+// including the names of variables passed to functions. Names may contain dots,
+// as built-in variables such as tusk.dir do.
 func FindPotentialVariables(text []byte) []string {
-	re := regexp.MustCompile(`\${([\w-]+)}`)
+	re := regexp.MustCompile(`\${(?:([\w.-]+)}|[\w-]+\(\s*([\w.-]+))`)
 
 	groups := re.FindAllStringSubmatch(string(text), -1)
 
 	names := make([]string, 0, len(groups))
 	for _, group := range groups {
-		names = append(names, group[1])
+		names = append(names, group[1]+group[2])
 	}
 
 	return names
@@ -75,7 +87,7 @@ func mapInterpolate(text []byte, m map[string]string) ([]byte, error) {
 
 // compile returns the regexp pattern for a given variable name.
 func compile(name string) (*regexp.Regexp, error) {
-	pattern := fmt.Sprintf(`\$({%s})`, name)
+	pattern := fmt.Sprintf(`\$({%s})`, regexp.QuoteMeta(name))
 	return regexp.Compile(pattern)
 }
 
@@ -77,13 +77,15 @@ func TestEscape(t *testing.T) {
 }
 
 func TestMap(t *testing.T) {
-	vars := map[string]string{"foo": "bar"}
+	vars := map[string]string{"foo": "bar", "a.b": "dotted"}
 
 	tests := []struct {
 		input string
 		want  string
 	}{
 		{"${foo}", "bar"},
+		{"${a.b}", "dotted"},
+		{"${aXb}", "${aXb}"},
 		{"foo", "foo"},
 		{"$foo", "$foo"},
 		{"${foo}${foo}", "barbar"},
@@ -121,6 +123,9 @@ func TestFindPotentialVariables(t *testing.T) {
 		{"${foo}${bar}", []string{"foo", "bar"}},
 		{"${foo}${FOO}", []string{"foo", "FOO"}},
 		{"_-${foo}.  ${bar} baz", []string{"foo", "bar"}},
+		{"${upper(foo)}", []string{"foo"}},
+		{`${foo} ${replace( bar, "a", "b")}`, []string{"foo", "bar"}},
+		{"${tusk.dir} ${upper(tusk.config)}", []string{"tusk.dir", "tusk.config"}},
 	}
 
 	for _, tt := range tests {
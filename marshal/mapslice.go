@@ -1,7 +1,10 @@
 package marshal
 
 import (
+	"cmp"
 	"fmt"
+	"maps"
+	"slices"
 
 	yaml "gopkg.in/yaml.v2"
 )
@@ -16,10 +19,10 @@ func ParseOrderedMap(
 	ordered := make([]string, 0, len(ms))
 
 	for _, itemMS := range ms {
-		name, ok := itemMS.Key.(string)
-		if !ok {
-			return nil, fmt.Errorf("%q is not a valid key name", itemMS.Key)
+		if !isScalar(itemMS.Key) {
+			return nil, fmt.Errorf("%v is not a valid key name", itemMS.Key)
 		}
+		name := fmt.Sprint(itemMS.Key)
 		ordered = append(ordered, name)
 
 		text, err := yaml.Marshal(itemMS.Value)
@@ -34,3 +37,103 @@ func ParseOrderedMap(
 
 	return ordered, nil
 }
+
+// UnmarshalOrderedMap unmarshals a mapping in order, calling assign with each
+// key and a function that unmarshals its value.
+//
+// Unlike unmarshaling into a yaml.MapSlice, keys brought in by merge keys
+// (`<<`) are kept, and each value is unmarshaled from the original document so
+// that merge keys within it are resolved as well. Since the original order of
+// merged keys is not known, they come first, sorted by name.
+func UnmarshalOrderedMap(
+	unmarshal func(any) error,
+	assign func(key string, unmarshal func(any) error) error,
+) error {
+	var ms yaml.MapSlice
+	if err := unmarshal(&ms); err != nil {
+		return err
+	}
+
+	for _, item := range ms {
+		if !isScalar(item.Key) {
+			return fmt.Errorf("%v is not a valid key name", item.Key)
+		}
+	}
+
+	var values map[string]deferredValue
+	if err := unmarshal(&values); err != nil {
+		return err
+	}
+
+	names := slices.Sorted(maps.Keys(values))
+	explicit := make([]string, 0, len(ms))
+	for _, item := range ms {
+		name := keyName(item.Key, names, explicit)
+		explicit = append(explicit, name)
+	}
+
+	merged := slices.DeleteFunc(slices.Collect(maps.Keys(values)), func(name string) bool {
+		return slices.Contains(explicit, name)
+	})
+	slices.SortFunc(merged, cmp.Compare)
+
+	for _, name := range slices.Concat(merged, explicit) {
+		if err := assign(name, values[name].unmarshalFunc()); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// isScalar reports whether a key is a scalar value, which can be used as a name.
+func isScalar(key any) bool {
+	switch key.(type) {
+	case nil, string, bool, int, int64, uint64, float64:
+		return true
+	default:
+		return false
+	}
+}
+
+// keyName returns the name of a key as it was written. Keys such as `yes` or
+// `1` are read by YAML as a bool or number, so they are matched with the first
+// name not already used that is read as the same value.
+func keyName(key any, names, used []string) string {
+	if name, ok := key.(string); ok {
+		return name
+	}
+
+	for _, name := range names {
+		if slices.Contains(used, name) {
+			continue
+		}
+
+		var value any
+		if err := yaml.Unmarshal([]byte(name), &value); err == nil && value == key {
+			return name
+		}
+	}
+
+	return fmt.Sprint(key)
+}
+
+// deferredValue holds on to a value so that it can be unmarshaled later.
+type deferredValue struct {
+	unmarshal func(any) error
+}
+
+// UnmarshalYAML saves the unmarshal function for later.
+func (v *deferredValue) UnmarshalYAML(unmarshal func(any) error) error {
+	v.unmarshal = unmarshal
+	return nil
+}
+
+// unmarshalFunc returns the function that unmarshals the value. Null values
+// are never passed to an unmarshaler, so they leave the target unchanged.
+func (v deferredValue) unmarshalFunc() func(any) error {
+	if v.unmarshal == nil {
+		return func(any) error { return nil }
+	}
+	return v.unmarshal
+}
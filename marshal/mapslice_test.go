@@ -75,5 +75,81 @@ func TestParseOrderedMap_validates_key(t *testing.T) {
 	}
 
 	_, err := ParseOrderedMap(ms, assign)
-	g.Should(be.ErrorEqual(err, `["foo" "bar"] is not a valid key name`))
+	g.Should(be.ErrorEqual(err, `[foo bar] is not a valid key name`))
+}
+
+func TestUnmarshalOrderedMap(t *testing.T) {
+	g := ghost.New(t)
+
+	text := `
+base: &base {a: 1, b: 2}
+items:
+  <<: {merged: {<<: *base}}
+  second: {<<: *base, b: 3}
+  first: ~
+`
+
+	var doc struct {
+		Base  pair         `yaml:"base"`
+		Items orderedPairs `yaml:"items"`
+	}
+	g.NoError(yaml.UnmarshalStrict([]byte(text), &doc))
+
+	g.Should(be.DeepEqual(doc.Items, orderedPairs{
+		{Key: "merged", Value: pair{A: 1, B: 2}},
+		{Key: "second", Value: pair{A: 1, B: 3}},
+		{Key: "first"},
+	}))
+}
+
+func TestUnmarshalOrderedMap_scalar_keys(t *testing.T) {
+	g := ghost.New(t)
+
+	text := `
+yes: {a: 1}
+1: {a: 2}
+off: {a: 3}
+`
+
+	var items orderedPairs
+	g.NoError(yaml.UnmarshalStrict([]byte(text), &items))
+
+	g.Should(be.DeepEqual(items, orderedPairs{
+		{Key: "yes", Value: pair{A: 1}},
+		{Key: "1", Value: pair{A: 2}},
+		{Key: "off", Value: pair{A: 3}},
+	}))
+}
+
+func TestUnmarshalOrderedMap_invalid_key(t *testing.T) {
+	g := ghost.New(t)
+
+	var items orderedPairs
+	err := yaml.UnmarshalStrict([]byte("[a, b]: {a: 1}"), &items)
+	g.Should(be.ErrorEqual(err, `[a b] is not a valid key name`))
+}
+
+type pair struct {
+	A int `yaml:"a"`
+	B int `yaml:"b"`
+}
+
+type orderedPairs []struct {
+	Key   string
+	Value pair
+}
+
+func (o *orderedPairs) UnmarshalYAML(unmarshal func(any) error) error {
+	return UnmarshalOrderedMap(unmarshal, func(key string, unmarshal func(any) error) error {
+		var value pair
+		if err := unmarshal(&value); err != nil {
+			return err
+		}
+
+		*o = append(*o, struct {
+			Key   string
+			Value pair
+		}{key, value})
+		return nil
+	})
 }
@@ -2,8 +2,8 @@ package runner
 
 import (
 	"errors"
-
-	yaml "gopkg.in/yaml.v2"
+	"fmt"
+	"strings"
 
 	"github.com/rliebz/tusk/marshal"
 )
@@ -11,6 +11,10 @@ import (
 // Arg represents a command-line argument.
 type Arg struct {
 	Passable `yaml:",inline"`
+
+	// Default is the value used when the arg is not passed. Only trailing args
+	// can have defaults.
+	Default *string `yaml:"default,omitempty"`
 }
 
 // Evaluate determines an argument's value.
@@ -31,12 +35,7 @@ type Args []*Arg
 
 // UnmarshalYAML unmarshals an ordered set of options and assigns names.
 func (a *Args) UnmarshalYAML(unmarshal func(any) error) error {
-	var ms yaml.MapSlice
-	if err := unmarshal(&ms); err != nil {
-		return err
-	}
-
-	args, err := getArgsWithOrder(ms)
+	args, err := getArgsWithOrder(unmarshal)
 	if err != nil {
 		return err
 	}
@@ -46,6 +45,52 @@ func (a *Args) UnmarshalYAML(unmarshal func(any) error) error {
 	return nil
 }
 
+// Required returns the number of args that must be passed, which excludes the
+// trailing args that have defaults.
+func (a Args) Required() int {
+	for i, arg := range a {
+		if arg.Default != nil {
+			return i
+		}
+	}
+	return len(a)
+}
+
+// accepts returns whether n args can be passed.
+func (a Args) accepts(n int) bool {
+	return n >= a.Required() && n <= len(a)
+}
+
+// ValidateArgCount checks that the number of args passed to a task is between the
+// number required and the total number of args.
+func (t *Task) ValidateArgCount(n int) error {
+	if t.Args.accepts(n) {
+		return nil
+	}
+
+	if t.Args.Required() == len(t.Args) {
+		return fmt.Errorf("task %q requires exactly %d args, got %d", t.Name, len(t.Args), n)
+	}
+
+	return fmt.Errorf(
+		"task %q requires between %d and %d args, got %d",
+		t.Name, t.Args.Required(), len(t.Args), n,
+	)
+}
+
+// subTaskArgCountError returns the error for a sub-task passed the wrong
+// number of args.
+func subTaskArgCountError(sub *Task, n int) error {
+	if sub.Args.Required() == len(sub.Args) {
+		return fmt.Errorf("subtask %q requires %d args but got %d", sub.Name, len(sub.Args), n)
+	}
+
+	return fmt.Errorf(
+		"subtask %q requires between %d and %d args but got %d",
+		sub.Name, sub.Args.Required(), len(sub.Args), n,
+	)
+}
+
 // Lookup finds an Arg by name.
 func (a *Args) Lookup(name string) (*Arg, bool) {
 	for _, arg := range *a {
@@ -61,13 +106,27 @@ func (a *Arg) validatePassed(value string) error {
 	return a.Passable.validatePassed("argument", value)
 }
 
-// getArgsWithOrder returns both the arg map and the ordered names.
-func getArgsWithOrder(ms yaml.MapSlice) ([]*Arg, error) {
-	args := make([]*Arg, 0, len(ms))
-	assign := func(name string, text []byte) error {
+// getArgsWithOrder returns the args in the order they are defined.
+func getArgsWithOrder(unmarshal func(any) error) ([]*Arg, error) {
+	var args []*Arg
+	assign := func(name string, unmarshal func(any) error) error {
+		if err := validateVarName("arg", name); err != nil {
+			return withKeys(err, "args", name)
+		}
+
 		var arg Arg
-		if err := yaml.UnmarshalStrict(text, &arg); err != nil {
-			return err
+		if err := unmarshal(&arg); err != nil {
+			return entryError(err, "arg", "args", name)
+		}
+
+		if arg.isList() || arg.isCount() {
+			return withKeys(
+				fmt.Errorf(
+					"arg %q: %s values are only supported for options",
+					name, strings.ToLower(arg.Type),
+				),
+				"args", name,
+			)
 		}
 
 		arg.Name = name
@@ -77,6 +136,18 @@ func getArgsWithOrder(ms yaml.MapSlice) ([]*Arg, error) {
 		return nil
 	}
 
-	_, err := marshal.ParseOrderedMap(ms, assign)
-	return args, err
+	if err := marshal.UnmarshalOrderedMap(unmarshal, assign); err != nil {
+		return nil, err
+	}
+
+	for i, arg := range args {
+		if arg.Default == nil && i > Args(args).Required() {
+			return nil, fmt.Errorf(
+				"arg %q must have a default, since it follows an arg with a default",
+				arg.Name,
+			)
+		}
+	}
+
+	return args, nil
 }
@@ -70,26 +70,11 @@ func TestEvaluate_nil(t *testing.T) {
 func TestGetArgsWithOrder(t *testing.T) {
 	g := ghost.New(t)
 
-	ms := yaml.MapSlice{
-		{
-			Key: "foo",
-			Value: &Arg{
-				Passable: Passable{
-					Usage: "first usage",
-				},
-			},
-		},
-		{
-			Key: "bar",
-			Value: &Arg{
-				Passable: Passable{
-					Usage: "other usage",
-				},
-			},
-		},
-	}
-
-	args, err := getArgsWithOrder(ms)
+	var args Args
+	err := yaml.UnmarshalStrict(
+		[]byte("{foo: {usage: first usage}, bar: {usage: other usage}}"),
+		&args,
+	)
 	g.NoError(err)
 
 	g.Must(be.SliceLen(args, 2))
@@ -103,10 +88,23 @@ func TestGetArgsWithOrder(t *testing.T) {
 func TestGetArgsWithOrder_invalid(t *testing.T) {
 	g := ghost.New(t)
 
-	ms := yaml.MapSlice{
-		{Key: "foo", Value: "not an arg"},
-	}
-
-	_, err := getArgsWithOrder(ms)
+	var args Args
+	err := yaml.UnmarshalStrict([]byte("foo: not an arg"), &args)
 	g.Should(be.ErrorContaining(err, "cannot unmarshal !!str `not an arg` into runner.Arg"))
 }
+
+func TestGetArgsWithOrder_list(t *testing.T) {
+	g := ghost.New(t)
+
+	var args Args
+	err := yaml.UnmarshalStrict([]byte("foo: {type: list}"), &args)
+	g.Should(be.ErrorEqual(err, `arg "foo": list values are only supported for options`))
+}
+
+func TestGetArgsWithOrder_count(t *testing.T) {
+	g := ghost.New(t)
+
+	var args Args
+	err := yaml.UnmarshalStrict([]byte("foo: {type: count}"), &args)
+	g.Should(be.ErrorEqual(err, `arg "foo": count values are only supported for options`))
+}
@@ -0,0 +1,96 @@
+package runner
+
+import (
+	"errors"
+	"regexp"
+	"strings"
+
+	yaml "gopkg.in/yaml.v2"
+)
+
+// shellSafe matches arguments that a POSIX shell reads as-is without quotes.
+var shellSafe = regexp.MustCompile(`^[\w@%+=:,./-]+$`)
+
+// unmarshalArgv unmarshals a command with exec given as a list of arguments.
+// Every other field is unmarshaled the same way as for any other command.
+func unmarshalArgv(unmarshal func(any) error, c *Command) error {
+	var item struct {
+		Exec []string       `yaml:"exec"`
+		Rest map[string]any `yaml:",inline"`
+	}
+	if err := unmarshal(&item); err != nil {
+		return err
+	}
+
+	switch {
+	case item.Exec == nil:
+		// A yaml.TypeError signals to keep trying other candidates.
+		return &yaml.TypeError{Errors: []string{"exec is not a list"}}
+	case len(item.Exec) == 0:
+		return errors.New("exec must include at least one argument")
+	}
+
+	text, err := yaml.Marshal(item.Rest)
+	if err != nil {
+		return err
+	}
+
+	type commandType Command // Use new type to avoid recursion
+	if err := yaml.UnmarshalStrict(text, (*commandType)(c)); err != nil {
+		return err
+	}
+
+	c.Argv = item.Exec
+	return nil
+}
+
+// MarshalYAML represents a command with a list of arguments using a list for
+// exec, so that commands survive interpolation.
+//
+// A print value generated from the arguments is omitted, so that it is
+// generated again from the interpolated arguments.
+func (c Command) MarshalYAML() (any, error) {
+	type commandType Command // Use new type to avoid recursion
+	item := commandType(c)
+	if len(c.Argv) == 0 {
+		return item, nil
+	}
+
+	if item.Print == quoteArgs(c.Argv) {
+		item.Print = ""
+	}
+
+	text, err := yaml.Marshal(item)
+	if err != nil {
+		return nil, err
+	}
+
+	var ms yaml.MapSlice
+	if err := yaml.Unmarshal(text, &ms); err != nil {
+		return nil, err
+	}
+
+	for i := range ms {
+		if ms[i].Key == "exec" {
+			ms[i].Value = c.Argv
+		}
+	}
+
+	return ms, nil
+}
+
+// quoteArgs joins a list of arguments into the form a POSIX shell would need
+// to read them back, quoting any argument that needs it.
+func quoteArgs(args []string) string {
+	quoted := make([]string, 0, len(args))
+	for _, arg := range args {
+		if shellSafe.MatchString(arg) {
+			quoted = append(quoted, arg)
+			continue
+		}
+
+		quoted = append(quoted, "'"+strings.ReplaceAll(arg, "'", `'\''`)+"'")
+	}
+
+	return strings.Join(quoted, " ")
+}
@@ -0,0 +1,80 @@
+package runner
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/rliebz/ghost"
+	"github.com/rliebz/ghost/be"
+
+	"github.com/rliebz/tusk/marshal"
+	"github.com/rliebz/tusk/ui"
+)
+
+func TestQuoteArgs(t *testing.T) {
+	tests := []struct {
+		args []string
+		want string
+	}{
+		{[]string{"go", "test", "./..."}, "go test ./..."},
+		{[]string{"echo", "hello world"}, "echo 'hello world'"},
+		{[]string{"echo", ""}, "echo ''"},
+		{[]string{"echo", "it's"}, `echo 'it'\''s'`},
+		{[]string{"echo", "$HOME", "a;b"}, "echo '$HOME' 'a;b'"},
+		{
+			[]string{"go", "test", "-run=TestFoo/bar", "--tags=a,b"},
+			"go test -run=TestFoo/bar --tags=a,b",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.want, func(t *testing.T) {
+			g := ghost.New(t)
+			g.Should(be.Equal(quoteArgs(tt.args), tt.want))
+		})
+	}
+}
+
+func TestTask_Execute_argv(t *testing.T) {
+	g := ghost.New(t)
+
+	stdout := new(bytes.Buffer)
+	logger := ui.New(ui.Config{Stdout: stdout, Stderr: new(bytes.Buffer)})
+
+	task := Task{
+		Name: "foo",
+		RunList: marshal.Slice[*Run]{{Command: marshal.Slice[*Command]{{
+			Argv: []string{"echo", "a  b", "$HOME", "; exit 1"},
+		}}}},
+	}
+
+	err := task.Execute(Context{Logger: logger, Interpreter: []string{"false"}})
+	g.NoError(err)
+
+	g.Should(be.Equal(stdout.String(), "a  b $HOME ; exit 1\n"))
+}
+
+func TestParseComplete_argv_env(t *testing.T) {
+	g := ghost.New(t)
+
+	t.Setenv("TUSK_TEST_GREETING", "")
+	t.Setenv("TUSK_TEST_NAME", "world")
+
+	cfg, err := ParseComplete(&ParseConfig{
+		CfgPath: "tusk.yml",
+		CfgText: []byte(`
+tasks:
+  greet:
+    run:
+      exec:
+        - echo
+        - ${env:TUSK_TEST_GREETING:-hello}
+        - ${env:TUSK_TEST_NAME}
+`),
+		TaskName: "greet",
+	})
+	g.NoError(err)
+
+	command := cfg.Tasks["greet"].RunList[0].Command[0]
+	g.Should(be.DeepEqual(command.Argv, []string{"echo", "hello", "world"}))
+}
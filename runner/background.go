@@ -0,0 +1,250 @@
+package runner
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/rliebz/tusk/marshal"
+)
+
+// backgroundStopTimeout is how long a background process has to exit after
+// being asked to terminate before it is killed. It can be overwritten during
+// tests.
+var backgroundStopTimeout = 5 * time.Second
+
+// waitForInterval is how often a wait-for probe is attempted by default.
+const waitForInterval = 100 * time.Millisecond
+
+// WaitFor is a probe that must succeed before a background command is
+// considered ready, so that later commands do not race its startup.
+type WaitFor struct {
+	// Address is a TCP address that must accept connections.
+	Address string `yaml:"address,omitempty"`
+
+	// URL is an HTTP URL that must respond without an error status.
+	URL string `yaml:"url,omitempty"`
+
+	// Timeout is how long to wait before giving up.
+	Timeout time.Duration `yaml:"timeout,omitempty"`
+
+	// Interval is how long to wait between attempts.
+	Interval time.Duration `yaml:"interval,omitempty"`
+}
+
+// UnmarshalYAML allows a string to be used as either an address or a URL.
+func (w *WaitFor) UnmarshalYAML(unmarshal func(any) error) error {
+	var str string
+	strCandidate := marshal.UnmarshalCandidate{
+		Unmarshal: func() error { return unmarshal(&str) },
+		Assign: func() {
+			if strings.Contains(str, "://") {
+				*w = WaitFor{URL: str}
+				return
+			}
+			*w = WaitFor{Address: str}
+		},
+	}
+
+	type waitForType WaitFor // Use new type to avoid recursion
+	var waitForItem waitForType
+	waitForCandidate := marshal.UnmarshalCandidate{
+		Unmarshal: func() error { return unmarshal(&waitForItem) },
+		Validate: func() error {
+			if (waitForItem.Address == "") == (waitForItem.URL == "") {
+				return errors.New("wait-for must specify exactly one of address or url")
+			}
+			if waitForItem.Timeout < 0 || waitForItem.Interval < 0 {
+				return errors.New("wait-for timeout and interval cannot be negative")
+			}
+			return nil
+		},
+		Assign: func() { *w = WaitFor(waitForItem) },
+	}
+
+	return marshal.UnmarshalOneOf(strCandidate, waitForCandidate)
+}
+
+// timeout returns how long to wait, which is 30 seconds unless set.
+func (w *WaitFor) timeout() time.Duration {
+	if w.Timeout == 0 {
+		return 30 * time.Second
+	}
+	return w.Timeout
+}
+
+// interval returns how long to wait between attempts, which is 100
+// milliseconds unless set.
+func (w *WaitFor) interval() time.Duration {
+	if w.Interval == 0 {
+		return waitForInterval
+	}
+	return w.Interval
+}
+
+// target returns the address or URL being probed.
+func (w *WaitFor) target() string {
+	if w.URL != "" {
+		return w.URL
+	}
+	return w.Address
+}
+
+// ready checks whether the probe succeeds.
+func (w *WaitFor) ready() bool {
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+
+	if w.URL != "" {
+		req, err := http.NewRequestWithContext(ctx, http.MethodGet, w.URL, http.NoBody)
+		if err != nil {
+			return false
+		}
+
+		resp, err := http.DefaultClient.Do(req)
+		if err != nil {
+			return false
+		}
+		resp.Body.Close() //nolint:errcheck
+		return resp.StatusCode < http.StatusBadRequest
+	}
+
+	var dialer net.Dialer
+	conn, err := dialer.DialContext(ctx, "tcp", w.Address)
+	if err != nil {
+		return false
+	}
+	conn.Close() //nolint:errcheck
+	return true
+}
+
+// wait blocks until the probe succeeds, done is closed, or the timeout passes.
+// A nil done channel waits for the probe or timeout only.
+func (w *WaitFor) wait(ctx Context, done <-chan struct{}) error {
+	target := w.target()
+
+	start := time.Now()
+	deadline := time.After(w.timeout())
+	ticker := time.NewTicker(w.interval())
+	defer ticker.Stop()
+	progress := time.NewTicker(waitProgressInterval)
+	defer progress.Stop()
+
+	for !w.ready() {
+		select {
+		case <-done:
+			return fmt.Errorf("background command exited before %s was ready", target)
+		case <-deadline:
+			return fmt.Errorf("timed out after %s waiting for %s", w.timeout(), target)
+		case <-progress.C:
+			ctx.Logger.Info(fmt.Sprintf(
+				"Waiting for %s (%s elapsed)", target, timeSince(start).Round(time.Second),
+			))
+		case <-ticker.C:
+		}
+	}
+
+	return nil
+}
+
+// backgroundProcess is a command running in the background.
+type backgroundProcess struct {
+	command *Command
+	stop    func() error
+	kill    func() error
+	done    chan struct{}
+}
+
+// terminate stops the process and any processes it started, first by asking
+// them to exit and then by killing them if they have not exited in time.
+func (p *backgroundProcess) terminate(ctx Context) {
+	select {
+	case <-p.done:
+		return
+	default:
+	}
+
+	ctx.Logger.Debug("Stopping background command:", p.command.Print)
+	if err := p.stop(); err != nil {
+		ctx.Logger.Debug("Stopping background command failed:", err)
+	}
+
+	select {
+	case <-p.done:
+		return
+	case <-time.After(backgroundStopTimeout):
+	}
+
+	ctx.Logger.Debug("Killing background command:", p.command.Print)
+	if err := p.kill(); err != nil {
+		ctx.Logger.Debug("Killing background command failed:", err)
+	}
+	<-p.done
+}
+
+// backgroundProcesses tracks the commands started in the background by a task,
+// so that they can be stopped once the task has finished.
+type backgroundProcesses struct {
+	mu        sync.Mutex
+	processes []*backgroundProcess
+}
+
+// start runs a command in the background, waiting for it to be ready if the
+// command has a wait-for probe.
+func (b *backgroundProcesses) start(ctx Context, c *Command) error {
+	if b == nil {
+		return errors.New("background commands can only be run by a task")
+	}
+
+	if err := ctx.interrupted(); err != nil {
+		return err
+	}
+
+	cmd, flush := c.newCmd(ctx)
+	setProcessGroup(cmd)
+	if err := cmd.Start(); err != nil {
+		flush()
+		return err
+	}
+
+	p := &backgroundProcess{
+		command: c,
+		stop:    func() error { return stopProcessGroup(cmd) },
+		kill:    func() error { return killProcessGroup(cmd) },
+		done:    make(chan struct{}),
+	}
+	go func() {
+		cmd.Wait() //nolint:errcheck
+		flush()
+		close(p.done)
+	}()
+
+	b.mu.Lock()
+	b.processes = append(b.processes, p)
+	b.mu.Unlock()
+	ctx.Interrupts.addBackground(ctx, p)
+
+	if c.WaitFor != nil {
+		return c.WaitFor.wait(ctx, p.done)
+	}
+
+	return nil
+}
+
+// stopAll stops every process started, in the reverse order they were started.
+func (b *backgroundProcesses) stopAll(ctx Context) {
+	b.mu.Lock()
+	processes := b.processes
+	b.processes = nil
+	b.mu.Unlock()
+
+	for i := len(processes) - 1; i >= 0; i-- {
+		processes[i].terminate(ctx)
+		ctx.Interrupts.removeBackground(processes[i])
+	}
+}
@@ -0,0 +1,152 @@
+package runner
+
+import (
+	"net"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/rliebz/ghost"
+	"github.com/rliebz/ghost/be"
+
+	"github.com/rliebz/tusk/marshal"
+	"github.com/rliebz/tusk/ui"
+)
+
+func TestTask_Execute_background(t *testing.T) {
+	g := ghost.New(t)
+
+	dir := t.TempDir()
+	task := Task{
+		Name: "foo",
+		RunList: marshal.Slice[*Run]{
+			{Command: marshal.Slice[*Command]{{
+				Exec: `(
+					trap 'echo stopped > stopped.txt; exit 0' TERM
+					echo started > started.txt
+					while :; do sleep 0.05; done
+				) & wait`,
+				Background: true,
+			}}},
+			{Command: marshal.Slice[*Command]{{
+				Exec: `while [ ! -f started.txt ]; do sleep 0.05; done`,
+			}}},
+		},
+	}
+
+	err := task.Execute(Context{
+		CfgPath: filepath.Join(dir, "tusk.yml"),
+		Logger:  ui.Noop(),
+	})
+	g.NoError(err)
+
+	// The process started by the background command is in the same process
+	// group, so it is stopped as well.
+	g.Should(be.Eventually(func() ghost.Result {
+		_, err := os.Stat(filepath.Join(dir, "stopped.txt"))
+		return be.Nil(err)
+	}, 5*time.Second, 50*time.Millisecond))
+}
+
+func TestTask_Execute_background_kill(t *testing.T) {
+	g := ghost.New(t)
+
+	t.Cleanup(func() { backgroundStopTimeout = 5 * time.Second })
+	backgroundStopTimeout = 50 * time.Millisecond
+
+	dir := t.TempDir()
+	task := Task{
+		Name: "foo",
+		RunList: marshal.Slice[*Run]{
+			{Command: marshal.Slice[*Command]{{
+				Exec:       `trap '' TERM; echo started > started.txt; while :; do sleep 0.05; done`,
+				Background: true,
+			}}},
+			{Command: marshal.Slice[*Command]{{
+				Exec: `while [ ! -f started.txt ]; do sleep 0.05; done`,
+			}}},
+		},
+	}
+
+	done := make(chan error)
+	go func() {
+		done <- task.Execute(Context{
+			CfgPath: filepath.Join(dir, "tusk.yml"),
+			Logger:  ui.Noop(),
+		})
+	}()
+
+	select {
+	case err := <-done:
+		g.NoError(err)
+	case <-time.After(5 * time.Second):
+		t.Fatal("background command was not killed")
+	}
+}
+
+func TestTask_Execute_background_waitFor(t *testing.T) {
+	var lc net.ListenConfig
+	listener, err := lc.Listen(t.Context(), "tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { listener.Close() }) //nolint:errcheck
+	open := listener.Addr().String()
+
+	unused, err := lc.Listen(t.Context(), "tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	closed := unused.Addr().String()
+	unused.Close() //nolint:errcheck
+
+	tests := []struct {
+		name    string
+		exec    string
+		waitFor WaitFor
+		wantErr string
+	}{
+		{
+			name:    "ready",
+			exec:    "sleep 30",
+			waitFor: WaitFor{Address: open},
+		},
+		{
+			name:    "timeout",
+			exec:    "sleep 30",
+			waitFor: WaitFor{Address: closed, Timeout: 200 * time.Millisecond},
+			wantErr: "timed out after 200ms waiting for " + closed,
+		},
+		{
+			name:    "exited",
+			exec:    "exit 0",
+			waitFor: WaitFor{Address: closed},
+			wantErr: "background command exited before " + closed + " was ready",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			g := ghost.New(t)
+
+			task := Task{
+				Name: "foo",
+				RunList: marshal.Slice[*Run]{
+					{Command: marshal.Slice[*Command]{{
+						Exec:       tt.exec,
+						Background: true,
+						WaitFor:    &tt.waitFor,
+					}}},
+				},
+			}
+
+			err := task.Execute(Context{Logger: ui.Noop()})
+			if tt.wantErr != "" {
+				g.Should(be.ErrorEqual(err, tt.wantErr))
+				return
+			}
+			g.NoError(err)
+		})
+	}
+}
@@ -0,0 +1,42 @@
+//go:build windows
+
+package runner
+
+import (
+	"os"
+	"os/exec"
+	"syscall"
+)
+
+// interruptSignals are the signals handled as interrupts.
+var interruptSignals = []os.Signal{os.Interrupt}
+
+// setProcessGroup runs a command in its own process group, so that it does
+// not receive console interrupts meant for tusk.
+func setProcessGroup(cmd *exec.Cmd) {
+	cmd.SysProcAttr = &syscall.SysProcAttr{
+		CreationFlags: syscall.CREATE_NEW_PROCESS_GROUP,
+	}
+}
+
+// stopProcessGroup stops a command. Windows has no equivalent of SIGTERM for
+// console processes in another process group, so the process is killed.
+func stopProcessGroup(cmd *exec.Cmd) error {
+	return cmd.Process.Kill()
+}
+
+// killProcessGroup kills a command.
+func killProcessGroup(cmd *exec.Cmd) error {
+	return cmd.Process.Kill()
+}
+
+// interruptProcess stops a command. Interrupts cannot be sent to another
+// process on Windows, so the process is killed.
+func interruptProcess(cmd *exec.Cmd) error {
+	return cmd.Process.Kill()
+}
+
+// raise exits as though the signal had not been handled.
+func raise(os.Signal) {
+	os.Exit(1)
+}
@@ -0,0 +1,41 @@
+package runner
+
+import (
+	"fmt"
+	"path/filepath"
+	"strings"
+)
+
+// builtinVarPrefix is the namespace of the variables that tusk provides for
+// interpolation. Args, options, and matrix variables cannot use it.
+const builtinVarPrefix = "tusk."
+
+// builtinVarNames are the names of the variables that tusk provides.
+var builtinVarNames = []string{builtinVarPrefix + "config", builtinVarPrefix + "dir"}
+
+// builtinVars returns the variables that tusk provides for interpolation,
+// which describe the config file at the given path.
+func builtinVars(cfgPath string) (map[string]string, error) {
+	if cfgPath == "" {
+		return map[string]string{}, nil
+	}
+
+	cfgPath, err := filepath.Abs(cfgPath)
+	if err != nil {
+		return nil, err
+	}
+
+	return map[string]string{
+		builtinVarPrefix + "dir":    filepath.Dir(cfgPath),
+		builtinVarPrefix + "config": cfgPath,
+	}, nil
+}
+
+// validateVarName checks that a user-defined variable name does not use the
+// reserved namespace.
+func validateVarName(kind, name string) error {
+	if strings.HasPrefix(name, builtinVarPrefix) {
+		return fmt.Errorf("%s %q: names starting with %q are reserved", kind, name, builtinVarPrefix)
+	}
+	return nil
+}
@@ -0,0 +1,79 @@
+package runner
+
+import (
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/rliebz/ghost"
+	"github.com/rliebz/ghost/be"
+	yaml "gopkg.in/yaml.v2"
+)
+
+func TestParseComplete_builtinVars(t *testing.T) {
+	g := ghost.New(t)
+
+	cfgText := `
+options:
+  out:
+    default: ${tusk.dir}/out
+tasks:
+  foo:
+    run: echo ${tusk.config} ${out} ${upper(tusk.dir)}
+`
+
+	cfgPath, err := filepath.Abs(filepath.Join("testdata", "tusk.yml"))
+	g.NoError(err)
+	dir := filepath.Dir(cfgPath)
+
+	cfg, err := ParseComplete(&ParseConfig{
+		CfgPath:  filepath.Join("testdata", "tusk.yml"),
+		CfgText:  []byte(cfgText),
+		TaskName: "foo",
+	})
+	g.NoError(err)
+
+	task := cfg.Tasks["foo"]
+	g.Should(be.Equal(
+		task.RunList[0].Command[0].Exec,
+		"echo "+cfgPath+" "+dir+"/out "+strings.ToUpper(dir),
+	))
+	g.Should(be.Equal(task.Vars["tusk.dir"], dir))
+}
+
+func TestValidateVarName(t *testing.T) {
+	tests := []struct {
+		name    string
+		input   string
+		target  any
+		wantErr string
+	}{
+		{
+			name:    "option",
+			input:   "tusk.dir: {}",
+			target:  new(Options),
+			wantErr: `option "tusk.dir": names starting with "tusk." are reserved`,
+		},
+		{
+			name:    "arg",
+			input:   "tusk.config: {}",
+			target:  new(Args),
+			wantErr: `arg "tusk.config": names starting with "tusk." are reserved`,
+		},
+		{
+			name:    "matrix variable",
+			input:   "tusk.os: [linux]",
+			target:  new(Matrix),
+			wantErr: `matrix variable "tusk.os": names starting with "tusk." are reserved`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			g := ghost.New(t)
+
+			err := yaml.UnmarshalStrict([]byte(tt.input), tt.target)
+			g.Should(be.ErrorEqual(err, tt.wantErr))
+		})
+	}
+}
@@ -0,0 +1,152 @@
+package runner
+
+import (
+	"errors"
+	"fmt"
+	"os/exec"
+	"path"
+	"strings"
+
+	"github.com/bmatcuk/doublestar/v4"
+
+	"github.com/rliebz/tusk/marshal"
+)
+
+const (
+	outsideRepoError = "error"
+	outsideRepoSkip  = "skip"
+)
+
+// ChangedFiles is a condition that passes when files matching any of a set of
+// paths have changed since a base git ref.
+type ChangedFiles struct {
+	// Base is the ref to compare against. Changes are found since the point
+	// where the current branch diverged from it, including uncommitted changes.
+	Base string `yaml:"base,omitempty"`
+
+	// Paths are the glob patterns to match changed files against, relative to
+	// the config file. A pattern matching a directory matches every file in it.
+	Paths marshal.Slice[string] `yaml:"paths"`
+
+	// OutsideRepo is either "error" or "skip", and determines what happens when
+	// the condition is evaluated outside of a git repository.
+	OutsideRepo string `yaml:"outside-repo,omitempty"`
+}
+
+// UnmarshalYAML ensures the condition is valid.
+func (c *ChangedFiles) UnmarshalYAML(unmarshal func(any) error) error {
+	type changedFilesType ChangedFiles // Use new type to avoid recursion
+	if err := unmarshal((*changedFilesType)(c)); err != nil {
+		return err
+	}
+
+	if len(c.Paths) == 0 {
+		return errors.New("changed-files must specify at least one path")
+	}
+
+	switch c.OutsideRepo {
+	case "", outsideRepoError, outsideRepoSkip:
+	default:
+		return fmt.Errorf(
+			"changed-files outside-repo %q must be one of [%s, %s]",
+			c.OutsideRepo, outsideRepoError, outsideRepoSkip,
+		)
+	}
+
+	return nil
+}
+
+func (w *When) validateChangedFiles(ctx Context) error {
+	if w.ChangedFiles == nil {
+		return newUnspecifiedError("changed-files")
+	}
+
+	c := w.ChangedFiles
+	files, err := gitChangedFiles(ctx, c.Base)
+	if err != nil {
+		if c.OutsideRepo == outsideRepoSkip && !isInsideRepo(ctx) {
+			return newCondFailError("not in a git repository")
+		}
+		return err
+	}
+
+	for _, file := range files {
+		if matchesAnyPath(file, c.Paths) {
+			return nil
+		}
+	}
+
+	return newCondFailErrorf("no changed files match: %s", c.Paths)
+}
+
+// gitChangedFiles returns the files changed since the merge base of a ref and
+// HEAD, relative to the config file's directory.
+func gitChangedFiles(ctx Context, base string) ([]string, error) {
+	if base == "" {
+		base = "HEAD"
+	}
+
+	mergeBase, err := gitOutput(ctx, "merge-base", base, "HEAD")
+	if err != nil {
+		return nil, err
+	}
+
+	out, err := gitOutput(ctx, "diff", "--name-only", "-z", "--relative", mergeBase)
+	if err != nil {
+		return nil, err
+	}
+
+	return splitNUL(out), nil
+}
+
+// splitNUL splits the NUL-separated output of a git command run with -z, so
+// that file names containing spaces or newlines are kept whole.
+func splitNUL(out string) []string {
+	var files []string
+	for file := range strings.SplitSeq(out, "\x00") {
+		if file != "" {
+			files = append(files, file)
+		}
+	}
+
+	return files
+}
+
+// isInsideRepo reports whether the config file is within a git repository.
+func isInsideRepo(ctx Context) bool {
+	out, err := gitOutput(ctx, "rev-parse", "--is-inside-work-tree")
+	return err == nil && out == "true"
+}
+
+// gitOutput runs a git command in the config file's directory and returns its
+// trimmed output.
+func gitOutput(ctx Context, args ...string) (string, error) {
+	cmd := execCommand("git", args...)
+	cmd.Dir = ctx.Dir()
+
+	out, err := cmd.Output()
+	if err != nil {
+		var exitErr *exec.ExitError
+		if errors.As(err, &exitErr) && len(exitErr.Stderr) > 0 {
+			return "", fmt.Errorf("git %s: %s", args[0], strings.TrimSpace(string(exitErr.Stderr)))
+		}
+		return "", fmt.Errorf("git %s: %w", args[0], err)
+	}
+
+	return strings.TrimSpace(string(out)), nil
+}
+
+// matchesAnyPath reports whether a file or any directory containing it matches
+// one of the patterns, which may use ** to match any number of directories.
+func matchesAnyPath(file string, patterns []string) bool {
+	for _, pattern := range patterns {
+		pattern = path.Clean(strings.TrimPrefix(pattern, "./"))
+		for p := file; p != "." && p != "/"; p = path.Dir(p) {
+			if ok, _ := doublestar.Match(pattern, p); ok {
+				return true
+			}
+		}
+	}
+
+	return false
+}
@@ -0,0 +1,170 @@
+package runner
+
+import (
+	"os"
+	"os/exec"
+	"path/filepath"
+	"testing"
+
+	"github.com/rliebz/ghost"
+	"github.com/rliebz/ghost/be"
+	yaml "gopkg.in/yaml.v2"
+)
+
+func TestChangedFiles_UnmarshalYAML(t *testing.T) {
+	tests := []struct {
+		name    string
+		input   string
+		want    ChangedFiles
+		wantErr string
+	}{
+		{
+			name:  "single path",
+			input: `{base: main, paths: src}`,
+			want:  ChangedFiles{Base: "main", Paths: []string{"src"}},
+		},
+		{
+			name:  "outside repo",
+			input: `{paths: [src, docs], outside-repo: skip}`,
+			want:  ChangedFiles{Paths: []string{"src", "docs"}, OutsideRepo: "skip"},
+		},
+		{
+			name:    "no paths",
+			input:   `{base: main}`,
+			wantErr: "changed-files must specify at least one path",
+		},
+		{
+			name:    "invalid outside repo",
+			input:   `{paths: src, outside-repo: ignore}`,
+			wantErr: `changed-files outside-repo "ignore" must be one of [error, skip]`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			g := ghost.New(t)
+
+			var got ChangedFiles
+			err := yaml.UnmarshalStrict([]byte(tt.input), &got)
+			if tt.wantErr != "" {
+				g.Should(be.ErrorEqual(err, tt.wantErr))
+				return
+			}
+			g.NoError(err)
+
+			g.Should(be.DeepEqual(got, tt.want))
+		})
+	}
+}
+
+func TestWhen_Validate_changedFiles(t *testing.T) {
+	dir := t.TempDir()
+	git := func(args ...string) {
+		t.Helper()
+
+		args = append([]string{"-c", "user.name=tusk", "-c", "user.email=tusk@example.com"}, args...)
+		cmd := exec.Command("git", args...)
+		cmd.Dir = dir
+		if out, err := cmd.CombinedOutput(); err != nil {
+			t.Fatalf("git %v: %v\n%s", args, err, out)
+		}
+	}
+	write := func(name, content string) {
+		t.Helper()
+
+		path := filepath.Join(dir, name)
+		if err := os.MkdirAll(filepath.Dir(path), 0o700); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path, []byte(content), 0o600); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	git("init", "-q", "-b", "main")
+	write("README.md", "hello")
+	write("api/main.go", "package main")
+	write("web/index.html", "<html>")
+	git("add", ".")
+	git("commit", "-q", "-m", "initial")
+	git("checkout", "-q", "-b", "feature")
+	write("api/handler/handler.go", "package handler")
+	write("docs/release notes.md", "notes")
+	git("add", ".")
+	git("commit", "-q", "-m", "change api")
+	write("README.md", "goodbye")
+
+	tests := []struct {
+		name     string
+		changed  ChangedFiles
+		wantFail bool
+	}{
+		{
+			name:    "directory changed since base",
+			changed: ChangedFiles{Base: "main", Paths: []string{"api"}},
+		},
+		{
+			name:    "glob changed since base",
+			changed: ChangedFiles{Base: "main", Paths: []string{"api/*/*.go"}},
+		},
+		{
+			name:    "double star changed since base",
+			changed: ChangedFiles{Base: "main", Paths: []string{"**/handler.go"}},
+		},
+		{
+			name:    "file name with spaces",
+			changed: ChangedFiles{Base: "main", Paths: []string{"docs/release notes.md"}},
+		},
+		{
+			name:     "nothing changed since base",
+			changed:  ChangedFiles{Base: "main", Paths: []string{"web"}},
+			wantFail: true,
+		},
+		{
+			name:    "uncommitted change",
+			changed: ChangedFiles{Paths: []string{"*.md"}},
+		},
+		{
+			name:     "committed change without base",
+			changed:  ChangedFiles{Paths: []string{"api"}},
+			wantFail: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			g := ghost.New(t)
+
+			w := When{ChangedFiles: &tt.changed}
+			err := w.Validate(Context{CfgPath: filepath.Join(dir, "tusk.yml")}, nil)
+			if tt.wantFail {
+				g.Should(be.True(IsFailedCondition(err)))
+				return
+			}
+			g.NoError(err)
+		})
+	}
+}
+
+func TestWhen_Validate_changedFiles_outside_repo(t *testing.T) {
+	t.Setenv("GIT_CEILING_DIRECTORIES", os.TempDir())
+	ctx := Context{CfgPath: filepath.Join(t.TempDir(), "tusk.yml")}
+
+	t.Run("error", func(t *testing.T) {
+		g := ghost.New(t)
+
+		w := When{ChangedFiles: &ChangedFiles{Paths: []string{"src"}}}
+		err := w.Validate(ctx, nil)
+		g.Should(be.ErrorContaining(err, "git merge-base: "))
+		g.Should(be.False(IsFailedCondition(err)))
+	})
+
+	t.Run("skip", func(t *testing.T) {
+		g := ghost.New(t)
+
+		w := When{ChangedFiles: &ChangedFiles{Paths: []string{"src"}, OutsideRepo: "skip"}}
+		err := w.Validate(ctx, nil)
+		g.Should(be.ErrorEqual(err, "not in a git repository"))
+		g.Should(be.True(IsFailedCondition(err)))
+	})
+}
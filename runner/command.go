@@ -1,15 +1,40 @@
 package runner
 
 import (
+	"bytes"
+	"errors"
+	"fmt"
 	"os"
 	"os/exec"
 	"path/filepath"
+	"runtime"
+	"strings"
+	"sync"
 
 	"github.com/rliebz/tusk/marshal"
 	"github.com/rliebz/tusk/ui"
 )
 
-var defaultInterpreter = []string{"sh", "-c"}
+// defaultInterpreter is the interpreter used when none is configured.
+var defaultInterpreter = sync.OnceValue(func() []string {
+	return detectInterpreter(runtime.GOOS, exec.LookPath)
+})
+
+// detectInterpreter returns a sensible interpreter for an operating system.
+//
+// Windows does not ship with sh, so PowerShell is used unless sh is available,
+// such as through Git for Windows.
+func detectInterpreter(goos string, lookPath func(string) (string, error)) []string {
+	if goos != "windows" {
+		return []string{"sh", "-c"}
+	}
+
+	if _, err := lookPath("sh"); err == nil {
+		return []string{"sh", "-c"}
+	}
+
+	return []string{"powershell", "-NoProfile", "-Command"}
+}
 
 // execCommand allows overwriting during tests.
 var execCommand = exec.Command
@@ -19,15 +44,42 @@ type Command struct {
 	// Exec is the script to execute.
 	Exec string `yaml:"exec"`
 
+	// Argv is the list of arguments to execute directly, without using the
+	// interpreter. It is set instead of Exec when exec is given as a list.
+	Argv []string `yaml:"-"`
+
 	// Print is the text that will be printed when the command is executed.
 	Print string `yaml:"print"`
 
 	// Quiet means that no text/hint will be printed before execution. Command
-	// output is still printed, similar to '--quiet' flag.
-	Quiet bool `yaml:"quiet,omitempty"`
+	// output is still printed, similar to '--quiet' flag. If nil, the command
+	// is quiet if any task running it is quiet.
+	Quiet *bool `yaml:"quiet,omitempty"`
+
+	// Capture means that command output is held back and only printed if the
+	// command fails.
+	Capture bool `yaml:"capture,omitempty"`
 
 	// Dir is the directory of the command.
 	Dir string `yaml:"dir"`
+
+	// Interpreter overrides the interpreter used for this command only.
+	Interpreter string `yaml:"interpreter,omitempty"`
+
+	// Env sets environment variables for this command only, taking priority
+	// over every other variable. A null value unsets the variable.
+	Env map[string]*string `yaml:"env,omitempty"`
+
+	// Background starts the command without waiting for it to finish. It is
+	// stopped once the task that started it has finished.
+	Background bool `yaml:"background,omitempty"`
+
+	// WaitFor is checked after a background command starts, so that the next
+	// command does not run until it is ready.
+	WaitFor *WaitFor `yaml:"wait-for,omitempty"`
+
+	// Retry runs the command again when it fails.
+	Retry *Retry `yaml:"retry,omitempty"`
 }
 
 // UnmarshalYAML allows strings to be interpreted as Do actions.
@@ -47,6 +99,12 @@ func (c *Command) UnmarshalYAML(unmarshal func(any) error) error {
 	var commandItem commandType
 	commandCandidate := marshal.UnmarshalCandidate{
 		Unmarshal: func() error { return unmarshal(&commandItem) },
+		Validate: func() error {
+			if err := validateBackground((*Command)(&commandItem)); err != nil {
+				return err
+			}
+			return validateInterpreter(commandItem.Interpreter)
+		},
 		Assign: func() {
 			*c = Command(commandItem)
 			if c.Print == "" {
@@ -55,37 +113,145 @@ func (c *Command) UnmarshalYAML(unmarshal func(any) error) error {
 		},
 	}
 
-	return marshal.UnmarshalOneOf(strCandidate, commandCandidate)
+	var argvItem Command
+	argvCandidate := marshal.UnmarshalCandidate{
+		Unmarshal: func() error { return unmarshalArgv(unmarshal, &argvItem) },
+		Validate: func() error {
+			if argvItem.Interpreter != "" {
+				return errors.New("interpreter cannot be used when exec is a list")
+			}
+			return validateBackground(&argvItem)
+		},
+		Assign: func() {
+			*c = argvItem
+			if c.Print == "" {
+				c.Print = quoteArgs(c.Argv)
+			}
+		},
+	}
+
+	return marshal.UnmarshalOneOf(strCandidate, commandCandidate, argvCandidate)
 }
 
 // newCmd creates an exec.Cmd that uses the interpreter and the script passed.
 func newCmd(ctx Context, script string) *exec.Cmd {
-	interpreter := defaultInterpreter
+	interpreter := defaultInterpreter()
 	if len(ctx.Interpreter) > 0 {
 		interpreter = ctx.Interpreter
 	}
 
+	resolved, err := ctx.interpolateInterpreter(interpreter)
+	if err == nil {
+		interpreter = resolved
+	}
+
 	path := interpreter[0]
 	args := []string{script}
 	if len(interpreter) > 1 {
 		args = append(interpreter[1:], args...)
 	}
 
+	cmd := newExecCmd(ctx, path, args...)
+	if err == nil {
+		err = cmd.Err
+	}
+	if err != nil {
+		cmd.Err = fmt.Errorf("interpreter %q: %w", strings.Join(interpreter, " "), err)
+	}
+	return cmd
+}
+
+// newExecCmd creates an exec.Cmd that runs a program directly.
+func newExecCmd(ctx Context, path string, args ...string) *exec.Cmd {
 	cmd := execCommand(path, args...)
 	cmd.Dir = ctx.Dir()
+	cmd.Env = commandEnv(ctx, cmd)
 	return cmd
 }
 
+// validateBackground checks that wait-for is only used by background commands,
+// and that retry is not.
+func validateBackground(c *Command) error {
+	if c.WaitFor != nil && !c.Background {
+		return errors.New("wait-for can only be used with background")
+	}
+
+	if c.Retry != nil && c.Background {
+		return errors.New("retry cannot be used with background")
+	}
+
+	return nil
+}
+
+// validateInterpreter checks that an interpreter, if set, names an executable.
+func validateInterpreter(interpreter string) error {
+	if interpreter != "" && len(strings.Fields(interpreter)) == 0 {
+		return errors.New("interpreter must name an executable")
+	}
+
+	return nil
+}
+
 // execCommand executes a shell command.
 func (c *Command) exec(ctx Context) error {
-	cmd := newCmd(ctx, c.Exec)
+	cmd, flush := c.newCmd(ctx)
+	defer flush()
 
-	cmd.Dir = filepath.Join(cmd.Dir, c.Dir)
 	cmd.Stdin = os.Stdin
+	if !shouldCapture(c, ctx) || cmd.Stderr == nil {
+		return runInterruptible(ctx, cmd)
+	}
+
+	// Output is combined so that it can be replayed in the order it was written.
+	var captured bytes.Buffer
+	stderr := cmd.Stderr
+	cmd.Stdout, cmd.Stderr = &captured, &captured
+
+	err := runInterruptible(ctx, cmd)
+	switch {
+	case err != nil:
+		stderr.Write(captured.Bytes()) //nolint:errcheck
+	case ctx.output != nil:
+		// Standard error already includes the output file when replaying.
+		ctx.output.Write(captured.Bytes()) //nolint:errcheck
+	}
+
+	return err
+}
+
+// output executes a shell command and returns its standard output, with any
+// surrounding whitespace removed. Error output is written as usual.
+func (c *Command) output(ctx Context) (string, error) {
+	cmd, flush := c.newCmd(ctx)
+	defer flush()
+
+	var stdout bytes.Buffer
+	cmd.Stdout = &stdout
+	if err := cmd.Run(); err != nil {
+		return "", err
+	}
+
+	return strings.TrimSpace(stdout.String()), nil
+}
+
+// newCmd creates an exec.Cmd for the command that runs in its directory and
+// writes its output to the logger. The flush function must be called once the
+// command has finished.
+func (c *Command) newCmd(ctx Context) (cmd *exec.Cmd, flush func()) {
+	if len(c.Argv) > 0 {
+		cmd = newExecCmd(ctx, c.Argv[0], c.Argv[1:]...)
+	} else {
+		cmd = newCmd(ctx.withInterpreter(c.Interpreter), c.Exec)
+	}
+	cmd.Dir = filepath.Join(cmd.Dir, c.Dir)
+	cmd.Env = withCommandEnv(cmd.Env, c.Env)
+	c.debugEnv(ctx)
+
+	flush = func() {}
 	if ctx.Logger.Level() > ui.LevelSilent {
-		cmd.Stdout = ctx.Logger.Stdout()
-		cmd.Stderr = ctx.Logger.Stderr()
+		cmd.Stdout, cmd.Stderr, flush = ctx.Logger.CommandOutput(c.Print, ctx.namespaces()...)
 	}
+	cmd.Stdout, cmd.Stderr = ctx.teeOutput(cmd.Stdout), ctx.teeOutput(cmd.Stderr)
 
-	return cmd.Run()
+	return cmd, flush
 }
@@ -7,6 +7,7 @@ import (
 	"path/filepath"
 	"strings"
 	"testing"
+	"time"
 
 	"github.com/google/go-cmp/cmp"
 	"github.com/rliebz/ghost"
@@ -17,6 +18,8 @@ import (
 )
 
 func TestCommand_UnmarshalYAML(t *testing.T) {
+	foo := "foo"
+
 	tests := []struct {
 		name string
 		yaml string
@@ -55,6 +58,70 @@ func TestCommand_UnmarshalYAML(t *testing.T) {
 				Dir:   "dirvalue",
 			},
 		},
+		{
+			"argv",
+			`{exec: [go, test, "./a b"], quiet: true}`,
+			Command{
+				Argv:  []string{"go", "test", "./a b"},
+				Print: "go test './a b'",
+				Quiet: ptr(true),
+			},
+		},
+		{
+			"argv-with-print",
+			`{exec: [go, test], print: testing}`,
+			Command{
+				Argv:  []string{"go", "test"},
+				Print: "testing",
+			},
+		},
+		{
+			"interpreter",
+			`{exec: print(1), interpreter: python3 -c}`,
+			Command{
+				Exec:        "print(1)",
+				Print:       "print(1)",
+				Interpreter: "python3 -c",
+			},
+		},
+		{
+			"env",
+			`{exec: example, env: {FOO: foo, BAR: null}}`,
+			Command{
+				Exec:  "example",
+				Print: "example",
+				Env:   map[string]*string{"FOO": &foo, "BAR": nil},
+			},
+		},
+		{
+			"argv-with-env",
+			`{exec: [example], env: {FOO: foo}}`,
+			Command{
+				Argv:  []string{"example"},
+				Print: "example",
+				Env:   map[string]*string{"FOO": &foo},
+			},
+		},
+		{
+			"background",
+			`{exec: serve, background: true, wait-for: localhost:8080}`,
+			Command{
+				Exec:       "serve",
+				Print:      "serve",
+				Background: true,
+				WaitFor:    &WaitFor{Address: "localhost:8080"},
+			},
+		},
+		{
+			"background-wait-for-url",
+			`{exec: [serve], background: true, wait-for: {url: "http://localhost", timeout: 5s}}`,
+			Command{
+				Argv:       []string{"serve"},
+				Print:      "serve",
+				Background: true,
+				WaitFor:    &WaitFor{URL: "http://localhost", Timeout: 5 * time.Second},
+			},
+		},
 	}
 
 	for _, tt := range tests {
@@ -65,17 +132,88 @@ func TestCommand_UnmarshalYAML(t *testing.T) {
 			err := yaml.UnmarshalStrict([]byte(tt.yaml), &got)
 			g.NoError(err)
 
-			g.Should(be.Equal(got, tt.want))
+			g.Should(be.DeepEqual(got, tt.want))
+		})
+	}
+}
+
+func TestCommand_UnmarshalYAML_empty_interpreter(t *testing.T) {
+	g := ghost.New(t)
+
+	var got Command
+	err := yaml.UnmarshalStrict([]byte(`{exec: example, interpreter: " "}`), &got)
+	g.Should(be.ErrorEqual(err, "interpreter must name an executable"))
+}
+
+func TestCommand_UnmarshalYAML_invalid_argv(t *testing.T) {
+	tests := []struct {
+		name    string
+		yaml    string
+		wantErr string
+	}{
+		{"empty", `{exec: []}`, "exec must include at least one argument"},
+		{
+			"interpreter",
+			`{exec: [go, test], interpreter: bash -c}`,
+			"interpreter cannot be used when exec is a list",
+		},
+		{
+			"wait-for without background",
+			`{exec: [serve], wait-for: localhost:8080}`,
+			"wait-for can only be used with background",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			g := ghost.New(t)
+
+			var got Command
+			err := yaml.UnmarshalStrict([]byte(tt.yaml), &got)
+			g.Should(be.ErrorEqual(err, tt.wantErr))
+		})
+	}
+}
+
+func TestDetectInterpreter(t *testing.T) {
+	found := func(file string) (string, error) { return "/bin/" + file, nil }
+	notFound := func(file string) (string, error) { return "", exec.ErrNotFound }
+
+	tests := []struct {
+		name     string
+		goos     string
+		lookPath func(string) (string, error)
+		want     []string
+	}{
+		{"linux", "linux", notFound, []string{"sh", "-c"}},
+		{"darwin", "darwin", notFound, []string{"sh", "-c"}},
+		{"windows with sh", "windows", found, []string{"sh", "-c"}},
+		{
+			"windows without sh",
+			"windows",
+			notFound,
+			[]string{"powershell", "-NoProfile", "-Command"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			g := ghost.New(t)
+
+			got := detectInterpreter(tt.goos, tt.lookPath)
+			g.Should(be.DeepEqual(got, tt.want))
 		})
 	}
 }
 
 func TestCommand_exec(t *testing.T) {
 	tests := []struct {
-		name        string
-		interpreter []string
-		command     string
-		want        []string
+		name               string
+		interpreter        []string
+		commandInterpreter string
+		vars               map[string]string
+		command            string
+		want               []string
 	}{
 		{
 			name:    "defaults",
@@ -88,6 +226,20 @@ func TestCommand_exec(t *testing.T) {
 			command:     `console.log("Hello world!")`,
 			want:        []string{"/usr/bin/env", "node", "-e", `console.log("Hello world!")`},
 		},
+		{
+			name:               "command interpreter",
+			interpreter:        []string{"/usr/bin/env", "node", "-e"},
+			commandInterpreter: "python3 -c",
+			command:            `print("Hello world!")`,
+			want:               []string{"python3", "-c", `print("Hello world!")`},
+		},
+		{
+			name:        "interpolated interpreter",
+			interpreter: []string{"${shell}", "-c"},
+			vars:        map[string]string{"shell": "bash"},
+			command:     `echo "Hello world!"`,
+			want:        []string{"bash", "-c", `echo "Hello world!"`},
+		},
 	}
 
 	for _, tt := range tests {
@@ -99,8 +251,9 @@ func TestCommand_exec(t *testing.T) {
 
 			wantDir := filepath.Dir(wd)
 			command := Command{
-				Exec: tt.command,
-				Dir:  "..",
+				Exec:        tt.command,
+				Dir:         "..",
+				Interpreter: tt.commandInterpreter,
 			}
 
 			t.Cleanup(func() { execCommand = exec.Command })
@@ -119,7 +272,7 @@ func TestCommand_exec(t *testing.T) {
 			ctx := Context{
 				Logger:      ui.Noop(),
 				Interpreter: tt.interpreter,
-			}
+			}.WithTask(&Task{Vars: tt.vars})
 
 			err = command.exec(ctx)
 			g.NoError(err)
@@ -127,6 +280,35 @@ func TestCommand_exec(t *testing.T) {
 	}
 }
 
+func TestCommand_exec_interpreter_not_found(t *testing.T) {
+	g := ghost.New(t)
+
+	command := Command{Exec: "example", Interpreter: "tusk-missing-interpreter -c"}
+
+	err := command.exec(Context{Logger: ui.Noop()})
+	g.Should(be.ErrorEqual(
+		err,
+		`interpreter "tusk-missing-interpreter -c": `+
+			`exec: "tusk-missing-interpreter": executable file not found in $PATH`,
+	))
+}
+
+func TestCommand_exec_interpreter_undefined(t *testing.T) {
+	g := ghost.New(t)
+
+	command := Command{Exec: "example"}
+	ctx := Context{
+		Logger:      ui.Noop(),
+		Interpreter: []string{"${shell}", "-c"},
+	}.WithTask(&Task{Vars: map[string]string{"other": "bash"}})
+
+	err := command.exec(ctx)
+	g.Should(be.ErrorEqual(
+		err,
+		`interpreter "${shell} -c": ${shell} does not refer to an arg or option`,
+	))
+}
+
 // TestCommand_exec_helper is a helper test that is called when mocking exec.
 //
 // The following environment variables can configure this function:
@@ -0,0 +1,24 @@
+package runner
+
+// applyCommonOptions adds the common options to every task that does not skip
+// them. An arg or option of the task itself takes precedence over a common
+// option of the same name. Each task gets its own copy of the options.
+func (c *Config) applyCommonOptions() {
+	if len(c.CommonOptions) == 0 {
+		return
+	}
+
+	for _, t := range c.Tasks {
+		if t.SkipCommonOptions {
+			continue
+		}
+
+		for _, opt := range copyTask(&Task{Options: c.CommonOptions}).Options {
+			_, isArg := t.Args.Lookup(opt.Name)
+			_, isOption := t.Options.Lookup(opt.Name)
+			if !isArg && !isOption {
+				t.Options = append(t.Options, opt)
+			}
+		}
+	}
+}
@@ -0,0 +1,85 @@
+package runner
+
+import (
+	"testing"
+
+	"github.com/rliebz/ghost"
+	"github.com/rliebz/ghost/be"
+)
+
+func TestParse_common_options(t *testing.T) {
+	g := ghost.New(t)
+
+	cfgText := []byte(`
+common-options:
+  verbose:
+    type: bool
+  env:
+    default: dev
+
+tasks:
+  build:
+    run: echo build
+  deploy:
+    options:
+      verbose:
+        type: bool
+        usage: Print every resource
+    run: echo deploy
+  greet:
+    args:
+      env: {}
+    run: echo ${env}
+  clean:
+    skip-common-options: true
+    run: echo clean
+`)
+
+	cfg, err := Parse(&ParseConfig{CfgPath: "tusk.yml", CfgText: cfgText})
+	g.NoError(err)
+
+	optionNames := func(name string) []string {
+		var names []string
+		for _, opt := range cfg.Tasks[name].Options {
+			names = append(names, opt.Name)
+		}
+		return names
+	}
+
+	g.Should(be.DeepEqual(optionNames("build"), []string{"verbose", "env"}))
+	g.Should(be.DeepEqual(optionNames("deploy"), []string{"verbose", "env"}))
+	g.Should(be.DeepEqual(optionNames("greet"), []string{"verbose"}))
+	g.Should(be.SliceLen(optionNames("clean"), 0))
+
+	verbose, ok := cfg.Tasks["deploy"].Options.Lookup("verbose")
+	g.Must(be.True(ok))
+	g.Should(be.Equal(verbose.Usage, "Print every resource"))
+
+	// Each task gets its own copy of a common option.
+	build, _ := cfg.Tasks["build"].Options.Lookup("env")
+	deploy, _ := cfg.Tasks["deploy"].Options.Lookup("env")
+	g.Should(be.True(build != deploy))
+}
+
+func TestParseComplete_common_options(t *testing.T) {
+	g := ghost.New(t)
+
+	cfgText := []byte(`
+common-options:
+  env:
+    default: dev
+
+tasks:
+  deploy:
+    run: echo ${env}
+`)
+
+	cfg, err := ParseComplete(&ParseConfig{
+		CfgText:  cfgText,
+		Flags:    map[string]string{"env": "prod"},
+		TaskName: "deploy",
+	})
+	g.NoError(err)
+
+	g.Should(be.Equal(cfg.Tasks["deploy"].RunList[0].Command[0].Exec, "echo prod"))
+}
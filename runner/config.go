@@ -1,27 +1,80 @@
 package runner
 
-import "github.com/rliebz/tusk/marshal"
+import (
+	"errors"
+	"fmt"
+	"maps"
+	"reflect"
+	"slices"
+	"strings"
+
+	yaml "gopkg.in/yaml.v2"
+
+	"github.com/rliebz/tusk/marshal"
+)
 
 // Config is a struct representing the format for configuration settings.
 type Config struct {
 	Name    string                 `yaml:"name"`
 	Usage   string                 `yaml:"usage"`
 	EnvFile marshal.Slice[EnvFile] `yaml:"env-file"`
+
+	// Default is the name of the task to run when no task is named on the
+	// command line.
+	Default string `yaml:"default"`
+
+	// EnvPrefix names the environment variable of every option that does not
+	// set one, as the prefix followed by the option name in upper snake case.
+	EnvPrefix string `yaml:"env-prefix"`
+
 	// The Interpreter field must be read before the config struct can be parsed
 	// completely from YAML. To do so, the config text parses it elsewhere in the
 	// code base independently from this struct.
 	//
 	// It is included here only so that strict unmarshaling does not fail.
-	Interpreter string `yaml:"interpreter"`
+	Interpreter Interpreter `yaml:"interpreter"`
+	// The LogFile field is read before the config is parsed so that output can
+	// be captured from the start. It is included here only so that strict
+	// unmarshaling does not fail.
+	LogFile string `yaml:"log-file"`
+
+	// CacheDir is the directory where cached files are stored. Once parsed, it
+	// is relative to the working directory rather than the config file.
+	CacheDir string `yaml:"cache-dir"`
+
+	Hooks *Hooks `yaml:"hooks,omitempty"`
+
+	// Includes are files and patterns for files that define additional tasks.
+	Includes marshal.Slice[string] `yaml:"includes,omitempty"`
+
+	// Import lists files from other tools whose scripts are added as tasks.
+	Import *Imports `yaml:"import,omitempty"`
+
+	Tasks   Tasks   `yaml:"tasks"`
+	Options Options `yaml:"options,omitempty"`
+
+	// CommonOptions are added to the options of every task, unless the task
+	// skips them or defines an arg or option of the same name.
+	CommonOptions Options `yaml:"common-options,omitempty"`
+
+	// Profiles are named sets of option defaults that can be selected when
+	// running a task.
+	Profiles map[string]Profile `yaml:"profiles,omitempty"`
 
-	Tasks   map[string]*Task `yaml:"tasks"`
-	Options Options          `yaml:"options,omitempty"`
+	// Extensions are the top-level keys that start with "x-", which are
+	// ignored so that they can hold YAML anchors to reuse elsewhere in the
+	// file.
+	Extensions map[string]any `yaml:",inline"`
+
+	// Warnings are problems found while parsing that do not stop the config
+	// from being used.
+	Warnings []string `yaml:"-"`
 }
 
 // UnmarshalYAML unmarshals and assigns names to options and tasks.
 func (c *Config) UnmarshalYAML(unmarshal func(any) error) error {
 	type configType Config // Use new type to avoid recursion
-	if err := unmarshal((*configType)(c)); err != nil {
+	if err := unmarshalWithExtensions(unmarshal, (*configType)(c), &c.Extensions); err != nil {
 		return err
 	}
 
@@ -31,3 +84,39 @@ func (c *Config) UnmarshalYAML(unmarshal func(any) error) error {
 
 	return nil
 }
+
+// unmarshalWithExtensions unmarshals a struct strictly, except that unknown
+// keys of the struct itself that start with "x-" are allowed, so that they can
+// hold YAML anchors to reuse elsewhere in the file.
+//
+// The struct collects its unknown keys in an inline map, which is passed as
+// extensions. Any key left there that does not start with "x-" is reported
+// along with the problems found within the struct's values.
+func unmarshalWithExtensions(unmarshal func(any) error, out any, extensions *map[string]any) error {
+	err := unmarshal(out)
+
+	var typeErr *yaml.TypeError
+	if err != nil && !errors.As(err, &typeErr) {
+		return err
+	}
+
+	var errs ValidationErrors
+	typeName := reflect.TypeOf(out).Elem().String()
+	for _, key := range slices.Sorted(maps.Keys(*extensions)) {
+		if !strings.HasPrefix(key, "x-") {
+			errs = append(errs, withKeys(
+				fmt.Errorf("field %s not found in type %s", key, typeName),
+				key,
+			))
+		}
+	}
+
+	switch {
+	case len(errs) == 0:
+		return err
+	case err != nil:
+		return append(errs, err)
+	default:
+		return errs
+	}
+}
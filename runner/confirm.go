@@ -0,0 +1,123 @@
+package runner
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+
+	"github.com/mattn/go-isatty"
+
+	"github.com/rliebz/tusk/marshal"
+)
+
+// ErrNotConfirmed is returned when a task that asks for confirmation is
+// declined.
+var ErrNotConfirmed = errors.New("task was not confirmed")
+
+// confirmInput is where answers to confirmation prompts are read from. It can
+// be overwritten during tests.
+var confirmInput io.Reader = os.Stdin
+
+// isInteractive reports whether confirmation prompts can be answered. It can be
+// overwritten during tests.
+var isInteractive = func() bool {
+	fd := os.Stdin.Fd()
+	return isatty.IsTerminal(fd) || isatty.IsCygwinTerminal(fd)
+}
+
+// Confirm is a prompt that must be accepted before a task runs.
+type Confirm struct {
+	// Prompt is the question to ask.
+	Prompt string `yaml:"prompt"`
+
+	// NonInteractive is what to do when there is no terminal to ask, which is
+	// either "confirm" or "abort". By default, the task is confirmed.
+	NonInteractive string `yaml:"non-interactive,omitempty"`
+}
+
+// UnmarshalYAML allows a string to be used as the prompt.
+func (c *Confirm) UnmarshalYAML(unmarshal func(any) error) error {
+	var str string
+	strCandidate := marshal.UnmarshalCandidate{
+		Unmarshal: func() error { return unmarshal(&str) },
+		Validate:  func() error { return validateConfirmPrompt(str) },
+		Assign:    func() { *c = Confirm{Prompt: str} },
+	}
+
+	type confirmType Confirm // Use new type to avoid recursion
+	var confirmItem confirmType
+	confirmCandidate := marshal.UnmarshalCandidate{
+		Unmarshal: func() error { return unmarshal(&confirmItem) },
+		Validate: func() error {
+			if err := validateConfirmPrompt(confirmItem.Prompt); err != nil {
+				return err
+			}
+
+			switch confirmItem.NonInteractive {
+			case "", "confirm", "abort":
+				return nil
+			default:
+				return fmt.Errorf(
+					`confirm non-interactive must be "confirm" or "abort", got %q`,
+					confirmItem.NonInteractive,
+				)
+			}
+		},
+		Assign: func() { *c = Confirm(confirmItem) },
+	}
+
+	return marshal.UnmarshalOneOf(strCandidate, confirmCandidate)
+}
+
+func validateConfirmPrompt(prompt string) error {
+	if strings.TrimSpace(prompt) == "" {
+		return errors.New("confirm must specify a prompt")
+	}
+	return nil
+}
+
+// ask returns whether the task should run. A nil confirmation always passes.
+func (c *Confirm) ask(ctx Context) (bool, error) {
+	if c == nil || ctx.Yes {
+		return true, nil
+	}
+
+	if !isInteractive() {
+		return c.NonInteractive != "abort", nil
+	}
+
+	ctx.Logger.PrintConfirm(c.Prompt)
+
+	answer, err := readLine(confirmInput)
+	if err != nil && !errors.Is(err, io.EOF) {
+		return false, fmt.Errorf("reading confirmation: %w", err)
+	}
+
+	switch strings.ToLower(strings.TrimSpace(answer)) {
+	case "y", "yes":
+		return true, nil
+	default:
+		return false, nil
+	}
+}
+
+// readLine reads a single line one byte at a time, so that no input meant for
+// later commands is consumed.
+func readLine(r io.Reader) (string, error) {
+	var line strings.Builder
+	b := make([]byte, 1)
+	for {
+		n, err := r.Read(b)
+		if n > 0 {
+			if b[0] == '\n' {
+				return line.String(), nil
+			}
+			line.WriteByte(b[0])
+		}
+		if err != nil {
+			return line.String(), err
+		}
+	}
+}
@@ -0,0 +1,215 @@
+package runner
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/rliebz/ghost"
+	"github.com/rliebz/ghost/be"
+	yaml "gopkg.in/yaml.v2"
+
+	"github.com/rliebz/tusk/marshal"
+	"github.com/rliebz/tusk/ui"
+)
+
+func TestConfirm_UnmarshalYAML(t *testing.T) {
+	tests := []struct {
+		name  string
+		input string
+		want  Confirm
+	}{
+		{
+			name:  "string",
+			input: `Are you sure?`,
+			want:  Confirm{Prompt: "Are you sure?"},
+		},
+		{
+			name:  "object",
+			input: `{prompt: "Are you sure?", non-interactive: abort}`,
+			want:  Confirm{Prompt: "Are you sure?", NonInteractive: "abort"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			g := ghost.New(t)
+
+			var got Confirm
+			err := yaml.UnmarshalStrict([]byte(tt.input), &got)
+			g.NoError(err)
+
+			g.Should(be.Equal(got, tt.want))
+		})
+	}
+}
+
+func TestConfirm_UnmarshalYAML_invalid(t *testing.T) {
+	tests := []struct {
+		name    string
+		input   string
+		wantErr string
+	}{
+		{
+			name:    "blank",
+			input:   `" "`,
+			wantErr: "confirm must specify a prompt",
+		},
+		{
+			name:    "missing prompt",
+			input:   `{non-interactive: abort}`,
+			wantErr: "confirm must specify a prompt",
+		},
+		{
+			name:    "unknown non-interactive",
+			input:   `{prompt: "Are you sure?", non-interactive: maybe}`,
+			wantErr: `confirm non-interactive must be "confirm" or "abort", got "maybe"`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			g := ghost.New(t)
+
+			var got Confirm
+			err := yaml.UnmarshalStrict([]byte(tt.input), &got)
+			g.Should(be.ErrorEqual(err, tt.wantErr))
+		})
+	}
+}
+
+func TestTask_Execute_confirm(t *testing.T) {
+	tests := []struct {
+		name        string
+		confirm     Confirm
+		yes         bool
+		interactive bool
+		input       string
+		wantErr     error
+		wantRan     bool
+	}{
+		{
+			name:        "accepted",
+			confirm:     Confirm{Prompt: "Are you sure?"},
+			interactive: true,
+			input:       "y\n",
+			wantRan:     true,
+		},
+		{
+			name:        "accepted in full",
+			confirm:     Confirm{Prompt: "Are you sure?"},
+			interactive: true,
+			input:       " Yes \n",
+			wantRan:     true,
+		},
+		{
+			name:        "declined",
+			confirm:     Confirm{Prompt: "Are you sure?"},
+			interactive: true,
+			input:       "n\n",
+			wantErr:     ErrNotConfirmed,
+		},
+		{
+			name:        "no answer",
+			confirm:     Confirm{Prompt: "Are you sure?"},
+			interactive: true,
+			wantErr:     ErrNotConfirmed,
+		},
+		{
+			name:        "yes",
+			confirm:     Confirm{Prompt: "Are you sure?"},
+			yes:         true,
+			interactive: true,
+			wantRan:     true,
+		},
+		{
+			name:    "non-interactive",
+			confirm: Confirm{Prompt: "Are you sure?"},
+			wantRan: true,
+		},
+		{
+			name:    "non-interactive abort",
+			confirm: Confirm{Prompt: "Are you sure?", NonInteractive: "abort"},
+			wantErr: ErrNotConfirmed,
+		},
+		{
+			name:    "non-interactive abort with yes",
+			confirm: Confirm{Prompt: "Are you sure?", NonInteractive: "abort"},
+			yes:     true,
+			wantRan: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			g := ghost.New(t)
+
+			origInteractive := isInteractive
+			t.Cleanup(func() {
+				confirmInput = os.Stdin
+				isInteractive = origInteractive
+			})
+			confirmInput = strings.NewReader(tt.input)
+			isInteractive = func() bool { return tt.interactive }
+
+			dir := t.TempDir()
+			task := Task{
+				Name:    "foo",
+				Confirm: &tt.confirm,
+				RunList: marshal.Slice[*Run]{
+					{Command: marshal.Slice[*Command]{{Exec: "echo ran > ran.txt"}}},
+				},
+			}
+
+			err := task.Execute(Context{
+				CfgPath: filepath.Join(dir, "tusk.yml"),
+				Logger:  ui.Noop(),
+				Yes:     tt.yes,
+			})
+			g.Should(be.True(errors.Is(err, tt.wantErr)))
+
+			_, err = os.Stat(filepath.Join(dir, "ran.txt"))
+			g.Should(be.Equal(err == nil, tt.wantRan))
+		})
+	}
+}
+
+func TestTask_Execute_confirm_subTask(t *testing.T) {
+	g := ghost.New(t)
+
+	origInteractive := isInteractive
+	t.Cleanup(func() {
+		confirmInput = os.Stdin
+		isInteractive = origInteractive
+	})
+	confirmInput = strings.NewReader("n\n")
+	isInteractive = func() bool { return true }
+
+	dir := t.TempDir()
+	sub := Task{
+		Name:    "sub",
+		Confirm: &Confirm{Prompt: "Are you sure?"},
+		RunList: marshal.Slice[*Run]{
+			{Command: marshal.Slice[*Command]{{Exec: "exit 0"}}},
+		},
+	}
+	task := Task{
+		Name:    "foo",
+		RunList: marshal.Slice[*Run]{{Tasks: []Task{sub}}},
+		OnFailure: marshal.Slice[*Run]{
+			{Command: marshal.Slice[*Command]{{Exec: "echo failed > failed.txt"}}},
+		},
+	}
+
+	err := task.Execute(Context{
+		CfgPath: filepath.Join(dir, "tusk.yml"),
+		Logger:  ui.Noop(),
+	})
+	g.Should(be.True(errors.Is(err, ErrNotConfirmed)))
+
+	// Declining aborts the run, so it is not handled as a failure.
+	_, err = os.Stat(filepath.Join(dir, "failed.txt"))
+	g.Should(be.True(errors.Is(err, os.ErrNotExist)))
+}
@@ -1,9 +1,13 @@
 package runner
 
 import (
+	"errors"
+	"io"
 	"path/filepath"
 	"slices"
+	"strings"
 
+	"github.com/rliebz/tusk/marshal"
 	"github.com/rliebz/tusk/ui"
 )
 
@@ -16,10 +20,91 @@ type Context struct {
 	// be defined for a Context.
 	Logger *ui.Logger
 
-	// Interpreter specifies how a command is meant to be executed.
+	// Interpreter specifies how a command is meant to be executed. If empty, a
+	// default is detected for the current operating system.
 	Interpreter []string
 
+	// Selection determines which named run items of the invoked task should
+	// be executed. It does not apply to sub-tasks.
+	Selection Selection
+
+	// Hooks are run before and after every task.
+	Hooks *Hooks
+
+	// Force runs tasks even when their targets are up to date.
+	Force bool
+
+	// KeepGoing runs the remaining run items of each task after one fails. The
+	// task still fails with the first error once every item has run.
+	KeepGoing bool
+
+	// CacheDir is the cache directory set by the config file. If empty, the
+	// default cache directory is used. Either may be overridden by the
+	// TUSK_CACHE_DIR environment variable.
+	CacheDir string
+
+	// Since is a git ref or time. If set, source files that have not changed
+	// since then are trusted to be unchanged since the task last ran, so that
+	// only the files that have changed are hashed to check if it is up to date.
+	Since string
+
+	// Yes confirms tasks that ask for confirmation without prompting.
+	Yes bool
+
+	// Completed records the tasks that have already run, which are skipped if
+	// a later task named on the command line runs them again. If nil, tasks
+	// run every time.
+	Completed *TaskRecord
+
+	// Interrupts handles interrupt signals, so that an interrupted task stops
+	// and runs its finally clause. If nil, an interrupt stops tusk at once, and
+	// commands running in the background are not stopped.
+	Interrupts *Interrupts
+
+	// MaxTaskDepth is the number of sub-tasks that may be nested within each
+	// other. If zero, a default of 50 is used.
+	MaxTaskDepth int
+
 	taskStack []*Task
+
+	// expanding holds the tasks whose sub-tasks are being added while parsing,
+	// which is used to detect tasks that run themselves.
+	expanding []*Task
+
+	// taskErr points to the error of the task whose finally clause is running,
+	// and is nil outside of a finally clause.
+	taskErr *error
+
+	// results holds the result of each named item of the run list of the
+	// current task, which its finally clause can check.
+	results map[string]string
+
+	// defaults holds the values computed by the commands of option defaults,
+	// so that each is run only once. If nil, nothing is cached.
+	defaults *defaultCache
+
+	// cleanup is set while an on-failure or finally clause runs, which is only
+	// stopped by a second interrupt.
+	cleanup bool
+
+	// env holds additional variables to set for commands.
+	env []envVar
+
+	// explicitEnv records the variables set with set-environment since the
+	// outermost task started, which tusk does not override.
+	explicitEnv *explicitEnv
+
+	// background holds the background commands started by the current task.
+	background *backgroundProcesses
+
+	// output receives a copy of the output of commands, if set.
+	output io.Writer
+
+	// step is the name of the run item whose commands are running, if any.
+	step string
+
+	// matrixCell describes the matrix values the current commands run with.
+	matrixCell string
 }
 
 // Dir is the directory that defines the config file, which is the relative
@@ -34,6 +119,58 @@ func (c Context) WithTask(t *Task) Context {
 	return c
 }
 
+// setResult records the result of a run item, if it is named.
+func (c Context) setResult(r *Run, result string) {
+	if c.results != nil && r.Name != "" {
+		c.results[r.Name] = result
+	}
+}
+
+// withInterpreter overrides the interpreter for commands, if one is set. The
+// interpreter is split on whitespace into an executable and its arguments.
+func (c Context) withInterpreter(interpreter string) Context {
+	if fields := strings.Fields(interpreter); len(fields) > 0 {
+		c.Interpreter = fields
+	}
+	return c
+}
+
+// interpolateInterpreter resolves references to args and options in an
+// interpreter using the values for the current task. Referring to anything
+// that is not defined is an error, since the interpreter would not name the
+// intended executable.
+func (c Context) interpolateInterpreter(interpreter []string) ([]string, error) {
+	text := strings.Join(interpreter, " ")
+	if !strings.Contains(text, "$") {
+		return interpreter, nil
+	}
+
+	var vars map[string]string
+	if len(c.taskStack) > 0 {
+		vars = c.taskStack[len(c.taskStack)-1].Vars
+	}
+
+	declared := make(map[string]struct{}, len(vars))
+	for name := range vars {
+		declared[name] = struct{}{}
+	}
+	if errs := validateReferences(text, declared); len(errs) > 0 {
+		return nil, errs[0]
+	}
+
+	if err := marshal.Interpolate(&text, vars); err != nil {
+		return nil, err
+	}
+
+	fields := strings.Fields(text)
+	if len(fields) == 0 {
+		return nil, errors.New("interpreter must name an executable")
+	}
+
+	c.Logger.Debug("Interpreter:", text)
+	return fields, nil
+}
+
 // TaskNames returns the list of task names in the stack, in order. Private
 // tasks are filtered out.
 func (c Context) TaskNames() []string {
@@ -45,3 +182,19 @@ func (c Context) TaskNames() []string {
 	}
 	return output
 }
+
+// namespaces returns the names that commands are printed under, which are the
+// task names followed by the current matrix cell, if any.
+func (c Context) namespaces() []string {
+	names := c.TaskNames()
+	if c.matrixCell != "" {
+		names = append(names, c.matrixCell)
+	}
+	return names
+}
+
+// withMatrixCell returns a context for running the commands of a matrix cell.
+func (c Context) withMatrixCell(label string) Context {
+	c.matrixCell = label
+	return c
+}
@@ -2,6 +2,11 @@ package runner
 
 import (
 	"encoding/json"
+	"fmt"
+	"maps"
+	"slices"
+	"strconv"
+	"strings"
 
 	"github.com/rliebz/tusk/marshal"
 )
@@ -91,6 +96,16 @@ type dependencyGetter interface {
 }
 
 func getDependencies(item dependencyGetter) ([]string, error) {
+	names, err := getInterpolated(item)
+	if err != nil {
+		return nil, err
+	}
+
+	return append(names, item.Dependencies()...), nil
+}
+
+// getInterpolated returns the names that may be interpolated within an item.
+func getInterpolated(item any) ([]string, error) {
 	// TODO: Remove json dependency by implementing stringer interface
 	// json is used to print computed fields that should not be yaml parseable
 	marshaled, err := json.Marshal(item)
@@ -98,8 +113,83 @@ func getDependencies(item dependencyGetter) ([]string, error) {
 		return nil, err
 	}
 
-	names := marshal.FindPotentialVariables(marshaled)
-	names = append(names, item.Dependencies()...)
+	return marshal.FindPotentialVariables(marshaled), nil
+}
+
+// findOptionCycles returns the names of options that form dependency cycles,
+// each beginning and ending with the same option. Every option that is part of
+// a cycle appears in at least one of them. Dependencies on names outside of the
+// options given are ignored.
+func findOptionCycles(options []*Option) ([][]string, error) {
+	graph := make(map[string][]string, len(options))
+	for _, opt := range options {
+		deps, err := getDependencies(opt)
+		if err != nil {
+			return nil, err
+		}
+
+		// The when clauses of an option may check its own value, which is the
+		// value passed for it rather than its default, so only interpolating an
+		// option within itself is a cycle.
+		interpolated, err := getInterpolated(opt)
+		if err != nil {
+			return nil, err
+		}
+		if !slices.Contains(interpolated, opt.Name) {
+			deps = slices.DeleteFunc(deps, func(dep string) bool { return dep == opt.Name })
+		}
+
+		slices.Sort(deps)
+		graph[opt.Name] = slices.Compact(deps)
+	}
+
+	finder := cycleFinder{graph: graph, visited: make(map[string]bool)}
+	for _, name := range slices.Sorted(maps.Keys(graph)) {
+		finder.visit(name)
+	}
+
+	return finder.cycles, nil
+}
+
+// cycleFinder performs a depth-first search for cycles in a dependency graph.
+type cycleFinder struct {
+	graph   map[string][]string
+	visited map[string]bool
+	path    []string
+	cycles  [][]string
+}
+
+func (f *cycleFinder) visit(name string) {
+	if i := slices.Index(f.path, name); i != -1 {
+		f.cycles = append(f.cycles, append(slices.Clone(f.path[i:]), name))
+		return
+	}
+
+	if f.visited[name] {
+		return
+	}
+	f.visited[name] = true
+
+	f.path = append(f.path, name)
+	for _, dep := range f.graph[name] {
+		if _, ok := f.graph[dep]; ok {
+			f.visit(dep)
+		}
+	}
+	f.path = f.path[:len(f.path)-1]
+}
+
+// newOptionCycleError describes a dependency cycle. An option that depends on
+// itself directly is described on its own, since it is the most common case.
+func newOptionCycleError(cycle []string) error {
+	if len(cycle) == 2 {
+		return fmt.Errorf("option %q depends on itself", cycle[0])
+	}
+
+	quoted := make([]string, 0, len(cycle))
+	for _, name := range cycle {
+		quoted = append(quoted, strconv.Quote(name))
+	}
 
-	return names, nil
+	return fmt.Errorf("options form a dependency cycle: option %s", strings.Join(quoted, " -> "))
 }
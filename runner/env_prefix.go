@@ -0,0 +1,67 @@
+package runner
+
+import (
+	"fmt"
+	"maps"
+	"slices"
+	"strings"
+	"unicode"
+)
+
+// applyEnvPrefix sets the environment variable of every option without one to
+// the name derived from the env prefix. It is an error for an option to be
+// given a variable that another option already uses.
+func (c *Config) applyEnvPrefix() error {
+	if c.EnvPrefix == "" {
+		return nil
+	}
+
+	// Options of the same name in different tasks may share a variable.
+	owners := make(map[string]string)
+	var derived []*Option
+	for _, opt := range c.allOptions() {
+		if opt.Environment != "" {
+			owners[opt.Environment] = opt.Name
+			continue
+		}
+		derived = append(derived, opt)
+	}
+
+	for _, opt := range derived {
+		env := envVarName(c.EnvPrefix, opt.Name)
+		if owner, ok := owners[env]; ok && owner != opt.Name {
+			return fmt.Errorf(
+				"options %q and %q both use the environment variable %q",
+				owner, opt.Name, env,
+			)
+		}
+
+		owners[env] = opt.Name
+		opt.Environment = env
+	}
+
+	return nil
+}
+
+// allOptions returns the shared options followed by the options of each task,
+// in a stable order.
+func (c *Config) allOptions() []*Option {
+	options := slices.Clone(c.Options)
+	for _, name := range slices.Sorted(maps.Keys(c.Tasks)) {
+		options = append(options, c.Tasks[name].Options...)
+	}
+	return options
+}
+
+// envVarName returns the environment variable for an option with a prefix,
+// where the option name is converted to upper snake case.
+func envVarName(prefix, name string) string {
+	converted := strings.Map(func(r rune) rune {
+		if unicode.IsLetter(r) || unicode.IsDigit(r) {
+			return unicode.ToUpper(r)
+		}
+		return '_'
+	}, name)
+
+	return strings.TrimSuffix(prefix, "_") + "_" + converted
+}
@@ -0,0 +1,110 @@
+package runner
+
+import (
+	"testing"
+
+	"github.com/rliebz/ghost"
+	"github.com/rliebz/ghost/be"
+)
+
+func TestParse_envPrefix(t *testing.T) {
+	g := ghost.New(t)
+
+	cfg, err := Parse(&ParseConfig{CfgPath: "tusk.yml", CfgText: []byte(`
+env-prefix: MYAPP
+options:
+  dry-run: {type: bool}
+tasks:
+  deploy:
+    options:
+      region: {}
+      token: {environment: DEPLOY_TOKEN}
+    run: echo ${region} ${token} ${dry-run}
+  release:
+    options:
+      region: {}
+    run: echo ${region}
+`)})
+	g.NoError(err)
+
+	dryRun, ok := cfg.Options.Lookup("dry-run")
+	g.Assert(ok)
+	g.Should(be.Equal(dryRun.Environment, "MYAPP_DRY_RUN"))
+
+	deploy := cfg.Tasks["deploy"]
+	region, ok := deploy.Options.Lookup("region")
+	g.Assert(ok)
+	g.Should(be.Equal(region.Environment, "MYAPP_REGION"))
+
+	token, ok := deploy.Options.Lookup("token")
+	g.Assert(ok)
+	g.Should(be.Equal(token.Environment, "DEPLOY_TOKEN"))
+
+	region, ok = cfg.Tasks["release"].Options.Lookup("region")
+	g.Assert(ok)
+	g.Should(be.Equal(region.Environment, "MYAPP_REGION"))
+}
+
+func TestParse_envPrefix_collision(t *testing.T) {
+	tests := []struct {
+		name    string
+		input   string
+		wantErr string
+	}{
+		{
+			name: "derived names",
+			input: `
+env-prefix: MYAPP
+options:
+  dry-run: {}
+  dry_run: {}
+tasks:
+  one:
+    run: echo ${dry-run} ${dry_run}
+`,
+			wantErr: `options "dry-run" and "dry_run" both use the environment variable "MYAPP_DRY_RUN"`,
+		},
+		{
+			name: "explicit name",
+			input: `
+env-prefix: MYAPP
+tasks:
+  one:
+    options:
+      token: {environment: MYAPP_KEY}
+      key: {}
+    run: echo ${token} ${key}
+`,
+			wantErr: `options "token" and "key" both use the environment variable "MYAPP_KEY"`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			g := ghost.New(t)
+
+			_, err := Parse(&ParseConfig{CfgPath: "tusk.yml", CfgText: []byte(tt.input)})
+			g.Should(be.ErrorEqual(err, tt.wantErr))
+		})
+	}
+}
+
+func TestEnvVarName(t *testing.T) {
+	tests := []struct {
+		prefix string
+		name   string
+		want   string
+	}{
+		{"MYAPP", "verbose", "MYAPP_VERBOSE"},
+		{"MYAPP", "dry-run", "MYAPP_DRY_RUN"},
+		{"MYAPP_", "log.level", "MYAPP_LOG_LEVEL"},
+		{"myapp", "Go2", "myapp_GO2"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			g := ghost.New(t)
+			g.Should(be.Equal(envVarName(tt.prefix, tt.name), tt.want))
+		})
+	}
+}
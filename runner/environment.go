@@ -0,0 +1,136 @@
+package runner
+
+import (
+	"maps"
+	"os/exec"
+	"slices"
+	"strings"
+	"sync"
+)
+
+// explicitEnv records the environment variables set or unset with
+// set-environment during a run, which take priority over the variables set by
+// tusk. It is safe to use while background commands are running.
+type explicitEnv struct {
+	mu   sync.Mutex
+	keys map[string]struct{}
+}
+
+func newExplicitEnv() *explicitEnv {
+	return &explicitEnv{keys: make(map[string]struct{})}
+}
+
+// has returns whether a variable has been set explicitly. A nil record has no
+// variables.
+func (e *explicitEnv) has(key string) bool {
+	if e == nil {
+		return false
+	}
+
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	_, ok := e.keys[key]
+	return ok
+}
+
+// add records that a variable has been set explicitly. Adding to a nil record
+// does nothing.
+func (e *explicitEnv) add(key string) {
+	if e == nil {
+		return
+	}
+
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	e.keys[key] = struct{}{}
+}
+
+// commandEnv returns the environment for a command, which is the environment
+// it would otherwise use along with variables describing where it is run from.
+func commandEnv(ctx Context, cmd *exec.Cmd) []string {
+	env := cmd.Environ()
+	for _, v := range ctx.tuskEnv() {
+		if ctx.explicitEnv.has(v.key) {
+			continue
+		}
+
+		// When keys are duplicated, the last value is used.
+		env = append(env, v.key+"="+v.value)
+	}
+
+	return env
+}
+
+// withCommandEnv applies the variables set for a single command to an
+// environment. A nil value unsets the variable.
+func withCommandEnv(env []string, vars map[string]*string) []string {
+	if len(vars) == 0 {
+		return env
+	}
+
+	result := make([]string, 0, len(env)+len(vars))
+	for _, kv := range env {
+		key, _, _ := strings.Cut(kv, "=")
+		if _, ok := vars[key]; !ok {
+			result = append(result, kv)
+		}
+	}
+
+	for _, key := range slices.Sorted(maps.Keys(vars)) {
+		if value := vars[key]; value != nil {
+			result = append(result, key+"="+*value)
+		}
+	}
+
+	return result
+}
+
+// debugEnv logs the variables set for a single command.
+func (c *Command) debugEnv(ctx Context) {
+	if len(c.Env) == 0 {
+		return
+	}
+
+	lines := []any{"Command environment:"}
+	for _, key := range slices.Sorted(maps.Keys(c.Env)) {
+		if value := c.Env[key]; value != nil {
+			lines = append(lines, key+"="+*value)
+		} else {
+			lines = append(lines, "unset "+key)
+		}
+	}
+
+	ctx.Logger.Debug(lines...)
+}
+
+type envVar struct {
+	key, value string
+}
+
+// tuskEnv returns the variables set by tusk for every command, followed by any
+// added to the context. The task variables refer to the innermost task, and
+// are only set within a task.
+func (c Context) tuskEnv() []envVar {
+	env := []envVar{
+		{"TUSK_CONFIG_PATH", c.CfgPath},
+		{"TUSK_CONFIG_DIR", c.Dir()},
+	}
+
+	if len(c.taskStack) == 0 {
+		return append(env, c.env...)
+	}
+
+	names := make([]string, 0, len(c.taskStack))
+	for _, t := range c.taskStack {
+		names = append(names, t.Name)
+	}
+
+	env = append(env,
+		envVar{"TUSK_TASK", names[len(names)-1]},
+		envVar{"TUSK_TASK_STACK", strings.Join(names, "/")},
+	)
+
+	return append(env, c.env...)
+}
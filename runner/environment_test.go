@@ -0,0 +1,189 @@
+package runner
+
+import (
+	"bytes"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/rliebz/ghost"
+	"github.com/rliebz/ghost/be"
+
+	"github.com/rliebz/tusk/marshal"
+	"github.com/rliebz/tusk/ui"
+)
+
+func TestContext_tuskEnv(t *testing.T) {
+	g := ghost.New(t)
+
+	ctx := Context{CfgPath: "/path/to/tusk.yml"}
+	g.Should(be.DeepEqual(ctx.tuskEnv(), []envVar{
+		{"TUSK_CONFIG_PATH", "/path/to/tusk.yml"},
+		{"TUSK_CONFIG_DIR", "/path/to"},
+	}))
+
+	ctx = ctx.WithTask(&Task{Name: "parent"})
+	ctx = ctx.WithTask(&Task{Name: "hidden", Private: true})
+	ctx = ctx.WithTask(&Task{Name: "child"})
+	g.Should(be.DeepEqual(ctx.tuskEnv(), []envVar{
+		{"TUSK_CONFIG_PATH", "/path/to/tusk.yml"},
+		{"TUSK_CONFIG_DIR", "/path/to"},
+		{"TUSK_TASK", "child"},
+		{"TUSK_TASK_STACK", "parent/hidden/child"},
+	}))
+}
+
+func TestTask_Execute_tusk_environment(t *testing.T) {
+	g := ghost.New(t)
+
+	t.Setenv("TUSK_TASK", "inherited")
+
+	dir := t.TempDir()
+	cfgPath := filepath.Join(dir, "tusk.yml")
+
+	stdout := new(bytes.Buffer)
+	ctx := Context{
+		CfgPath: cfgPath,
+		Logger:  ui.New(ui.Config{Stdout: stdout, Stderr: new(bytes.Buffer)}),
+	}
+
+	task := Task{
+		Name: "child",
+		RunList: marshal.Slice[*Run]{{Command: marshal.Slice[*Command]{{
+			Exec: `echo "$TUSK_TASK $TUSK_TASK_STACK $TUSK_CONFIG_DIR $TUSK_CONFIG_PATH"`,
+		}}}},
+	}
+
+	err := task.Execute(ctx.WithTask(&Task{Name: "parent"}))
+	g.NoError(err)
+
+	g.Should(be.Equal(
+		stdout.String(),
+		"child parent/child "+dir+" "+cfgPath+"\n",
+	))
+}
+
+func TestTask_Execute_tusk_environment_override(t *testing.T) {
+	g := ghost.New(t)
+
+	t.Setenv("TUSK_TASK", "")
+
+	stdout := new(bytes.Buffer)
+	ctx := Context{
+		CfgPath: filepath.Join(t.TempDir(), "tusk.yml"),
+		Logger:  ui.New(ui.Config{Stdout: stdout, Stderr: new(bytes.Buffer)}),
+	}
+
+	override := "override"
+	task := Task{
+		Name: "foo",
+		RunList: marshal.Slice[*Run]{
+			{SetEnvironment: map[string]*string{"TUSK_TASK": &override}},
+			{Command: marshal.Slice[*Command]{{Exec: `echo "$TUSK_TASK"`}}},
+		},
+	}
+
+	err := task.Execute(ctx)
+	g.NoError(err)
+
+	g.Should(be.Equal(stdout.String(), "override\n"))
+
+	// The override only applies to the run that set it.
+	stdout.Reset()
+	next := Task{
+		Name:    "bar",
+		RunList: marshal.Slice[*Run]{{Command: marshal.Slice[*Command]{{Exec: `echo "$TUSK_TASK"`}}}},
+	}
+	err = next.Execute(ctx)
+	g.NoError(err)
+
+	g.Should(be.Equal(stdout.String(), "bar\n"))
+}
+
+func TestWithCommandEnv(t *testing.T) {
+	value := "command"
+
+	tests := []struct {
+		name string
+		env  []string
+		vars map[string]*string
+		want []string
+	}{
+		{
+			name: "no variables",
+			env:  []string{"FOO=env"},
+			want: []string{"FOO=env"},
+		},
+		{
+			name: "new variable",
+			env:  []string{"FOO=env"},
+			vars: map[string]*string{"BAR": &value},
+			want: []string{"FOO=env", "BAR=command"},
+		},
+		{
+			name: "overridden variable",
+			env:  []string{"FOO=env", "BAR=env", "FOO=again"},
+			vars: map[string]*string{"FOO": &value},
+			want: []string{"BAR=env", "FOO=command"},
+		},
+		{
+			name: "unset variable",
+			env:  []string{"FOO=env", "BAR=env"},
+			vars: map[string]*string{"FOO": nil},
+			want: []string{"BAR=env"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			g := ghost.New(t)
+
+			got := withCommandEnv(tt.env, tt.vars)
+			g.Should(be.DeepEqual(got, tt.want))
+		})
+	}
+}
+
+func TestTask_Execute_command_environment(t *testing.T) {
+	g := ghost.New(t)
+
+	t.Setenv("TUSK_TEST_FOO", "")
+	t.Setenv("TUSK_TEST_BAR", "")
+
+	stdout := new(bytes.Buffer)
+	stderr := new(bytes.Buffer)
+	ctx := Context{
+		CfgPath: filepath.Join(t.TempDir(), "tusk.yml"),
+		Logger: ui.New(ui.Config{
+			Stdout:    stdout,
+			Stderr:    stderr,
+			Verbosity: ui.LevelVerbose,
+		}),
+	}
+
+	run, command, only := "run", "command", "only"
+	echo := `echo "$TUSK_TEST_FOO $TUSK_TEST_BAR"`
+	task := Task{
+		Name: "foo",
+		RunList: marshal.Slice[*Run]{
+			{SetEnvironment: map[string]*string{"TUSK_TEST_FOO": &run}},
+			{Command: marshal.Slice[*Command]{
+				{Exec: echo, Env: map[string]*string{
+					"TUSK_TEST_FOO": &command,
+					"TUSK_TEST_BAR": &only,
+				}},
+				{Exec: echo},
+			}},
+		},
+	}
+
+	err := task.Execute(ctx)
+	g.NoError(err)
+
+	g.Should(be.Equal(stdout.String(), "command only\nrun \n"))
+	g.Should(be.True(strings.Contains(
+		stderr.String(),
+		"Command environment:\n",
+	)))
+	g.Should(be.True(strings.Contains(stderr.String(), "TUSK_TEST_BAR=only\n")))
+}
@@ -3,6 +3,8 @@ package runner
 import (
 	"errors"
 	"fmt"
+
+	"github.com/rliebz/tusk/ui"
 )
 
 // IsFailedCondition checks if an error was because of a failed condition.
@@ -38,6 +40,31 @@ func newCondFailErrorf(msg string, a ...any) error {
 	return &conditionFailedError{formatted}
 }
 
+// commandError is the error of a command that failed, which records the command
+// so that it can be reported after the task keeps going.
+type commandError struct {
+	command string
+	err     error
+}
+
+func (e *commandError) Error() string {
+	return e.err.Error()
+}
+
+func (e *commandError) Unwrap() error {
+	return e.err
+}
+
+// newFailure returns the failure of a run item for the summary of a task that
+// keeps going, including the command that failed if there was one.
+func newFailure(err error) ui.Failure {
+	var cerr *commandError
+	if errors.As(err, &cerr) {
+		return ui.Failure{Command: cerr.command, Err: err}
+	}
+	return ui.Failure{Err: err}
+}
+
 // IsUnspecifiedClause checks if an error was because a clause is not defined.
 func IsUnspecifiedClause(err error) bool {
 	var uc unspecifiedClause
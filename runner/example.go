@@ -0,0 +1,45 @@
+package runner
+
+import (
+	"errors"
+	"strings"
+
+	"github.com/rliebz/tusk/marshal"
+)
+
+// Example is a command showing how to run a task, which is printed in the
+// help for the task.
+type Example struct {
+	// Command is the command line to run.
+	Command string `yaml:"command"`
+
+	// Description explains what the command does.
+	Description string `yaml:"description,omitempty"`
+}
+
+// UnmarshalYAML allows a string to be used as the command.
+func (e *Example) UnmarshalYAML(unmarshal func(any) error) error {
+	var str string
+	strCandidate := marshal.UnmarshalCandidate{
+		Unmarshal: func() error { return unmarshal(&str) },
+		Validate:  func() error { return validateExampleCommand(str) },
+		Assign:    func() { *e = Example{Command: str} },
+	}
+
+	type exampleType Example // Use new type to avoid recursion
+	var exampleItem exampleType
+	exampleCandidate := marshal.UnmarshalCandidate{
+		Unmarshal: func() error { return unmarshal(&exampleItem) },
+		Validate:  func() error { return validateExampleCommand(exampleItem.Command) },
+		Assign:    func() { *e = Example(exampleItem) },
+	}
+
+	return marshal.UnmarshalOneOf(strCandidate, exampleCandidate)
+}
+
+func validateExampleCommand(command string) error {
+	if strings.TrimSpace(command) == "" {
+		return errors.New("example must specify a command")
+	}
+	return nil
+}
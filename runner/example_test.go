@@ -0,0 +1,69 @@
+package runner
+
+import (
+	"testing"
+
+	"github.com/rliebz/ghost"
+	"github.com/rliebz/ghost/be"
+	yaml "gopkg.in/yaml.v2"
+)
+
+func TestExample_UnmarshalYAML(t *testing.T) {
+	tests := []struct {
+		name  string
+		input string
+		want  Example
+	}{
+		{
+			name:  "string",
+			input: `tusk build`,
+			want:  Example{Command: "tusk build"},
+		},
+		{
+			name:  "object",
+			input: `{command: tusk build --release, description: Build for release}`,
+			want:  Example{Command: "tusk build --release", Description: "Build for release"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			g := ghost.New(t)
+
+			var got Example
+			err := yaml.UnmarshalStrict([]byte(tt.input), &got)
+			g.NoError(err)
+
+			g.Should(be.Equal(got, tt.want))
+		})
+	}
+}
+
+func TestExample_UnmarshalYAML_invalid(t *testing.T) {
+	tests := []struct {
+		name    string
+		input   string
+		wantErr string
+	}{
+		{
+			name:    "blank",
+			input:   `" "`,
+			wantErr: "example must specify a command",
+		},
+		{
+			name:    "missing command",
+			input:   `{description: Build for release}`,
+			wantErr: "example must specify a command",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			g := ghost.New(t)
+
+			var got Example
+			err := yaml.UnmarshalStrict([]byte(tt.input), &got)
+			g.Should(be.ErrorEqual(err, tt.wantErr))
+		})
+	}
+}
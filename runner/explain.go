@@ -0,0 +1,225 @@
+package runner
+
+import (
+	"fmt"
+	"maps"
+	"os"
+	"runtime"
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/rliebz/tusk/marshal"
+)
+
+// Explain prints why the task would or would not run, without running it. This
+// includes whether its targets are up to date, and the result of every when
+// clause of each item in its run list.
+//
+// Checking a when clause may still run the commands of `command` and `version`
+// clauses, since their result cannot be known otherwise.
+func (t *Task) Explain(ctx Context) error {
+	ctx = ctx.WithTask(t).withInterpreter(t.Interpreter)
+
+	if err := ctx.Selection.validate(t); err != nil {
+		return err
+	}
+
+	cachePath, err := t.taskInputCachePath(ctx)
+	if err != nil {
+		return err
+	}
+
+	check, err := t.checkCache(ctx, cachePath)
+	if err != nil {
+		return fmt.Errorf("checking cache: %w", err)
+	}
+
+	ctx.Logger.Println(t.Name)
+	if check.upToDate {
+		ctx.Logger.Println("  skipped: " + check.reason)
+	} else {
+		ctx.Logger.Println("  runs: " + check.reason)
+	}
+
+	if t.isCacheable() {
+		ctx.Logger.Println("    source: " + strings.Join(t.Source, ", "))
+		ctx.Logger.Println("    target: " + strings.Join(t.Target, ", "))
+		if t.Cache != nil && len(t.Cache.Inputs) > 0 {
+			ctx.Logger.Println("    inputs: " + strings.Join(t.Cache.Inputs, ", "))
+		}
+		ctx.Logger.Println("    cache file: " + cachePath)
+	}
+	if check.cached != "" {
+		ctx.Logger.Println("    cached target checksum: " + check.cached)
+		ctx.Logger.Println("    current target checksum: " + check.current)
+	}
+
+	for i, r := range t.RunList {
+		if err := t.explainRun(ctx, i, r); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// explainRun prints whether a run item would run, along with the result of
+// each of its when clauses.
+func (t *Task) explainRun(ctx Context, i int, r *Run) error {
+	label := fmt.Sprintf("run item %d", i+1)
+	if r.Name != "" {
+		label += fmt.Sprintf(" (%s)", r.Name)
+	}
+
+	if reason, ok := ctx.Selection.excludes(r); ok {
+		ctx.Logger.Println("  " + label + ": skipped: " + reason)
+		return nil
+	}
+
+	var lines []string
+	runs := true
+	for _, w := range r.When {
+		lines = append(lines, "    when:")
+
+		clauses := w.check(ctx, t.Vars)
+		errs := make([]error, 0, len(clauses))
+		for _, c := range clauses {
+			if c.err != nil && !IsFailedCondition(c.err) {
+				return fmt.Errorf("%s: when clause %q: %w", label, c.name, c.err)
+			}
+
+			input := w.clauseInput(c.name, t.Vars)
+			line := fmt.Sprintf("      %s %s: %t", c.name, input, c.err == nil)
+			if c.err != nil {
+				line += fmt.Sprintf(" (%s)", c.err)
+			}
+			lines = append(lines, line)
+			errs = append(errs, c.err)
+		}
+
+		if validateAny(errs...) != nil {
+			runs = false
+		}
+	}
+
+	if runs {
+		ctx.Logger.Println("  " + label + ": runs")
+	} else {
+		ctx.Logger.Println("  " + label + ": skipped")
+	}
+	for _, line := range lines {
+		ctx.Logger.Println(line)
+	}
+
+	return nil
+}
+
+// clauseInput describes the values a when clause checks, along with the
+// current values they are compared to.
+func (w *When) clauseInput(name string, vars map[string]string) string {
+	switch name {
+	case "os":
+		return fmt.Sprintf("%s (current: %s)", strings.Join(w.OS, ", "), runtime.GOOS)
+	case "equal":
+		return describeCases(w.Equal, vars)
+	case "not-equal":
+		return describeCases(w.NotEqual, vars)
+	case "greater-than":
+		return describeCases(w.GreaterThan, vars)
+	case "greater-or-equal":
+		return describeCases(w.GreaterOrEqual, vars)
+	case "less-than":
+		return describeCases(w.LessThan, vars)
+	case "less-or-equal":
+		return describeCases(w.LessOrEqual, vars)
+	case "environment":
+		return describeEnvironment(w.Environment)
+	case "env-matches":
+		return describeCases(w.EnvMatches, environMap())
+	case "exists":
+		return strings.Join(w.Exists, ", ")
+	case "exists-dir":
+		return strings.Join(w.ExistsDir, ", ")
+	case "exists-file":
+		return strings.Join(w.ExistsFile, ", ")
+	case "not-exists":
+		return strings.Join(w.NotExists, ", ")
+	case "command":
+		return strings.Join(w.Command, ", ")
+	case "changed-files":
+		return strings.Join(w.ChangedFiles.Paths, ", ")
+	case "file-contains":
+		return fmt.Sprintf("%s matching %s", w.FileContains.Path, w.FileContains.Pattern)
+	case "version":
+		return w.Version.Constraint
+	case "failed":
+		return strconv.FormatBool(*w.Failed)
+	case "succeeded":
+		return strconv.FormatBool(*w.Succeeded)
+	case "all-of":
+		return countConditions(len(w.AllOf))
+	case "any-of":
+		return countConditions(len(w.AnyOf))
+	case "not":
+		return countConditions(1)
+	default:
+		return ""
+	}
+}
+
+// countConditions describes the number of nested when items of a clause.
+func countConditions(n int) string {
+	if n == 1 {
+		return "1 condition"
+	}
+	return fmt.Sprintf("%d conditions", n)
+}
+
+// describeCases describes the values expected for each name, along with the
+// current value of each.
+func describeCases(cases map[string]marshal.Slice[string], current map[string]string) string {
+	descriptions := make([]string, 0, len(cases))
+	for _, name := range slices.Sorted(maps.Keys(cases)) {
+		value, ok := current[name]
+		actual := "unset"
+		if ok {
+			actual = strconv.Quote(value)
+		}
+
+		descriptions = append(descriptions, fmt.Sprintf(
+			"%s: %s (current: %s)", name, strings.Join(cases[name], ", "), actual,
+		))
+	}
+
+	return strings.Join(descriptions, "; ")
+}
+
+// describeEnvironment describes the values expected for each environment
+// variable, where a nil value means the variable is unset.
+func describeEnvironment(environment map[string]marshal.Slice[*string]) string {
+	cases := make(map[string]marshal.Slice[string], len(environment))
+	for name, values := range environment {
+		for _, value := range values {
+			if value == nil {
+				cases[name] = append(cases[name], "unset")
+			} else {
+				cases[name] = append(cases[name], *value)
+			}
+		}
+	}
+
+	return describeCases(cases, environMap())
+}
+
+// environMap returns the environment variables that are set.
+func environMap() map[string]string {
+	env := make(map[string]string)
+	for _, kv := range os.Environ() {
+		if name, value, ok := strings.Cut(kv, "="); ok {
+			env[name] = value
+		}
+	}
+
+	return env
+}
@@ -0,0 +1,152 @@
+package runner
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"runtime"
+	"testing"
+
+	"github.com/rliebz/ghost"
+	"github.com/rliebz/ghost/be"
+
+	"github.com/rliebz/tusk/internal/xtesting"
+	"github.com/rliebz/tusk/ui"
+)
+
+func TestTask_Explain(t *testing.T) {
+	tests := []struct {
+		name      string
+		cfgText   string
+		selection Selection
+		want      string
+	}{
+		{
+			name:    "no conditions",
+			cfgText: `tasks: { foo: { run: echo foo } }`,
+			want: `foo
+  runs: task does not have both source and target
+  run item 1: runs
+`,
+		},
+		{
+			name: "conditions",
+			cfgText: `
+tasks:
+  foo:
+    options:
+      env: {default: dev}
+    run:
+      - when: {os: ` + runtime.GOOS + `}
+        command: echo os
+      - when:
+          equal: {env: prod}
+          exists: missing.txt
+        command: echo deploy
+`,
+			want: `foo
+  runs: task does not have both source and target
+  run item 1: runs
+    when:
+      os ` + runtime.GOOS + ` (current: ` + runtime.GOOS + `): true
+  run item 2: skipped
+    when:
+      equal env: prod (current: "dev"): false (no options matched)
+      exists missing.txt: false (no required file exists: [missing.txt])
+`,
+		},
+		{
+			name: "every when item must pass",
+			cfgText: `
+tasks:
+  foo:
+    run:
+      - when: [{exists: tusk.yml}, {not-exists: tusk.yml}]
+        command: echo foo
+`,
+			want: `foo
+  runs: task does not have both source and target
+  run item 1: skipped
+    when:
+      exists tusk.yml: true
+    when:
+      not-exists tusk.yml: false (file exists: tusk.yml)
+`,
+		},
+		{
+			name: "selection",
+			cfgText: `
+tasks:
+  foo:
+    run:
+      - {name: a, command: echo a}
+      - {name: b, command: echo b}
+`,
+			selection: Selection{Skip: []string{"a"}},
+			want: `foo
+  runs: task does not have both source and target
+  run item 1 (a): skipped: run item "a" skipped
+  run item 2 (b): runs
+`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			g := ghost.New(t)
+
+			dir := t.TempDir()
+			cfgPath := filepath.Join(dir, "tusk.yml")
+			g.NoError(os.WriteFile(cfgPath, []byte(tt.cfgText), 0o600))
+
+			cfg, err := ParseComplete(&ParseConfig{
+				CfgPath:  cfgPath,
+				CfgText:  []byte(tt.cfgText),
+				TaskName: "foo",
+			})
+			g.NoError(err)
+
+			stdout := new(bytes.Buffer)
+			err = cfg.Tasks["foo"].Explain(Context{
+				CfgPath:   cfgPath,
+				Logger:    ui.New(ui.Config{Stdout: stdout}),
+				Selection: tt.selection,
+			})
+			g.NoError(err)
+
+			g.Should(be.Equal(stdout.String(), tt.want))
+		})
+	}
+}
+
+func TestTask_Explain_cache(t *testing.T) {
+	g := ghost.New(t)
+
+	t.Setenv("XDG_CACHE_HOME", t.TempDir())
+
+	dir := xtesting.UseTempDir(t)
+	cfgPath := filepath.Join(dir, "tusk.yml")
+	g.NoError(os.WriteFile(filepath.Join(dir, "in.txt"), []byte("in"), 0o600))
+
+	task := Task{
+		Name:    "foo",
+		Source:  []string{"in.txt"},
+		Target:  []string{"out.txt"},
+		RunList: []*Run{{Command: []*Command{{Exec: "echo out > out.txt"}}}},
+	}
+
+	explain := func() string {
+		stdout := new(bytes.Buffer)
+		err := task.Explain(Context{CfgPath: cfgPath, Logger: ui.New(ui.Config{Stdout: stdout})})
+		g.NoError(err)
+		return stdout.String()
+	}
+
+	g.Should(be.StringContaining(explain(), "runs: task has not run with the current source files and values"))
+
+	g.NoError(task.Execute(Context{CfgPath: cfgPath, Logger: ui.Noop()}))
+	g.Should(be.StringContaining(explain(), "skipped: target files are unchanged since the last run"))
+
+	g.NoError(os.WriteFile(filepath.Join(dir, "out.txt"), []byte("changed"), 0o600))
+	g.Should(be.StringContaining(explain(), "runs: target files changed since the last run"))
+}
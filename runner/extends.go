@@ -0,0 +1,136 @@
+package runner
+
+import (
+	"fmt"
+	"maps"
+	"slices"
+	"strings"
+)
+
+// resolveExtends merges every task that extends another with the task it
+// extends. Chains are resolved in order, and a cycle is an error.
+func resolveExtends(tasks map[string]*Task) error {
+	resolved := make(map[string]bool, len(tasks))
+	for _, name := range slices.Sorted(maps.Keys(tasks)) {
+		if err := resolveTaskExtends(tasks, name, resolved, nil); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// resolveTaskExtends resolves the task with the given name, after first
+// resolving the task it extends. The stack is the chain of tasks that extend
+// it, so that cycles can be detected.
+func resolveTaskExtends(
+	tasks map[string]*Task,
+	name string,
+	resolved map[string]bool,
+	stack []string,
+) error {
+	if resolved[name] {
+		return nil
+	}
+
+	if i := slices.Index(stack, name); i >= 0 {
+		return fmt.Errorf(
+			"tasks extend each other in a cycle: %s",
+			strings.Join(slices.Concat(stack[i:], []string{name}), " -> "),
+		)
+	}
+
+	t := tasks[name]
+	if t.Extends == "" {
+		resolved[name] = true
+		return nil
+	}
+
+	base, ok := tasks[t.Extends]
+	if !ok {
+		return fmt.Errorf("task %q: extends undefined task %q", name, t.Extends)
+	}
+
+	err := resolveTaskExtends(tasks, t.Extends, resolved, append(slices.Clip(stack), name))
+	if err != nil {
+		return err
+	}
+
+	t.inherit(base)
+	if err := t.isValid(); err != nil {
+		return taskErrors(name, err)
+	}
+
+	resolved[name] = true
+	return nil
+}
+
+// inherit fills in the fields of a task from the task it extends. Fields the
+// task sets itself take precedence, except that options are merged by name and
+// append-run is added to the end of the inherited run list. Aliases, examples,
+// and private are never inherited.
+func (t *Task) inherit(base *Task) {
+	if len(t.Args) == 0 {
+		t.Args = copyTask(base).Args
+	}
+
+	t.Options = mergeOptions(copyTask(base).Options, t.Options)
+
+	if len(t.RunList) == 0 {
+		t.RunList = slices.Concat(base.RunList, t.AppendRun)
+	}
+	t.AppendRun = nil
+
+	if len(t.OnFailure) == 0 {
+		t.OnFailure = base.OnFailure
+	}
+	if len(t.Finally) == 0 {
+		t.Finally = base.Finally
+	}
+	if t.Usage == "" {
+		t.Usage = base.Usage
+	}
+	if t.Description == "" {
+		t.Description = base.Description
+	}
+	if t.Interpreter == "" {
+		t.Interpreter = base.Interpreter
+	}
+	if t.Output == nil {
+		t.Output = base.Output
+	}
+	if t.Confirm == nil {
+		t.Confirm = base.Confirm
+	}
+	if len(t.Source) == 0 && len(t.Target) == 0 {
+		t.Source, t.Target = base.Source, base.Target
+	}
+	if t.Cache == nil {
+		t.Cache = base.Cache
+	}
+
+	t.Quiet = t.Quiet || base.Quiet
+	t.Capture = t.Capture || base.Capture
+	t.KeepGoing = t.KeepGoing || base.KeepGoing
+	t.SkipCommonOptions = t.SkipCommonOptions || base.SkipCommonOptions
+}
+
+// mergeOptions returns the base options in order, with any option of the same
+// name replaced by the override, followed by the remaining overrides.
+func mergeOptions(base, overrides Options) Options {
+	merged := make(Options, 0, len(base)+len(overrides))
+	for _, opt := range base {
+		if override, ok := overrides.Lookup(opt.Name); ok {
+			opt = override
+		}
+		merged = append(merged, opt)
+	}
+
+	for _, opt := range overrides {
+		if _, ok := base.Lookup(opt.Name); !ok {
+			merged = append(merged, opt)
+		}
+	}
+
+	return merged
+}
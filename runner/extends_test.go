@@ -0,0 +1,181 @@
+package runner
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/rliebz/ghost"
+	"github.com/rliebz/ghost/be"
+)
+
+func TestParse_extends(t *testing.T) {
+	g := ghost.New(t)
+
+	cfgText := `
+tasks:
+  deploy:
+    private: true
+    usage: Deploy the app
+    options:
+      env:
+        default: dev
+      replicas:
+        default: "1"
+    run: ./deploy.sh ${env} ${replicas}
+    finally: echo done
+    source: src/**
+    target: dist/**
+  deploy-prod:
+    extends: deploy
+    options:
+      env:
+        default: prod
+      region:
+        default: us
+    append-run: ./notify.sh
+  deploy-eu:
+    extends: deploy-prod
+    usage: Deploy to the EU
+    options:
+      region:
+        default: eu
+    run: ./deploy-eu.sh
+`
+
+	cfg, err := Parse(&ParseConfig{CfgPath: "tusk.yml", CfgText: []byte(cfgText)})
+	g.NoError(err)
+
+	prod := cfg.Tasks["deploy-prod"]
+	g.Should(be.Equal(prod.Usage, "Deploy the app"))
+	g.Should(be.False(prod.Private))
+	g.Should(be.DeepEqual(optionDefaults(prod), map[string]string{
+		"env":      "prod",
+		"replicas": "1",
+		"region":   "us",
+	}))
+	g.Should(be.DeepEqual(optionNames(prod), []string{"env", "replicas", "region"}))
+	g.Should(be.DeepEqual(runExecs(prod), []string{"./deploy.sh ${env} ${replicas}", "./notify.sh"}))
+	g.Should(be.SliceLen(prod.Finally, 1))
+	g.Should(be.DeepEqual([]string(prod.Source), []string{"src/**"}))
+	g.Should(be.DeepEqual([]string(prod.Target), []string{"dist/**"}))
+
+	eu := cfg.Tasks["deploy-eu"]
+	g.Should(be.Equal(eu.Usage, "Deploy to the EU"))
+	g.Should(be.DeepEqual(optionDefaults(eu), map[string]string{
+		"env":      "prod",
+		"replicas": "1",
+		"region":   "eu",
+	}))
+	g.Should(be.DeepEqual(runExecs(eu), []string{"./deploy-eu.sh"}))
+
+	// The base task is unchanged.
+	g.Should(be.DeepEqual(optionDefaults(cfg.Tasks["deploy"]), map[string]string{
+		"env":      "dev",
+		"replicas": "1",
+	}))
+}
+
+func TestParse_extends_include(t *testing.T) {
+	g := ghost.New(t)
+
+	dir := t.TempDir()
+	g.NoError(os.WriteFile(
+		filepath.Join(dir, "base.yml"),
+		[]byte("usage: Included base\nrun: echo base"),
+		0o600,
+	))
+
+	cfgText := `
+tasks:
+  base:
+    include: base.yml
+  child:
+    extends: base
+    append-run: echo child
+`
+
+	cfg, err := Parse(&ParseConfig{CfgPath: filepath.Join(dir, "tusk.yml"), CfgText: []byte(cfgText)})
+	g.NoError(err)
+
+	child := cfg.Tasks["child"]
+	g.Should(be.Equal(child.Usage, "Included base"))
+	g.Should(be.DeepEqual(runExecs(child), []string{"echo base", "echo child"}))
+}
+
+func TestParse_extends_errors(t *testing.T) {
+	tests := []struct {
+		name    string
+		input   string
+		wantErr string
+	}{
+		{
+			name:    "undefined",
+			input:   `tasks: { a: { extends: b } }`,
+			wantErr: `task "a": extends undefined task "b"`,
+		},
+		{
+			name:    "cycle",
+			input:   `tasks: { a: { extends: b }, b: { extends: c }, c: { extends: a } }`,
+			wantErr: `tasks extend each other in a cycle: a -> b -> c -> a`,
+		},
+		{
+			name:    "self",
+			input:   `tasks: { a: { extends: a } }`,
+			wantErr: `tasks extend each other in a cycle: a -> a`,
+		},
+		{
+			name:    "append-run without extends",
+			input:   `tasks: { a: { append-run: echo } }`,
+			wantErr: "`append-run` can only be used with `extends`",
+		},
+		{
+			name:    "run and append-run",
+			input:   `tasks: { a: { run: echo }, b: { extends: a, run: echo, append-run: echo } }`,
+			wantErr: "`run` and `append-run` cannot be used together",
+		},
+		{
+			name: "option conflicts with inherited arg",
+			input: `tasks: {
+				a: { args: { foo: {} }, run: echo },
+				b: { extends: a, options: { foo: {} } },
+			}`,
+			wantErr: `task "b": argument and option "foo" must have unique names within a task`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			g := ghost.New(t)
+
+			_, err := Parse(&ParseConfig{CfgPath: "tusk.yml", CfgText: []byte(tt.input)})
+			g.Should(be.ErrorContaining(err, tt.wantErr))
+		})
+	}
+}
+
+func optionNames(t *Task) []string {
+	names := make([]string, 0, len(t.Options))
+	for _, opt := range t.Options {
+		names = append(names, opt.Name)
+	}
+	return names
+}
+
+func optionDefaults(t *Task) map[string]string {
+	defaults := make(map[string]string, len(t.Options))
+	for _, opt := range t.Options {
+		defaults[opt.Name] = opt.DefaultValues[0].Value
+	}
+	return defaults
+}
+
+func runExecs(t *Task) []string {
+	var execs []string
+	for _, r := range t.RunList {
+		for _, c := range r.Command {
+			execs = append(execs, c.Exec)
+		}
+	}
+	return execs
+}
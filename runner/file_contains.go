@@ -0,0 +1,69 @@
+package runner
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+)
+
+// FileContains is a condition that passes when any line of a file matches a
+// regular expression.
+type FileContains struct {
+	// Path is the file to check, relative to the config file.
+	Path string `yaml:"path"`
+
+	// Pattern is the regular expression to match each line against.
+	Pattern string `yaml:"pattern"`
+}
+
+// UnmarshalYAML ensures the condition is valid.
+func (c *FileContains) UnmarshalYAML(unmarshal func(any) error) error {
+	type fileContainsType FileContains // Use new type to avoid recursion
+	if err := unmarshal((*fileContainsType)(c)); err != nil {
+		return err
+	}
+
+	if c.Path == "" {
+		return errors.New("file-contains must specify a path")
+	}
+
+	if c.Pattern == "" {
+		return errors.New("file-contains must specify a pattern")
+	}
+
+	return nil
+}
+
+func (w *When) validateFileContains(ctx Context) error {
+	if w.FileContains == nil {
+		return newUnspecifiedError("file-contains")
+	}
+
+	c := w.FileContains
+
+	// The pattern is compiled here rather than while unmarshaling, since it may
+	// not be a valid expression until interpolation has occurred.
+	re, err := regexp.Compile(c.Pattern)
+	if err != nil {
+		return fmt.Errorf("file-contains pattern: %w", err)
+	}
+
+	data, err := os.ReadFile(filepath.Join(ctx.Dir(), c.Path))
+	if errors.Is(err, os.ErrNotExist) {
+		return newCondFailErrorf("file does not exist: %s", c.Path)
+	}
+	if err != nil {
+		return err
+	}
+
+	for line := range bytes.Lines(data) {
+		if re.Match(bytes.TrimRight(line, "\r\n")) {
+			return nil
+		}
+	}
+
+	return newCondFailErrorf("no line in %s matches pattern: %s", c.Path, c.Pattern)
+}
@@ -0,0 +1,135 @@
+package runner
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/rliebz/ghost"
+	"github.com/rliebz/ghost/be"
+	yaml "gopkg.in/yaml.v2"
+
+	"github.com/rliebz/tusk/marshal"
+)
+
+func TestFileContains_UnmarshalYAML(t *testing.T) {
+	tests := []struct {
+		name    string
+		input   string
+		want    FileContains
+		wantErr string
+	}{
+		{
+			name:  "path and pattern",
+			input: `{path: go.mod, pattern: 'go 1\.2[0-9]'}`,
+			want:  FileContains{Path: "go.mod", Pattern: `go 1\.2[0-9]`},
+		},
+		{
+			name:    "no path",
+			input:   `{pattern: foo}`,
+			wantErr: "file-contains must specify a path",
+		},
+		{
+			name:    "no pattern",
+			input:   `{path: go.mod}`,
+			wantErr: "file-contains must specify a pattern",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			g := ghost.New(t)
+
+			var got FileContains
+			err := yaml.UnmarshalStrict([]byte(tt.input), &got)
+			if tt.wantErr != "" {
+				g.Should(be.ErrorEqual(err, tt.wantErr))
+				return
+			}
+			g.NoError(err)
+
+			g.Should(be.DeepEqual(got, tt.want))
+		})
+	}
+}
+
+func TestWhen_Validate_fileContains(t *testing.T) {
+	dir := t.TempDir()
+	content := "module example.com/foo\r\n\r\ngo 1.24.4\r\n"
+	err := os.WriteFile(filepath.Join(dir, "go.mod"), []byte(content), 0o600)
+	ghost.New(t).NoError(err)
+
+	tests := []struct {
+		name     string
+		contains FileContains
+		wantFail bool
+	}{
+		{
+			name:     "matching line",
+			contains: FileContains{Path: "go.mod", Pattern: `go 1\.2[0-9]`},
+		},
+		{
+			name:     "anchored to line",
+			contains: FileContains{Path: "go.mod", Pattern: `^go 1\.24\.4$`},
+		},
+		{
+			name:     "no matching line",
+			contains: FileContains{Path: "go.mod", Pattern: `go 1\.1[0-9]`},
+			wantFail: true,
+		},
+		{
+			name:     "pattern spanning lines",
+			contains: FileContains{Path: "go.mod", Pattern: `foo\s+go`},
+			wantFail: true,
+		},
+		{
+			name:     "missing file",
+			contains: FileContains{Path: "missing.mod", Pattern: `go`},
+			wantFail: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			g := ghost.New(t)
+
+			w := When{FileContains: &tt.contains}
+			err := w.Validate(Context{CfgPath: filepath.Join(dir, "tusk.yml")}, nil)
+			if tt.wantFail {
+				g.Should(be.True(IsFailedCondition(err)))
+				return
+			}
+			g.NoError(err)
+		})
+	}
+}
+
+func TestWhen_Validate_fileContains_invalid_pattern(t *testing.T) {
+	g := ghost.New(t)
+
+	w := When{FileContains: &FileContains{Path: "go.mod", Pattern: `go (`}}
+	err := w.Validate(Context{}, nil)
+	g.Should(be.ErrorEqual(
+		err,
+		"file-contains pattern: error parsing regexp: missing closing ): `go (`",
+	))
+}
+
+func TestOption_dependencies_fileContains(t *testing.T) {
+	g := ghost.New(t)
+
+	option := &Option{DefaultValues: marshal.Slice[Value]{
+		{
+			When: WhenList{{FileContains: &FileContains{
+				Path:    "${file}",
+				Pattern: "version ${version}",
+			}}},
+			Value: "yes",
+		},
+	}}
+
+	got, err := getDependencies(option)
+	g.NoError(err)
+
+	g.Should(beEqualUnordered(got, []string{"file", "version"}))
+}
@@ -0,0 +1,161 @@
+package runner
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"maps"
+	"slices"
+	"strconv"
+	"strings"
+)
+
+// graphEdge is a sub-task run by a task, or the task that a task extends.
+type graphEdge struct {
+	from, to string
+	// clause is the clause of the task that runs the sub-task, or "extends".
+	clause string
+}
+
+// WriteGraph writes the sub-tasks run by each task of the config file as a
+// Graphviz DOT graph, along with the task each task extends. Private tasks are
+// drawn with dashed outlines, and the sub-tasks that form a cycle are drawn in
+// red. No task is run.
+//
+// If any root tasks are given, only those tasks and the tasks they lead to are
+// included.
+func WriteGraph(w io.Writer, meta *ParseConfig, roots ...string) error {
+	if meta.CfgPath == "" {
+		return errors.New("no config file found")
+	}
+
+	cfg, err := Parse(meta)
+	if err != nil {
+		return err
+	}
+
+	all := slices.Sorted(maps.Keys(cfg.Tasks))
+	edges := taskGraphEdges(cfg.Tasks, all)
+
+	adjacent := make(map[string][]string)
+	subTasks := make(map[string][]string)
+	for _, e := range edges {
+		adjacent[e.from] = append(adjacent[e.from], e.to)
+		if e.clause != clauseExtends {
+			subTasks[e.from] = append(subTasks[e.from], e.to)
+		}
+	}
+
+	included := make(map[string]bool)
+	for _, root := range roots {
+		if _, ok := cfg.Tasks[root]; !ok {
+			return fmt.Errorf("task %q is not defined", root)
+		}
+		for name := range reachable(adjacent, root) {
+			included[name] = true
+		}
+	}
+
+	var names []string
+	for _, name := range all {
+		if len(roots) == 0 || included[name] {
+			names = append(names, name)
+		}
+	}
+
+	var b strings.Builder
+	b.WriteString("digraph tusk {\n")
+	for _, name := range names {
+		if cfg.Tasks[name].Private {
+			fmt.Fprintf(&b, "  %s [style=dashed];\n", strconv.Quote(name))
+			continue
+		}
+		fmt.Fprintf(&b, "  %s;\n", strconv.Quote(name))
+	}
+	for _, e := range edges {
+		if len(roots) > 0 && !included[e.from] {
+			continue
+		}
+
+		var attrs []string
+		if e.clause != "run" {
+			attrs = append(attrs, "label="+strconv.Quote(e.clause))
+		}
+		switch {
+		case e.clause == clauseExtends:
+			attrs = append(attrs, "arrowhead=empty")
+		case reachable(subTasks, e.to)[e.from]:
+			attrs = append(attrs, "color=red")
+		}
+
+		fmt.Fprintf(&b, "  %s -> %s", strconv.Quote(e.from), strconv.Quote(e.to))
+		if len(attrs) > 0 {
+			fmt.Fprintf(&b, " [%s]", strings.Join(attrs, ", "))
+		}
+		b.WriteString(";\n")
+	}
+	b.WriteString("}\n")
+
+	_, err = io.WriteString(w, b.String())
+	return err
+}
+
+// clauseExtends marks the edge from a task to the task it extends.
+const clauseExtends = "extends"
+
+// taskGraphEdges returns the sub-tasks run by each task, in order, followed by
+// the task it extends. Each sub-task is listed once for each clause of a task
+// that runs it.
+func taskGraphEdges(tasks Tasks, names []string) []graphEdge {
+	var edges []graphEdge
+	for _, name := range names {
+		t := tasks[name]
+		clauses := []struct {
+			name string
+			runs []*Run
+		}{
+			{"run", t.RunList},
+			{"on-failure", t.OnFailure},
+			{"finally", t.Finally},
+		}
+
+		for _, clause := range clauses {
+			seen := make(map[string]bool)
+			for _, r := range clause.runs {
+				for _, subTask := range r.SubTaskList {
+					if seen[subTask.Name] {
+						continue
+					}
+					seen[subTask.Name] = true
+					edges = append(edges, graphEdge{name, subTask.Name, clause.name})
+				}
+			}
+		}
+
+		if t.Extends != "" {
+			edges = append(edges, graphEdge{name, t.Extends, clauseExtends})
+		}
+	}
+
+	return edges
+}
+
+// reachable returns the tasks that can be reached from a task, including the
+// task itself.
+func reachable(adjacent map[string][]string, from string) map[string]bool {
+	visited := make(map[string]bool)
+	stack := []string{from}
+	for len(stack) > 0 {
+		name := stack[len(stack)-1]
+		stack = stack[:len(stack)-1]
+
+		if visited[name] {
+			continue
+		}
+		visited[name] = true
+
+		stack = append(stack, adjacent[name]...)
+	}
+
+	return visited
+}
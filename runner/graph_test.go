@@ -0,0 +1,164 @@
+package runner
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/rliebz/ghost"
+	"github.com/rliebz/ghost/be"
+)
+
+func TestWriteGraph(t *testing.T) {
+	tests := []struct {
+		name  string
+		input string
+		roots []string
+		want  string
+	}{
+		{
+			name: "sub-tasks",
+			input: `
+tasks:
+  release:
+    run:
+      - task: build
+      - task:
+          name: test
+          options: {short: true}
+      - task: build
+    on-failure:
+      task: notify
+  build:
+    run: go build ./...
+  test:
+    options:
+      short:
+        type: bool
+    run: go test ./...
+  notify:
+    private: true
+    run: echo failed
+`,
+			want: `digraph tusk {
+  "build";
+  "notify" [style=dashed];
+  "release";
+  "test";
+  "release" -> "build";
+  "release" -> "test";
+  "release" -> "notify" [label="on-failure"];
+}
+`,
+		},
+		{
+			name: "cycle",
+			input: `
+tasks:
+  a:
+    run:
+      task: b
+  b:
+    run:
+      - task: a
+      - task: c
+  c:
+    finally:
+      task: c
+`,
+			want: `digraph tusk {
+  "a";
+  "b";
+  "c";
+  "a" -> "b" [color=red];
+  "b" -> "a" [color=red];
+  "b" -> "c";
+  "c" -> "c" [label="finally", color=red];
+}
+`,
+		},
+		{
+			name: "extends",
+			input: `
+tasks:
+  base:
+    private: true
+    run:
+      task: setup
+  setup:
+    run: echo setup
+  build:
+    extends: base
+`,
+			want: `digraph tusk {
+  "base" [style=dashed];
+  "build";
+  "setup";
+  "base" -> "setup";
+  "build" -> "setup";
+  "build" -> "base" [label="extends", arrowhead=empty];
+}
+`,
+		},
+		{
+			name: "roots",
+			input: `
+tasks:
+  release:
+    run:
+      task: build
+  build:
+    run:
+      task: generate
+  generate:
+    run: go generate ./...
+  lint:
+    run:
+      task: generate
+  test:
+    run: go test ./...
+`,
+			roots: []string{"build", "test"},
+			want: `digraph tusk {
+  "build";
+  "generate";
+  "test";
+  "build" -> "generate";
+}
+`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			g := ghost.New(t)
+
+			buf := new(bytes.Buffer)
+			err := WriteGraph(
+				buf,
+				&ParseConfig{CfgPath: "tusk.yml", CfgText: []byte(tt.input)},
+				tt.roots...,
+			)
+			g.NoError(err)
+
+			g.Should(be.Equal(buf.String(), tt.want))
+		})
+	}
+}
+
+func TestWriteGraph_undefined_root(t *testing.T) {
+	g := ghost.New(t)
+
+	err := WriteGraph(
+		new(bytes.Buffer),
+		&ParseConfig{CfgPath: "tusk.yml", CfgText: []byte("tasks: {build: {run: echo}}")},
+		"lint",
+	)
+	g.Should(be.ErrorEqual(err, `task "lint" is not defined`))
+}
+
+func TestWriteGraph_no_config(t *testing.T) {
+	g := ghost.New(t)
+
+	err := WriteGraph(new(bytes.Buffer), &ParseConfig{})
+	g.Should(be.ErrorEqual(err, "no config file found"))
+}
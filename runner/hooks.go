@@ -0,0 +1,79 @@
+package runner
+
+import (
+	"fmt"
+	"slices"
+
+	"github.com/rliebz/tusk/marshal"
+)
+
+// Hooks are commands that run before and after every task, including
+// sub-tasks, such as to record metrics or audit logs.
+//
+// Hook commands are run with TUSK_TASK_STATUS set, along with the variables
+// set for every command.
+type Hooks struct {
+	// BeforeTask is run before each task, with a status of "running".
+	BeforeTask marshal.Slice[*Command] `yaml:"before-task,omitempty"`
+
+	// AfterTask is run after each task and its finally clause, with a status of
+	// either "success" or "failure".
+	AfterTask marshal.Slice[*Command] `yaml:"after-task,omitempty"`
+
+	// Fatal means that a failing hook fails the task. Otherwise, hook failures
+	// are reported as warnings.
+	Fatal bool `yaml:"fatal,omitempty"`
+}
+
+const (
+	hookStatusRunning = "running"
+	hookStatusSuccess = "success"
+	hookStatusFailure = "failure"
+)
+
+// before runs the hooks for a task that is about to start.
+func (h *Hooks) before(ctx Context) error {
+	if h == nil {
+		return nil
+	}
+
+	return h.run(ctx, "before-task", h.BeforeTask, hookStatusRunning)
+}
+
+// after runs the hooks for a task that has finished. An error from a fatal
+// hook does not overwrite an existing error.
+func (h *Hooks) after(ctx Context, err *error) {
+	if h == nil {
+		return
+	}
+
+	status := hookStatusSuccess
+	if *err != nil {
+		status = hookStatusFailure
+	}
+
+	if herr := h.run(ctx, "after-task", h.AfterTask, status); herr != nil && *err == nil {
+		*err = herr
+	}
+}
+
+func (h *Hooks) run(ctx Context, name string, commands []*Command, status string) error {
+	ctx.env = append(slices.Clip(ctx.env), envVar{"TUSK_TASK_STATUS", status})
+
+	for _, command := range commands {
+		if !shouldBeQuiet(command, ctx) {
+			ctx.Logger.PrintCommandWithParenthetical(command.Print, name, ctx.TaskNames()...)
+		}
+
+		if err := command.exec(ctx); err != nil {
+			err = fmt.Errorf("%s hook %q: %w", name, command.Print, err)
+			if h.Fatal {
+				return err
+			}
+
+			ctx.Logger.Warn(err)
+		}
+	}
+
+	return nil
+}
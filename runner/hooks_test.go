@@ -0,0 +1,105 @@
+package runner
+
+import (
+	"bytes"
+	"path/filepath"
+	"testing"
+
+	"github.com/rliebz/ghost"
+	"github.com/rliebz/ghost/be"
+
+	"github.com/rliebz/tusk/marshal"
+	"github.com/rliebz/tusk/ui"
+)
+
+func TestTask_Execute_hooks(t *testing.T) {
+	report := `echo "$TUSK_TASK $TUSK_TASK_STATUS"`
+
+	tests := []struct {
+		name       string
+		hooks      *Hooks
+		exec       string
+		wantStdout string
+		wantStderr string
+		wantErr    string
+	}{
+		{
+			name: "success",
+			hooks: &Hooks{
+				BeforeTask: marshal.Slice[*Command]{{Exec: report, Quiet: ptr(true)}},
+				AfterTask:  marshal.Slice[*Command]{{Exec: report, Quiet: ptr(true)}},
+			},
+			exec:       "echo task",
+			wantStdout: "foo running\ntask\nfoo success\n",
+		},
+		{
+			name: "task failure",
+			hooks: &Hooks{
+				AfterTask: marshal.Slice[*Command]{{Exec: report, Quiet: ptr(true)}},
+			},
+			exec:       "exit 1",
+			wantStdout: "foo failure\n",
+			wantStderr: "exit status 1\n",
+			wantErr:    "exit status 1",
+		},
+		{
+			name: "non-fatal hook failure",
+			hooks: &Hooks{
+				BeforeTask: marshal.Slice[*Command]{{Exec: "exit 2", Print: "hook", Quiet: ptr(true)}},
+			},
+			exec:       "echo task",
+			wantStdout: "task\n",
+			wantStderr: "Warning: before-task hook \"hook\": exit status 2\n",
+		},
+		{
+			name: "fatal before hook failure",
+			hooks: &Hooks{
+				BeforeTask: marshal.Slice[*Command]{{Exec: "exit 2", Print: "hook", Quiet: ptr(true)}},
+				Fatal:      true,
+			},
+			exec:    "echo task",
+			wantErr: `before-task hook "hook": exit status 2`,
+		},
+		{
+			name: "fatal after hook failure",
+			hooks: &Hooks{
+				AfterTask: marshal.Slice[*Command]{{Exec: "exit 2", Print: "hook", Quiet: ptr(true)}},
+				Fatal:     true,
+			},
+			exec:       "echo task",
+			wantStdout: "task\n",
+			wantErr:    `after-task hook "hook": exit status 2`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			g := ghost.New(t)
+
+			stdout := new(bytes.Buffer)
+			stderr := new(bytes.Buffer)
+			ctx := Context{
+				CfgPath: filepath.Join(t.TempDir(), "tusk.yml"),
+				Logger:  ui.New(ui.Config{Stdout: stdout, Stderr: stderr}),
+				Hooks:   tt.hooks,
+			}
+
+			task := Task{
+				Name: "foo",
+				RunList: marshal.Slice[*Run]{{Command: marshal.Slice[*Command]{{
+					Exec: tt.exec, Quiet: ptr(true),
+				}}}},
+			}
+
+			err := task.Execute(ctx)
+			if tt.wantErr != "" {
+				g.Should(be.ErrorEqual(err, tt.wantErr))
+			} else {
+				g.NoError(err)
+			}
+
+			g.Should(be.Equal(stdout.String(), tt.wantStdout))
+			g.Should(be.Equal(stderr.String(), tt.wantStderr))
+		})
+	}
+}
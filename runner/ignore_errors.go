@@ -0,0 +1,60 @@
+package runner
+
+import (
+	"fmt"
+
+	"github.com/rliebz/tusk/marshal"
+)
+
+// IgnoreErrors determines whether the commands of a run item keep running after
+// one of them fails.
+type IgnoreErrors int
+
+const (
+	// ignoreErrorsNever stops at the first failing command.
+	ignoreErrorsNever IgnoreErrors = iota
+
+	// ignoreErrorsAlways runs every command and ignores any failures.
+	ignoreErrorsAlways
+
+	// ignoreErrorsUntilEnd runs every command, then fails with the first error
+	// if any command failed.
+	ignoreErrorsUntilEnd
+)
+
+// UnmarshalYAML allows a boolean or "at-end" to be used.
+func (i *IgnoreErrors) UnmarshalYAML(unmarshal func(any) error) error {
+	var b bool
+	boolCandidate := marshal.UnmarshalCandidate{
+		Unmarshal: func() error { return unmarshal(&b) },
+		Assign: func() {
+			*i = ignoreErrorsNever
+			if b {
+				*i = ignoreErrorsAlways
+			}
+		},
+	}
+
+	var str string
+	strCandidate := marshal.UnmarshalCandidate{
+		Unmarshal: func() error { return unmarshal(&str) },
+		Validate: func() error {
+			if str != "at-end" {
+				return fmt.Errorf(`ignore-errors must be true, false, or "at-end", got %q`, str)
+			}
+			return nil
+		},
+		Assign: func() { *i = ignoreErrorsUntilEnd },
+	}
+
+	return marshal.UnmarshalOneOf(boolCandidate, strCandidate)
+}
+
+// ignoredError returns the error a run item fails with after its commands have
+// run, given the first error that occurred.
+func (r *Run) ignoredError(err error) error {
+	if r.IgnoreErrors == ignoreErrorsAlways {
+		return nil
+	}
+	return err
+}
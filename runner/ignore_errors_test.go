@@ -0,0 +1,43 @@
+package runner
+
+import (
+	"testing"
+
+	"github.com/rliebz/ghost"
+	"github.com/rliebz/ghost/be"
+	yaml "gopkg.in/yaml.v2"
+)
+
+func TestIgnoreErrors_UnmarshalYAML(t *testing.T) {
+	tests := []struct {
+		input string
+		want  IgnoreErrors
+	}{
+		{input: `false`, want: ignoreErrorsNever},
+		{input: `true`, want: ignoreErrorsAlways},
+		{input: `at-end`, want: ignoreErrorsUntilEnd},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.input, func(t *testing.T) {
+			g := ghost.New(t)
+
+			var got IgnoreErrors
+			err := yaml.UnmarshalStrict([]byte(tt.input), &got)
+			g.NoError(err)
+
+			g.Should(be.Equal(got, tt.want))
+		})
+	}
+}
+
+func TestIgnoreErrors_UnmarshalYAML_invalid(t *testing.T) {
+	g := ghost.New(t)
+
+	var got IgnoreErrors
+	err := yaml.UnmarshalStrict([]byte(`sometimes`), &got)
+	g.Should(be.ErrorEqual(
+		err,
+		`ignore-errors must be true, false, or "at-end", got "sometimes"`,
+	))
+}
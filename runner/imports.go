@@ -0,0 +1,123 @@
+package runner
+
+import (
+	"encoding/json"
+	"fmt"
+	"maps"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+
+	"github.com/rliebz/tusk/marshal"
+)
+
+// Imports are files from other tools whose scripts are added as tasks.
+type Imports struct {
+	// NPM is the path of a package.json file, relative to the config file.
+	NPM string `yaml:"npm,omitempty"`
+}
+
+// packageManagers are the lock files that identify the package manager used to
+// run npm scripts, in order of priority.
+var packageManagers = []struct {
+	lockFile string
+	name     string
+}{
+	{"pnpm-lock.yaml", "pnpm"},
+	{"yarn.lock", "yarn"},
+	{"bun.lock", "bun"},
+	{"bun.lockb", "bun"},
+	{"package-lock.json", "npm"},
+}
+
+// packageJSON is the part of a package.json file that is imported.
+type packageJSON struct {
+	PackageManager string            `json:"packageManager"`
+	Scripts        map[string]string `json:"scripts"`
+}
+
+// loadImports adds a task for each script of the imported files. Tasks defined
+// by the config file take precedence, so a script is left out with a warning
+// if its name is already used.
+func (c *Config) loadImports(cfgPath string) error {
+	if c.Import == nil || c.Import.NPM == "" {
+		return nil
+	}
+
+	path := c.Import.NPM
+	if !filepath.IsAbs(path) {
+		path = filepath.Join(filepath.Dir(cfgPath), path)
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return fmt.Errorf("importing npm scripts: %w", err)
+	}
+
+	var pkg packageJSON
+	if err := json.Unmarshal(data, &pkg); err != nil {
+		return fmt.Errorf("importing npm scripts: decoding %s: %w", c.Import.NPM, err)
+	}
+
+	dir, err := filepath.Rel(filepath.Dir(cfgPath), filepath.Dir(path))
+	if err != nil {
+		dir = filepath.Dir(path)
+	}
+	manager := pkg.manager(filepath.Dir(path))
+
+	taken := make(map[string]bool, len(c.Tasks))
+	for name, t := range c.Tasks {
+		taken[name] = true
+		for _, alias := range t.Aliases {
+			taken[alias] = true
+		}
+	}
+
+	if c.Tasks == nil {
+		c.Tasks = make(map[string]*Task, len(pkg.Scripts))
+	}
+
+	for _, name := range slices.Sorted(maps.Keys(pkg.Scripts)) {
+		if taken[name] {
+			c.Warnings = append(c.Warnings, fmt.Sprintf(
+				"npm script %q is not imported, since a task with that name is already defined",
+				name,
+			))
+			continue
+		}
+
+		argv := []string{manager, "run", name}
+		c.Tasks[name] = &Task{
+			Name:        name,
+			Description: pkg.Scripts[name],
+			Imported:    "npm",
+			RunList: marshal.Slice[*Run]{{
+				Command: marshal.Slice[*Command]{{
+					Argv:  argv,
+					Print: strings.Join(argv, " "),
+					Dir:   dir,
+				}},
+			}},
+		}
+	}
+
+	return nil
+}
+
+// manager returns the package manager that runs the scripts of a package in a
+// directory, which is the one named by the package.json file, or else the one
+// whose lock file is found. By default, npm is used.
+func (p packageJSON) manager(dir string) string {
+	if name, _, _ := strings.Cut(p.PackageManager, "@"); name != "" {
+		return name
+	}
+
+	for _, pm := range packageManagers {
+		if _, err := os.Stat(filepath.Join(dir, pm.lockFile)); err == nil {
+			return pm.name
+		}
+	}
+
+	return "npm"
+}
@@ -0,0 +1,125 @@
+package runner
+
+import (
+	"maps"
+	"os"
+	"path/filepath"
+	"slices"
+	"testing"
+
+	"github.com/rliebz/ghost"
+	"github.com/rliebz/ghost/be"
+)
+
+func TestParse_import_npm(t *testing.T) {
+	tests := []struct {
+		name         string
+		files        map[string]string
+		cfgText      string
+		wantTasks    []string
+		wantPrint    string
+		wantDir      string
+		wantWarnings []string
+		wantErr      string
+	}{
+		{
+			name: "scripts",
+			files: map[string]string{
+				"package.json": `{"scripts": {"build": "tsc", "lint": "eslint ."}}`,
+			},
+			cfgText:   "import: { npm: package.json }",
+			wantTasks: []string{"build", "lint"},
+			wantPrint: "npm run build",
+			wantDir:   ".",
+		},
+		{
+			name: "nested package",
+			files: map[string]string{
+				"web/package.json": `{"scripts": {"build": "vite build"}}`,
+				"web/yarn.lock":    "",
+			},
+			cfgText:   "import: { npm: web/package.json }",
+			wantTasks: []string{"build"},
+			wantPrint: "yarn run build",
+			wantDir:   "web",
+		},
+		{
+			name: "package manager field",
+			files: map[string]string{
+				"package.json":      `{"packageManager": "pnpm@9.1.0", "scripts": {"build": "tsc"}}`,
+				"package-lock.json": "{}",
+			},
+			cfgText:   "import: { npm: package.json }",
+			wantTasks: []string{"build"},
+			wantPrint: "pnpm run build",
+			wantDir:   ".",
+		},
+		{
+			name: "name collision",
+			files: map[string]string{
+				"package.json": `{"scripts": {"build": "tsc", "check": "tsc --noEmit", "lint": "eslint ."}}`,
+			},
+			cfgText: `
+import: { npm: package.json }
+tasks:
+  build: { run: make build }
+  test: { aliases: [check], run: make test }
+`,
+			wantTasks: []string{"build", "lint", "test"},
+			wantWarnings: []string{
+				`npm script "build" is not imported, since a task with that name is already defined`,
+				`npm script "check" is not imported, since a task with that name is already defined`,
+			},
+		},
+		{
+			name:    "missing package",
+			cfgText: "import: { npm: package.json }",
+			wantErr: "importing npm scripts: open ",
+		},
+		{
+			name: "invalid package",
+			files: map[string]string{
+				"package.json": `{"scripts": []}`,
+			},
+			cfgText: "import: { npm: package.json }",
+			wantErr: "importing npm scripts: decoding package.json: ",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			g := ghost.New(t)
+
+			dir := t.TempDir()
+			for name, content := range tt.files {
+				path := filepath.Join(dir, name)
+				g.NoError(os.MkdirAll(filepath.Dir(path), 0o750))
+				g.NoError(os.WriteFile(path, []byte(content), 0o600))
+			}
+
+			cfg, err := Parse(&ParseConfig{
+				CfgPath: filepath.Join(dir, "tusk.yml"),
+				CfgText: []byte(tt.cfgText),
+			})
+			if tt.wantErr != "" {
+				g.Should(be.ErrorContaining(err, tt.wantErr))
+				return
+			}
+			g.NoError(err)
+
+			g.Should(be.DeepEqual(slices.Sorted(maps.Keys(cfg.Tasks)), tt.wantTasks))
+			g.Should(be.DeepEqual(cfg.Warnings, tt.wantWarnings))
+
+			if tt.wantPrint == "" {
+				return
+			}
+
+			build := cfg.Tasks["build"]
+			g.Should(be.Equal(build.Imported, "npm"))
+			g.Must(be.SliceLen(build.RunList, 1))
+			g.Must(be.SliceLen(build.RunList[0].Command, 1))
+			g.Should(be.Equal(build.RunList[0].Command[0].Print, tt.wantPrint))
+			g.Should(be.Equal(build.RunList[0].Command[0].Dir, tt.wantDir))
+		})
+	}
+}
@@ -0,0 +1,224 @@
+package runner
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+// remoteIncludeClient is the client used to fetch remote included files.
+var remoteIncludeClient = &http.Client{Timeout: 30 * time.Second}
+
+// includeReader reads the contents of included files, which may be local
+// paths or HTTPS URLs.
+type includeReader struct {
+	// offline prevents remote files from being fetched, so only cached copies
+	// can be used.
+	offline bool
+
+	// cacheDir is the cache directory set by the config file, if any.
+	cacheDir string
+}
+
+// isRemoteInclude returns whether an include refers to a URL.
+func isRemoteInclude(location string) bool {
+	return strings.Contains(location, "://")
+}
+
+// resolveInclude returns the location of an include relative to dir, which is
+// either a local directory or the URL of a remote directory.
+func resolveInclude(dir, include string) (string, error) {
+	switch {
+	case isRemoteInclude(include):
+		return include, nil
+	case isRemoteInclude(dir):
+		base, err := url.Parse(dir)
+		if err != nil {
+			return "", err
+		}
+		ref, err := url.Parse(filepath.ToSlash(include))
+		if err != nil {
+			return "", err
+		}
+		return base.ResolveReference(ref).String(), nil
+	case filepath.IsAbs(include):
+		return filepath.Clean(include), nil
+	default:
+		return filepath.Join(dir, include), nil
+	}
+}
+
+// includeDir returns the directory that includes inside the file at a
+// location are resolved relative to.
+func includeDir(location string) string {
+	if !isRemoteInclude(location) {
+		return filepath.Dir(location)
+	}
+
+	u, err := url.Parse(location)
+	if err != nil {
+		return location
+	}
+	return u.ResolveReference(&url.URL{Path: "."}).String()
+}
+
+// read returns the contents of an included file. If a checksum is given, the
+// contents must match it. Remote files require a checksum and are cached.
+func (r includeReader) read(location, checksum string) ([]byte, error) {
+	if isRemoteInclude(location) {
+		return r.readRemote(location, checksum)
+	}
+
+	data, err := os.ReadFile(location)
+	if err != nil {
+		return nil, fmt.Errorf("opening included file: %w", err)
+	}
+
+	if checksum != "" {
+		if err := verifyChecksum(data, checksum); err != nil {
+			return nil, fmt.Errorf("included file %q: %w", location, err)
+		}
+	}
+
+	return data, nil
+}
+
+// readRemote returns the contents of a remote included file, using the cached
+// copy if there is one.
+func (r includeReader) readRemote(location, checksum string) ([]byte, error) {
+	u, err := url.Parse(location)
+	if err != nil {
+		return nil, fmt.Errorf("include %q: %w", location, err)
+	}
+	if u.Scheme != "https" {
+		return nil, fmt.Errorf("include %q must use https", location)
+	}
+	if checksum == "" {
+		return nil, fmt.Errorf("include %q must specify a sha256 checksum", location)
+	}
+
+	cachePath, err := remoteIncludeCachePath(r.cacheDir, checksum)
+	if err != nil {
+		return nil, err
+	}
+
+	if data, err := os.ReadFile(cachePath); err == nil && verifyChecksum(data, checksum) == nil {
+		return data, nil
+	}
+
+	if r.offline {
+		return nil, fmt.Errorf("include %q is not cached and cannot be fetched offline", location)
+	}
+
+	data, err := fetchInclude(location)
+	if err != nil {
+		return nil, fmt.Errorf("fetching include %q: %w", location, err)
+	}
+
+	if err := verifyChecksum(data, checksum); err != nil {
+		return nil, fmt.Errorf("include %q: %w", location, err)
+	}
+
+	if err := writeCacheFile(cachePath, data); err != nil {
+		return nil, fmt.Errorf("caching include %q: %w", location, err)
+	}
+
+	return data, nil
+}
+
+// fetchInclude downloads a remote included file.
+func fetchInclude(location string) ([]byte, error) {
+	req, err := http.NewRequestWithContext(context.Background(), http.MethodGet, location, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	resp, err := remoteIncludeClient.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close() //nolint:errcheck
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("unexpected status %q", resp.Status)
+	}
+
+	return io.ReadAll(resp.Body)
+}
+
+// verifyChecksum checks that data has the given hex-encoded sha256 checksum.
+func verifyChecksum(data []byte, checksum string) error {
+	if err := validateChecksum(checksum); err != nil {
+		return err
+	}
+	want, _ := hex.DecodeString(checksum)
+
+	got := sha256.Sum256(data)
+	if !bytes.Equal(got[:], want) {
+		return fmt.Errorf(
+			"checksum mismatch: expected sha256 %s, got %s",
+			strings.ToLower(checksum), hex.EncodeToString(got[:]),
+		)
+	}
+
+	return nil
+}
+
+// remoteIncludeCachePath returns where a remote included file with the given
+// checksum is cached. Since files are stored by checksum, any URL serving the
+// same contents shares a cached copy.
+func remoteIncludeCachePath(cfgCacheDir string, checksum string) (string, error) {
+	cacheDir, err := tuskCacheDir(cfgCacheDir)
+	if err != nil {
+		return "", err
+	}
+
+	return filepath.Join(cacheDir, "includes", strings.ToLower(checksum)+".yml"), nil
+}
+
+// writeCacheFile writes a file atomically, so that an interrupted write never
+// leaves a partial file behind.
+func writeCacheFile(path string, data []byte) error {
+	if err := os.MkdirAll(filepath.Dir(path), 0o700); err != nil {
+		return err
+	}
+
+	f, err := os.CreateTemp(filepath.Dir(path), ".tmp-*")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(f.Name()) //nolint:errcheck
+
+	_, err = f.Write(data)
+	if closeErr := f.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		return err
+	}
+
+	return os.Rename(f.Name(), path)
+}
+
+// validateChecksum checks that a checksum, if given, is a sha256 hash.
+func validateChecksum(checksum string) error {
+	if checksum == "" {
+		return nil
+	}
+
+	b, err := hex.DecodeString(checksum)
+	if err != nil || len(b) != sha256.Size {
+		return fmt.Errorf("invalid sha256 checksum %q", checksum)
+	}
+
+	return nil
+}
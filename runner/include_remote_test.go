@@ -0,0 +1,170 @@
+package runner
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/rliebz/ghost"
+	"github.com/rliebz/ghost/be"
+)
+
+// serveIncludes serves files over HTTPS, counting the requests made.
+func serveIncludes(t *testing.T, files map[string]string) (url string, requests *int) {
+	t.Helper()
+
+	requests = new(int)
+	server := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		*requests++
+		content, ok := files[r.URL.Path]
+		if !ok {
+			http.NotFound(w, r)
+			return
+		}
+		w.Write([]byte(content)) //nolint:errcheck
+	}))
+	t.Cleanup(server.Close)
+
+	client := remoteIncludeClient
+	remoteIncludeClient = server.Client()
+	t.Cleanup(func() { remoteIncludeClient = client })
+
+	t.Setenv("XDG_CACHE_HOME", t.TempDir())
+
+	return server.URL, requests
+}
+
+func sha256Hex(s string) string {
+	sum := sha256.Sum256([]byte(s))
+	return hex.EncodeToString(sum[:])
+}
+
+func TestParse_include_remote(t *testing.T) {
+	g := ghost.New(t)
+
+	release := "usage: Release\nrun: echo release"
+	url, requests := serveIncludes(t, map[string]string{"/tasks/release.yml": release})
+
+	cfgText := "tasks: { release: { include: " + url + "/tasks/release.yml, " +
+		"sha256: " + sha256Hex(release) + " } }"
+
+	cfg, err := Parse(&ParseConfig{CfgPath: "tusk.yml", CfgText: []byte(cfgText)})
+	g.NoError(err)
+	g.Should(be.Equal(cfg.Tasks["release"].Usage, "Release"))
+	g.Should(be.Equal(*requests, 1))
+
+	// The cached copy is used, even when offline.
+	cfg, err = Parse(&ParseConfig{CfgPath: "tusk.yml", CfgText: []byte(cfgText), Offline: true})
+	g.NoError(err)
+	g.Should(be.Equal(cfg.Tasks["release"].Usage, "Release"))
+	g.Should(be.Equal(*requests, 1))
+}
+
+func TestParse_include_remote_nested(t *testing.T) {
+	g := ghost.New(t)
+
+	inner := "usage: Inner\nrun: echo inner"
+	outer := "include: inner.yml\nsha256: " + sha256Hex(inner)
+	url, _ := serveIncludes(t, map[string]string{
+		"/tasks/outer.yml": outer,
+		"/tasks/inner.yml": inner,
+	})
+
+	cfgText := "tasks: { foo: { include: " + url + "/tasks/outer.yml, " +
+		"sha256: " + sha256Hex(outer) + " } }"
+
+	cfg, err := Parse(&ParseConfig{CfgPath: "tusk.yml", CfgText: []byte(cfgText)})
+	g.NoError(err)
+	g.Should(be.Equal(cfg.Tasks["foo"].Usage, "Inner"))
+}
+
+func TestParse_include_remote_errors(t *testing.T) {
+	release := "run: echo release"
+
+	tests := []struct {
+		name     string
+		path     string
+		checksum string
+		offline  bool
+		wantErr  string
+	}{
+		{
+			name:     "checksum mismatch",
+			path:     "/release.yml",
+			checksum: sha256Hex("something else"),
+			wantErr: `include "{url}/release.yml": checksum mismatch: ` +
+				"expected sha256 " + sha256Hex("something else") + ", got " + sha256Hex(release),
+		},
+		{
+			name:     "not found",
+			path:     "/missing.yml",
+			checksum: sha256Hex(release),
+			wantErr:  `fetching include "{url}/missing.yml": unexpected status "404 Not Found"`,
+		},
+		{
+			name:     "missing checksum",
+			path:     "/release.yml",
+			checksum: "",
+			wantErr:  `include "{url}/release.yml" must specify a sha256 checksum`,
+		},
+		{
+			name:     "offline without cache",
+			path:     "/release.yml",
+			checksum: sha256Hex(release),
+			offline:  true,
+			wantErr:  `include "{url}/release.yml" is not cached and cannot be fetched offline`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			g := ghost.New(t)
+
+			url, _ := serveIncludes(t, map[string]string{"/release.yml": release})
+
+			cfgText := "tasks: { release: { include: " + url + tt.path
+			if tt.checksum != "" {
+				cfgText += ", sha256: " + tt.checksum
+			}
+			cfgText += " } }"
+
+			_, err := Parse(&ParseConfig{
+				CfgPath: "tusk.yml",
+				CfgText: []byte(cfgText),
+				Offline: tt.offline,
+			})
+			g.Should(be.ErrorEqual(
+				err,
+				`task "release": `+strings.ReplaceAll(tt.wantErr, "{url}", url),
+			))
+		})
+	}
+}
+
+func TestParse_include_http(t *testing.T) {
+	g := ghost.New(t)
+
+	cfgText := "tasks: { foo: { include: http://example.com/foo.yml, sha256: " +
+		sha256Hex("") + " } }"
+
+	_, err := Parse(&ParseConfig{CfgPath: "tusk.yml", CfgText: []byte(cfgText)})
+	g.Should(be.ErrorEqual(err, `task "foo": include "http://example.com/foo.yml" must use https`))
+}
+
+func TestParse_include_local_checksum(t *testing.T) {
+	g := ghost.New(t)
+
+	dir := t.TempDir()
+	content := "run: echo foo"
+	g.NoError(os.WriteFile(filepath.Join(dir, "foo.yml"), []byte(content), 0o600))
+
+	cfgText := "tasks: { foo: { include: foo.yml, sha256: " + sha256Hex("other") + " } }"
+
+	_, err := Parse(&ParseConfig{CfgPath: filepath.Join(dir, "tusk.yml"), CfgText: []byte(cfgText)})
+	g.Should(be.ErrorContaining(err, "checksum mismatch"))
+}
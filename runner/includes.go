@@ -0,0 +1,239 @@
+package runner
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"maps"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+
+	"github.com/bmatcuk/doublestar/v4"
+	yaml "gopkg.in/yaml.v2"
+
+	"github.com/rliebz/tusk/marshal"
+)
+
+// includedFile is the format of a file listed in the config's includes.
+type includedFile struct {
+	Includes   marshal.Slice[string] `yaml:"includes"`
+	Tasks      Tasks                 `yaml:"tasks"`
+	Extensions map[string]any        `yaml:",inline"`
+}
+
+// UnmarshalYAML allows keys starting with "x-", as in the main config file.
+func (f *includedFile) UnmarshalYAML(unmarshal func(any) error) error {
+	type includedFileType includedFile // Use new type to avoid recursion
+	return unmarshalWithExtensions(unmarshal, (*includedFileType)(f), &f.Extensions)
+}
+
+// includeLoader merges the tasks of included files into a config.
+type includeLoader struct {
+	// dir is the directory of the config file, which file names in errors are
+	// relative to.
+	dir string
+
+	tasks   map[string]*Task
+	owners  map[string]string
+	visited map[string]bool
+	reader  includeReader
+}
+
+// taskDirName is the name of the directory next to the config file whose YAML
+// files are included without being listed.
+const taskDirName = ".tusk.d"
+
+// loadIncludes merges the tasks of every file matched by the config's includes,
+// followed by those of the files in the task directory, if there is one. Paths
+// are resolved relative to the directory containing the config file.
+func (c *Config) loadIncludes(cfgPath string, r includeReader) error {
+	cfgPath, err := filepath.Abs(cfgPath)
+	if err != nil {
+		return err
+	}
+
+	taskDir := filepath.Join(filepath.Dir(cfgPath), taskDirName)
+	info, err := os.Stat(taskDir)
+	hasTaskDir := err == nil && info.IsDir()
+
+	if len(c.Includes) == 0 && !hasTaskDir {
+		return nil
+	}
+
+	if c.Tasks == nil {
+		c.Tasks = make(map[string]*Task)
+	}
+
+	l := includeLoader{
+		dir:     filepath.Dir(cfgPath),
+		tasks:   c.Tasks,
+		owners:  make(map[string]string, len(c.Tasks)),
+		visited: map[string]bool{cfgPath: true},
+		reader:  r,
+	}
+	for name := range c.Tasks {
+		l.owners[name] = filepath.Base(cfgPath)
+	}
+
+	stack := []string{cfgPath}
+	if err := l.loadPatterns(l.dir, c.Includes, stack); err != nil {
+		return err
+	}
+
+	if !hasTaskDir {
+		return nil
+	}
+
+	paths, err := yamlFilesIn(taskDir)
+	if err != nil {
+		return fmt.Errorf("reading %s: %w", taskDirName, err)
+	}
+
+	for _, path := range paths {
+		if err := l.loadFile(path, stack); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// loadPatterns loads every file matched by the patterns, which are relative to
+// dir. The stack is the chain of files that included them.
+func (l *includeLoader) loadPatterns(dir string, patterns []string, stack []string) error {
+	for _, pattern := range patterns {
+		paths, err := expandInclude(dir, pattern)
+		if err != nil {
+			return err
+		}
+
+		for _, path := range paths {
+			if err := l.loadFile(path, stack); err != nil {
+				return err
+			}
+		}
+	}
+
+	return nil
+}
+
+// loadFile merges the tasks defined by a file, then loads its own includes.
+func (l *includeLoader) loadFile(path string, stack []string) error {
+	if i := slices.Index(stack, path); i >= 0 {
+		return newIncludeCycleError(slices.Concat(stack[i:], []string{path}), l.rel)
+	}
+
+	if l.visited[path] {
+		return nil
+	}
+	l.visited[path] = true
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return fmt.Errorf("opening included file: %w", err)
+	}
+
+	decoder := yaml.NewDecoder(bytes.NewReader(data))
+	decoder.SetStrict(true)
+
+	var included includedFile
+	if err := decoder.Decode(&included); err != nil {
+		var typeErr *yaml.TypeError
+		var kerr *keyError
+		if errors.As(err, &typeErr) || errors.As(err, &kerr) {
+			return locateNamedError(l.rel(path), data, err)
+		}
+		return fmt.Errorf("decoding included file %q: %w", l.rel(path), err)
+	}
+
+	dir := filepath.Dir(path)
+	for _, name := range slices.Sorted(maps.Keys(included.Tasks)) {
+		if owner, ok := l.owners[name]; ok {
+			return fmt.Errorf(
+				"task %q is defined in both %s and %s",
+				name, owner, l.rel(path),
+			)
+		}
+
+		t := included.Tasks[name]
+		t.Name = name
+		if err := t.loadInclude(dir, l.reader); err != nil {
+			return eachError(err, func(err error) error {
+				return fmt.Errorf("task %q: %w", name, err)
+			})
+		}
+
+		l.tasks[name] = t
+		l.owners[name] = l.rel(path)
+	}
+
+	return l.loadPatterns(dir, included.Includes, append(slices.Clip(stack), path))
+}
+
+// rel returns a path relative to the config file's directory where possible,
+// for use in error messages.
+func (l *includeLoader) rel(path string) string {
+	rel, err := filepath.Rel(l.dir, path)
+	if err != nil {
+		return path
+	}
+	return rel
+}
+
+// expandInclude returns the files matched by an include pattern, in order. A
+// directory matches every YAML file directly inside it.
+func expandInclude(dir, pattern string) ([]string, error) {
+	path := pattern
+	if !filepath.IsAbs(path) {
+		path = filepath.Join(dir, path)
+	}
+
+	var paths []string
+	var err error
+	if info, statErr := os.Stat(path); statErr == nil && info.IsDir() {
+		paths, err = yamlFilesIn(path)
+	} else {
+		paths, err = doublestar.FilepathGlob(path, doublestar.WithFilesOnly())
+	}
+	if err != nil {
+		return nil, fmt.Errorf("include %q: %w", pattern, err)
+	}
+	if len(paths) == 0 {
+		return nil, fmt.Errorf("include %q matched no files", pattern)
+	}
+
+	slices.Sort(paths)
+	return paths, nil
+}
+
+// yamlFilesIn returns the YAML files directly inside a directory, in lexical
+// order.
+func yamlFilesIn(dir string) ([]string, error) {
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return nil, err
+	}
+
+	var paths []string
+	for _, entry := range entries {
+		ext := filepath.Ext(entry.Name())
+		if entry.IsDir() || (ext != ".yml" && ext != ".yaml") {
+			continue
+		}
+		paths = append(paths, filepath.Join(dir, entry.Name()))
+	}
+
+	return paths, nil
+}
+
+// newIncludeCycleError describes a chain of includes that ends where it began,
+// with each path formatted for display.
+func newIncludeCycleError(paths []string, format func(string) string) error {
+	names := make([]string, 0, len(paths))
+	for _, path := range paths {
+		names = append(names, format(path))
+	}
+	return fmt.Errorf("include cycle: %s", strings.Join(names, " -> "))
+}
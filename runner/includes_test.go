@@ -0,0 +1,195 @@
+package runner
+
+import (
+	"maps"
+	"os"
+	"path/filepath"
+	"slices"
+	"testing"
+
+	"github.com/rliebz/ghost"
+	"github.com/rliebz/ghost/be"
+)
+
+func TestParse_includes(t *testing.T) {
+	tests := []struct {
+		name      string
+		files     map[string]string
+		cfgText   string
+		wantTasks []string
+		wantErr   string
+	}{
+		{
+			name: "glob",
+			files: map[string]string{
+				"tasks/build.yml": "tasks: { build: { run: echo build } }",
+				"tasks/test.yml":  "tasks: { test: { run: echo test }, lint: { run: echo lint } }",
+				"tasks/notes.txt": "not yaml",
+			},
+			cfgText:   "includes: tasks/*.yml\ntasks: { main: { run: echo main } }",
+			wantTasks: []string{"build", "lint", "main", "test"},
+		},
+		{
+			name: "directory",
+			files: map[string]string{
+				"tasks/build.yml":    "tasks: { build: { run: echo build } }",
+				"tasks/test.yaml":    "tasks: { test: { run: echo test } }",
+				"tasks/notes.txt":    "not yaml",
+				"tasks/nested/x.yml": "tasks: { x: { run: echo x } }",
+			},
+			cfgText:   "includes: tasks",
+			wantTasks: []string{"build", "test"},
+		},
+		{
+			name: "nested relative to included file",
+			files: map[string]string{
+				"tasks/all.yml":       "includes: [more/*.yml]",
+				"tasks/more/foo.yml":  "tasks: { foo: { include: ../foo-task.yaml } }",
+				"tasks/foo-task.yaml": "run: echo foo",
+			},
+			cfgText:   "includes: tasks/all.yml",
+			wantTasks: []string{"foo"},
+		},
+		{
+			name: "extension keys",
+			files: map[string]string{
+				"tasks.yml": "x-run: &run echo build\ntasks: { build: { run: *run } }",
+			},
+			cfgText:   "includes: tasks.yml",
+			wantTasks: []string{"build"},
+		},
+		{
+			name: "included more than once",
+			files: map[string]string{
+				"a.yml":      "includes: shared.yml",
+				"b.yml":      "includes: shared.yml",
+				"shared.yml": "tasks: { shared: { run: echo shared } }",
+			},
+			cfgText:   "includes: [a.yml, b.yml]",
+			wantTasks: []string{"shared"},
+		},
+		{
+			name: "duplicate task",
+			files: map[string]string{
+				"tasks/a.yml": "tasks: { foo: { run: echo a } }",
+				"tasks/b.yml": "tasks: { foo: { run: echo b } }",
+			},
+			cfgText: "includes: tasks/*.yml",
+			wantErr: `task "foo" is defined in both ` +
+				filepath.Join("tasks", "a.yml") + " and " + filepath.Join("tasks", "b.yml"),
+		},
+		{
+			name: "duplicate of config task",
+			files: map[string]string{
+				"a.yml": "tasks: { foo: { run: echo a } }",
+			},
+			cfgText: "includes: a.yml\ntasks: { foo: { run: echo main } }",
+			wantErr: `task "foo" is defined in both tusk.yml and a.yml`,
+		},
+		{
+			name: "cycle",
+			files: map[string]string{
+				"a.yml": "includes: b.yml",
+				"b.yml": "includes: a.yml",
+			},
+			cfgText: "includes: a.yml",
+			wantErr: "include cycle: a.yml -> b.yml -> a.yml",
+		},
+		{
+			name: "no matches",
+			files: map[string]string{
+				"tasks/a.yml": "tasks: { foo: { run: echo a } }",
+			},
+			cfgText: "includes: other/*.yml",
+			wantErr: `include "other/*.yml" matched no files`,
+		},
+		{
+			name: "task directory",
+			files: map[string]string{
+				".tusk.d/build.yml":  "tasks: { build: { run: echo build } }",
+				".tusk.d/test.yaml":  "includes: ../more.yml\ntasks: { test: { run: echo test } }",
+				".tusk.d/notes.txt":  "not yaml",
+				".tusk.d/sub/a.yml":  "tasks: { nested: { run: echo nested } }",
+				"more.yml":           "tasks: { lint: { run: echo lint } }",
+				"tasks/release.yaml": "tasks: { release: { run: echo release } }",
+			},
+			cfgText:   "includes: tasks\ntasks: { main: { run: echo main } }",
+			wantTasks: []string{"build", "lint", "main", "release", "test"},
+		},
+		{
+			name: "task directory duplicate",
+			files: map[string]string{
+				".tusk.d/a.yml": "tasks: { foo: { run: echo a } }",
+				".tusk.d/b.yml": "tasks: { foo: { run: echo b } }",
+			},
+			cfgText: "tasks: { main: { run: echo main } }",
+			wantErr: `task "foo" is defined in both .tusk.d/a.yml and .tusk.d/b.yml`,
+		},
+		{
+			name: "task directory overlaps config",
+			files: map[string]string{
+				".tusk.d/a.yml": "tasks: { main: { run: echo a } }",
+			},
+			cfgText: "tasks: { main: { run: echo main } }",
+			wantErr: `task "main" is defined in both tusk.yml and .tusk.d/a.yml`,
+		},
+		{
+			name: "invalid",
+			files: map[string]string{
+				"a.yml": "tasks: { foo: { unknown: true } }",
+			},
+			cfgText: "includes: a.yml",
+			wantErr: `a.yml:1: task "foo": field unknown not found in type runner.taskType`,
+		},
+		{
+			name: "unknown key",
+			files: map[string]string{
+				"a.yml": "x-ok: true\nunknown: true\ntasks: {}",
+			},
+			cfgText: "includes: a.yml",
+			wantErr: `a.yml:2: field unknown not found in type runner.includedFileType`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			g := ghost.New(t)
+
+			dir := t.TempDir()
+			for name, content := range tt.files {
+				path := filepath.Join(dir, name)
+				g.NoError(os.MkdirAll(filepath.Dir(path), 0o750))
+				g.NoError(os.WriteFile(path, []byte(content), 0o600))
+			}
+
+			cfg, err := Parse(&ParseConfig{
+				CfgPath: filepath.Join(dir, "tusk.yml"),
+				CfgText: []byte(tt.cfgText),
+			})
+			if tt.wantErr != "" {
+				g.Should(be.ErrorContaining(err, tt.wantErr))
+				return
+			}
+			g.NoError(err)
+
+			g.Should(be.DeepEqual(slices.Sorted(maps.Keys(cfg.Tasks)), tt.wantTasks))
+			for name, task := range cfg.Tasks {
+				g.Should(be.Equal(task.Name, name))
+			}
+		})
+	}
+}
+
+func TestParse_include_cycle(t *testing.T) {
+	g := ghost.New(t)
+
+	dir := t.TempDir()
+	g.NoError(os.WriteFile(filepath.Join(dir, "a.yml"), []byte("include: b.yml"), 0o600))
+	g.NoError(os.WriteFile(filepath.Join(dir, "b.yml"), []byte("include: a.yml"), 0o600))
+
+	_, err := Parse(&ParseConfig{
+		CfgPath: filepath.Join(dir, "tusk.yml"),
+		CfgText: []byte("tasks: { foo: { include: a.yml } }"),
+	})
+	g.Should(be.ErrorEqual(err, `task "foo": include cycle: a.yml -> b.yml -> a.yml`))
+}
@@ -0,0 +1,78 @@
+package runner
+
+import (
+	"fmt"
+	"maps"
+	"slices"
+	"strings"
+
+	"github.com/rliebz/tusk/marshal"
+)
+
+// Interpreter is the global interpreter setting. It is either a single
+// interpreter used on every operating system, or a mapping of operating system
+// names to the interpreter used on each.
+type Interpreter struct {
+	// All is the interpreter used regardless of the operating system.
+	All string
+
+	// ByOS is the interpreter to use for each operating system, keyed by the
+	// normalized operating system name.
+	ByOS map[string]string
+}
+
+// UnmarshalYAML allows a string to set the interpreter for every operating
+// system.
+func (i *Interpreter) UnmarshalYAML(unmarshal func(any) error) error {
+	var str string
+	strCandidate := marshal.UnmarshalCandidate{
+		Unmarshal: func() error { return unmarshal(&str) },
+		Validate:  func() error { return validateInterpreter(str) },
+		Assign:    func() { *i = Interpreter{All: str} },
+	}
+
+	var byOS map[string]string
+	var normalized map[string]string
+	mapCandidate := marshal.UnmarshalCandidate{
+		Unmarshal: func() error { return unmarshal(&byOS) },
+		Validate: func() error {
+			var err error
+			normalized, err = normalizeInterpreters(byOS)
+			return err
+		},
+		Assign: func() { *i = Interpreter{ByOS: normalized} },
+	}
+
+	return marshal.UnmarshalOneOf(strCandidate, mapCandidate)
+}
+
+// normalizeInterpreters keys interpreters by normalized operating system name,
+// so that aliases such as "macos" can be used.
+func normalizeInterpreters(byOS map[string]string) (map[string]string, error) {
+	normalized := make(map[string]string, len(byOS))
+	for _, name := range slices.Sorted(maps.Keys(byOS)) {
+		interpreter := byOS[name]
+		if err := validateInterpreter(interpreter); err != nil {
+			return nil, fmt.Errorf("interpreter for %q: %w", name, err)
+		}
+
+		goos := normalizeOS(name)
+		if _, ok := normalized[goos]; ok {
+			return nil, fmt.Errorf("interpreter is defined more than once for %q", goos)
+		}
+		normalized[goos] = interpreter
+	}
+
+	return normalized, nil
+}
+
+// Fields returns the executable and arguments of the interpreter for an
+// operating system. If no interpreter is set for that operating system, the
+// result is empty and the default interpreter should be used.
+func (i Interpreter) Fields(goos string) []string {
+	if i.ByOS != nil {
+		return strings.Fields(i.ByOS[goos])
+	}
+
+	return strings.Fields(i.All)
+}
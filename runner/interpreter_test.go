@@ -0,0 +1,132 @@
+package runner
+
+import (
+	"testing"
+
+	"github.com/rliebz/ghost"
+	"github.com/rliebz/ghost/be"
+	yaml "gopkg.in/yaml.v2"
+)
+
+func TestInterpreter_UnmarshalYAML(t *testing.T) {
+	tests := []struct {
+		name  string
+		input string
+		want  Interpreter
+	}{
+		{
+			name:  "string",
+			input: `bash -c`,
+			want:  Interpreter{All: "bash -c"},
+		},
+		{
+			name:  "operating systems",
+			input: `{linux: bash -c, windows: pwsh -Command}`,
+			want: Interpreter{ByOS: map[string]string{
+				"linux":   "bash -c",
+				"windows": "pwsh -Command",
+			}},
+		},
+		{
+			name:  "operating system aliases",
+			input: `{macos: zsh -c, Win: cmd /c}`,
+			want: Interpreter{ByOS: map[string]string{
+				"darwin":  "zsh -c",
+				"windows": "cmd /c",
+			}},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			g := ghost.New(t)
+
+			var got Interpreter
+			err := yaml.UnmarshalStrict([]byte(tt.input), &got)
+			g.NoError(err)
+
+			g.Should(be.DeepEqual(got, tt.want))
+		})
+	}
+}
+
+func TestInterpreter_UnmarshalYAML_invalid(t *testing.T) {
+	tests := []struct {
+		name    string
+		input   string
+		wantErr string
+	}{
+		{
+			name:    "blank",
+			input:   `" "`,
+			wantErr: "interpreter must name an executable",
+		},
+		{
+			name:    "blank operating system",
+			input:   `{linux: " "}`,
+			wantErr: `interpreter for "linux": interpreter must name an executable`,
+		},
+		{
+			name:    "duplicate operating system",
+			input:   `{darwin: sh -c, macos: zsh -c}`,
+			wantErr: `interpreter is defined more than once for "darwin"`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			g := ghost.New(t)
+
+			var got Interpreter
+			err := yaml.UnmarshalStrict([]byte(tt.input), &got)
+			g.Should(be.ErrorEqual(err, tt.wantErr))
+		})
+	}
+}
+
+func TestInterpreter_Fields(t *testing.T) {
+	tests := []struct {
+		name        string
+		interpreter Interpreter
+		goos        string
+		want        []string
+	}{
+		{
+			name: "unset",
+			goos: "linux",
+			want: []string{},
+		},
+		{
+			name:        "all",
+			interpreter: Interpreter{All: "node -e"},
+			goos:        "windows",
+			want:        []string{"node", "-e"},
+		},
+		{
+			name: "operating system",
+			interpreter: Interpreter{ByOS: map[string]string{
+				"linux":   "bash -c",
+				"windows": "pwsh -Command",
+			}},
+			goos: "windows",
+			want: []string{"pwsh", "-Command"},
+		},
+		{
+			name: "missing operating system",
+			interpreter: Interpreter{ByOS: map[string]string{
+				"windows": "pwsh -Command",
+			}},
+			goos: "darwin",
+			want: []string{},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			g := ghost.New(t)
+
+			got := tt.interpreter.Fields(tt.goos)
+			g.Should(be.DeepEqual(got, tt.want))
+		})
+	}
+}
@@ -0,0 +1,199 @@
+package runner
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"maps"
+	"os"
+	"os/exec"
+	"os/signal"
+	"sync"
+)
+
+// ErrInterrupted is returned when a task is stopped because tusk was
+// interrupted.
+var ErrInterrupted = errors.New("interrupted")
+
+// Interrupts handles interrupt signals while tasks run, rather than letting
+// them stop tusk immediately. When handled gracefully, the first interrupt
+// stops the running command and the rest of the run list of every task, after
+// which the on-failure and finally clauses still run. A second interrupt stops
+// those clauses as well. Otherwise, the first interrupt stops everything.
+//
+// Every interrupt also stops the commands running in the background, since
+// they run in their own process groups and would otherwise be orphaned.
+type Interrupts struct {
+	run      context.Context
+	cleanup  context.Context
+	graceful bool
+	exit     bool
+
+	mu         sync.Mutex
+	cancels    []context.CancelCauseFunc
+	background map[*backgroundProcess]Context
+	signals    chan os.Signal
+}
+
+// NotifyInterrupts starts handling interrupt signals. Stop must be called once
+// the tasks have finished.
+//
+// Handling interrupts without grace still lets tusk finish up once the tasks
+// have stopped, such as by writing a summary file, where an unhandled interrupt
+// would stop it immediately.
+func NotifyInterrupts(graceful bool) *Interrupts {
+	return newInterrupts(graceful).notify()
+}
+
+// StopOnInterrupt starts handling interrupt signals only to stop the commands
+// running in the background, after which the signal stops tusk immediately as
+// though it had not been handled. Stop must be called once the tasks have
+// finished.
+func StopOnInterrupt() *Interrupts {
+	i := newInterrupts(false)
+	i.exit = true
+	return i.notify()
+}
+
+// notify starts handling interrupt signals.
+func (i *Interrupts) notify() *Interrupts {
+	i.signals = make(chan os.Signal, 1)
+	signal.Notify(i.signals, interruptSignals...)
+	go func() {
+		for sig := range i.signals {
+			i.interrupt()
+			if i.exit {
+				signal.Stop(i.signals)
+				raise(sig)
+			}
+		}
+	}()
+
+	return i
+}
+
+func newInterrupts(graceful bool) *Interrupts {
+	run, cancelRun := context.WithCancelCause(context.Background())
+	cleanup, cancelCleanup := context.WithCancelCause(context.Background())
+	return &Interrupts{
+		run:        run,
+		cleanup:    cleanup,
+		graceful:   graceful,
+		cancels:    []context.CancelCauseFunc{cancelRun, cancelCleanup},
+		background: make(map[*backgroundProcess]Context),
+	}
+}
+
+// Stop stops handling interrupt signals.
+func (i *Interrupts) Stop() {
+	if i.signals == nil {
+		return
+	}
+
+	signal.Stop(i.signals)
+	close(i.signals)
+}
+
+// interrupt stops the next phase of running tasks that has not been stopped,
+// or every phase if interrupts are not handled gracefully, along with every
+// command running in the background.
+func (i *Interrupts) interrupt() {
+	i.mu.Lock()
+	n := min(len(i.cancels), 1)
+	if !i.graceful {
+		n = len(i.cancels)
+	}
+
+	for _, cancel := range i.cancels[:n] {
+		cancel(ErrInterrupted)
+	}
+	i.cancels = i.cancels[n:]
+
+	background := maps.Clone(i.background)
+	i.mu.Unlock()
+
+	var wg sync.WaitGroup
+	for p, ctx := range background {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			p.terminate(ctx)
+		}()
+	}
+	wg.Wait()
+}
+
+// addBackground records a command running in the background, so that it is
+// stopped if tusk is interrupted.
+func (i *Interrupts) addBackground(ctx Context, p *backgroundProcess) {
+	if i == nil {
+		return
+	}
+
+	i.mu.Lock()
+	defer i.mu.Unlock()
+	i.background[p] = ctx
+}
+
+// removeBackground forgets a command that is no longer running.
+func (i *Interrupts) removeBackground(p *backgroundProcess) {
+	if i == nil {
+		return
+	}
+
+	i.mu.Lock()
+	defer i.mu.Unlock()
+	delete(i.background, p)
+}
+
+// interruption returns a context that is done once the current phase of the
+// task is interrupted, or nil if interrupts are not handled.
+func (c Context) interruption() context.Context {
+	switch {
+	case c.Interrupts == nil:
+		return nil
+	case c.cleanup:
+		return c.Interrupts.cleanup
+	default:
+		return c.Interrupts.run
+	}
+}
+
+// interrupted returns an error if the current phase of the task has been
+// interrupted.
+func (c Context) interrupted() error {
+	if done := c.interruption(); done != nil {
+		return context.Cause(done)
+	}
+
+	return nil
+}
+
+// runInterruptible runs a command, which is interrupted if the current phase
+// of the task is interrupted before it finishes.
+func runInterruptible(ctx Context, cmd *exec.Cmd) error {
+	done := ctx.interruption()
+	if done == nil {
+		return cmd.Run()
+	}
+
+	if err := ctx.interrupted(); err != nil {
+		return err
+	}
+
+	if err := cmd.Start(); err != nil {
+		return err
+	}
+
+	stop := context.AfterFunc(done, func() {
+		interruptProcess(cmd) //nolint:errcheck
+	})
+	defer stop()
+
+	err := cmd.Wait()
+	if cause := ctx.interrupted(); cause != nil && err != nil {
+		return fmt.Errorf("%w: %w", cause, err)
+	}
+
+	return err
+}
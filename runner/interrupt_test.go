@@ -0,0 +1,145 @@
+package runner
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/rliebz/ghost"
+	"github.com/rliebz/ghost/be"
+
+	"github.com/rliebz/tusk/marshal"
+	"github.com/rliebz/tusk/ui"
+)
+
+func TestTask_Execute_interrupted(t *testing.T) {
+	tests := []struct {
+		name       string
+		interrupts int
+		graceful   bool
+		want       string
+	}{
+		{
+			name:     "not interrupted",
+			graceful: true,
+			want:     "run\nfinally\n",
+		},
+		{
+			name:       "interrupted",
+			interrupts: 1,
+			graceful:   true,
+			want:       "finally\n",
+		},
+		{
+			name:       "interrupted twice",
+			interrupts: 2,
+			graceful:   true,
+		},
+		{
+			name:       "interrupted without grace",
+			interrupts: 1,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			g := ghost.New(t)
+
+			dir := t.TempDir()
+			echo := func(text string) marshal.Slice[*Command] {
+				return marshal.Slice[*Command]{{Exec: "echo " + text + " >> out.txt"}}
+			}
+			task := Task{
+				Name:    "foo",
+				RunList: marshal.Slice[*Run]{{Command: echo("run")}},
+				Finally: marshal.Slice[*Run]{{Command: echo("finally")}},
+			}
+
+			interrupts := newInterrupts(tt.graceful)
+			for range tt.interrupts {
+				interrupts.interrupt()
+			}
+
+			err := task.Execute(Context{
+				CfgPath:    filepath.Join(dir, "tusk.yml"),
+				Logger:     ui.Noop(),
+				Interrupts: interrupts,
+			})
+			if tt.interrupts > 0 {
+				g.Should(be.ErrorIs(err, ErrInterrupted))
+			} else {
+				g.NoError(err)
+			}
+
+			got, err := os.ReadFile(filepath.Join(dir, "out.txt"))
+			if tt.want == "" {
+				g.Should(be.ErrorIs(err, os.ErrNotExist))
+				return
+			}
+			g.NoError(err)
+			g.Should(be.Equal(string(got), tt.want))
+		})
+	}
+}
+
+func TestTask_Execute_interrupted_command(t *testing.T) {
+	g := ghost.New(t)
+
+	task := Task{
+		Name: "foo",
+		RunList: marshal.Slice[*Run]{
+			{Command: marshal.Slice[*Command]{{Exec: "exec sleep 10"}}},
+		},
+	}
+
+	interrupts := newInterrupts(true)
+	time.AfterFunc(50*time.Millisecond, interrupts.interrupt)
+
+	start := time.Now()
+	err := task.Execute(Context{Logger: ui.Noop(), Interrupts: interrupts})
+	g.Should(be.ErrorIs(err, ErrInterrupted))
+	g.Should(be.True(time.Since(start) < 5*time.Second))
+}
+
+func TestTask_Execute_interrupted_background(t *testing.T) {
+	g := ghost.New(t)
+
+	dir := t.TempDir()
+	task := Task{
+		Name: "foo",
+		RunList: marshal.Slice[*Run]{
+			{Command: marshal.Slice[*Command]{{
+				Exec:       `trap 'echo stopped > stopped.txt; exit 0' TERM; while :; do sleep 0.05; done`,
+				Background: true,
+			}}},
+			{Command: marshal.Slice[*Command]{{Exec: "exec sleep 10"}}},
+		},
+		// The background command is only stopped by the task after the finally
+		// clause, so it must be stopped by the interrupt for this to finish.
+		Finally: marshal.Slice[*Run]{
+			{Command: marshal.Slice[*Command]{{
+				Exec: `while [ ! -f stopped.txt ]; do sleep 0.05; done`,
+			}}},
+		},
+	}
+
+	interrupts := newInterrupts(true)
+	time.AfterFunc(200*time.Millisecond, interrupts.interrupt)
+
+	done := make(chan error)
+	go func() {
+		done <- task.Execute(Context{
+			CfgPath:    filepath.Join(dir, "tusk.yml"),
+			Logger:     ui.Noop(),
+			Interrupts: interrupts,
+		})
+	}()
+
+	select {
+	case err := <-done:
+		g.Should(be.ErrorIs(err, ErrInterrupted))
+	case <-time.After(5 * time.Second):
+		t.Fatal("background command was not stopped when interrupted")
+	}
+}
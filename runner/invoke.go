@@ -0,0 +1,61 @@
+package runner
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/rliebz/tusk/ui"
+)
+
+// RunTask runs a task from the config file at ctx.CfgPath without the command
+// line interface. The args and option values are passed by name, the same as
+// they would be passed on the command line, so passing an option that the task
+// does not define or that is private is an error.
+//
+// If ctx.Logger is nil, nothing is printed.
+func RunTask(ctx Context, taskName string, args []string, flags map[string]string) error {
+	cfgText, err := os.ReadFile(ctx.CfgPath)
+	if err != nil {
+		return fmt.Errorf("reading config file: %w", err)
+	}
+
+	cfg, err := ParseComplete(&ParseConfig{
+		Args:         args,
+		CfgPath:      ctx.CfgPath,
+		CfgText:      cfgText,
+		Flags:        flags,
+		Interpreter:  ctx.Interpreter,
+		MaxTaskDepth: ctx.MaxTaskDepth,
+		TaskName:     taskName,
+	})
+	if err != nil {
+		return err
+	}
+
+	t, ok := cfg.Tasks[taskName]
+	if !ok {
+		return fmt.Errorf("task %q is not defined", taskName)
+	}
+
+	return ExecuteTask(ctx, cfg, t)
+}
+
+// ExecuteTask runs a task of a config parsed with ParseComplete, whose args and
+// option values have already been passed.
+//
+// If ctx.Hooks or ctx.CacheDir are not set, those of the config are used. If
+// ctx.Logger is nil, nothing is printed.
+func ExecuteTask(ctx Context, cfg *Config, t *Task) error {
+	if ctx.Hooks == nil {
+		ctx.Hooks = cfg.Hooks
+	}
+	if ctx.CacheDir == "" {
+		ctx.CacheDir = cfg.CacheDir
+	}
+	if ctx.Logger == nil {
+		ctx.Logger = ui.Noop()
+	}
+	defer ctx.Completed.finish()
+
+	return t.Execute(ctx)
+}
@@ -0,0 +1,127 @@
+package runner
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/rliebz/ghost"
+	"github.com/rliebz/ghost/be"
+
+	"github.com/rliebz/tusk/internal/xtesting"
+)
+
+func TestRunTask(t *testing.T) {
+	g := ghost.New(t)
+
+	dir := xtesting.UseTempDir(t)
+	cfgPath := filepath.Join(dir, "tusk.yml")
+
+	err := os.WriteFile(cfgPath, []byte(`
+options:
+  greeting:
+    default: Hello
+tasks:
+  greet:
+    args:
+      name: {}
+    options:
+      punctuation:
+        default: "."
+    run: echo "${greeting}, ${name}${punctuation}" > out.txt
+`), 0o600)
+	g.NoError(err)
+
+	err = RunTask(
+		Context{CfgPath: cfgPath},
+		"greet",
+		[]string{"World"},
+		map[string]string{"punctuation": "!"},
+	)
+	g.NoError(err)
+
+	out, err := os.ReadFile("out.txt")
+	g.NoError(err)
+	g.Should(be.Equal(string(out), "Hello, World!\n"))
+}
+
+func TestRunTask_errors(t *testing.T) {
+	dir := xtesting.UseTempDir(t)
+	cfgPath := filepath.Join(dir, "tusk.yml")
+
+	err := os.WriteFile(cfgPath, []byte(`
+tasks:
+  greet:
+    args:
+      name: {}
+    options:
+      loud:
+        type: bool
+      secret:
+        private: true
+        default: hidden
+    run: exit 1
+`), 0o600)
+	ghost.New(t).NoError(err)
+
+	tests := []struct {
+		name     string
+		cfgPath  string
+		taskName string
+		args     []string
+		flags    map[string]string
+		wantErr  string
+	}{
+		{
+			name:     "missing config",
+			cfgPath:  filepath.Join(dir, "missing.yml"),
+			taskName: "greet",
+			wantErr:  "reading config file: open " + filepath.Join(dir, "missing.yml"),
+		},
+		{
+			name:     "undefined task",
+			cfgPath:  cfgPath,
+			taskName: "wrong",
+			wantErr:  `task "wrong" is not defined`,
+		},
+		{
+			name:     "wrong arg count",
+			cfgPath:  cfgPath,
+			taskName: "greet",
+			wantErr:  `task "greet" requires exactly 1 args, got 0`,
+		},
+		{
+			name:     "undefined option",
+			cfgPath:  cfgPath,
+			taskName: "greet",
+			args:     []string{"World"},
+			flags:    map[string]string{"lowd": "true"},
+			wantErr:  `option "lowd" is not defined for task "greet"`,
+		},
+		{
+			name:     "private option",
+			cfgPath:  cfgPath,
+			taskName: "greet",
+			args:     []string{"World"},
+			flags:    map[string]string{"secret": "shown"},
+			wantErr:  `option "secret" of task "greet" is private`,
+		},
+		{
+			name:     "task failure",
+			cfgPath:  cfgPath,
+			taskName: "greet",
+			args:     []string{"World"},
+			flags:    map[string]string{"loud": "true"},
+			wantErr:  "exit status 1",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			g := ghost.New(t)
+
+			err := RunTask(Context{CfgPath: tt.cfgPath}, tt.taskName, tt.args, tt.flags)
+			g.Should(be.ErrorContaining(err, tt.wantErr))
+		})
+	}
+}
@@ -0,0 +1,199 @@
+package runner
+
+import (
+	"errors"
+	"fmt"
+	"path/filepath"
+	"regexp"
+	"slices"
+
+	yaml "gopkg.in/yaml.v2"
+	yamlv3 "gopkg.in/yaml.v3"
+)
+
+// keyError is an error for a value in a config file, along with the path of
+// keys leading to the value so that its line can be found later.
+//
+// The YAML library used for decoding does not track positions, so the line is
+// looked up from the original text only when an error is reported.
+type keyError struct {
+	keys []string
+	err  error
+}
+
+// Error returns the message of the underlying error.
+func (e *keyError) Error() string {
+	return e.err.Error()
+}
+
+// Unwrap returns the underlying error.
+func (e *keyError) Unwrap() error {
+	return e.err
+}
+
+// withKeys records that an error is for the value at the given path of keys.
+// Any keys already recorded by the error are relative to that value.
+func withKeys(err error, keys ...string) error {
+	var kerr *keyError
+	if errors.As(err, &kerr) {
+		keys = slices.Concat(keys, kerr.keys)
+	}
+
+	return &keyError{keys: keys, err: err}
+}
+
+// entryError adds the kind, name, and key path of a named entry, such as an
+// option, to an error from unmarshaling it. Type errors are returned as they
+// are, since they already include line numbers.
+func entryError(err error, kind, key, name string) error {
+	var typeErr *yaml.TypeError
+	if errors.As(err, &typeErr) {
+		return err
+	}
+
+	return withKeys(fmt.Errorf("%s %q: %w", kind, name, err), key, name)
+}
+
+// typeErrorPattern matches a problem found by strict unmarshaling, which starts
+// with the line it was found on.
+var typeErrorPattern = regexp.MustCompile(`(?s)^line (\d+): (.*)$`)
+
+// prefixTypeError adds a prefix to a problem found by strict unmarshaling,
+// keeping the line number first.
+func prefixTypeError(msg, prefix string) string {
+	m := typeErrorPattern.FindStringSubmatch(msg)
+	if m == nil {
+		return prefix + msg
+	}
+
+	return "line " + m[1] + ": " + prefix + m[2]
+}
+
+// typeErrors splits the problems found by strict unmarshaling into a list, so
+// that each can be reported on its own. Each line number is prefixed with the
+// name of the file, unless the name is empty.
+func typeErrors(name string, typeErr *yaml.TypeError) ValidationErrors {
+	errs := make(ValidationErrors, 0, len(typeErr.Errors))
+	for _, msg := range typeErr.Errors {
+		m := typeErrorPattern.FindStringSubmatch(msg)
+		if m == nil || name == "" {
+			errs = append(errs, errors.New(msg))
+			continue
+		}
+
+		errs = append(errs, fmt.Errorf("%s:%s: %s", name, m[1], m[2]))
+	}
+
+	return errs
+}
+
+// locateError prefixes an error with the name of the config file and the line
+// of the value it is for, if that line can be found in the text of the file.
+// Problems found by strict unmarshaling are returned as [ValidationErrors], and
+// each problem in [ValidationErrors] is located on its own.
+func locateError(cfgPath string, text []byte, err error) error {
+	name := ""
+	if cfgPath != "" {
+		name = filepath.Base(cfgPath)
+	}
+
+	return locateNamedError(name, text, err)
+}
+
+// locateNamedError is like [locateError], but takes the name of the file to
+// report rather than its path.
+func locateNamedError(name string, text []byte, err error) error {
+	if errs, ok := err.(ValidationErrors); ok { //nolint:errorlint // Only a list itself is split.
+		located := make(ValidationErrors, 0, len(errs))
+		for _, err := range errs {
+			err = locateNamedError(name, text, err)
+			if nested, ok := err.(ValidationErrors); ok { //nolint:errorlint // Only a list itself is split.
+				located = append(located, nested...)
+				continue
+			}
+			located = append(located, err)
+		}
+		return located
+	}
+
+	var typeErr *yaml.TypeError
+	if errors.As(err, &typeErr) {
+		return typeErrors(name, typeErr)
+	}
+
+	line, ok := errorLine(text, err)
+	switch {
+	case !ok:
+		return err
+	case name == "":
+		return fmt.Errorf("line %d: %w", line, err)
+	default:
+		return fmt.Errorf("%s:%d: %w", name, line, err)
+	}
+}
+
+// errorLine returns the line of the value an error is for. Only values that
+// can be found by following every key recorded by the error are located.
+func errorLine(text []byte, err error) (int, bool) {
+	var kerr *keyError
+	if !errors.As(err, &kerr) {
+		return 0, false
+	}
+
+	var doc yamlv3.Node
+	if yamlv3.Unmarshal(text, &doc) != nil || len(doc.Content) == 0 {
+		return 0, false
+	}
+
+	line := 0
+	node := doc.Content[0]
+	for _, key := range kerr.keys {
+		k, v, ok := lookupKey(node, key)
+		if !ok {
+			return 0, false
+		}
+		line, node = k.Line, v
+	}
+
+	return line, true
+}
+
+// lookupKey returns the key and value nodes for a key of a mapping. Keys that
+// are brought in by merge keys are found as well, but those set directly take
+// precedence.
+func lookupKey(node *yamlv3.Node, key string) (k, v *yamlv3.Node, ok bool) {
+	node = resolveAlias(node)
+	if node.Kind != yamlv3.MappingNode {
+		return nil, nil, false
+	}
+
+	var merged []*yamlv3.Node
+	for i := 0; i+1 < len(node.Content); i += 2 {
+		k, v := node.Content[i], resolveAlias(node.Content[i+1])
+		switch {
+		case k.ShortTag() == "!!merge" && v.Kind == yamlv3.SequenceNode:
+			merged = append(merged, v.Content...)
+		case k.ShortTag() == "!!merge":
+			merged = append(merged, v)
+		case k.Value == key:
+			return k, v, true
+		}
+	}
+
+	for _, m := range merged {
+		if k, v, ok := lookupKey(m, key); ok {
+			return k, v, true
+		}
+	}
+
+	return nil, nil, false
+}
+
+// resolveAlias returns the node an alias refers to, or the node itself if it
+// is not an alias.
+func resolveAlias(node *yamlv3.Node) *yamlv3.Node {
+	for node.Kind == yamlv3.AliasNode {
+		node = node.Alias
+	}
+	return node
+}
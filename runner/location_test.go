@@ -0,0 +1,119 @@
+package runner
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/rliebz/ghost"
+	"github.com/rliebz/ghost/be"
+)
+
+func TestParse_error_location(t *testing.T) {
+	tests := []struct {
+		name    string
+		files   map[string]string
+		cfgText string
+		wantErr string
+	}{
+		{
+			name: "task",
+			cfgText: `
+tasks:
+  build:
+    run: echo build
+  deploy:
+    args: {env: {}}
+    options: {env: {}}
+`,
+			wantErr: `tusk.yml:7: task "deploy": ` +
+				`argument and option "env" must have unique names within a task`,
+		},
+		{
+			name: "task option",
+			cfgText: `
+tasks:
+  deploy:
+    options:
+      env:
+        short: ab
+`,
+			wantErr: `tusk.yml:5: task "deploy": option "env": ` +
+				`option short name "ab" cannot exceed one character`,
+		},
+		{
+			name: "shared option",
+			cfgText: `
+options:
+  env: {required: true, default: dev}
+`,
+			wantErr: `tusk.yml:3: option "env": default value defined for required option`,
+		},
+		{
+			name: "arg name",
+			cfgText: `
+tasks:
+  deploy:
+    args:
+      tusk.env: {}
+`,
+			wantErr: `tusk.yml:5: task "deploy": arg "tusk.env": ` +
+				`names starting with "tusk." are reserved`,
+		},
+		{
+			name: "merged option",
+			cfgText: `
+x-options: &options
+  env: {required: true, default: dev}
+tasks:
+  deploy:
+    options:
+      <<: *options
+`,
+			wantErr: `tusk.yml:3: task "deploy": option "env": default value defined for required option`,
+		},
+		{
+			name: "included file",
+			files: map[string]string{
+				"tasks.yml": "tasks:\n  deploy:\n    source: in.txt\n",
+			},
+			cfgText: "includes: tasks.yml",
+			wantErr: `tasks.yml:3: task "deploy": task source cannot be defined without target`,
+		},
+		{
+			name: "included task",
+			files: map[string]string{
+				"deploy.yml": "options:\n  env:\n    short: ab\n",
+			},
+			cfgText: "tasks: { deploy: { include: deploy.yml } }",
+			wantErr: `task "deploy": deploy.yml:2: option "env": ` +
+				`option short name "ab" cannot exceed one character`,
+		},
+		{
+			name: "type errors",
+			cfgText: `
+tasks:
+  deploy:
+    unknown: true
+`,
+			wantErr: `tusk.yml:4: task "deploy": field unknown not found in type runner.taskType`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			g := ghost.New(t)
+
+			dir := t.TempDir()
+			for name, text := range tt.files {
+				g.NoError(os.WriteFile(filepath.Join(dir, name), []byte(text), 0o600))
+			}
+
+			_, err := Parse(&ParseConfig{
+				CfgPath: filepath.Join(dir, "tusk.yml"),
+				CfgText: []byte(tt.cfgText),
+			})
+			g.Should(be.ErrorEqual(err, tt.wantErr))
+		})
+	}
+}
@@ -0,0 +1,142 @@
+package runner
+
+import (
+	"errors"
+	"fmt"
+	"slices"
+	"strings"
+
+	yaml "gopkg.in/yaml.v2"
+
+	"github.com/rliebz/tusk/marshal"
+)
+
+// Matrix is an ordered set of variables, each with a list of values. The
+// commands of a run item with a matrix run once for every combination.
+type Matrix []*MatrixAxis
+
+// MatrixAxis is a single variable of a matrix and the values it takes.
+type MatrixAxis struct {
+	Name   string
+	Values marshal.Slice[string]
+}
+
+// UnmarshalYAML unmarshals an ordered set of variables.
+func (m *Matrix) UnmarshalYAML(unmarshal func(any) error) error {
+	var axes Matrix
+	assign := func(name string, unmarshal func(any) error) error {
+		if err := validateVarName("matrix variable", name); err != nil {
+			return err
+		}
+
+		var values marshal.Slice[string]
+		if err := unmarshal(&values); err != nil {
+			return err
+		}
+
+		if len(values) == 0 {
+			return fmt.Errorf("matrix variable %q has no values", name)
+		}
+
+		axes = append(axes, &MatrixAxis{Name: name, Values: values})
+		return nil
+	}
+
+	if err := marshal.UnmarshalOrderedMap(unmarshal, assign); err != nil {
+		return err
+	}
+
+	if len(axes) == 0 {
+		return errors.New("matrix must have at least one variable")
+	}
+
+	*m = axes
+	return nil
+}
+
+// MarshalYAML represents a matrix as a mapping, preserving its order.
+func (m Matrix) MarshalYAML() (any, error) {
+	ms := make(yaml.MapSlice, 0, len(m))
+	for _, axis := range m {
+		ms = append(ms, yaml.MapItem{Key: axis.Name, Value: axis.Values})
+	}
+	return ms, nil
+}
+
+// names returns the names of the matrix variables.
+func (m Matrix) names() []string {
+	names := make([]string, 0, len(m))
+	for _, axis := range m {
+		names = append(names, axis.Name)
+	}
+	return names
+}
+
+// matrixCell is a single combination of matrix values.
+type matrixCell struct {
+	values map[string]string
+	label  string
+}
+
+// cells returns every combination of values, varying the last variable
+// fastest.
+func (m Matrix) cells() []matrixCell {
+	combinations := [][]string{nil}
+	for _, axis := range m {
+		next := make([][]string, 0, len(combinations)*len(axis.Values))
+		for _, combination := range combinations {
+			for _, value := range axis.Values {
+				next = append(next, slices.Concat(combination, []string{value}))
+			}
+		}
+		combinations = next
+	}
+
+	cells := make([]matrixCell, 0, len(combinations))
+	for _, combination := range combinations {
+		values := make(map[string]string, len(m))
+		coordinates := make([]string, 0, len(m))
+		for i, axis := range m {
+			values[axis.Name] = combination[i]
+			coordinates = append(coordinates, axis.Name+"="+combination[i])
+		}
+
+		cells = append(cells, matrixCell{
+			values: values,
+			label:  strings.Join(coordinates, " "),
+		})
+	}
+
+	return cells
+}
+
+// forCell returns a copy of the run item where the commands have the matrix
+// variables replaced by the values of a cell.
+func (r *Run) forCell(cell matrixCell) (*Run, error) {
+	result := *r
+	result.Matrix = nil
+
+	// Interpolation decodes into new commands, leaving the originals untouched.
+	if err := marshal.Interpolate(&result.Command, cell.values); err != nil {
+		return nil, err
+	}
+
+	return &result, nil
+}
+
+// runMatrix runs the commands of a run item once for each cell of its matrix,
+// stopping at the first cell that fails.
+func (t *Task) runMatrix(ctx Context, r *Run, s executionState) error {
+	for _, cell := range r.Matrix.cells() {
+		cellRun, err := r.forCell(cell)
+		if err != nil {
+			return err
+		}
+
+		if err := t.runCommands(ctx.withMatrixCell(cell.label), cellRun, s); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
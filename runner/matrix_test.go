@@ -0,0 +1,143 @@
+package runner
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/rliebz/ghost"
+	"github.com/rliebz/ghost/be"
+	yaml "gopkg.in/yaml.v2"
+
+	"github.com/rliebz/tusk/marshal"
+	"github.com/rliebz/tusk/ui"
+)
+
+func TestRun_UnmarshalYAML_matrix(t *testing.T) {
+	tests := []struct {
+		name    string
+		input   string
+		want    Matrix
+		wantErr string
+	}{
+		{
+			name:  "ordered",
+			input: `{ matrix: { os: [linux, darwin], go: "1.23" }, command: echo }`,
+			want: Matrix{
+				{Name: "os", Values: marshal.Slice[string]{"linux", "darwin"}},
+				{Name: "go", Values: marshal.Slice[string]{"1.23"}},
+			},
+		},
+		{
+			name:    "empty variable",
+			input:   `{ matrix: { os: [] }, command: echo }`,
+			wantErr: `matrix variable "os" has no values`,
+		},
+		{
+			name:    "no variables",
+			input:   `{ matrix: {}, command: echo }`,
+			wantErr: "matrix must have at least one variable",
+		},
+		{
+			name:    "without command",
+			input:   `{ matrix: { os: [linux] }, task: foo }`,
+			wantErr: "`matrix` can only be used with `command`",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			g := ghost.New(t)
+
+			var got Run
+			err := yaml.UnmarshalStrict([]byte(tt.input), &got)
+			if tt.wantErr != "" {
+				g.Should(be.ErrorEqual(err, tt.wantErr))
+				return
+			}
+			g.NoError(err)
+
+			g.Should(be.DeepEqual(got.Matrix, tt.want))
+		})
+	}
+}
+
+func TestMatrix_cells(t *testing.T) {
+	g := ghost.New(t)
+
+	m := Matrix{
+		{Name: "go", Values: marshal.Slice[string]{"1.22", "1.23"}},
+		{Name: "os", Values: marshal.Slice[string]{"linux", "darwin"}},
+	}
+
+	var labels []string
+	for _, cell := range m.cells() {
+		labels = append(labels, cell.label)
+	}
+
+	g.Should(be.DeepEqual(labels, []string{
+		"go=1.22 os=linux",
+		"go=1.22 os=darwin",
+		"go=1.23 os=linux",
+		"go=1.23 os=darwin",
+	}))
+}
+
+func TestTask_Execute_matrix(t *testing.T) {
+	tests := []struct {
+		name       string
+		run        string
+		wantStdout string
+		wantStderr string
+		wantErr    string
+	}{
+		{
+			name:       "every combination",
+			run:        `{ matrix: { a: [1, 2], b: [x, z] }, command: "echo ${a}${b}" }`,
+			wantStdout: "1x\n1z\n2x\n2z\n",
+			wantStderr: "foo > a=1 b=x $ echo 1x\n" +
+				"foo > a=1 b=z $ echo 1z\n" +
+				"foo > a=2 b=x $ echo 2x\n" +
+				"foo > a=2 b=z $ echo 2z\n",
+		},
+		{
+			name:       "functions",
+			run:        `{ matrix: { os: [linux] }, command: "echo ${upper(os)}" }`,
+			wantStdout: "LINUX\n",
+			wantStderr: "foo > os=linux $ echo LINUX\n",
+		},
+		{
+			name:       "stops at first failure",
+			run:        `{ matrix: { code: [0, 1, 2] }, command: "exit ${code}" }`,
+			wantStderr: "foo > code=0 $ exit 0\nfoo > code=1 $ exit 1\nexit status 1\n",
+			wantErr:    "exit status 1",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			g := ghost.New(t)
+
+			var r Run
+			g.NoError(yaml.UnmarshalStrict([]byte(tt.run), &r))
+
+			stdout := new(bytes.Buffer)
+			stderr := new(bytes.Buffer)
+			logger := ui.New(ui.Config{Stdout: stdout, Stderr: stderr})
+
+			exec := r.Command[0].Exec
+			task := Task{Name: "foo", RunList: marshal.Slice[*Run]{&r}}
+			err := task.Execute(Context{Logger: logger})
+			if tt.wantErr != "" {
+				g.Should(be.ErrorEqual(err, tt.wantErr))
+			} else {
+				g.NoError(err)
+			}
+
+			g.Should(be.Equal(stdout.String(), tt.wantStdout))
+			g.Should(be.Equal(stderr.String(), tt.wantStderr))
+
+			// The original commands are left untouched for later runs.
+			g.Should(be.Equal(r.Command[0].Exec, exec))
+		})
+	}
+}
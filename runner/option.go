@@ -1,12 +1,13 @@
 package runner
 
 import (
+	"cmp"
 	"errors"
 	"fmt"
 	"os"
 	"reflect"
-
-	yaml "gopkg.in/yaml.v2"
+	"strconv"
+	"strings"
 
 	"github.com/rliebz/tusk/marshal"
 )
@@ -20,6 +21,18 @@ type Option struct {
 	Required bool
 	Rewrite  string
 
+	// Separator joins the values of a list option for interpolation. It
+	// defaults to a single space.
+	Separator string
+
+	// ExclusiveWith lists the other options of the task that cannot be passed
+	// along with this one.
+	ExclusiveWith marshal.Slice[string] `yaml:"exclusive-with"`
+
+	// Interpreter overrides the interpreter used for commands that compute the
+	// option's default value.
+	Interpreter string
+
 	// Used to determine value
 	Environment   string
 	DefaultValues marshal.Slice[Value] `yaml:"default"`
@@ -77,6 +90,14 @@ func (o *Option) validate() error {
 		return errors.New("rewrite may only be performed on boolean values")
 	}
 
+	if o.Separator != "" && !o.isList() {
+		return errors.New("separator may only be set for list values")
+	}
+
+	if err := validateInterpreter(o.Interpreter); err != nil {
+		return err
+	}
+
 	return nil
 }
 
@@ -85,13 +106,6 @@ func (o *Option) validatePrivate() error {
 		return errors.New("option cannot be both private and required")
 	}
 
-	if o.Environment != "" {
-		return fmt.Errorf(
-			"environment variable %q defined for private option",
-			o.Environment,
-		)
-	}
-
 	if o.Short != "" {
 		return errors.New("option cannot be private and specify a short name")
 	}
@@ -104,7 +118,41 @@ func (o *Option) validatePrivate() error {
 }
 
 func (o *Option) validatePassed(value string) error {
-	return o.Passable.validatePassed("option", value)
+	if !o.isList() {
+		return o.Passable.validatePassed("option", value)
+	}
+
+	if value == "" {
+		return nil
+	}
+
+	for _, item := range strings.Split(value, o.separator()) {
+		if err := o.Passable.validatePassed("option", item); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// JoinValues joins the values passed for a list option into its value.
+func (o *Option) JoinValues(values []string) string {
+	return strings.Join(values, o.separator())
+}
+
+// separator returns the separator used to join the values of a list option.
+func (o *Option) separator() string {
+	return cmp.Or(o.Separator, " ")
+}
+
+// passedValue converts an evaluated value back to the value that would have
+// been passed, undoing any rewrite.
+func (o *Option) passedValue(value string) string {
+	if !o.isBoolean() || o.Rewrite == "" {
+		return value
+	}
+
+	return strconv.FormatBool(value == o.Rewrite)
 }
 
 // Evaluate determines an option's value.
@@ -135,14 +183,14 @@ func (o *Option) getValue(ctx Context, vars map[string]string) (string, error) {
 		return o.cacheValue, nil
 	}
 
-	if !o.Private {
-		if value, found := o.getSpecified(); found {
-			if err := o.validatePassed(value); err != nil {
-				return "", err
-			}
-
-			return value, nil
+	// Private options have no command-line flag, so a passed value can only
+	// come from a parent task.
+	if value, found := o.getSpecified(); found {
+		if err := o.validatePassed(value); err != nil {
+			return "", err
 		}
+
+		return value, nil
 	}
 
 	if o.Required {
@@ -176,7 +224,7 @@ func (o *Option) StaticDefault() (string, bool) {
 		return "", false
 	case 1:
 		value := o.DefaultValues[0]
-		if len(value.When) != 0 || value.Command != "" {
+		if len(value.When) != 0 || value.Command != "" || value.CommandSucceeds != "" {
 			return "", false
 		}
 
@@ -187,6 +235,8 @@ func (o *Option) StaticDefault() (string, bool) {
 }
 
 func (o *Option) getDefaultValue(ctx Context, vars map[string]string) (string, error) {
+	ctx = ctx.withInterpreter(o.Interpreter)
+
 	for _, candidate := range o.DefaultValues {
 		if err := candidate.When.Validate(ctx, vars); err != nil {
 			if !IsFailedCondition(err) {
@@ -195,7 +245,7 @@ func (o *Option) getDefaultValue(ctx Context, vars map[string]string) (string, e
 			continue
 		}
 
-		value, err := candidate.commandValueOrDefault(ctx)
+		value, err := candidate.commandValueOrDefault(ctx, o.Name)
 		if err != nil {
 			return "", fmt.Errorf("could not compute value for option %q: %w", o.Name, err)
 		}
@@ -224,12 +274,7 @@ type Options []*Option
 
 // UnmarshalYAML unmarshals an ordered set of options and assigns names.
 func (o *Options) UnmarshalYAML(unmarshal func(any) error) error {
-	var ms yaml.MapSlice
-	if err := unmarshal(&ms); err != nil {
-		return err
-	}
-
-	options, err := getOptionsWithOrder(ms)
+	options, err := getOptionsWithOrder(unmarshal)
 	if err != nil {
 		return err
 	}
@@ -250,13 +295,17 @@ func (o *Options) Lookup(name string) (*Option, bool) {
 	return nil, false
 }
 
-// getOptionsWithOrder returns both the option map and the ordered names.
-func getOptionsWithOrder(ms yaml.MapSlice) ([]*Option, error) {
-	options := make([]*Option, 0, len(ms))
-	assign := func(name string, text []byte) error {
+// getOptionsWithOrder returns the options in the order they are defined.
+func getOptionsWithOrder(unmarshal func(any) error) ([]*Option, error) {
+	var options []*Option
+	assign := func(name string, unmarshal func(any) error) error {
+		if err := validateVarName("option", name); err != nil {
+			return withKeys(err, "options", name)
+		}
+
 		var opt Option
-		if err := yaml.UnmarshalStrict(text, &opt); err != nil {
-			return err
+		if err := unmarshal(&opt); err != nil {
+			return entryError(err, "option", "options", name)
 		}
 		opt.Name = name
 
@@ -265,6 +314,6 @@ func getOptionsWithOrder(ms yaml.MapSlice) ([]*Option, error) {
 		return nil
 	}
 
-	_, err := marshal.ParseOrderedMap(ms, assign)
+	err := marshal.UnmarshalOrderedMap(unmarshal, assign)
 	return options, err
 }
@@ -8,6 +8,7 @@ import (
 	yaml "gopkg.in/yaml.v2"
 
 	"github.com/rliebz/tusk/marshal"
+	"github.com/rliebz/tusk/ui"
 )
 
 func TestOption_Dependencies(t *testing.T) {
@@ -66,6 +67,20 @@ func TestOption_Evaluate(t *testing.T) {
 			}},
 			"command",
 		},
+		{
+			"command succeeds",
+			&Option{DefaultValues: marshal.Slice[Value]{
+				{CommandSucceeds: "exit 0"},
+			}},
+			"true",
+		},
+		{
+			"command fails",
+			&Option{DefaultValues: marshal.Slice[Value]{
+				{CommandSucceeds: "echo fail && exit 1"},
+			}},
+			"false",
+		},
 		{
 			"environment variable only",
 			&Option{Environment: "OPTION_VAR"},
@@ -76,6 +91,16 @@ func TestOption_Evaluate(t *testing.T) {
 			&Option{Passable: Passable{Passed: "passed"}},
 			"passed",
 		},
+		{
+			"private environment variable",
+			&Option{Private: true, Environment: "OPTION_VAR"},
+			"option_val",
+		},
+		{
+			"private passed variable",
+			&Option{Private: true, Passable: Passable{Passed: "passed"}},
+			"passed",
+		},
 		{
 			"conditional value",
 			&Option{DefaultValues: marshal.Slice[Value]{
@@ -104,7 +129,7 @@ func TestOption_Evaluate(t *testing.T) {
 		t.Run(tt.name, func(t *testing.T) {
 			g := ghost.New(t)
 
-			got, err := tt.input.Evaluate(Context{}, nil)
+			got, err := tt.input.Evaluate(Context{Logger: ui.Noop()}, nil)
 			g.NoError(err)
 
 			g.Should(be.Equal(got, tt.want))
@@ -112,6 +137,74 @@ func TestOption_Evaluate(t *testing.T) {
 	}
 }
 
+func TestOption_Evaluate_command_interpreter(t *testing.T) {
+	g := ghost.New(t)
+
+	option := Option{
+		Interpreter: "echo from",
+		DefaultValues: marshal.Slice[Value]{
+			{Command: "option interpreter"},
+		},
+	}
+
+	ctx := Context{Logger: ui.Noop(), Interpreter: []string{"false"}}
+	got, err := option.Evaluate(ctx, nil)
+	g.NoError(err)
+
+	g.Should(be.Equal(got, "from option interpreter"))
+}
+
+func TestOption_Evaluate_commandSucceeds_interpreter(t *testing.T) {
+	g := ghost.New(t)
+
+	option := Option{
+		DefaultValues: marshal.Slice[Value]{
+			{CommandSucceeds: "exit 0"},
+		},
+	}
+
+	ctx := Context{Logger: ui.Noop(), Interpreter: []string{"false"}}
+	got, err := option.Evaluate(ctx, nil)
+	g.NoError(err)
+
+	g.Should(be.Equal(got, "false"))
+}
+
+func TestOption_Evaluate_commandSucceeds_not_run(t *testing.T) {
+	g := ghost.New(t)
+
+	option := Option{
+		Passable: Passable{Name: "my-opt"},
+		DefaultValues: marshal.Slice[Value]{
+			{CommandSucceeds: "exit 0"},
+		},
+	}
+
+	ctx := Context{Logger: ui.Noop(), Interpreter: []string{"tusk-fake-interpreter"}}
+	_, err := option.Evaluate(ctx, nil)
+	g.Should(be.ErrorContaining(
+		err,
+		`could not compute value for option "my-opt": running "exit 0": `,
+	))
+}
+
+func TestOption_Evaluate_command_failure(t *testing.T) {
+	g := ghost.New(t)
+
+	option := Option{
+		Passable: Passable{Name: "my-opt"},
+		DefaultValues: marshal.Slice[Value]{
+			{Command: "exit 1"},
+		},
+	}
+
+	_, err := option.Evaluate(Context{Logger: ui.Noop()}, nil)
+	g.Should(be.ErrorEqual(
+		err,
+		`could not compute value for option "my-opt": running "exit 1": exit status 1`,
+	))
+}
+
 func TestOption_Evaluate_required_nothing_passed(t *testing.T) {
 	g := ghost.New(t)
 
@@ -249,6 +342,31 @@ func TestOption_Evaluate_values_with_invalid_passed(t *testing.T) {
 	g.Should(be.ErrorEqual(err, `value "foo" for option "my-opt" must be one of [bad, values, FOO]`))
 }
 
+func TestOption_Evaluate_list(t *testing.T) {
+	g := ghost.New(t)
+
+	newOption := func(passed string) Option {
+		return Option{
+			Passable: Passable{
+				Name:          "my-opt",
+				Type:          "list",
+				Passed:        passed,
+				ValuesAllowed: marshal.Slice[string]{"foo", "bar"},
+			},
+			Separator: ",",
+		}
+	}
+
+	option := newOption("foo,bar")
+	got, err := option.Evaluate(Context{}, nil)
+	g.NoError(err)
+	g.Should(be.Equal(got, "foo,bar"))
+
+	option = newOption("foo,baz")
+	_, err = option.Evaluate(Context{}, nil)
+	g.Should(be.ErrorEqual(err, `value "baz" for option "my-opt" must be one of [foo, bar]`))
+}
+
 func TestOption_Evaluate_values_with_invalid_environment(t *testing.T) {
 	g := ghost.New(t)
 
@@ -338,10 +456,6 @@ func TestOption_UnmarshalYAML_invalid_definitions(t *testing.T) {
 			"private and required defined",
 			"{private: true, required: true}",
 		},
-		{
-			"private and environment defined",
-			"{private: true, environment: ENV_VAR}",
-		},
 		{
 			"private and short defined",
 			"{private: true, short: n}",
@@ -354,6 +468,10 @@ func TestOption_UnmarshalYAML_invalid_definitions(t *testing.T) {
 			"required and default defined",
 			"{required: true, default: foo}",
 		},
+		{
+			"separator defined for non-list",
+			"{separator: ','}",
+		},
 	}
 
 	for _, tt := range tests {
@@ -370,12 +488,11 @@ func TestOption_UnmarshalYAML_invalid_definitions(t *testing.T) {
 func TestGetOptionsWithOrder(t *testing.T) {
 	g := ghost.New(t)
 
-	ms := yaml.MapSlice{
-		{Key: "foo", Value: &Option{Environment: "fooenv"}},
-		{Key: "bar", Value: &Option{Environment: "barenv"}},
-	}
-
-	options, err := getOptionsWithOrder(ms)
+	var options Options
+	err := yaml.UnmarshalStrict(
+		[]byte("{foo: {environment: fooenv}, bar: {environment: barenv}}"),
+		&options,
+	)
 	g.NoError(err)
 
 	g.Must(be.SliceLen(options, 2))
@@ -0,0 +1,102 @@
+package runner
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sync"
+
+	"github.com/rliebz/tusk/marshal"
+)
+
+// Output is a file that the output of a task's commands is copied to, while
+// still being printed as usual.
+type Output struct {
+	// Path is the file to write to, relative to the config file.
+	Path string `yaml:"path"`
+
+	// Append adds to the end of the file instead of overwriting it.
+	Append bool `yaml:"append,omitempty"`
+}
+
+// UnmarshalYAML allows a string to be used as the path.
+func (o *Output) UnmarshalYAML(unmarshal func(any) error) error {
+	var str string
+	strCandidate := marshal.UnmarshalCandidate{
+		Unmarshal: func() error { return unmarshal(&str) },
+		Validate:  func() error { return validateOutputPath(str) },
+		Assign:    func() { *o = Output{Path: str} },
+	}
+
+	type outputType Output // Use new type to avoid recursion
+	var outputItem outputType
+	outputCandidate := marshal.UnmarshalCandidate{
+		Unmarshal: func() error { return unmarshal(&outputItem) },
+		Validate:  func() error { return validateOutputPath(outputItem.Path) },
+		Assign:    func() { *o = Output(outputItem) },
+	}
+
+	return marshal.UnmarshalOneOf(strCandidate, outputCandidate)
+}
+
+func validateOutputPath(path string) error {
+	if path == "" {
+		return errors.New("output must specify a path")
+	}
+	return nil
+}
+
+// open opens the output file for writing.
+func (o *Output) open(ctx Context) (*os.File, error) {
+	path := o.Path
+	if !filepath.IsAbs(path) {
+		path = filepath.Join(ctx.Dir(), path)
+	}
+
+	flag := os.O_WRONLY | os.O_CREATE | os.O_TRUNC
+	if o.Append {
+		flag = os.O_WRONLY | os.O_CREATE | os.O_APPEND
+	}
+
+	//nolint:gosec
+	f, err := os.OpenFile(path, flag, 0o644)
+	if err != nil {
+		return nil, fmt.Errorf("opening output file: %w", err)
+	}
+
+	return f, nil
+}
+
+// withOutput copies the output of commands to a writer as well as printing it.
+func (c Context) withOutput(w io.Writer) Context {
+	c.output = &syncWriter{w: w}
+	return c
+}
+
+// teeOutput returns a writer that also writes to the output file, if one is
+// set.
+func (c Context) teeOutput(w io.Writer) io.Writer {
+	switch {
+	case c.output == nil:
+		return w
+	case w == nil:
+		return c.output
+	default:
+		return io.MultiWriter(w, c.output)
+	}
+}
+
+// syncWriter serializes writes, so that standard output and error can be
+// written to the same file at the same time.
+type syncWriter struct {
+	mu sync.Mutex
+	w  io.Writer
+}
+
+func (s *syncWriter) Write(p []byte) (int, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.w.Write(p)
+}
@@ -0,0 +1,183 @@
+package runner
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/rliebz/ghost"
+	"github.com/rliebz/ghost/be"
+	yaml "gopkg.in/yaml.v2"
+
+	"github.com/rliebz/tusk/marshal"
+	"github.com/rliebz/tusk/ui"
+)
+
+func TestOutput_UnmarshalYAML(t *testing.T) {
+	tests := []struct {
+		name    string
+		input   string
+		want    Output
+		wantErr string
+	}{
+		{
+			name:  "string",
+			input: "out.log",
+			want:  Output{Path: "out.log"},
+		},
+		{
+			name:  "object",
+			input: "{path: out.log, append: true}",
+			want:  Output{Path: "out.log", Append: true},
+		},
+		{
+			name:    "empty string",
+			input:   `""`,
+			wantErr: "output must specify a path",
+		},
+		{
+			name:    "missing path",
+			input:   "{append: true}",
+			wantErr: "output must specify a path",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			g := ghost.New(t)
+
+			var got Output
+			err := yaml.UnmarshalStrict([]byte(tt.input), &got)
+			if tt.wantErr != "" {
+				g.Should(be.ErrorEqual(err, tt.wantErr))
+				return
+			}
+			g.NoError(err)
+
+			g.Should(be.Equal(got, tt.want))
+		})
+	}
+}
+
+func TestTask_Execute_output(t *testing.T) {
+	tests := []struct {
+		name       string
+		existing   string
+		append     bool
+		command    Command
+		wantFile   string
+		wantStdout string
+		wantStderr string
+		wantErr    string
+	}{
+		{
+			name:       "stdout",
+			existing:   "old\n",
+			command:    Command{Exec: "echo out", Print: "cmd"},
+			wantFile:   "out\n",
+			wantStdout: "out\n",
+			wantStderr: "foo $ cmd\n",
+		},
+		{
+			name:       "stderr",
+			command:    Command{Exec: "echo err >&2", Print: "cmd"},
+			wantFile:   "err\n",
+			wantStderr: "foo $ cmd\nerr\n",
+		},
+		{
+			name:       "append",
+			existing:   "old\n",
+			append:     true,
+			command:    Command{Exec: "echo out", Print: "cmd"},
+			wantFile:   "old\nout\n",
+			wantStdout: "out\n",
+			wantStderr: "foo $ cmd\n",
+		},
+		{
+			name:       "captured success",
+			command:    Command{Exec: "echo out", Print: "cmd", Capture: true},
+			wantFile:   "out\n",
+			wantStderr: "foo $ cmd\n",
+		},
+		{
+			name:       "captured failure",
+			command:    Command{Exec: "echo out; exit 1", Print: "cmd", Capture: true},
+			wantFile:   "out\n",
+			wantStderr: "foo $ cmd\nout\nexit status 1\n",
+			wantErr:    "exit status 1",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			g := ghost.New(t)
+
+			dir := t.TempDir()
+			path := filepath.Join(dir, "out.log")
+			if tt.existing != "" {
+				g.NoError(os.WriteFile(path, []byte(tt.existing), 0o600))
+			}
+
+			stdout := new(bytes.Buffer)
+			stderr := new(bytes.Buffer)
+			logger := ui.New(ui.Config{Stdout: stdout, Stderr: stderr})
+
+			task := Task{
+				Name:    "foo",
+				Output:  &Output{Path: "out.log", Append: tt.append},
+				RunList: marshal.Slice[*Run]{{Command: marshal.Slice[*Command]{&tt.command}}},
+			}
+
+			err := task.Execute(Context{
+				CfgPath: filepath.Join(dir, "tusk.yml"),
+				Logger:  logger,
+			})
+			if tt.wantErr != "" {
+				g.Should(be.ErrorEqual(err, tt.wantErr))
+			} else {
+				g.NoError(err)
+			}
+
+			got, err := os.ReadFile(path)
+			g.NoError(err)
+
+			g.Should(be.Equal(string(got), tt.wantFile))
+			g.Should(be.Equal(stdout.String(), tt.wantStdout))
+			g.Should(be.Equal(stderr.String(), tt.wantStderr))
+		})
+	}
+}
+
+func TestTask_Execute_output_subTask(t *testing.T) {
+	g := ghost.New(t)
+
+	dir := t.TempDir()
+	cfgText := `
+tasks:
+  inner:
+    run: echo inner
+  outer:
+    output: out.log
+    run:
+      - echo outer
+      - task: inner
+`
+
+	cfg, err := ParseComplete(&ParseConfig{
+		CfgPath:  filepath.Join(dir, "tusk.yml"),
+		CfgText:  []byte(cfgText),
+		TaskName: "outer",
+	})
+	g.NoError(err)
+
+	err = cfg.Tasks["outer"].Execute(Context{
+		CfgPath: filepath.Join(dir, "tusk.yml"),
+		Logger:  ui.Noop(),
+	})
+	g.NoError(err)
+
+	got, err := os.ReadFile(filepath.Join(dir, "out.log"))
+	g.NoError(err)
+	g.Should(be.Equal(string(got), "outer\ninner\n"))
+}
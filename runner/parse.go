@@ -1,24 +1,120 @@
 package runner
 
 import (
+	"cmp"
 	"fmt"
+	"maps"
 	"path/filepath"
+	"slices"
+	"strings"
 
 	yaml "gopkg.in/yaml.v2"
 
 	"github.com/rliebz/tusk/marshal"
+	"github.com/rliebz/tusk/ui"
 )
 
-// Parse loads the contents of a config file into a struct.
-func Parse(text []byte) (*Config, error) {
+// Parse loads the contents of a config file into a struct. Included files are
+// resolved relative to the directory containing the config file.
+func Parse(meta *ParseConfig) (*Config, error) {
 	var cfg Config
-	if err := yaml.UnmarshalStrict(text, &cfg); err != nil {
+	if err := yaml.UnmarshalStrict(meta.CfgText, &cfg); err != nil {
+		return nil, locateError(meta.CfgPath, meta.CfgText, err)
+	}
+
+	cfg.CacheDir = resolveCacheDir(meta.CfgPath, cfg.CacheDir)
+
+	r := includeReader{offline: meta.Offline, cacheDir: cfg.CacheDir}
+	dir := filepath.Dir(meta.CfgPath)
+	for _, name := range slices.Sorted(maps.Keys(cfg.Tasks)) {
+		if err := cfg.Tasks[name].loadInclude(dir, r); err != nil {
+			return nil, eachError(err, func(err error) error {
+				return fmt.Errorf("task %q: %w", name, err)
+			})
+		}
+	}
+
+	if err := cfg.loadIncludes(meta.CfgPath, r); err != nil {
+		return nil, err
+	}
+
+	if err := cfg.loadImports(meta.CfgPath); err != nil {
+		return nil, err
+	}
+
+	if err := resolveExtends(cfg.Tasks); err != nil {
+		return nil, err
+	}
+
+	if err := cfg.mergeUserConfig(meta); err != nil {
+		return nil, err
+	}
+
+	cfg.applyCommonOptions()
+
+	if err := validateAliases(cfg.Tasks); err != nil {
+		return nil, err
+	}
+
+	if err := validateDefault(&cfg); err != nil {
+		return nil, err
+	}
+
+	if err := cfg.applyEnvPrefix(); err != nil {
 		return nil, err
 	}
 
 	return &cfg, nil
 }
 
+// validateDefault checks that the default task can be run from the command
+// line.
+func validateDefault(cfg *Config) error {
+	if cfg.Default == "" {
+		return nil
+	}
+
+	t, ok := cfg.Tasks[cfg.Default]
+	switch {
+	case !ok:
+		return fmt.Errorf("default task %q is not defined", cfg.Default)
+	case t.Private:
+		return fmt.Errorf("default task %q cannot be private", cfg.Default)
+	}
+
+	return nil
+}
+
+// validateAliases checks that every task alias can be used in place of the
+// task's name, without conflicting with any other task or alias.
+func validateAliases(tasks map[string]*Task) error {
+	owners := make(map[string]string)
+	for _, name := range slices.Sorted(maps.Keys(tasks)) {
+		t := tasks[name]
+		if t.Private && len(t.Aliases) > 0 {
+			return fmt.Errorf("task %q: private tasks cannot have aliases", name)
+		}
+
+		for _, alias := range t.Aliases {
+			owner, owned := owners[alias]
+			_, isTask := tasks[alias]
+			switch {
+			case strings.TrimSpace(alias) == "":
+				return fmt.Errorf("task %q: alias cannot be empty", name)
+			case isTask:
+				return fmt.Errorf("task %q: alias %q is already the name of a task", name, alias)
+			case owned && owner == name:
+				return fmt.Errorf("task %q: alias %q is listed more than once", name, alias)
+			case owned:
+				return fmt.Errorf("alias %q is used by both task %q and task %q", alias, owner, name)
+			}
+			owners[alias] = name
+		}
+	}
+
+	return nil
+}
+
 // ParseConfig is the configuration for parsing the configuration file.
 type ParseConfig struct {
 	Args        []string
@@ -26,13 +122,24 @@ type ParseConfig struct {
 	CfgText     []byte
 	Flags       map[string]string
 	Interpreter []string
+	Offline     bool
+	Profile     string
 	TaskName    string
+
+	// MaxTaskDepth is the number of sub-tasks that may be nested within each
+	// other. If zero, a default of 50 is used.
+	MaxTaskDepth int
+
+	// UserCfgPath is the path of the user-level config file, whose tasks are
+	// merged into the config. It is ignored if empty.
+	UserCfgPath string
+	UserCfgText []byte
 }
 
 // ParseComplete parses the file completely with env file parsing and
 // interpolation.
 func ParseComplete(meta *ParseConfig) (*Config, error) {
-	cfg, err := Parse(meta.CfgText)
+	cfg, err := Parse(meta)
 	if err != nil {
 		return nil, err
 	}
@@ -42,19 +149,30 @@ func ParseComplete(meta *ParseConfig) (*Config, error) {
 		return nil, err
 	}
 
+	if err := cfg.applyProfile(meta.Profile); err != nil {
+		return nil, err
+	}
+
 	t, isTaskSet := cfg.Tasks[meta.TaskName]
 	if !isTaskSet {
 		return cfg, nil
 	}
 
-	passed, err := combineArgsAndFlags(t, meta.Args, meta.Flags)
+	if err := checkInterpolation(meta, cfg, t); err != nil {
+		return nil, err
+	}
+
+	passed, err := combineArgsAndFlags(t, cfg, meta.Args, meta.Flags)
 	if err != nil {
 		return nil, err
 	}
 
 	ctx := Context{
-		CfgPath:     meta.CfgPath,
-		Interpreter: meta.Interpreter,
+		CfgPath:      meta.CfgPath,
+		Logger:       ui.Noop(),
+		Interpreter:  meta.Interpreter,
+		MaxTaskDepth: meta.MaxTaskDepth,
+		defaults:     newDefaultCache(),
 	}
 
 	if err := passTaskValues(ctx, t, cfg, passed); err != nil {
@@ -65,18 +183,23 @@ func ParseComplete(meta *ParseConfig) (*Config, error) {
 }
 
 func combineArgsAndFlags(
-	t *Task, args []string, flags map[string]string,
+	t *Task, cfg *Config, args []string, flags map[string]string,
 ) (map[string]string, error) {
-	if len(t.Args) != len(args) {
-		return nil, fmt.Errorf(
-			"task %q requires exactly %d args, got %d",
-			t.Name, len(t.Args), len(args),
-		)
+	if err := t.ValidateArgCount(len(args)); err != nil {
+		return nil, err
+	}
+
+	if err := checkFlags(t, cfg, flags); err != nil {
+		return nil, err
+	}
+
+	if err := t.checkConflicts(flags); err != nil {
+		return nil, err
 	}
 
 	passed := make(map[string]string, len(args)+len(flags))
-	for i, arg := range t.Args {
-		passed[arg.Name] = args[i]
+	for i, value := range args {
+		passed[t.Args[i].Name] = value
 	}
 	for name, value := range flags {
 		passed[name] = value
@@ -85,6 +208,32 @@ func combineArgsAndFlags(
 	return passed, nil
 }
 
+// checkFlags returns an error for a flag that does not name an option that can
+// be passed to a task, which is either a public option of the task or a public
+// global option that it uses.
+func checkFlags(t *Task, cfg *Config, flags map[string]string) error {
+	if len(flags) == 0 {
+		return nil
+	}
+
+	options, err := FindAllOptions(t, cfg)
+	if err != nil {
+		return err
+	}
+
+	for _, name := range slices.Sorted(maps.Keys(flags)) {
+		i := slices.IndexFunc(options, func(o *Option) bool { return o.Name == name })
+		switch {
+		case i < 0:
+			return fmt.Errorf("option %q is not defined for task %q", name, t.Name)
+		case options[i].Private:
+			return fmt.Errorf("option %q of task %q is private", name, t.Name)
+		}
+	}
+
+	return nil
+}
+
 func passTaskValues(
 	ctx Context,
 	t *Task,
@@ -100,9 +249,49 @@ func passTaskValues(
 		return err
 	}
 
+	if err := checkTaskChain(ctx, t); err != nil {
+		return err
+	}
+
+	ctx.expanding = append(slices.Clip(ctx.expanding), t)
 	return addSubTasks(ctx, t, cfg)
 }
 
+// defaultMaxTaskDepth is the number of sub-tasks that may be nested within
+// each other when no maximum is set.
+const defaultMaxTaskDepth = 50
+
+// checkTaskChain returns an error if a task runs itself with the same values
+// through its sub-tasks, which would never finish, or if sub-tasks are nested
+// deeper than the maximum depth.
+func checkTaskChain(ctx Context, t *Task) error {
+	key := t.recordKey()
+	for i, prev := range ctx.expanding {
+		if prev.recordKey() == key {
+			return fmt.Errorf("task cycle detected: %s", formatTaskChain(ctx.expanding[i:], t))
+		}
+	}
+
+	maxDepth := cmp.Or(ctx.MaxTaskDepth, defaultMaxTaskDepth)
+	if len(ctx.expanding) >= maxDepth {
+		return fmt.Errorf(
+			"tasks are nested more than %d deep: %s",
+			maxDepth, formatTaskChain(ctx.expanding, t),
+		)
+	}
+
+	return nil
+}
+
+// formatTaskChain joins the names of a chain of tasks ending in t.
+func formatTaskChain(chain []*Task, t *Task) string {
+	names := make([]string, 0, len(chain)+1)
+	for _, prev := range chain {
+		names = append(names, prev.Name)
+	}
+	return strings.Join(append(names, t.Name), " -> ")
+}
+
 func interpolateGlobalOptions(
 	ctx Context,
 	t *Task,
@@ -114,7 +303,16 @@ func interpolateGlobalOptions(
 		return nil, err
 	}
 
-	vars := make(map[string]string, len(globalOptions))
+	cfgPath := ctx.CfgPath
+	if t.UserConfig != "" {
+		cfgPath = t.UserConfig
+	}
+
+	vars, err := builtinVars(cfgPath)
+	if err != nil {
+		return nil, err
+	}
+
 	for _, o := range globalOptions {
 		if err := interpolateOption(ctx, o, passed, vars); err != nil {
 			return nil, err
@@ -150,7 +348,10 @@ func interpolateArg(a *Arg, passed, vars map[string]string) error {
 	}
 
 	valuePassed, ok := passed[a.Name]
-	if !ok {
+	switch {
+	case !ok && a.Default != nil:
+		valuePassed = *a.Default
+	case !ok:
 		return fmt.Errorf("no value passed for arg %q", a.Name)
 	}
 
@@ -212,10 +413,38 @@ func interpolateTask(ctx Context, t *Task, passed, vars map[string]string) error
 		}
 	}
 
+	if err := marshal.Interpolate(&t.Interpreter, taskVars); err != nil {
+		return err
+	}
+
 	if err := marshal.Interpolate(&t.RunList, taskVars); err != nil {
 		return err
 	}
 
+	// Source and target paths are resolved before the task runs, so the cache
+	// is kept separately for each set of paths.
+	if err := marshal.Interpolate(&t.Source, taskVars); err != nil {
+		return err
+	}
+
+	if err := marshal.Interpolate(&t.Target, taskVars); err != nil {
+		return err
+	}
+
+	if t.Output != nil {
+		// The output may be shared with the task it extends, so it is copied
+		// rather than interpolated in place.
+		output := *t.Output
+		if err := marshal.Interpolate(&output, taskVars); err != nil {
+			return err
+		}
+		t.Output = &output
+	}
+
+	if err := marshal.Interpolate(&t.OnFailure, taskVars); err != nil {
+		return err
+	}
+
 	if err := marshal.Interpolate(&t.Finally, taskVars); err != nil {
 		return err
 	}
@@ -228,7 +457,7 @@ func interpolateTask(ctx Context, t *Task, passed, vars map[string]string) error
 func addSubTasks(ctx Context, t *Task, cfg *Config) error {
 	for _, run := range t.AllRunItems() {
 		for _, desc := range run.SubTaskList {
-			sub, err := newTaskFromSub(ctx, desc, cfg)
+			sub, err := newTaskFromSub(ctx, t, desc, cfg)
 			if err != nil {
 				return err
 			}
@@ -240,7 +469,7 @@ func addSubTasks(ctx Context, t *Task, cfg *Config) error {
 	return nil
 }
 
-func newTaskFromSub(ctx Context, desc *SubTask, cfg *Config) (*Task, error) {
+func newTaskFromSub(ctx Context, parent *Task, desc *SubTask, cfg *Config) (*Task, error) {
 	st, ok := cfg.Tasks[desc.Name]
 	if !ok {
 		return nil, fmt.Errorf("sub-task %q is not defined", desc.Name)
@@ -248,12 +477,21 @@ func newTaskFromSub(ctx Context, desc *SubTask, cfg *Config) (*Task, error) {
 
 	subTask := copyTask(st)
 
-	values, err := getArgValues(subTask, desc.Args)
+	values, err := getArgValues(parent, subTask, desc.Args)
 	if err != nil {
 		return nil, err
 	}
 
-	for optName, optValue := range desc.Options {
+	if err := subTask.checkConflicts(desc.Options); err != nil {
+		return nil, fmt.Errorf("task %q: %w", parent.Name, err)
+	}
+
+	options, err := getOptionValues(parent, desc, subTask, cfg)
+	if err != nil {
+		return nil, err
+	}
+
+	for optName, optValue := range options {
 		opt, ok := subTask.Options.Lookup(optName)
 		if !ok {
 			return nil, fmt.Errorf(
@@ -276,6 +514,61 @@ func newTaskFromSub(ctx Context, desc *SubTask, cfg *Config) (*Task, error) {
 	return subTask, nil
 }
 
+// passAllOptions is the value of pass-options that passes every option.
+const passAllOptions = "all"
+
+// getOptionValues returns the option values to pass to a sub-task, including
+// any values passed through from the parent task. Values set explicitly for
+// the sub-task take priority.
+func getOptionValues(
+	parent *Task, desc *SubTask, subTask *Task, cfg *Config,
+) (map[string]string, error) {
+	names := []string(desc.PassOptions)
+	if slices.Contains(names, passAllOptions) {
+		names = nil
+		for _, opt := range subTask.Options {
+			if _, ok := lookupEvaluatedOption(parent, cfg, opt.Name); ok && !opt.Private {
+				names = append(names, opt.Name)
+			}
+		}
+	}
+
+	values := make(map[string]string, len(names)+len(desc.Options))
+	for _, name := range names {
+		opt, ok := lookupEvaluatedOption(parent, cfg, name)
+		if !ok {
+			return nil, fmt.Errorf(
+				"option %q cannot be passed from task %q",
+				name, parent.Name,
+			)
+		}
+
+		values[name] = opt.passedValue(parent.Vars[name])
+	}
+
+	maps.Copy(values, desc.Options)
+
+	return values, nil
+}
+
+// lookupEvaluatedOption finds an option that has been evaluated for a task,
+// including shared options.
+func lookupEvaluatedOption(t *Task, cfg *Config, name string) (*Option, bool) {
+	if _, ok := t.Vars[name]; !ok {
+		return nil, false
+	}
+
+	if opt, ok := t.Options.Lookup(name); ok {
+		return opt, true
+	}
+
+	if _, ok := t.Args.Lookup(name); ok {
+		return nil, false
+	}
+
+	return cfg.Options.Lookup(name)
+}
+
 // copyTask returns a copy of a task, replacing references with new values.
 func copyTask(t *Task) *Task {
 	newTask := *t
@@ -297,17 +590,18 @@ func copyTask(t *Task) *Task {
 	return &newTask
 }
 
-func getArgValues(subTask *Task, argsPassed []string) (map[string]string, error) {
-	if len(argsPassed) != len(subTask.Args) {
-		return nil, fmt.Errorf(
-			"subtask %q requires %d args but got %d",
-			subTask.Name, len(subTask.Args), len(argsPassed),
-		)
+func getArgValues(parent *Task, subTask *Task, args SubTaskArgs) (map[string]string, error) {
+	argsPassed, err := args.values(subTask)
+	if err == nil && !subTask.Args.accepts(len(argsPassed)) {
+		err = subTaskArgCountError(subTask, len(argsPassed))
+	}
+	if err != nil {
+		return nil, fmt.Errorf("task %q: %w", parent.Name, err)
 	}
 
 	values := make(map[string]string)
-	for i, arg := range subTask.Args {
-		argValue := argsPassed[i]
+	for i, argValue := range argsPassed {
+		arg := subTask.Args[i]
 		if err := arg.validatePassed(argValue); err != nil {
 			return nil, err
 		}
@@ -1,6 +1,7 @@
 package runner
 
 import (
+	"fmt"
 	"os"
 	"path/filepath"
 	"testing"
@@ -12,6 +13,327 @@ import (
 	"github.com/rliebz/tusk/marshal"
 )
 
+func TestParse_include(t *testing.T) {
+	want := &Task{
+		Name:  "foo",
+		Usage: "A valid example of an included task",
+		RunList: marshal.Slice[*Run]{{Command: marshal.Slice[*Command]{{
+			Exec:  `echo "We're in!"`,
+			Print: `echo "We're in!"`,
+		}}}},
+	}
+
+	absPath, err := filepath.Abs(filepath.Join("testdata", "included.yml"))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name    string
+		include string
+		want    *Task
+		wantErr string
+	}{
+		{
+			name:    "relative to config file",
+			include: "included.yml",
+			want:    want,
+		},
+		{
+			name:    "relative to included file",
+			include: filepath.Join("include", "nested.yml"),
+			want:    want,
+		},
+		{
+			name:    "relative to included file in sibling directory",
+			include: filepath.Join("include", "sibling.yml"),
+			want:    want,
+		},
+		{
+			name:    "absolute",
+			include: absPath,
+			want:    want,
+		},
+		{
+			name:    "invalid",
+			include: "included-invalid.yml",
+			wantErr: `task "foo": included-invalid.yml:1: field wrong not found in type runner.taskType`,
+		},
+		{
+			name:    "missing",
+			include: "not-a-real-file.yml",
+			wantErr: `task "foo": opening included file`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			g := ghost.New(t)
+
+			cfgPath := filepath.Join("testdata", "tusk.yml")
+			cfgText := fmt.Sprintf("tasks: { foo: { include: %q } }", tt.include)
+
+			cfg, err := Parse(&ParseConfig{CfgPath: cfgPath, CfgText: []byte(cfgText)})
+			if tt.wantErr != "" {
+				g.Should(be.ErrorContaining(err, tt.wantErr))
+				return
+			}
+			g.NoError(err)
+
+			g.Should(be.DeepEqual(cfg.Tasks["foo"], tt.want))
+		})
+	}
+}
+
+func TestParse_include_working_directory(t *testing.T) {
+	g := ghost.New(t)
+
+	cfgPath, err := filepath.Abs(filepath.Join("testdata", "tusk.yml"))
+	g.NoError(err)
+
+	// Includes should not depend on where tusk is run from.
+	t.Chdir(t.TempDir())
+
+	cfgText := "tasks: { foo: { include: include/sibling.yml } }"
+	cfg, err := Parse(&ParseConfig{CfgPath: cfgPath, CfgText: []byte(cfgText)})
+	g.NoError(err)
+
+	g.Should(be.Equal(cfg.Tasks["foo"].Usage, "A valid example of an included task"))
+}
+
+func TestParse_include_overrides(t *testing.T) {
+	tests := []struct {
+		name            string
+		cfgText         string
+		wantUsage       string
+		wantDescription string
+		wantQuiet       bool
+	}{
+		{
+			name:      "included values",
+			cfgText:   `tasks: { foo: { include: included.yml } }`,
+			wantUsage: "A valid example of an included task",
+		},
+		{
+			name: "outer values take precedence",
+			cfgText: `tasks: { foo: {
+				include: included.yml,
+				usage: Overridden usage,
+				description: Overridden description,
+				quiet: true,
+			} }`,
+			wantUsage:       "Overridden usage",
+			wantDescription: "Overridden description",
+			wantQuiet:       true,
+		},
+		{
+			name: "nested includes",
+			cfgText: `tasks: { foo: {
+				include: include/nested.yml,
+				description: Overridden description,
+			} }`,
+			wantUsage:       "A valid example of an included task",
+			wantDescription: "Overridden description",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			g := ghost.New(t)
+
+			cfg, err := Parse(&ParseConfig{
+				CfgPath: filepath.Join("testdata", "tusk.yml"),
+				CfgText: []byte(tt.cfgText),
+			})
+			g.NoError(err)
+
+			task := cfg.Tasks["foo"]
+			g.Should(be.Equal(task.Name, "foo"))
+			g.Should(be.Equal(task.Usage, tt.wantUsage))
+			g.Should(be.Equal(task.Description, tt.wantDescription))
+			g.Should(be.Equal(task.Quiet, tt.wantQuiet))
+			g.Should(be.SliceLen(task.RunList, 1))
+		})
+	}
+}
+
+func TestParse_aliases(t *testing.T) {
+	g := ghost.New(t)
+
+	cfg, err := Parse(&ParseConfig{
+		CfgPath: "tusk.yml",
+		CfgText: []byte(`tasks: { test: { aliases: [t, tst] } }`),
+	})
+	g.NoError(err)
+
+	g.Should(be.DeepEqual(cfg.Tasks["test"].Aliases, marshal.Slice[string]{"t", "tst"}))
+}
+
+func TestParse_scalar_task_names(t *testing.T) {
+	g := ghost.New(t)
+
+	cfg, err := Parse(&ParseConfig{
+		CfgPath: "tusk.yml",
+		CfgText: []byte(`tasks: { yes: { run: echo yes }, 1: { run: echo 1 } }`),
+	})
+	g.NoError(err)
+
+	g.Should(be.Equal(cfg.Tasks["yes"].Name, "yes"))
+	g.Should(be.Equal(cfg.Tasks["1"].Name, "1"))
+}
+
+func TestParse_aliases_invalid(t *testing.T) {
+	tests := []struct {
+		name    string
+		input   string
+		wantErr string
+	}{
+		{
+			name:    "empty",
+			input:   `tasks: { test: { aliases: [""] } }`,
+			wantErr: `task "test": alias cannot be empty`,
+		},
+		{
+			name:    "private",
+			input:   `tasks: { test: { private: true, aliases: t } }`,
+			wantErr: `task "test": private tasks cannot have aliases`,
+		},
+		{
+			name:    "task name",
+			input:   `tasks: { test: { aliases: lint }, lint: {} }`,
+			wantErr: `task "test": alias "lint" is already the name of a task`,
+		},
+		{
+			name:    "own name",
+			input:   `tasks: { test: { aliases: test } }`,
+			wantErr: `task "test": alias "test" is already the name of a task`,
+		},
+		{
+			name:    "repeated",
+			input:   `tasks: { test: { aliases: [t, t] } }`,
+			wantErr: `task "test": alias "t" is listed more than once`,
+		},
+		{
+			name:    "other task",
+			input:   `tasks: { test: { aliases: t }, tidy: { aliases: t } }`,
+			wantErr: `alias "t" is used by both task "test" and task "tidy"`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			g := ghost.New(t)
+
+			_, err := Parse(&ParseConfig{CfgPath: "tusk.yml", CfgText: []byte(tt.input)})
+			g.Should(be.ErrorEqual(err, tt.wantErr))
+		})
+	}
+}
+
+func TestParse_default_invalid(t *testing.T) {
+	tests := []struct {
+		name    string
+		input   string
+		wantErr string
+	}{
+		{
+			name:    "undefined",
+			input:   `{default: build, tasks: { test: { run: echo } }}`,
+			wantErr: `default task "build" is not defined`,
+		},
+		{
+			name:    "private",
+			input:   `{default: test, tasks: { test: { private: true, run: echo } }}`,
+			wantErr: `default task "test" cannot be private`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			g := ghost.New(t)
+
+			_, err := Parse(&ParseConfig{CfgPath: "tusk.yml", CfgText: []byte(tt.input)})
+			g.Should(be.ErrorEqual(err, tt.wantErr))
+		})
+	}
+}
+
+func TestParse_merge_keys(t *testing.T) {
+	g := ghost.New(t)
+
+	cfgText := `
+x-options: &options
+  env: {default: dev}
+x-task: &task
+  usage: Base usage
+  options:
+    <<: *options
+    verbose: {type: bool}
+  run: echo ${env}
+
+tasks:
+  build:
+    <<: *task
+    usage: Build
+  deploy:
+    <<: *task
+    options:
+      <<: *options
+      region: {default: us, usage: Region}
+`
+
+	cfg, err := Parse(&ParseConfig{CfgPath: "tusk.yml", CfgText: []byte(cfgText)})
+	g.NoError(err)
+
+	build := cfg.Tasks["build"]
+	g.Should(be.Equal(build.Usage, "Build"))
+	g.Should(be.Equal(build.RunList[0].Command[0].Exec, "echo ${env}"))
+	g.Should(be.SliceLen(build.Options, 2))
+	g.Should(be.Equal(build.Options[0].Name, "env"))
+	g.Should(be.Equal(build.Options[1].Name, "verbose"))
+
+	deploy := cfg.Tasks["deploy"]
+	g.Should(be.Equal(deploy.Usage, "Base usage"))
+	g.Should(be.SliceLen(deploy.Options, 2))
+	g.Should(be.Equal(deploy.Options[0].Name, "env"))
+	g.Should(be.Equal(deploy.Options[1].Name, "region"))
+}
+
+func TestParse_extensions_invalid(t *testing.T) {
+	tests := []struct {
+		name    string
+		input   string
+		wantErr string
+	}{
+		{
+			name:    "unknown key",
+			input:   "x-ok: 1\nunknown: 2",
+			wantErr: "tusk.yml:2: field unknown not found in type runner.configType",
+		},
+		{
+			name:    "nested extension",
+			input:   "tasks: { foo: { x-extra: 1 } }",
+			wantErr: `tusk.yml:1: task "foo": field x-extra not found in type runner.taskType`,
+		},
+		{
+			name:  "unknown keys and nested errors",
+			input: "x-ok: &ok 1\nunknown: *ok\ntasks: { foo: { extra: 1 } }\nother: 3",
+			wantErr: "tusk.yml:4: field other not found in type runner.configType\n" +
+				"tusk.yml:2: field unknown not found in type runner.configType\n" +
+				`tusk.yml:3: task "foo": field extra not found in type runner.taskType`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			g := ghost.New(t)
+
+			_, err := Parse(&ParseConfig{CfgPath: "tusk.yml", CfgText: []byte(tt.input)})
+			g.Should(be.ErrorEqual(err, tt.wantErr))
+		})
+	}
+}
+
 var interpolatetests = []struct {
 	name     string
 	input    string
@@ -60,6 +382,78 @@ tasks:
 		}},
 	},
 
+	{
+		"argument defaults",
+		`
+options:
+  env:
+    default: dev
+tasks:
+  mytask:
+    args:
+      foo: {}
+      bar: { default: barvalue }
+      baz: { default: "${env}-${foo}" }
+    run: echo ${foo} ${bar} ${baz}
+`,
+		[]string{"foovalue"},
+		map[string]string{},
+		"mytask",
+		marshal.Slice[*Run]{{
+			Command: marshal.Slice[*Command]{{
+				Exec:  "echo foovalue barvalue dev-foovalue",
+				Print: "echo foovalue barvalue dev-foovalue",
+			}},
+		}},
+	},
+
+	{
+		"argument defaults overridden",
+		`
+tasks:
+  mytask:
+    args:
+      foo: {}
+      bar: { default: barvalue }
+    run: echo ${foo} ${bar}
+`,
+		[]string{"foovalue", "other"},
+		map[string]string{},
+		"mytask",
+		marshal.Slice[*Run]{{
+			Command: marshal.Slice[*Command]{{
+				Exec:  "echo foovalue other",
+				Print: "echo foovalue other",
+			}},
+		}},
+	},
+
+	{
+		"sub-task argument defaults",
+		`
+tasks:
+  one:
+    args:
+      foo: {}
+      bar: { default: barvalue }
+    run: echo ${foo} ${bar}
+  mytask:
+    run:
+      task:
+        name: one
+        args: foovalue
+`,
+		[]string{},
+		map[string]string{},
+		"mytask",
+		marshal.Slice[*Run]{{
+			Command: marshal.Slice[*Command]{{
+				Exec:  "echo foovalue barvalue",
+				Print: "echo foovalue barvalue",
+			}},
+		}},
+	},
+
 	{
 		"multiple argument interpolation",
 		`
@@ -526,6 +920,47 @@ tasks:
 		}},
 	},
 
+	{
+		"sub-task call with named args",
+		`
+options:
+  foo:
+    default: foovalue
+tasks:
+  pretask:
+    args:
+      one: {}
+      two:
+        default: twodefault
+    run: echo ${one} ${two}
+  mytask:
+    run:
+      - task:
+          name: pretask
+          args:
+            two: ${foo}-two
+            one: ${foo}-one
+      - task:
+          name: pretask
+          args:
+            one: first
+`,
+		[]string{},
+		map[string]string{},
+		"mytask",
+		marshal.Slice[*Run]{{
+			Command: marshal.Slice[*Command]{{
+				Exec:  "echo foovalue-one foovalue-two",
+				Print: "echo foovalue-one foovalue-two",
+			}},
+		}, {
+			Command: marshal.Slice[*Command]{{
+				Exec:  "echo first twodefault",
+				Print: "echo first twodefault",
+			}},
+		}},
+	},
+
 	{
 		"repeated sub-task call with different options",
 		`
@@ -562,83 +997,201 @@ tasks:
 	},
 
 	{
-		"nested sub-task dependencies with sub-task-level options",
+		"sub-task call with private options",
 		`
 tasks:
-  roottask:
+  pretask:
+    private: true
     options:
       foo:
-        default: foovalue
+        private: true
+        default: default
     run: echo ${foo}
-  pretask:
-    run:
-      task: roottask
   mytask:
     run:
-      task: pretask
+      - task: pretask
+      - task:
+          name: pretask
+          options:
+            foo: passed
 `,
 		[]string{},
 		map[string]string{},
 		"mytask",
 		marshal.Slice[*Run]{{
 			Command: marshal.Slice[*Command]{{
-				Exec:  "echo foovalue",
-				Print: "echo foovalue",
+				Exec:  "echo default",
+				Print: "echo default",
+			}},
+		}, {
+			Command: marshal.Slice[*Command]{{
+				Exec:  "echo passed",
+				Print: "echo passed",
 			}},
 		}},
 	},
 
 	{
-		"finally dependencies",
+		"sub-task with passed options",
 		`
 options:
-  foo:
-    default: foovalue
+  shared:
+    default: sharedvalue
 tasks:
   pretask:
-    run: echo ${foo}
+    options:
+      foo: {}
+      bar: {}
+      shared: {}
+    run: echo ${foo} ${bar} ${shared}
   mytask:
-    run: echo hello
-    finally:
-      task: pretask
+    options:
+      foo:
+        default: foovalue
+      bar:
+        default: barvalue
+    run:
+      - echo ${shared}
+      - task:
+          name: pretask
+          pass-options: [foo, shared]
 `,
 		[]string{},
 		map[string]string{},
 		"mytask",
 		marshal.Slice[*Run]{{
 			Command: marshal.Slice[*Command]{{
-				Exec:  "echo hello",
-				Print: "echo hello",
+				Exec:  "echo sharedvalue",
+				Print: "echo sharedvalue",
 			}},
 		}, {
 			Command: marshal.Slice[*Command]{{
-				Exec:  "echo foovalue",
-				Print: "echo foovalue",
+				Exec:  "echo foovalue  sharedvalue",
+				Print: "echo foovalue  sharedvalue",
 			}},
 		}},
 	},
 
 	{
-		"sub-task finally dependencies",
+		"sub-task with all options passed",
 		`
-options:
-  foo:
-    default: foovalue
-  bar:
-    default: barvalue
 tasks:
   pretask:
-    run: echo pre-${foo}
-    finally: echo pre-${bar}
+    options:
+      foo: {}
+      bar: {}
+      baz: {}
+      fix:
+        type: bool
+        rewrite: --fix
+    run: echo ${foo} ${bar} ${baz} ${fix}
   mytask:
-    run: echo first
-    finally:
-      - task: pretask
-      - command: echo done
-`,
-		[]string{},
-		map[string]string{},
-		"mytask",
+    args:
+      baz: {}
+    options:
+      foo:
+        default: foovalue
+      bar:
+        default: barvalue
+      fix:
+        type: bool
+        rewrite: --fix
+    run:
+      task:
+        name: pretask
+        pass-options: all
+        options:
+          bar: override
+`,
+		[]string{"bazvalue"},
+		map[string]string{"fix": "true"},
+		"mytask",
+		marshal.Slice[*Run]{{
+			Command: marshal.Slice[*Command]{{
+				Exec:  "echo foovalue override  --fix",
+				Print: "echo foovalue override  --fix",
+			}},
+		}},
+	},
+
+	{
+		"nested sub-task dependencies with sub-task-level options",
+		`
+tasks:
+  roottask:
+    options:
+      foo:
+        default: foovalue
+    run: echo ${foo}
+  pretask:
+    run:
+      task: roottask
+  mytask:
+    run:
+      task: pretask
+`,
+		[]string{},
+		map[string]string{},
+		"mytask",
+		marshal.Slice[*Run]{{
+			Command: marshal.Slice[*Command]{{
+				Exec:  "echo foovalue",
+				Print: "echo foovalue",
+			}},
+		}},
+	},
+
+	{
+		"finally dependencies",
+		`
+options:
+  foo:
+    default: foovalue
+tasks:
+  pretask:
+    run: echo ${foo}
+  mytask:
+    run: echo hello
+    finally:
+      task: pretask
+`,
+		[]string{},
+		map[string]string{},
+		"mytask",
+		marshal.Slice[*Run]{{
+			Command: marshal.Slice[*Command]{{
+				Exec:  "echo hello",
+				Print: "echo hello",
+			}},
+		}, {
+			Command: marshal.Slice[*Command]{{
+				Exec:  "echo foovalue",
+				Print: "echo foovalue",
+			}},
+		}},
+	},
+
+	{
+		"sub-task finally dependencies",
+		`
+options:
+  foo:
+    default: foovalue
+  bar:
+    default: barvalue
+tasks:
+  pretask:
+    run: echo pre-${foo}
+    finally: echo pre-${bar}
+  mytask:
+    run: echo first
+    finally:
+      - task: pretask
+      - command: echo done
+`,
+		[]string{},
+		map[string]string{},
+		"mytask",
 		marshal.Slice[*Run]{{
 			Command: marshal.Slice[*Command]{{
 				Exec:  "echo first",
@@ -1048,7 +1601,7 @@ tasks:
         name: one
 `,
 		taskName: "two",
-		wantErr:  `subtask "one" requires 1 args but got 0`,
+		wantErr:  `task "two": subtask "one" requires 1 args but got 0`,
 	},
 	{
 		name: "not passing correct arg type to subtask",
@@ -1068,6 +1621,42 @@ tasks:
 		taskName: "two",
 		wantErr:  `value "somevalue" for argument "foo" is not of type "int"`,
 	},
+	{
+		name: "passing undefined named arg to subtask",
+		input: `
+tasks:
+  one:
+    args:
+      foo: {}
+    run: echo hello
+  two:
+    run:
+      task:
+        name: one
+        args: {foo: a, bar: b}
+`,
+		taskName: "two",
+		wantErr:  `task "two": arg "bar" cannot be passed to task "one"`,
+	},
+	{
+		name: "skipping named arg to subtask",
+		input: `
+tasks:
+  one:
+    args:
+      foo: {}
+      bar: {}
+    run: echo hello
+  two:
+    run:
+      task:
+        name: one
+        args: {bar: b}
+`,
+		taskName: "two",
+		wantErr: `task "two": arg "foo" must be passed to task "one" ` +
+			`along with the args after it`,
+	},
 	{
 		name: "passing non-arg to subtask",
 		input: `
@@ -1081,7 +1670,7 @@ tasks:
         args: foo
 `,
 		taskName: "two",
-		wantErr:  `subtask "one" requires 0 args but got 1`,
+		wantErr:  `task "two": subtask "one" requires 0 args but got 1`,
 	},
 	{
 		name: "not passing required option to subtask",
@@ -1116,6 +1705,23 @@ tasks:
 		taskName: "two",
 		wantErr:  `value "somevalue" for option "foo" is not of type "float"`,
 	},
+	{
+		name: "passing negative count to subtask",
+		input: `
+tasks:
+  one:
+    options:
+      verbose: {type: count}
+    run: echo hello
+  two:
+    run:
+      task:
+        name: one
+        options: {verbose: -1}
+`,
+		taskName: "two",
+		wantErr:  `value "-1" for option "verbose" is not of type "count"`,
+	},
 	{
 		name: "passing non-option to subtask",
 		input: `
@@ -1145,6 +1751,38 @@ tasks:
       task:
         name: one
         options: {foo: replacement}
+`,
+		taskName: "two",
+		wantErr:  `option "foo" cannot be passed to task "one"`,
+	},
+	{
+		name: "passing through undefined option to subtask",
+		input: `
+tasks:
+  one:
+    options: {foo: {}}
+    run: echo ${foo}
+  two:
+    run:
+      task:
+        name: one
+        pass-options: foo
+`,
+		taskName: "two",
+		wantErr:  `option "foo" cannot be passed from task "two"`,
+	},
+	{
+		name: "passing through non-option to subtask",
+		input: `
+tasks:
+  one:
+    run: echo hello
+  two:
+    options: {foo: {}}
+    run:
+      task:
+        name: one
+        pass-options: foo
 `,
 		taskName: "two",
 		wantErr:  `option "foo" cannot be passed to task "one"`,
@@ -1160,6 +1798,68 @@ tasks:
 		taskName: "mytask",
 		wantErr:  `sub-task "fake" is not defined`,
 	},
+	{
+		name: "exclusive options passed together",
+		input: `
+tasks:
+  deploy:
+    options:
+      canary: {type: bool}
+      full: {type: bool, exclusive-with: canary}
+      region: {default: us-east-1}
+    run: echo ${canary} ${full} ${region}
+`,
+		flags:    map[string]string{"canary": "true", "full": "true", "region": "eu-west-1"},
+		taskName: "deploy",
+		wantErr:  `options cannot be used together: --canary, --full`,
+	},
+	{
+		name: "exclusive options passed together to sub-task",
+		input: `
+tasks:
+  deploy:
+    options:
+      canary: {type: bool}
+      full: {type: bool, exclusive-with: canary}
+    run: echo ${canary} ${full}
+  release:
+    run:
+      task:
+        name: deploy
+        options: {canary: true, full: true}
+`,
+		taskName: "release",
+		wantErr:  `task "release": options cannot be used together: --canary, --full`,
+	},
+	{
+		name: "sub-task runs itself",
+		input: `
+tasks:
+  mytask:
+    run:
+      task: mytask
+`,
+		taskName: "mytask",
+		wantErr:  `task cycle detected: mytask -> mytask`,
+	},
+	{
+		name: "sub-tasks run each other",
+		input: `
+tasks:
+  a:
+    run:
+      task: b
+  b:
+    options: {foo: {default: bar}}
+    run:
+      task: {name: a}
+  c:
+    run:
+      task: a
+`,
+		taskName: "c",
+		wantErr:  `task cycle detected: a -> b -> a`,
+	},
 	{
 		name: "argument and option share name",
 		input: `
@@ -1173,7 +1873,8 @@ tasks:
 `,
 		flags:    map[string]string{"foo": "foovalue"},
 		taskName: "mytask",
-		wantErr:  `argument and option "foo" must have unique names within a task`,
+		wantErr: `line 6: task "mytask": ` +
+			`argument and option "foo" must have unique names within a task`,
 	},
 	{
 		name: "argument not passed",
@@ -1198,6 +1899,50 @@ tasks:
 		taskName: "mytask",
 		wantErr:  `task "mytask" requires exactly 0 args, got 1`,
 	},
+	{
+		name: "required argument not passed with defaults",
+		input: `
+tasks:
+  mytask:
+    args:
+      foo: {}
+      bar: { default: barvalue }
+    run: echo oops
+`,
+		taskName: "mytask",
+		wantErr:  `task "mytask" requires between 1 and 2 args, got 0`,
+	},
+	{
+		name: "argument without default after default",
+		input: `
+tasks:
+  mytask:
+    args:
+      foo: { default: foovalue }
+      bar: {}
+    run: echo oops
+`,
+		taskName: "mytask",
+		wantErr: `line 3: task "mytask": ` +
+			`arg "bar" must have a default, since it follows an arg with a default`,
+	},
+	{
+		name: "too many arguments passed to subtask with defaults",
+		input: `
+tasks:
+  one:
+    args:
+      foo: { default: foovalue }
+    run: echo hello
+  two:
+    run:
+      task:
+        name: one
+        args: [a, b]
+`,
+		taskName: "two",
+		wantErr:  `task "two": subtask "one" requires between 0 and 1 args but got 2`,
+	},
 
 	{
 		name: "non-boolean rewrite",
@@ -1214,7 +1959,8 @@ tasks:
 			"foo": "true",
 		},
 		taskName: "mytask",
-		wantErr:  "rewrite may only be performed on boolean values",
+		wantErr: `line 5: task "mytask": option "foo": ` +
+			`rewrite may only be performed on boolean values`,
 	},
 
 	{
@@ -1226,7 +1972,7 @@ tasks:
     run: echo ${bar}
 `,
 		taskName: "mytask",
-		wantErr:  "task source cannot be defined without target",
+		wantErr:  `line 4: task "mytask": task source cannot be defined without target`,
 	},
 
 	{
@@ -1238,10 +1984,68 @@ tasks:
     run: echo ${bar}
 `,
 		taskName: "mytask",
-		wantErr:  "task target cannot be defined without source",
+		wantErr:  `line 4: task "mytask": task target cannot be defined without source`,
+	},
+
+	{
+		name: "cache without source",
+		input: `
+tasks:
+  mytask:
+    cache: {inputs: [BUILD_MODE]}
+    run: echo ${bar}
+`,
+		taskName: "mytask",
+		wantErr:  `line 4: task "mytask": task cache cannot be defined without source and target`,
+	},
+
+	{
+		name: "empty cache input",
+		input: `
+tasks:
+  mytask:
+    source: foo.txt
+    target: bar.txt
+    cache: {inputs: [""]}
+    run: echo ${bar}
+`,
+		taskName: "mytask",
+		wantErr:  `line 6: task "mytask": task cache input cannot be empty`,
+	},
+
+	{
+		name: "empty interpreter",
+		input: `
+tasks:
+  mytask:
+    interpreter: " "
+    run: echo ${bar}
+`,
+		taskName: "mytask",
+		wantErr:  `line 4: task "mytask": interpreter must name an executable`,
 	},
 }
 
+func TestParseComplete_option_default_command(t *testing.T) {
+	g := ghost.New(t)
+
+	cfg, err := ParseComplete(&ParseConfig{
+		CfgText: []byte(`
+tasks:
+  mytask:
+    options:
+      foo:
+        default:
+          command: echo foovalue
+    run: echo ${foo}
+`),
+		TaskName: "mytask",
+	})
+	g.NoError(err)
+
+	g.Should(be.Equal(cfg.Tasks["mytask"].RunList[0].Command[0].Exec, "echo foovalue"))
+}
+
 func TestParseComplete_invalid(t *testing.T) {
 	for _, tt := range invalidinterpolatetests {
 		t.Run(tt.name, func(t *testing.T) {
@@ -1270,6 +2074,189 @@ given input:
 	}
 }
 
+func TestParseComplete_undefined_references(t *testing.T) {
+	g := ghost.New(t)
+
+	cfgText := []byte(`
+tasks:
+  build:
+    run:
+      - echo ${target} $${HOME} ${tusk.dir}
+      - task:
+          name: deploy
+          options: {env: "${environment}"}
+  deploy:
+    options:
+      env: {}
+    run:
+      - when: {equal: {env: "${stage}"}}
+        command: echo ${env}
+  unrelated:
+    run: echo ${missing}
+`)
+
+	cfg, err := ParseComplete(&ParseConfig{
+		CfgPath:  "tusk.yml",
+		CfgText:  cfgText,
+		TaskName: "build",
+	})
+	g.NoError(err)
+
+	g.Should(be.DeepEqual(cfg.Warnings, []string{
+		`tusk.yml:3: task "build": ${target} does not refer to an arg or option`,
+		`tusk.yml:3: task "build": ${environment} does not refer to an arg or option`,
+		`tusk.yml:9: task "deploy": ${stage} does not refer to an arg or option`,
+	}))
+}
+
+func TestParseComplete_source_target(t *testing.T) {
+	g := ghost.New(t)
+
+	cfgText := []byte(`
+tasks:
+  build:
+    options:
+      module:
+        default: core
+    source: src/${module}/**/*.go
+    target:
+      - bin/${module}
+      - bin/${upper(module)}.txt
+    run: go build -o bin/${module} ./src/${module}
+`)
+
+	cfg, err := ParseComplete(&ParseConfig{
+		CfgText:  cfgText,
+		Flags:    map[string]string{"module": "api"},
+		TaskName: "build",
+	})
+	g.NoError(err)
+
+	task := cfg.Tasks["build"]
+	g.Should(be.DeepEqual(task.Source, marshal.Slice[string]{"src/api/**/*.go"}))
+	g.Should(be.DeepEqual(task.Target, marshal.Slice[string]{"bin/api", "bin/API.txt"}))
+}
+
+func TestParseComplete_output(t *testing.T) {
+	g := ghost.New(t)
+
+	cfgText := []byte(`
+tasks:
+  test:
+    options:
+      package:
+        default: core
+    output:
+      path: logs/${package}.log
+      append: true
+    run: go test ./${package}
+`)
+
+	cfg, err := ParseComplete(&ParseConfig{
+		CfgText:  cfgText,
+		Flags:    map[string]string{"package": "api"},
+		TaskName: "test",
+	})
+	g.NoError(err)
+
+	task := cfg.Tasks["test"]
+	g.Should(be.DeepEqual(task.Output, &Output{Path: "logs/api.log", Append: true}))
+}
+
+func TestParseComplete_source_target_undefined(t *testing.T) {
+	g := ghost.New(t)
+
+	cfgText := []byte(`
+tasks:
+  build:
+    source: src/${module}/**/*.go
+    target: bin/${output}
+    run: go build ./...
+`)
+
+	cfg, err := ParseComplete(&ParseConfig{
+		CfgPath:  "tusk.yml",
+		CfgText:  cfgText,
+		TaskName: "build",
+	})
+	g.NoError(err)
+
+	g.Should(be.DeepEqual(cfg.Warnings, []string{
+		`tusk.yml:3: task "build": ${module} does not refer to an arg or option`,
+		`tusk.yml:3: task "build": ${output} does not refer to an arg or option`,
+	}))
+}
+
+func TestParseComplete_option_cycle(t *testing.T) {
+	g := ghost.New(t)
+
+	cfgText := []byte(`
+tasks:
+  build:
+    options:
+      target:
+        default: ${target}/bin
+    run: echo ${target}
+`)
+
+	_, err := ParseComplete(&ParseConfig{
+		CfgPath:  "tusk.yml",
+		CfgText:  cfgText,
+		TaskName: "build",
+	})
+	g.Should(be.ErrorEqual(err, `tusk.yml:3: task "build": option "target" depends on itself`))
+}
+
+func TestParseComplete_max_task_depth(t *testing.T) {
+	cfgText := []byte(`
+tasks:
+  a:
+    run: {task: b}
+  b:
+    run: {task: c}
+  c:
+    run: {task: d}
+  d:
+    run: echo d
+`)
+
+	tests := []struct {
+		name         string
+		maxTaskDepth int
+		wantErr      string
+	}{
+		{
+			name:         "exceeded",
+			maxTaskDepth: 3,
+			wantErr:      "tasks are nested more than 3 deep: a -> b -> c -> d",
+		},
+		{
+			name:         "custom",
+			maxTaskDepth: 4,
+		},
+		{
+			name: "default",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			g := ghost.New(t)
+
+			_, err := ParseComplete(&ParseConfig{
+				CfgText:      cfgText,
+				MaxTaskDepth: tt.maxTaskDepth,
+				TaskName:     "a",
+			})
+			if tt.wantErr == "" {
+				g.NoError(err)
+				return
+			}
+			g.Should(be.ErrorEqual(err, tt.wantErr))
+		})
+	}
+}
+
 func TestParseComplete_no_task(t *testing.T) {
 	g := ghost.New(t)
 
@@ -1324,6 +2311,61 @@ tasks:
 	})
 	g.NoError(err)
 
-	g.Check(cfg.Tasks["quietCmd"].RunList[0].Command[0].Quiet)
+	g.Check(*cfg.Tasks["quietCmd"].RunList[0].Command[0].Quiet)
 	g.Check(cfg.Tasks["quietTask"].Quiet)
 }
+
+func TestParseComplete_interpreter(t *testing.T) {
+	g := ghost.New(t)
+
+	cfgText := []byte(`
+tasks:
+  mytask:
+    options:
+      python:
+        default: python3
+    interpreter: ${python} -c
+    run:
+      - print("task")
+      - exec: console.log("command")
+        interpreter: node -e
+`)
+
+	cfg, err := ParseComplete(&ParseConfig{
+		Args:     []string{},
+		Flags:    map[string]string{},
+		CfgText:  cfgText,
+		TaskName: "mytask",
+	})
+	g.NoError(err)
+
+	task := cfg.Tasks["mytask"]
+	g.Should(be.Equal(task.Interpreter, "python3 -c"))
+	g.Should(be.Equal(task.RunList[1].Command[0].Interpreter, "node -e"))
+}
+
+func TestParseComplete_argv(t *testing.T) {
+	g := ghost.New(t)
+
+	cfgText := []byte(`
+tasks:
+  mytask:
+    options:
+      name:
+        default: it's a test
+    run:
+      - exec: [echo, "${name}", "$${HOME}"]
+`)
+
+	cfg, err := ParseComplete(&ParseConfig{
+		Args:     []string{},
+		Flags:    map[string]string{},
+		CfgText:  cfgText,
+		TaskName: "mytask",
+	})
+	g.NoError(err)
+
+	command := cfg.Tasks["mytask"].RunList[0].Command[0]
+	g.Should(be.DeepEqual(command.Argv, []string{"echo", "it's a test", "${HOME}"}))
+	g.Should(be.Equal(command.Print, `echo 'it'\''s a test' '${HOME}'`))
+}
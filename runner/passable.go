@@ -48,6 +48,9 @@ func (p *Passable) hasValidType(value string) bool {
 	case p.isBoolean():
 		_, err := strconv.ParseBool(value)
 		return err == nil
+	case p.isCount():
+		n, err := strconv.Atoi(value)
+		return err == nil && n >= 0
 	case p.isInt():
 		_, err := strconv.Atoi(value)
 		return err == nil
@@ -60,7 +63,7 @@ func (p *Passable) hasValidType(value string) bool {
 }
 
 func (p *Passable) isNumeric() bool {
-	return p.isInt() || p.isFloat()
+	return p.isInt() || p.isFloat() || p.isCount()
 }
 
 func (p *Passable) isFloat() bool {
@@ -81,6 +84,15 @@ func (p *Passable) isInt() bool {
 	}
 }
 
+// isCount checks whether the value is the number of times a flag was passed.
+func (p *Passable) isCount() bool {
+	return strings.ToLower(p.Type) == "count"
+}
+
+func (p *Passable) isList() bool {
+	return strings.ToLower(p.Type) == "list"
+}
+
 func (p *Passable) isBoolean() bool {
 	switch strings.ToLower(p.Type) {
 	case "bool", "boolean":
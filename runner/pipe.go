@@ -0,0 +1,95 @@
+package runner
+
+import (
+	"cmp"
+	"os"
+	"os/exec"
+)
+
+// execPipeline runs commands at the same time, with the standard output of
+// each command connected to the standard input of the next. The first command
+// reads from stdin and the last command writes its output as usual.
+//
+// Every command is waited on, and the last failure in the pipeline is
+// returned.
+func execPipeline(ctx Context, commands []*Command) error {
+	cmds := make([]*exec.Cmd, 0, len(commands))
+	flushes := make([]func(), 0, len(commands))
+	defer func() {
+		for _, flush := range flushes {
+			flush()
+		}
+	}()
+
+	for _, command := range commands {
+		cmd, flush := command.newCmd(ctx)
+		cmds = append(cmds, cmd)
+		flushes = append(flushes, flush)
+	}
+
+	cmds[0].Stdin = os.Stdin
+
+	pipes, err := connectPipes(cmds)
+	if err != nil {
+		return err
+	}
+
+	return startAndWait(cmds, pipes)
+}
+
+// connectPipes connects the standard output of each command to the standard
+// input of the next, returning the ends of each pipe.
+func connectPipes(cmds []*exec.Cmd) ([]*os.File, error) {
+	var pipes []*os.File
+	for i := range len(cmds) - 1 {
+		r, w, err := os.Pipe()
+		if err != nil {
+			closeFiles(pipes)
+			return nil, err
+		}
+		pipes = append(pipes, r, w)
+
+		cmds[i].Stdout = w
+		cmds[i+1].Stdin = r
+	}
+
+	return pipes, nil
+}
+
+// startAndWait starts every command before waiting for them all to finish.
+//
+// The parent's copies of each pipe are closed once the commands have started,
+// or the next command would never see the end of its input.
+//
+// As with pipefail in a shell, the error returned is that of the last command
+// to fail, since earlier commands may be stopped by a broken pipe when a later
+// command exits early.
+func startAndWait(cmds []*exec.Cmd, pipes []*os.File) error {
+	var startErr error
+	started := make([]*exec.Cmd, 0, len(cmds))
+	for _, cmd := range cmds {
+		if err := cmd.Start(); err != nil {
+			startErr = err
+			break
+		}
+		started = append(started, cmd)
+	}
+
+	closeFiles(pipes)
+
+	var waitErr error
+	for _, cmd := range started {
+		if err := cmd.Wait(); err != nil {
+			waitErr = err
+		}
+	}
+
+	return cmp.Or(startErr, waitErr)
+}
+
+// closeFiles closes every file, ignoring errors.
+func closeFiles(files []*os.File) {
+	for _, f := range files {
+		f.Close() //nolint:errcheck
+	}
+}
@@ -0,0 +1,86 @@
+package runner
+
+import (
+	"fmt"
+	"maps"
+	"slices"
+
+	"github.com/rliebz/tusk/marshal"
+)
+
+// Profile is a named set of option values that replace the defaults of the
+// options with those names.
+type Profile map[string]string
+
+// profileOverride is a value from a profile and the option it applies to.
+type profileOverride struct {
+	option *Option
+	value  string
+}
+
+// applyProfile replaces the defaults of every option named by a profile. An
+// empty name applies no profile.
+func (c *Config) applyProfile(name string) error {
+	if name == "" {
+		return nil
+	}
+
+	if _, ok := c.Profiles[name]; !ok {
+		return fmt.Errorf("profile %q is not defined", name)
+	}
+
+	overrides, err := c.profileOverrides(name)
+	if err != nil {
+		return err
+	}
+
+	for _, o := range overrides {
+		o.option.DefaultValues = marshal.Slice[Value]{{Value: o.value}}
+		// The profile provides the value that would otherwise be required.
+		o.option.Required = false
+	}
+
+	return nil
+}
+
+// profileOverrides returns the options a profile applies to, which includes
+// every shared or task option with a matching name. Each value must be valid
+// for every option it applies to.
+func (c *Config) profileOverrides(name string) ([]profileOverride, error) {
+	profile := c.Profiles[name]
+
+	var overrides []profileOverride
+	for _, optName := range slices.Sorted(maps.Keys(profile)) {
+		value := profile[optName]
+
+		options := c.optionsNamed(optName)
+		if len(options) == 0 {
+			return nil, fmt.Errorf("profile %q: option %q is not defined", name, optName)
+		}
+
+		for _, opt := range options {
+			if err := opt.validatePassed(value); err != nil {
+				return nil, fmt.Errorf("profile %q: %w", name, err)
+			}
+			overrides = append(overrides, profileOverride{opt, value})
+		}
+	}
+
+	return overrides, nil
+}
+
+// optionsNamed returns the shared and task options with a given name.
+func (c *Config) optionsNamed(name string) []*Option {
+	var options []*Option
+	if opt, ok := c.Options.Lookup(name); ok {
+		options = append(options, opt)
+	}
+
+	for _, taskName := range slices.Sorted(maps.Keys(c.Tasks)) {
+		if opt, ok := c.Tasks[taskName].Options.Lookup(name); ok {
+			options = append(options, opt)
+		}
+	}
+
+	return options
+}
@@ -0,0 +1,132 @@
+package runner
+
+import (
+	"testing"
+
+	"github.com/rliebz/ghost"
+	"github.com/rliebz/ghost/be"
+)
+
+const profileConfig = `
+options:
+  env:
+    default: dev
+profiles:
+  prod:
+    env: production
+    replicas: 3
+  bad-value:
+    replicas: many
+  bad-option:
+    unknown: value
+tasks:
+  deploy:
+    options:
+      replicas:
+        type: int
+        default: 1
+      region:
+        required: true
+    run: echo ${env} ${replicas} ${region}
+`
+
+func TestParseComplete_profile(t *testing.T) {
+	tests := []struct {
+		name    string
+		profile string
+		flags   map[string]string
+		want    map[string]string
+	}{
+		{
+			name:  "no profile",
+			flags: map[string]string{"region": "us"},
+			want:  map[string]string{"env": "dev", "replicas": "1", "region": "us"},
+		},
+		{
+			name:    "profile replaces defaults",
+			profile: "prod",
+			flags:   map[string]string{"region": "us"},
+			want:    map[string]string{"env": "production", "replicas": "3", "region": "us"},
+		},
+		{
+			name:    "flags take priority",
+			profile: "prod",
+			flags:   map[string]string{"region": "us", "replicas": "5"},
+			want:    map[string]string{"env": "production", "replicas": "5", "region": "us"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			g := ghost.New(t)
+
+			cfg, err := ParseComplete(&ParseConfig{
+				CfgText:  []byte(profileConfig),
+				Flags:    tt.flags,
+				Profile:  tt.profile,
+				TaskName: "deploy",
+			})
+			g.NoError(err)
+
+			g.Should(be.DeepEqual(cfg.Tasks["deploy"].Vars, tt.want))
+		})
+	}
+}
+
+func TestParseComplete_profile_required(t *testing.T) {
+	g := ghost.New(t)
+
+	cfgText := `
+profiles:
+  eu: { region: eu-west-1 }
+tasks:
+  deploy:
+    options:
+      region:
+        required: true
+    run: echo ${region}
+`
+
+	cfg, err := ParseComplete(&ParseConfig{
+		CfgText:  []byte(cfgText),
+		Profile:  "eu",
+		TaskName: "deploy",
+	})
+	g.NoError(err)
+
+	g.Should(be.Equal(cfg.Tasks["deploy"].Vars["region"], "eu-west-1"))
+}
+
+func TestParseComplete_profile_invalid(t *testing.T) {
+	tests := []struct {
+		profile string
+		wantErr string
+	}{
+		{
+			profile: "staging",
+			wantErr: `profile "staging" is not defined`,
+		},
+		{
+			profile: "bad-value",
+			wantErr: `profile "bad-value": value "many" for option "replicas" is not of type "int"`,
+		},
+		{
+			profile: "bad-option",
+			wantErr: `profile "bad-option": option "unknown" is not defined`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.profile, func(t *testing.T) {
+			g := ghost.New(t)
+
+			_, err := ParseComplete(&ParseConfig{
+				CfgText:  []byte(profileConfig),
+				Flags:    map[string]string{"region": "us"},
+				Profile:  tt.profile,
+				TaskName: "deploy",
+			})
+			g.Should(be.ErrorEqual(err, tt.wantErr))
+		})
+	}
+}
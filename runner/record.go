@@ -0,0 +1,70 @@
+package runner
+
+import (
+	"maps"
+	"slices"
+	"strings"
+)
+
+// TaskRecord records the tasks that have completed, so that a task runs at
+// most once when several tasks are run together. Tasks are only the same when
+// they have the same name and the same value for every arg and option.
+//
+// Only tasks completed by an earlier task named on the command line are
+// skipped. A task that runs the same sub-task more than once still runs it
+// every time.
+type TaskRecord struct {
+	completed map[string]bool
+
+	// current holds the tasks completed by the task that is running, which
+	// are only skipped once it finishes.
+	current map[string]bool
+}
+
+// NewTaskRecord returns an empty record of completed tasks.
+func NewTaskRecord() *TaskRecord {
+	return &TaskRecord{
+		completed: make(map[string]bool),
+		current:   make(map[string]bool),
+	}
+}
+
+// has returns whether a task was completed by an earlier task named on the
+// command line. A nil record has no tasks.
+func (r *TaskRecord) has(t *Task) bool {
+	if r == nil {
+		return false
+	}
+
+	return r.completed[t.recordKey()]
+}
+
+// add records that a task has completed. Adding to a nil record does nothing.
+func (r *TaskRecord) add(t *Task) {
+	if r == nil {
+		return
+	}
+
+	r.current[t.recordKey()] = true
+}
+
+// finish marks the tasks completed by the task named on the command line as
+// done, so that later tasks skip them. Finishing a nil record does nothing.
+func (r *TaskRecord) finish() {
+	if r == nil {
+		return
+	}
+
+	maps.Copy(r.completed, r.current)
+	clear(r.current)
+}
+
+// recordKey identifies a task by its name and the values it runs with.
+func (t *Task) recordKey() string {
+	parts := []string{t.Name}
+	for _, name := range slices.Sorted(maps.Keys(t.Vars)) {
+		parts = append(parts, name+"="+t.Vars[name])
+	}
+
+	return strings.Join(parts, "\x00")
+}
@@ -0,0 +1,119 @@
+package runner
+
+import (
+	"errors"
+	"fmt"
+	"os/exec"
+	"slices"
+	"time"
+
+	"github.com/rliebz/tusk/marshal"
+)
+
+// Retry runs a command again when it fails.
+type Retry struct {
+	// Attempts is the number of times the command runs before giving up,
+	// including the first.
+	Attempts int `yaml:"attempts"`
+
+	// Delay is how long to wait between attempts.
+	Delay time.Duration `yaml:"delay,omitempty"`
+
+	// OnExitCodes are the exit codes that are retried. If empty, every failure
+	// is retried.
+	OnExitCodes marshal.Slice[int] `yaml:"on-exit-codes,omitempty"`
+}
+
+// UnmarshalYAML allows the number of attempts to be used as a short form.
+func (r *Retry) UnmarshalYAML(unmarshal func(any) error) error {
+	var attempts int
+	attemptsCandidate := marshal.UnmarshalCandidate{
+		Unmarshal: func() error { return unmarshal(&attempts) },
+		Validate:  func() error { return (&Retry{Attempts: attempts}).validate() },
+		Assign:    func() { *r = Retry{Attempts: attempts} },
+	}
+
+	type retryType Retry // Use new type to avoid recursion
+	var retryItem retryType
+	retryCandidate := marshal.UnmarshalCandidate{
+		Unmarshal: func() error { return unmarshal(&retryItem) },
+		Validate:  func() error { return (*Retry)(&retryItem).validate() },
+		Assign:    func() { *r = Retry(retryItem) },
+	}
+
+	return marshal.UnmarshalOneOf(attemptsCandidate, retryCandidate)
+}
+
+func (r *Retry) validate() error {
+	if r.Attempts < 1 {
+		return errors.New("retry attempts must be positive")
+	}
+	if r.Delay < 0 {
+		return errors.New("retry delay cannot be negative")
+	}
+	return nil
+}
+
+// run runs a command until it succeeds, it fails in a way that is not
+// retried, or every attempt has been used.
+func (r *Retry) run(ctx Context, desc string, run func() error) error {
+	err := run()
+	if r == nil {
+		return err
+	}
+
+	for attempt := 2; attempt <= r.Attempts && r.retries(err); attempt++ {
+		ctx.Logger.Warn(fmt.Sprintf(
+			"%s failed: %s, retrying (attempt %d of %d)", desc, err, attempt, r.Attempts,
+		))
+
+		if perr := r.pause(ctx); perr != nil {
+			return perr
+		}
+
+		err = run()
+	}
+
+	return err
+}
+
+// retries returns whether a failure should be retried.
+func (r *Retry) retries(err error) bool {
+	if err == nil || errors.Is(err, ErrInterrupted) {
+		return false
+	}
+
+	if len(r.OnExitCodes) == 0 {
+		return true
+	}
+
+	var exitErr *exec.ExitError
+	if !errors.As(err, &exitErr) {
+		return false
+	}
+
+	return slices.Contains(r.OnExitCodes, exitErr.ExitCode())
+}
+
+// pause waits for the delay between attempts, stopping early if the task is
+// interrupted.
+func (r *Retry) pause(ctx Context) error {
+	if r.Delay == 0 {
+		return ctx.interrupted()
+	}
+
+	timer := time.NewTimer(r.Delay)
+	defer timer.Stop()
+
+	var done <-chan struct{}
+	if interruption := ctx.interruption(); interruption != nil {
+		done = interruption.Done()
+	}
+
+	select {
+	case <-timer.C:
+		return nil
+	case <-done:
+		return ctx.interrupted()
+	}
+}
@@ -0,0 +1,169 @@
+package runner
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/rliebz/ghost"
+	"github.com/rliebz/ghost/be"
+	yaml "gopkg.in/yaml.v2"
+
+	"github.com/rliebz/tusk/marshal"
+	"github.com/rliebz/tusk/ui"
+)
+
+func TestRetry_UnmarshalYAML(t *testing.T) {
+	tests := []struct {
+		name  string
+		input string
+		want  Retry
+	}{
+		{
+			name:  "short form",
+			input: `3`,
+			want:  Retry{Attempts: 3},
+		},
+		{
+			name:  "attempts and delay",
+			input: `{attempts: 5, delay: 2s}`,
+			want:  Retry{Attempts: 5, Delay: 2 * time.Second},
+		},
+		{
+			name:  "exit codes",
+			input: `{attempts: 3, on-exit-codes: [1, 75]}`,
+			want:  Retry{Attempts: 3, OnExitCodes: marshal.Slice[int]{1, 75}},
+		},
+		{
+			name:  "single exit code",
+			input: `{attempts: 3, on-exit-codes: 75}`,
+			want:  Retry{Attempts: 3, OnExitCodes: marshal.Slice[int]{75}},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			g := ghost.New(t)
+
+			var got Retry
+			err := yaml.UnmarshalStrict([]byte(tt.input), &got)
+			g.NoError(err)
+
+			g.Should(be.DeepEqual(got, tt.want))
+		})
+	}
+}
+
+func TestRetry_UnmarshalYAML_invalid(t *testing.T) {
+	tests := []struct {
+		name    string
+		input   string
+		wantErr string
+	}{
+		{
+			name:    "zero attempts",
+			input:   `0`,
+			wantErr: "retry attempts must be positive",
+		},
+		{
+			name:    "missing attempts",
+			input:   `{delay: 1s}`,
+			wantErr: "retry attempts must be positive",
+		},
+		{
+			name:    "negative delay",
+			input:   `{attempts: 2, delay: -1s}`,
+			wantErr: "retry delay cannot be negative",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			g := ghost.New(t)
+
+			var got Retry
+			err := yaml.UnmarshalStrict([]byte(tt.input), &got)
+			g.Should(be.ErrorEqual(err, tt.wantErr))
+		})
+	}
+}
+
+func TestCommand_UnmarshalYAML_retry_background(t *testing.T) {
+	g := ghost.New(t)
+
+	var got Command
+	err := yaml.UnmarshalStrict([]byte(`{exec: ./serve, background: true, retry: 3}`), &got)
+	g.Should(be.ErrorEqual(err, "retry cannot be used with background"))
+}
+
+func TestTask_Execute_retry(t *testing.T) {
+	// The command fails with exit code 75 until its third attempt.
+	exec := `n=$(cat attempts 2>/dev/null || echo 0); n=$((n+1)); echo $n > attempts; ` +
+		`[ $n -ge 3 ] || exit 75`
+
+	tests := []struct {
+		name         string
+		retry        *Retry
+		wantAttempts string
+		wantErr      string
+	}{
+		{
+			name:         "no retry",
+			wantAttempts: "1",
+			wantErr:      "exit status 75",
+		},
+		{
+			name:         "any exit code",
+			retry:        &Retry{Attempts: 3},
+			wantAttempts: "3",
+		},
+		{
+			name:         "matching exit code",
+			retry:        &Retry{Attempts: 5, OnExitCodes: marshal.Slice[int]{1, 75}},
+			wantAttempts: "3",
+		},
+		{
+			name:         "other exit code",
+			retry:        &Retry{Attempts: 5, OnExitCodes: marshal.Slice[int]{1}},
+			wantAttempts: "1",
+			wantErr:      "exit status 75",
+		},
+		{
+			name:         "too few attempts",
+			retry:        &Retry{Attempts: 2, Delay: time.Millisecond},
+			wantAttempts: "2",
+			wantErr:      "exit status 75",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			g := ghost.New(t)
+
+			dir := t.TempDir()
+			task := Task{
+				Name: "foo",
+				RunList: marshal.Slice[*Run]{{Command: marshal.Slice[*Command]{{
+					Exec:  exec,
+					Retry: tt.retry,
+				}}}},
+			}
+
+			err := task.Execute(Context{
+				CfgPath: filepath.Join(dir, "tusk.yml"),
+				Logger:  ui.Noop(),
+			})
+			if tt.wantErr != "" {
+				g.Should(be.ErrorEqual(err, tt.wantErr))
+			} else {
+				g.NoError(err)
+			}
+
+			attempts, err := os.ReadFile(filepath.Join(dir, "attempts"))
+			g.NoError(err)
+			g.Should(be.Equal(strings.TrimSpace(string(attempts)), tt.wantAttempts))
+		})
+	}
+}
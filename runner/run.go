@@ -2,16 +2,32 @@ package runner
 
 import (
 	"errors"
+	"maps"
+	"slices"
+	"strings"
 
 	"github.com/rliebz/tusk/marshal"
 )
 
 // Run defines a a single runnable item within a task.
 type Run struct {
+	// Name optionally identifies the run item so that it can be selected.
+	Name string `yaml:"name,omitempty"`
+
 	When           WhenList                `yaml:",omitempty"`
 	Command        marshal.Slice[*Command] `yaml:",omitempty"`
 	SubTaskList    marshal.Slice[*SubTask] `yaml:"task,omitempty"`
 	SetEnvironment map[string]*string      `yaml:"set-environment,omitempty"`
+	Wait           *Wait                   `yaml:"wait,omitempty"`
+
+	// Matrix runs the commands once for every combination of its values.
+	Matrix Matrix `yaml:"matrix,omitempty"`
+
+	// Pipe connects the output of each command to the input of the next.
+	Pipe bool `yaml:"pipe,omitempty"`
+
+	// IgnoreErrors keeps running the remaining commands after one fails.
+	IgnoreErrors IgnoreErrors `yaml:"ignore-errors,omitempty"`
 
 	// Computed members not specified in yaml file
 	Tasks []Task `yaml:"-"`
@@ -35,6 +51,7 @@ func (r *Run) UnmarshalYAML(unmarshal func(any) error) error {
 				len(runItem.Command) != 0,
 				len(runItem.SubTaskList) != 0,
 				runItem.SetEnvironment != nil,
+				runItem.Wait != nil,
 			}
 
 			count := 0
@@ -48,6 +65,30 @@ func (r *Run) UnmarshalYAML(unmarshal func(any) error) error {
 				return errors.New("only one action can be defined in `run`")
 			}
 
+			if runItem.Pipe && len(runItem.Command) == 0 {
+				return errors.New("`pipe` can only be used with `command`")
+			}
+
+			if len(runItem.Matrix) != 0 && len(runItem.Command) == 0 {
+				return errors.New("`matrix` can only be used with `command`")
+			}
+
+			if runItem.IgnoreErrors != ignoreErrorsNever && len(runItem.Command) == 0 {
+				return errors.New("`ignore-errors` can only be used with `command`")
+			}
+
+			if runItem.Pipe && slices.ContainsFunc(runItem.Command, func(c *Command) bool {
+				return c.Background
+			}) {
+				return errors.New("`pipe` cannot be used with background commands")
+			}
+
+			if runItem.Pipe && slices.ContainsFunc(runItem.Command, func(c *Command) bool {
+				return c.Retry != nil
+			}) {
+				return errors.New("`pipe` cannot be used with retried commands")
+			}
+
 			return nil
 		},
 	}
@@ -55,22 +96,102 @@ func (r *Run) UnmarshalYAML(unmarshal func(any) error) error {
 	return marshal.UnmarshalOneOf(commandCandidate, runCandidate)
 }
 
+// Dependencies returns a list of options that are required explicitly.
+// This does not include interpolations.
+func (r *Run) Dependencies() []string {
+	return r.When.Dependencies()
+}
+
 func (r *Run) shouldRun(ctx Context, vars map[string]string) (bool, error) {
 	if err := r.When.Validate(ctx, vars); err != nil {
 		if !IsFailedCondition(err) {
 			return false, err
 		}
 
-		for _, command := range r.Command {
-			ctx.Logger.PrintCommandSkipped(command.Print, err.Error())
-		}
+		r.printSkipped(ctx, err.Error())
+		return false, nil
+	}
 
+	return true, nil
+}
+
+// printSkipped logs that the run item was skipped. A named run item is logged
+// once by name, while any other is logged by each of its actions. Skipped
+// sub-tasks are recorded for the run summary either way.
+func (r *Run) printSkipped(ctx Context, reason string) {
+	if r.Name != "" {
+		ctx.Logger.PrintStepSkipped(r.Name, reason, ctx.namespaces()...)
 		for _, subTask := range r.SubTaskList {
-			ctx.Logger.PrintTaskSkipped(subTask.Name, err.Error())
+			recordSkip(ctx, subTask.Name, len(ctx.taskStack), reason)
 		}
+		return
+	}
 
-		return false, nil
+	for _, command := range r.Command {
+		ctx.Logger.PrintCommandSkipped(command.Print, reason)
 	}
 
-	return true, nil
+	for _, subTask := range r.SubTaskList {
+		skipTask(ctx, subTask.Name, len(ctx.taskStack), reason)
+	}
+
+	if r.Wait != nil {
+		ctx.Logger.PrintCommandSkipped(r.Wait.String(), reason)
+	}
+}
+
+// withFailure returns a copy of the run item where the commands refer to the
+// error that caused the task to fail. The message is never inserted into a
+// script, where quotes or substitutions within it would be run. Instead, the
+// failure variable in a script expands the variable holding the message, and
+// is only replaced by the message itself in text that no shell interprets.
+func (r *Run) withFailure(err error) *Run {
+	return r.withReplacers(
+		strings.NewReplacer(failureVariable, "${"+failureEnv+"}"),
+		strings.NewReplacer(failureVariable, err.Error()),
+	)
+}
+
+// withResults returns a copy of the run item where the commands have the result
+// variable of each named item of the run list replaced by its result.
+func (r *Run) withResults(results map[string]string) *Run {
+	oldnew := make([]string, 0, 2*len(results))
+	for name, result := range results {
+		oldnew = append(oldnew, "${"+resultPrefix+name+"}", result)
+	}
+
+	replacer := strings.NewReplacer(oldnew...)
+	return r.withReplacers(replacer, replacer)
+}
+
+// withReplacers returns a copy of the run item where the commands have their
+// text replaced. Scripts run by the interpreter use the script replacer, while
+// printed text, argument lists, and environment values use the text replacer.
+func (r *Run) withReplacers(script, text *strings.Replacer) *Run {
+	replace := text.Replace
+
+	result := *r
+	result.Command = make(marshal.Slice[*Command], 0, len(r.Command))
+	for _, c := range r.Command {
+		command := *c
+		command.Exec = script.Replace(c.Exec)
+		command.Print = replace(c.Print)
+
+		command.Argv = slices.Clone(c.Argv)
+		for i, arg := range command.Argv {
+			command.Argv[i] = replace(arg)
+		}
+
+		command.Env = maps.Clone(c.Env)
+		for key, value := range command.Env {
+			if value != nil {
+				v := replace(*value)
+				command.Env[key] = &v
+			}
+		}
+
+		result.Command = append(result.Command, &command)
+	}
+
+	return &result
 }
@@ -86,6 +86,7 @@ func TestRun_UnmarshalYAML_command_and_subtask(t *testing.T) {
 		`{task: echo 'hello', environment: {foo: bar}}`,
 		`{command: example, task: echo 'hello', environment: {foo: bar}}`,
 		`{environment: {foo: bar}, set-environment: {bar: baz}}`,
+		`{command: example, wait: 5s}`,
 	}
 
 	for _, input := range tests {
@@ -99,6 +100,39 @@ func TestRun_UnmarshalYAML_command_and_subtask(t *testing.T) {
 	}
 }
 
+func TestRun_UnmarshalYAML_pipe(t *testing.T) {
+	g := ghost.New(t)
+
+	var r Run
+	err := yaml.UnmarshalStrict([]byte(`{pipe: true, command: [echo hello, cat]}`), &r)
+	g.NoError(err)
+	g.Should(be.Equal(r.Pipe, true))
+
+	err = yaml.UnmarshalStrict([]byte(`{pipe: true, task: foo}`), &r)
+	g.Should(be.ErrorContaining(err, "`pipe` can only be used with `command`"))
+
+	err = yaml.UnmarshalStrict(
+		[]byte(`{pipe: true, command: [{exec: serve, background: true}, cat]}`),
+		&r,
+	)
+	g.Should(be.ErrorContaining(err, "`pipe` cannot be used with background commands"))
+
+	err = yaml.UnmarshalStrict([]byte(`{pipe: true, command: [{exec: fetch, retry: 3}, cat]}`), &r)
+	g.Should(be.ErrorContaining(err, "`pipe` cannot be used with retried commands"))
+}
+
+func TestRun_UnmarshalYAML_ignoreErrors(t *testing.T) {
+	g := ghost.New(t)
+
+	var r Run
+	err := yaml.UnmarshalStrict([]byte(`{ignore-errors: true, command: [exit 1, echo hi]}`), &r)
+	g.NoError(err)
+	g.Should(be.Equal(r.IgnoreErrors, ignoreErrorsAlways))
+
+	err = yaml.UnmarshalStrict([]byte(`{ignore-errors: at-end, task: foo}`), &r)
+	g.Should(be.ErrorContaining(err, "`ignore-errors` can only be used with `command`"))
+}
+
 func TestRun_shouldRun(t *testing.T) {
 	tests := []struct {
 		name  string
@@ -0,0 +1,67 @@
+package runner
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+)
+
+// Selection determines which named run items should be executed.
+//
+// If Only is non-empty, only run items with a name listed are executed. Run
+// items with a name listed in Skip are never executed. Finally clauses are not
+// affected by selection.
+type Selection struct {
+	Only []string
+	Skip []string
+}
+
+// validate checks that every name selected refers to a run item in the task.
+func (s Selection) validate(t *Task) error {
+	names := make([]string, 0, len(t.RunList))
+	for _, r := range t.RunList {
+		if r.Name != "" {
+			names = append(names, r.Name)
+		}
+	}
+
+	for _, name := range slices.Concat(s.Only, s.Skip) {
+		if slices.Contains(names, name) {
+			continue
+		}
+
+		var err error
+		if len(names) == 0 {
+			err = fmt.Errorf("task %q has no named run items, but %q was selected", t.Name, name)
+		} else {
+			err = fmt.Errorf(
+				"task %q has no run item named %q (one of: %s)",
+				t.Name, name, strings.Join(names, ", "),
+			)
+		}
+
+		// Names such as "subtask:step" look like they select a run item of a
+		// sub-task, which is not supported.
+		if strings.Contains(name, ":") {
+			err = fmt.Errorf("%w; run items of sub-tasks cannot be selected", err)
+		}
+
+		return err
+	}
+
+	return nil
+}
+
+// excludes returns whether a run item is excluded by the selection, along with
+// the reason why.
+func (s Selection) excludes(r *Run) (reason string, excluded bool) {
+	if len(s.Only) > 0 && !slices.Contains(s.Only, r.Name) {
+		return "run item not selected", true
+	}
+
+	if r.Name != "" && slices.Contains(s.Skip, r.Name) {
+		return fmt.Sprintf("run item %q skipped", r.Name), true
+	}
+
+	return "", false
+}
@@ -0,0 +1,142 @@
+package runner
+
+import (
+	"encoding/json"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"time"
+)
+
+// sourceSumsFile is the name of the file in a task's cache directory that
+// records the sum of each source file, as of the last time they were checked.
+const sourceSumsFile = "sources.json"
+
+// sourceChecksum returns a checksum of the source files of a task.
+//
+// Normally every source file is hashed. When only files changed since a git
+// ref or time are to be checked, the other files reuse the sums recorded the
+// last time the sources were checked that way, which trusts that they are
+// unchanged. Sums are only recorded when checking changed files, so that other
+// runs do not pay for writing them.
+func (t *Task) sourceChecksum(ctx Context, cacheDir string) (string, error) {
+	fsys := os.DirFS(ctx.Dir())
+	if !t.isCacheable() || ctx.Since == "" {
+		return dirChecksum("source", fsys, t.Source, ctx.Logger)
+	}
+
+	sumsPath := filepath.Join(cacheDir, sourceSumsFile)
+	var known knownSum
+	if changed, ok := ctx.changedSince(); ok {
+		recorded := readSourceSums(sumsPath)
+		known = func(path string, d fs.DirEntry) ([]byte, bool) {
+			if changed(path, d) {
+				return nil, false
+			}
+			sum, ok := recorded[path]
+			return sum, ok
+		}
+	}
+
+	results, err := dirSums("source", fsys, t.Source, known, ctx.Logger)
+	if err != nil {
+		return "", err
+	}
+
+	// Recording the sums only speeds up later checks, so failing to is not an
+	// error, as with a read-only cache.
+	writeSourceSums(sumsPath, results)
+
+	return combineSums(results), nil
+}
+
+// changedSince returns a function that reports whether a file may have changed
+// since the git ref or time to check from. If there is nothing to check from,
+// or the changed files cannot be found, every file must be checked instead.
+func (c Context) changedSince() (changed func(path string, d fs.DirEntry) bool, ok bool) {
+	if c.Since == "" {
+		return nil, false
+	}
+
+	if since, ok := parseSinceTime(c.Since, time.Now()); ok {
+		return func(_ string, d fs.DirEntry) bool {
+			info, err := d.Info()
+			return err != nil || info.ModTime().After(since)
+		}, true
+	}
+
+	files, err := gitChangedFiles(c, c.Since)
+	if err != nil {
+		c.Logger.Debug("Checking all source files:", err)
+		return nil, false
+	}
+
+	// Untracked files are not listed as changed, but may have changed anyway.
+	untracked, err := gitOutput(c, "ls-files", "--others", "--exclude-standard", "-z")
+	if err != nil {
+		c.Logger.Debug("Checking all source files:", err)
+		return nil, false
+	}
+	files = append(files, splitNUL(untracked)...)
+
+	set := make(map[string]struct{}, len(files))
+	for _, file := range files {
+		set[file] = struct{}{}
+	}
+
+	return func(path string, _ fs.DirEntry) bool {
+		_, ok := set[path]
+		return ok
+	}, true
+}
+
+// parseSinceTime parses the time to check for changed files from, which is
+// either a timestamp, a date, or a duration before now.
+func parseSinceTime(s string, now time.Time) (time.Time, bool) {
+	if d, err := time.ParseDuration(s); err == nil {
+		return now.Add(-d), true
+	}
+
+	for _, layout := range []string{time.RFC3339, time.DateTime, time.DateOnly} {
+		if t, err := time.ParseInLocation(layout, s, time.Local); err == nil {
+			return t, true
+		}
+	}
+
+	return time.Time{}, false
+}
+
+// readSourceSums returns the sums recorded for each source file, or nothing if
+// they cannot be read.
+func readSourceSums(path string) map[string][]byte {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil
+	}
+
+	var sums map[string][]byte
+	if json.Unmarshal(data, &sums) != nil {
+		return nil
+	}
+
+	return sums
+}
+
+// writeSourceSums records the sum of each source file, if possible.
+func writeSourceSums(path string, results []result) {
+	sums := make(map[string][]byte, len(results))
+	for _, r := range results {
+		sums[r.path] = r.sum
+	}
+
+	data, err := json.Marshal(sums)
+	if err != nil {
+		return
+	}
+
+	if os.MkdirAll(filepath.Dir(path), 0o700) != nil {
+		return
+	}
+
+	os.WriteFile(path, data, 0o600) //nolint:errcheck
+}
@@ -0,0 +1,123 @@
+package runner
+
+import (
+	"os"
+	"os/exec"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/rliebz/ghost"
+	"github.com/rliebz/ghost/be"
+
+	"github.com/rliebz/tusk/internal/xtesting"
+	"github.com/rliebz/tusk/marshal"
+	"github.com/rliebz/tusk/ui"
+)
+
+func TestParseSinceTime(t *testing.T) {
+	now := time.Date(2026, 1, 2, 3, 4, 5, 0, time.Local)
+
+	tests := []struct {
+		input  string
+		want   time.Time
+		wantOK bool
+	}{
+		{input: "90m", want: now.Add(-90 * time.Minute), wantOK: true},
+		{
+			input:  "2025-12-31T23:00:00Z",
+			want:   time.Date(2025, 12, 31, 23, 0, 0, 0, time.UTC),
+			wantOK: true,
+		},
+		{
+			input:  "2025-12-31 23:00:00",
+			want:   time.Date(2025, 12, 31, 23, 0, 0, 0, time.Local),
+			wantOK: true,
+		},
+		{input: "2025-12-31", want: time.Date(2025, 12, 31, 0, 0, 0, 0, time.Local), wantOK: true},
+		{input: "main"},
+		{input: "HEAD~2"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.input, func(t *testing.T) {
+			g := ghost.New(t)
+
+			got, ok := parseSinceTime(tt.input, now)
+			g.Should(be.Equal(ok, tt.wantOK))
+			g.Should(be.True(got.Equal(tt.want)))
+		})
+	}
+}
+
+func TestTask_sourceChecksum_since(t *testing.T) {
+	g := ghost.New(t)
+
+	dir := xtesting.UseTempDir(t)
+	t.Setenv("XDG_CACHE_HOME", t.TempDir())
+
+	git := func(args ...string) {
+		t.Helper()
+
+		args = append([]string{"-c", "user.name=tusk", "-c", "user.email=tusk@example.com"}, args...)
+		cmd := exec.Command("git", args...)
+		if out, err := cmd.CombinedOutput(); err != nil {
+			t.Fatalf("git %v: %v\n%s", args, err, out)
+		}
+	}
+	write := func(name, content string, modTime time.Time) {
+		t.Helper()
+
+		g.NoError(os.WriteFile(name, []byte(content), 0o600))
+		g.NoError(os.Chtimes(name, modTime, modTime))
+	}
+
+	old := time.Now().Add(-2 * time.Hour)
+	write("a.txt", "a", old)
+	write("b.txt", "b", old)
+	git("init", "-q", "-b", "main")
+	git("add", ".")
+	git("commit", "-q", "-m", "initial")
+
+	write("c d.txt", "c", old)
+
+	task := &Task{
+		Name:   "build",
+		Source: marshal.Slice[string]{"*.txt"},
+		Target: marshal.Slice[string]{"out.txt"},
+	}
+	cacheDir, err := taskCacheDir(filepath.Join(dir, "tusk.yml"), "", task.Name)
+	g.NoError(err)
+	checksum := func(since string) string {
+		t.Helper()
+
+		ctx := Context{CfgPath: filepath.Join(dir, "tusk.yml"), Logger: ui.Noop(), Since: since}
+		sum, err := task.sourceChecksum(ctx, cacheDir)
+		g.NoError(err)
+		return sum
+	}
+
+	// Sums are only recorded when checking changed files.
+	original := checksum("")
+	_, err = os.Stat(filepath.Join(cacheDir, sourceSumsFile))
+	g.Should(be.ErrorIs(err, os.ErrNotExist))
+	g.Should(be.Equal(checksum("1h"), original))
+
+	// Files that are not modified after the time given are trusted.
+	write("a.txt", "changed", old)
+	g.Should(be.Equal(checksum("1h"), original))
+
+	write("a.txt", "changed", time.Now())
+	changed := checksum("1h")
+	g.Should(be.Equal(changed, checksum("")))
+	g.Should(be.True(changed != original))
+
+	// Files changed since a git ref are hashed, as are untracked files.
+	write("b.txt", "changed", old)
+	write("c d.txt", "changed", old)
+	g.Should(be.Equal(checksum("HEAD"), checksum("")))
+
+	// Every file is hashed if the changed files cannot be found.
+	write("a.txt", "changed again", old)
+	g.Should(be.Equal(checksum("not-a-ref"), checksum("")))
+}
@@ -11,19 +11,25 @@ import (
 	"io"
 	"io/fs"
 	"os"
+	"path"
 	"path/filepath"
 	"runtime"
 	"slices"
+	"strings"
 
 	"github.com/bmatcuk/doublestar/v4"
 	"golang.org/x/sync/errgroup"
+	yaml "gopkg.in/yaml.v2"
 
 	"github.com/rliebz/tusk/internal/xdg"
+	"github.com/rliebz/tusk/marshal"
+	"github.com/rliebz/tusk/ui"
 )
 
-// CleanCache deletes all cached files.
-func CleanCache() error {
-	cacheDir, err := tuskCacheDir()
+// CleanCache deletes all cached files. The cache directory set by the config
+// file is used, if any.
+func CleanCache(cfgCacheDir string) error {
+	cacheDir, err := tuskCacheDir(cfgCacheDir)
 	if err != nil {
 		return err
 	}
@@ -37,12 +43,12 @@ func CleanCache() error {
 }
 
 // CleanProjectCache deletes cached files related to the current config file.
-func CleanProjectCache(cfgPath string) error {
+func CleanProjectCache(cfgPath string, cfgCacheDir string) error {
 	if cfgPath == "" {
 		return errors.New("no config file found")
 	}
 
-	cacheDir, err := projectCacheDir(cfgPath)
+	cacheDir, err := projectCacheDir(cfgPath, cfgCacheDir)
 	if err != nil {
 		return err
 	}
@@ -56,12 +62,12 @@ func CleanProjectCache(cfgPath string) error {
 }
 
 // CleanTaskCache deletes cached files related to the given task.
-func CleanTaskCache(cfgPath string, task string) error {
+func CleanTaskCache(cfgPath string, cfgCacheDir string, task string) error {
 	if cfgPath == "" {
 		return errors.New("no config file found")
 	}
 
-	cacheDir, err := taskCacheDir(cfgPath, task)
+	cacheDir, err := taskCacheDir(cfgPath, cfgCacheDir, task)
 	if err != nil {
 		return err
 	}
@@ -75,48 +81,122 @@ func CleanTaskCache(cfgPath string, task string) error {
 }
 
 func (t *Task) isUpToDate(ctx Context, cachePath string) (bool, error) {
-	if !t.isCacheable() {
-		return false, nil
+	check, err := t.checkCache(ctx, cachePath)
+	return check.upToDate, err
+}
+
+// cacheCheck is the result of checking whether the targets of a task are up
+// to date.
+type cacheCheck struct {
+	upToDate bool
+
+	// reason explains why the targets are or are not up to date.
+	reason string
+
+	// cached and current are the target checksums recorded after the last run
+	// and computed now. They are only set if both could be found.
+	cached  string
+	current string
+}
+
+// checkCache checks whether the targets of a task are up to date, using the
+// cache file for the current inputs.
+func (t *Task) checkCache(ctx Context, cachePath string) (cacheCheck, error) {
+	switch {
+	case ctx.Force:
+		return cacheCheck{reason: "forced to run"}, nil
+	case !t.isCacheable():
+		return cacheCheck{reason: "task does not have both source and target"}, nil
 	}
 
 	cachedChecksumBytes, err := os.ReadFile(cachePath)
 	if errors.Is(err, fs.ErrNotExist) {
-		return false, nil
+		return cacheCheck{reason: "task has not run with the current source files and values"}, nil
 	}
 	if err != nil {
-		return false, err
+		return cacheCheck{}, err
 	}
 
 	if len(cachedChecksumBytes) == 0 {
-		return false, nil
+		return cacheCheck{reason: "no target files existed after the last run"}, nil
 	}
 
 	outputChecksum, err := t.outputChecksum(ctx)
 	if err != nil {
-		return false, err
+		return cacheCheck{}, err
 	}
 
-	return outputChecksum == string(cachedChecksumBytes), nil
+	check := cacheCheck{
+		upToDate: outputChecksum == string(cachedChecksumBytes),
+		reason:   "target files changed since the last run",
+		cached:   string(cachedChecksumBytes),
+		current:  outputChecksum,
+	}
+	if check.upToDate {
+		check.reason = "target files are unchanged since the last run"
+	}
+
+	return check, nil
 }
 
-// taskInputCachePath returns a unique file path based on the inputs of a task.
+// taskInputCachePath returns a unique file path based on the inputs of a task,
+// which are its source files, its run items, and its arg and option values.
 func (t *Task) taskInputCachePath(c Context) (string, error) {
-	taskCacheDir, err := taskCacheDir(c.CfgPath, t.Name)
+	taskCacheDir, err := taskCacheDir(c.CfgPath, c.CacheDir, t.Name)
+	if err != nil {
+		return "", err
+	}
+
+	sourceChecksum, err := t.sourceChecksum(c, taskCacheDir)
 	if err != nil {
 		return "", err
 	}
 
-	filename, err := dirChecksum("source", os.DirFS(c.Dir()), t.Source)
+	definition, err := t.definitionText()
 	if err != nil {
 		return "", err
 	}
 
+	h := fnv.New64a()
+	if _, err := io.WriteString(h, sourceChecksum); err != nil {
+		return "", err
+	}
+	if _, err := h.Write(definition); err != nil {
+		return "", err
+	}
+
+	filename := encodeToString(h)
 	return filepath.Join(taskCacheDir, filename), nil
 }
 
+// definitionText returns the parts of an interpolated task that determine what
+// it does, so that changing any of them invalidates the cache.
+func (t *Task) definitionText() ([]byte, error) {
+	text, err := yaml.Marshal(struct {
+		Interpreter string
+		Run         marshal.Slice[*Run]
+		OnFailure   marshal.Slice[*Run]
+		Finally     marshal.Slice[*Run]
+		Vars        map[string]string
+		Env         map[string]*string `yaml:",omitempty"`
+	}{
+		Interpreter: t.Interpreter,
+		Run:         t.RunList,
+		OnFailure:   t.OnFailure,
+		Finally:     t.Finally,
+		Vars:        t.Vars,
+		Env:         t.Cache.env(),
+	})
+	if err != nil {
+		return nil, fmt.Errorf("hashing task definition: %w", err)
+	}
+
+	return text, nil
+}
+
 // taskCacheDir returns the file path specific to this task.
-func taskCacheDir(cfgPath string, taskName string) (string, error) {
-	projectCacheDir, err := projectCacheDir(cfgPath)
+func taskCacheDir(cfgPath string, cfgCacheDir string, taskName string) (string, error) {
+	projectCacheDir, err := projectCacheDir(cfgPath, cfgCacheDir)
 	if err != nil {
 		return "", err
 	}
@@ -130,13 +210,13 @@ func taskCacheDir(cfgPath string, taskName string) (string, error) {
 	return filepath.Join(projectCacheDir, filename), nil
 }
 
-func projectCacheDir(cfgPath string) (string, error) {
+func projectCacheDir(cfgPath string, cfgCacheDir string) (string, error) {
 	cfgPath, err := filepath.Abs(cfgPath)
 	if err != nil {
 		return "", err
 	}
 
-	cacheDir, err := tuskCacheDir()
+	cacheDir, err := tuskCacheDir(cfgCacheDir)
 	if err != nil {
 		return "", err
 	}
@@ -151,7 +231,21 @@ func projectCacheDir(cfgPath string) (string, error) {
 	return filepath.Join(cacheDir, filename), nil
 }
 
-func tuskCacheDir() (string, error) {
+// cacheDirEnv is the environment variable that sets the cache directory.
+const cacheDirEnv = "TUSK_CACHE_DIR"
+
+// tuskCacheDir returns the directory where all cached files are stored. The
+// environment takes precedence over the directory set by the config file, and
+// the default is within the user's cache directory.
+func tuskCacheDir(cfgCacheDir string) (string, error) {
+	if dir := os.Getenv(cacheDirEnv); dir != "" {
+		return filepath.Abs(dir)
+	}
+
+	if cfgCacheDir != "" {
+		return cfgCacheDir, nil
+	}
+
 	xdgCacheHome, err := xdg.CacheHome()
 	if err != nil {
 		return "", err
@@ -160,9 +254,34 @@ func tuskCacheDir() (string, error) {
 	return filepath.Join(xdgCacheHome, "tusk"), nil
 }
 
+// ReadCacheDir reads the cache directory set by a config file, which is
+// relative to the config file's directory. If none is set, it returns an empty
+// string. This is read before the config is parsed, so that the cache can be
+// cleaned even if the config is invalid.
+func ReadCacheDir(cfgPath string, cfgText []byte) (string, error) {
+	var cfg struct {
+		CacheDir string `yaml:"cache-dir"`
+	}
+
+	if err := yaml.Unmarshal(cfgText, &cfg); err != nil {
+		return "", err
+	}
+
+	return resolveCacheDir(cfgPath, cfg.CacheDir), nil
+}
+
+// resolveCacheDir resolves a cache directory relative to the config file.
+func resolveCacheDir(cfgPath string, dir string) string {
+	if dir == "" || filepath.IsAbs(dir) {
+		return dir
+	}
+
+	return filepath.Join(filepath.Dir(cfgPath), dir)
+}
+
 // outputChecksum returns a checksum for the output of a task.
 func (t *Task) outputChecksum(c Context) (string, error) {
-	filename, err := dirChecksum("target", os.DirFS(c.Dir()), t.Target)
+	filename, err := dirChecksum("target", os.DirFS(c.Dir()), t.Target, c.Logger)
 	var pnfe *patternNotFoundError
 	switch {
 	case errors.As(err, &pnfe):
@@ -195,6 +314,51 @@ func (t *Task) cache(ctx Context, cachePath string) error {
 	return nil
 }
 
+// Cache configures what the targets of a task depend on, beyond its source
+// files and its arg and option values.
+type Cache struct {
+	// Inputs are the names of environment variables that the targets depend on.
+	Inputs marshal.Slice[string] `yaml:"inputs"`
+}
+
+// validate checks that the cache of a task is valid. A nil cache is valid.
+func (c *Cache) validate(t *Task) error {
+	if c == nil {
+		return nil
+	}
+
+	if len(t.Source) == 0 {
+		return errors.New("task cache cannot be defined without source and target")
+	}
+
+	for _, name := range c.Inputs {
+		if name == "" {
+			return errors.New("task cache input cannot be empty")
+		}
+	}
+
+	return nil
+}
+
+// env returns the value of each input, or nil if it is unset, so that an unset
+// variable is not the same as an empty one.
+func (c *Cache) env() map[string]*string {
+	if c == nil || len(c.Inputs) == 0 {
+		return nil
+	}
+
+	env := make(map[string]*string, len(c.Inputs))
+	for _, name := range c.Inputs {
+		if value, ok := os.LookupEnv(name); ok {
+			env[name] = &value
+		} else {
+			env[name] = nil
+		}
+	}
+
+	return env
+}
+
 func (t *Task) isCacheable() bool {
 	return len(t.Source) != 0 && len(t.Target) != 0
 }
@@ -211,6 +375,10 @@ func (e *patternNotFoundError) Error() string {
 type entry struct {
 	path string
 	d    fs.DirEntry
+
+	// link is set for a symlink that is hashed by where it points, rather than
+	// by the contents of the file it points to.
+	link bool
 }
 
 type result struct {
@@ -218,20 +386,41 @@ type result struct {
 	sum  []byte
 }
 
-func dirChecksum(kind string, dir fs.FS, patterns []string) (string, error) {
+func dirChecksum(kind string, dir fs.FS, patterns []string, logger *ui.Logger) (string, error) {
+	results, err := dirSums(kind, dir, patterns, nil, logger)
+	if err != nil {
+		return "", err
+	}
+
+	return combineSums(results), nil
+}
+
+// knownSum returns the sum of a file if it is already known, so that the file
+// does not need to be hashed again.
+type knownSum func(path string, d fs.DirEntry) ([]byte, bool)
+
+// dirSums returns the sum of each file matching the patterns, sorted by path.
+// Files with a known sum are not hashed. If known is nil, every file is hashed.
+func dirSums(
+	kind string,
+	dir fs.FS,
+	patterns []string,
+	known knownSum,
+	logger *ui.Logger,
+) ([]result, error) {
 	g, ctx := errgroup.WithContext(context.Background())
 	numWorkers := runtime.GOMAXPROCS(0)
 
 	entries := make(chan entry, numWorkers*2)
 	g.Go(func() error {
 		defer close(entries)
-		return walkEntries(ctx, entries, kind, dir, patterns)
+		return walkEntries(ctx, entries, kind, dir, patterns, logger)
 	})
 
 	results := make(chan result, numWorkers*2)
 	for range numWorkers {
 		g.Go(func() error {
-			return hashEntries(ctx, results, entries)
+			return hashEntries(ctx, results, entries, known)
 		})
 	}
 	go func() {
@@ -245,19 +434,24 @@ func dirChecksum(kind string, dir fs.FS, patterns []string) (string, error) {
 	}
 
 	if err := g.Wait(); err != nil {
-		return "", err
+		return nil, err
 	}
 
 	slices.SortFunc(resultList, func(a, b result) int {
 		return cmp.Compare(a.path, b.path)
 	})
 
+	return resultList, nil
+}
+
+// combineSums returns a checksum of the sums of a sorted list of files.
+func combineSums(results []result) string {
 	h := fnv.New64a()
-	for _, result := range resultList {
+	for _, result := range results {
 		h.Write(result.sum)
 	}
 
-	return encodeToString(h), nil
+	return encodeToString(h)
 }
 
 // walkEntries iterates over a set of files and writes them to entries.
@@ -267,47 +461,124 @@ func walkEntries(
 	kind string,
 	dir fs.FS,
 	patterns []string,
+	logger *ui.Logger,
 ) error {
+	files, err := globFiles(kind, dir, patterns, logger)
+	if err != nil {
+		return err
+	}
+
+	for _, file := range files {
+		select {
+		case entries <- file:
+		case <-ctx.Done():
+			return ctx.Err()
+		}
+	}
+
+	return nil
+}
+
+// globFiles returns the files matching a list of patterns. As with gitignore,
+// patterns starting with "!" exclude the files matched by the patterns before
+// them, and a later pattern may match an excluded file again.
+//
+// Every pattern that is not an exclusion must match at least one file. An
+// exclusion that leaves no files is almost certainly a mistake, so it is
+// reported as a warning.
+func globFiles(kind string, dir fs.FS, patterns []string, logger *ui.Logger) ([]entry, error) {
+	var files []entry
+	seen := make(map[string]bool)
+
 	for _, glob := range patterns {
+		if exclude, ok := strings.CutPrefix(glob, "!"); ok {
+			exclude = filepath.ToSlash(filepath.Clean(exclude))
+			if strings.HasSuffix(glob, "/") {
+				exclude = path.Join(exclude, "**")
+			}
+			if !doublestar.ValidatePattern(exclude) {
+				return nil, fmt.Errorf("invalid %s pattern: %s", kind, glob)
+			}
+
+			matchedBefore := len(files)
+			files = slices.DeleteFunc(files, func(e entry) bool {
+				excluded, _ := doublestar.Match(exclude, e.path)
+				if excluded {
+					delete(seen, e.path)
+				}
+				return excluded
+			})
+			if matchedBefore > 0 && len(files) == 0 {
+				logger.Warn(fmt.Sprintf("%s pattern %s excludes every file", kind, glob))
+			}
+			continue
+		}
+
+		pattern := filepath.Clean(glob)
+		opts := []doublestar.GlobOption{
+			doublestar.WithFailOnIOErrors(),
+			doublestar.WithFilesOnly(),
+			doublestar.WithFailOnPatternNotExist(),
+		}
+
+		// A directory stands for every file inside of it. Symlinks inside are not
+		// followed, so that they cannot point back to the directory, or nowhere.
+		isDir := strings.HasSuffix(glob, "/")
+		if isDir {
+			pattern = filepath.Join(pattern, "**")
+			opts = append(opts, doublestar.WithNoFollow())
+		}
+
 		count := 0
 		err := doublestar.GlobWalk(
 			dir,
-			filepath.Clean(glob),
+			pattern,
 			func(path string, d fs.DirEntry) error {
 				count++
-				select {
-				case entries <- entry{path, d}:
-				case <-ctx.Done():
-					return ctx.Err()
+				if !seen[path] {
+					seen[path] = true
+					link := isDir && d.Type()&fs.ModeSymlink != 0
+					files = append(files, entry{path: path, d: d, link: link})
 				}
 				return nil
 			},
-			doublestar.WithFailOnIOErrors(),
-			doublestar.WithFilesOnly(),
-			doublestar.WithFailOnPatternNotExist(),
+			opts...,
 		)
 		switch {
 		case errors.Is(err, doublestar.ErrPatternNotExist) || (err == nil && count == 0):
-			return &patternNotFoundError{kind: kind, pattern: glob}
+			return nil, &patternNotFoundError{kind: kind, pattern: glob}
 		case err != nil:
-			return err
+			return nil, err
 		}
 	}
 
-	return nil
+	return files, nil
 }
 
-// hashEntries iterates over entries and hashes the files into results.
+// hashEntries iterates over entries and hashes the files into results, unless
+// their sums are already known.
 func hashEntries(
 	ctx context.Context,
 	results chan<- result,
 	entries <-chan entry,
+	known knownSum,
 ) error {
 	buf := make([]byte, 1024*1024)
 	for entry := range entries {
-		sum, err := hashFile(entry.path, entry.d, buf)
-		if err != nil {
-			return err
+		sum, ok := []byte(nil), false
+		if known != nil {
+			sum, ok = known(entry.path, entry.d)
+		}
+		if !ok {
+			var err error
+			if entry.link {
+				sum, err = hashLink(entry.path)
+			} else {
+				sum, err = hashFile(entry.path, entry.d, buf)
+			}
+			if err != nil {
+				return err
+			}
 		}
 		select {
 		case results <- result{entry.path, sum}:
@@ -341,6 +612,25 @@ func hashFile(path string, d fs.DirEntry, buf []byte) ([]byte, error) {
 	return h.Sum(nil), nil
 }
 
+// hashLink hashes a symlink by the path it points to.
+func hashLink(path string) ([]byte, error) {
+	dest, err := os.Readlink(path)
+	if err != nil {
+		return nil, err
+	}
+
+	h := fnv.New64a()
+	if _, err := io.WriteString(h, path); err != nil {
+		return nil, err
+	}
+
+	if _, err := io.WriteString(h, "->"+dest); err != nil {
+		return nil, err
+	}
+
+	return h.Sum(nil), nil
+}
+
 func encodeToString(h hash.Hash) string {
 	return base64.RawStdEncoding.EncodeToString(h.Sum(nil))
 }
@@ -1,12 +1,18 @@
 package runner
 
 import (
+	"bytes"
+	"io"
+	"io/fs"
 	"os"
 	"path/filepath"
 	"testing"
+	"testing/fstest"
 
 	"github.com/rliebz/ghost"
 	"github.com/rliebz/ghost/be"
+
+	"github.com/rliebz/tusk/ui"
 )
 
 func TestCleanCache(t *testing.T) {
@@ -19,13 +25,82 @@ func TestCleanCache(t *testing.T) {
 	err := os.MkdirAll(cacheDir, 0o700)
 	g.NoError(err)
 
-	err = CleanCache()
+	err = CleanCache("")
 	g.NoError(err)
 
 	_, err = os.Stat(cacheDir)
 	g.Should(be.ErrorIs(err, os.ErrNotExist))
 }
 
+func TestCleanCache_cache_dir(t *testing.T) {
+	g := ghost.New(t)
+
+	t.Setenv("XDG_CACHE_HOME", t.TempDir())
+
+	cfgCacheDir := filepath.Join(t.TempDir(), "cache")
+	err := os.MkdirAll(cfgCacheDir, 0o700)
+	g.NoError(err)
+
+	envCacheDir := filepath.Join(t.TempDir(), "cache")
+	err = os.MkdirAll(envCacheDir, 0o700)
+	g.NoError(err)
+
+	err = CleanCache(cfgCacheDir)
+	g.NoError(err)
+
+	_, err = os.Stat(cfgCacheDir)
+	g.Should(be.ErrorIs(err, os.ErrNotExist))
+
+	// The environment takes precedence over the config file.
+	t.Setenv("TUSK_CACHE_DIR", envCacheDir)
+	err = os.MkdirAll(cfgCacheDir, 0o700)
+	g.NoError(err)
+
+	err = CleanCache(cfgCacheDir)
+	g.NoError(err)
+
+	_, err = os.Stat(envCacheDir)
+	g.Should(be.ErrorIs(err, os.ErrNotExist))
+	_, err = os.Stat(cfgCacheDir)
+	g.NoError(err)
+}
+
+func TestReadCacheDir(t *testing.T) {
+	absDir := t.TempDir()
+
+	tests := []struct {
+		name    string
+		cfgText string
+		want    string
+	}{
+		{
+			name: "not set",
+			want: "",
+		},
+		{
+			name:    "relative",
+			cfgText: "cache-dir: .cache/tusk",
+			want:    filepath.Join("path", "to", ".cache", "tusk"),
+		},
+		{
+			name:    "absolute",
+			cfgText: "cache-dir: " + absDir,
+			want:    absDir,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			g := ghost.New(t)
+
+			cfgPath := filepath.Join("path", "to", "tusk.yml")
+			got, err := ReadCacheDir(cfgPath, []byte(tt.cfgText))
+			g.NoError(err)
+			g.Should(be.Equal(got, tt.want))
+		})
+	}
+}
+
 func TestCleanProjectCache(t *testing.T) {
 	g := ghost.New(t)
 
@@ -34,12 +109,12 @@ func TestCleanProjectCache(t *testing.T) {
 
 	cacheDir := filepath.Join(cacheHome, "tusk")
 
-	projectDir1, err := projectCacheDir("tusk.yml")
+	projectDir1, err := projectCacheDir("tusk.yml", "")
 	g.NoError(err)
 	err = os.MkdirAll(projectDir1, 0o700)
 	g.NoError(err)
 
-	projectDir2, err := projectCacheDir("tusk-2.yml")
+	projectDir2, err := projectCacheDir("tusk-2.yml", "")
 	g.NoError(err)
 	err = os.MkdirAll(projectDir2, 0o700)
 	g.NoError(err)
@@ -48,7 +123,7 @@ func TestCleanProjectCache(t *testing.T) {
 	g.NoError(err)
 	g.Must(be.SliceLen(entries, 2))
 
-	err = CleanProjectCache("tusk.yml")
+	err = CleanProjectCache("tusk.yml", "")
 	g.NoError(err)
 
 	entries, err = os.ReadDir(cacheDir)
@@ -62,7 +137,7 @@ func TestCleanProjectCache_no_cfg_path(t *testing.T) {
 	cacheHome := t.TempDir()
 	t.Setenv("XDG_CACHE_HOME", cacheHome) // just in case
 
-	err := CleanProjectCache("")
+	err := CleanProjectCache("", "")
 	g.Should(be.ErrorEqual(err, "no config file found"))
 }
 
@@ -72,36 +147,36 @@ func TestCleanTaskCache(t *testing.T) {
 	cacheHome := t.TempDir()
 	t.Setenv("XDG_CACHE_HOME", cacheHome)
 
-	taskDir, err := taskCacheDir("tusk.yml", "my-task")
+	taskDir, err := taskCacheDir("tusk.yml", "", "my-task")
 	g.NoError(err)
 	err = os.MkdirAll(taskDir, 0o700)
 	g.NoError(err)
 
-	sameProjectTaskDir, err := taskCacheDir("tusk.yml", "other-task")
+	sameProjectTaskDir, err := taskCacheDir("tusk.yml", "", "other-task")
 	g.NoError(err)
 	err = os.MkdirAll(sameProjectTaskDir, 0o700)
 	g.NoError(err)
 
-	otherProjectTaskDir, err := taskCacheDir("tusk-2.yml", "my-task")
+	otherProjectTaskDir, err := taskCacheDir("tusk-2.yml", "", "my-task")
 	g.NoError(err)
 	err = os.MkdirAll(otherProjectTaskDir, 0o700)
 	g.NoError(err)
 
-	projectDir, err := projectCacheDir("tusk.yml")
+	projectDir, err := projectCacheDir("tusk.yml", "")
 	g.NoError(err)
 
 	entries, err := os.ReadDir(projectDir)
 	g.NoError(err)
 	g.Must(be.SliceLen(entries, 2))
 
-	err = CleanTaskCache("tusk.yml", "my-task")
+	err = CleanTaskCache("tusk.yml", "", "my-task")
 	g.NoError(err)
 
 	entries, err = os.ReadDir(projectDir)
 	g.NoError(err)
 	g.Must(be.SliceLen(entries, 1))
 
-	otherProjectDir, err := projectCacheDir("tusk-2.yml")
+	otherProjectDir, err := projectCacheDir("tusk-2.yml", "")
 	g.NoError(err)
 
 	otherEntries, err := os.ReadDir(otherProjectDir)
@@ -115,6 +190,106 @@ func TestCleanTaskCache_no_cfg_path(t *testing.T) {
 	cacheHome := t.TempDir()
 	t.Setenv("XDG_CACHE_HOME", cacheHome) // just in case
 
-	err := CleanTaskCache("", "foo")
+	err := CleanTaskCache("", "", "foo")
 	g.Should(be.ErrorEqual(err, "no config file found"))
 }
+
+func TestGlobFiles(t *testing.T) {
+	dir := fstest.MapFS{
+		"src/a.go":      {},
+		"src/a_gen.go":  {},
+		"src/b/b.go":    {},
+		"src/.DS_Store": {},
+		"README.md":     {},
+	}
+
+	tests := []struct {
+		name     string
+		patterns []string
+		want     []string
+		wantWarn string
+	}{
+		{
+			name:     "no exclusions",
+			patterns: []string{"src/*.go", "README.md"},
+			want:     []string{"src/a.go", "src/a_gen.go", "README.md"},
+		},
+		{
+			name:     "duplicate matches",
+			patterns: []string{"src/*.go", "src/a.go"},
+			want:     []string{"src/a.go", "src/a_gen.go"},
+		},
+		{
+			name:     "exclusions",
+			patterns: []string{"src/**", "!src/**/*_gen.go", "!**/.DS_Store"},
+			want:     []string{"src/a.go", "src/b/b.go"},
+		},
+		{
+			name:     "included again",
+			patterns: []string{"src/**", "!src/**/*.go", "src/a.go"},
+			want:     []string{"src/.DS_Store", "src/a.go"},
+		},
+		{
+			name:     "exclusion before any matches",
+			patterns: []string{"!src/a.go", "src/a.go"},
+			want:     []string{"src/a.go"},
+		},
+		{
+			name:     "directory",
+			patterns: []string{"src/"},
+			want:     []string{"src/.DS_Store", "src/a.go", "src/a_gen.go", "src/b/b.go"},
+		},
+		{
+			name:     "directory excluded",
+			patterns: []string{"src/**/*.go", "!src/b/"},
+			want:     []string{"src/a.go", "src/a_gen.go"},
+		},
+		{
+			name:     "everything excluded",
+			patterns: []string{"src/*.go", "!**"},
+			wantWarn: "source pattern !** excludes every file",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			g := ghost.New(t)
+
+			var stderr bytes.Buffer
+			logger := ui.New(ui.Config{Stdout: io.Discard, Stderr: &stderr})
+
+			files, err := globFiles("source", dir, tt.patterns, logger)
+			g.NoError(err)
+
+			var got []string
+			for _, file := range files {
+				got = append(got, file.path)
+			}
+			g.Should(be.DeepEqual(got, tt.want))
+
+			if tt.wantWarn == "" {
+				g.Should(be.Equal(stderr.String(), ""))
+			} else {
+				g.Should(be.StringContaining(stderr.String(), tt.wantWarn))
+			}
+		})
+	}
+}
+
+func TestGlobFiles_invalid_exclusion(t *testing.T) {
+	g := ghost.New(t)
+
+	dir := fstest.MapFS{"a.go": {}}
+
+	_, err := globFiles("source", dir, []string{"*.go", "![a"}, ui.Noop())
+	g.Should(be.ErrorEqual(err, "invalid source pattern: ![a"))
+}
+
+func TestGlobFiles_empty_directory(t *testing.T) {
+	g := ghost.New(t)
+
+	dir := fstest.MapFS{"dist": {Mode: fs.ModeDir}}
+
+	_, err := globFiles("target", dir, []string{"dist/"}, ui.Noop())
+	g.Should(be.ErrorEqual(err, "no target files found matching pattern: dist/"))
+}
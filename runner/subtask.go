@@ -1,12 +1,24 @@
 package runner
 
-import "github.com/rliebz/tusk/marshal"
+import (
+	"encoding/json"
+	"fmt"
+	"maps"
+	"slices"
+
+	"github.com/rliebz/tusk/marshal"
+)
 
 // SubTask is a description of a sub-task with passed options.
 type SubTask struct {
 	Name    string
-	Args    marshal.Slice[string]
+	Args    SubTaskArgs
 	Options map[string]string
+
+	// PassOptions lists the options of the parent task whose values should be
+	// passed to the sub-task. The value "all" passes every option that both
+	// tasks define.
+	PassOptions marshal.Slice[string] `yaml:"pass-options"`
 }
 
 // UnmarshalYAML allows unmarshaling a string to represent the subtask name.
@@ -26,3 +38,79 @@ func (s *SubTask) UnmarshalYAML(unmarshal func(any) error) error {
 
 	return marshal.UnmarshalOneOf(nameCandidate, subTaskCandidate)
 }
+
+// SubTaskArgs are the values of the args passed to a sub-task, which are either
+// listed in order or mapped by arg name.
+type SubTaskArgs struct {
+	list  []string
+	named map[string]string
+}
+
+// UnmarshalYAML allows a single value, a list, or a map of values by name.
+func (a *SubTaskArgs) UnmarshalYAML(unmarshal func(any) error) error {
+	var list marshal.Slice[string]
+	listCandidate := marshal.UnmarshalCandidate{
+		Unmarshal: func() error { return unmarshal(&list) },
+		Assign:    func() { *a = SubTaskArgs{list: list} },
+	}
+
+	var named map[string]string
+	namedCandidate := marshal.UnmarshalCandidate{
+		Unmarshal: func() error { return unmarshal(&named) },
+		Assign:    func() { *a = SubTaskArgs{named: named} },
+	}
+
+	return marshal.UnmarshalOneOf(listCandidate, namedCandidate)
+}
+
+// MarshalYAML represents the args the same way they were defined, so that
+// they survive interpolation.
+func (a SubTaskArgs) MarshalYAML() (any, error) {
+	if a.named != nil {
+		return a.named, nil
+	}
+
+	return a.list, nil
+}
+
+// MarshalJSON represents the args the same way as MarshalYAML, so that the
+// names they interpolate can be found.
+func (a SubTaskArgs) MarshalJSON() ([]byte, error) {
+	if a.named != nil {
+		return json.Marshal(a.named)
+	}
+
+	return json.Marshal(a.list)
+}
+
+// values returns the values of the args in the order the sub-task defines
+// them. Args passed by name must not skip over any arg defined before them.
+func (a SubTaskArgs) values(sub *Task) ([]string, error) {
+	if a.named == nil {
+		return a.list, nil
+	}
+
+	for _, name := range slices.Sorted(maps.Keys(a.named)) {
+		if _, ok := sub.Args.Lookup(name); !ok {
+			return nil, fmt.Errorf("arg %q cannot be passed to task %q", name, sub.Name)
+		}
+	}
+
+	values := make([]string, 0, len(a.named))
+	for _, arg := range sub.Args {
+		value, ok := a.named[arg.Name]
+		if !ok {
+			break
+		}
+		values = append(values, value)
+	}
+
+	if len(values) < len(a.named) {
+		return nil, fmt.Errorf(
+			"arg %q must be passed to task %q along with the args after it",
+			sub.Args[len(values)].Name, sub.Name,
+		)
+	}
+
+	return values, nil
+}
@@ -6,6 +6,8 @@ import (
 	"github.com/rliebz/ghost"
 	"github.com/rliebz/ghost/be"
 	yaml "gopkg.in/yaml.v2"
+
+	"github.com/rliebz/tusk/marshal"
 )
 
 func TestSubTask_UnmarshalYAML(t *testing.T) {
@@ -22,3 +24,56 @@ func TestSubTask_UnmarshalYAML(t *testing.T) {
 	g.Should(be.DeepEqual(st1, st2))
 	g.Should(be.DeepEqual(st1, SubTask{Name: "example"}))
 }
+
+func TestSubTask_UnmarshalYAML_pass_options(t *testing.T) {
+	g := ghost.New(t)
+
+	var got SubTask
+	err := yaml.UnmarshalStrict([]byte(`{name: example, pass-options: all}`), &got)
+	g.NoError(err)
+
+	g.Should(be.DeepEqual(got, SubTask{
+		Name:        "example",
+		PassOptions: marshal.Slice[string]{"all"},
+	}))
+}
+
+func TestSubTaskArgs_UnmarshalYAML(t *testing.T) {
+	tests := []struct {
+		name  string
+		input string
+		want  SubTaskArgs
+	}{
+		{
+			name:  "single value",
+			input: `a`,
+			want:  SubTaskArgs{list: []string{"a"}},
+		},
+		{
+			name:  "list",
+			input: `[a, b]`,
+			want:  SubTaskArgs{list: []string{"a", "b"}},
+		},
+		{
+			name:  "named",
+			input: `{foo: a, bar: b}`,
+			want:  SubTaskArgs{named: map[string]string{"foo": "a", "bar": "b"}},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			g := ghost.New(t)
+
+			var got SubTaskArgs
+			err := yaml.UnmarshalStrict([]byte(tt.input), &got)
+			g.NoError(err)
+			g.Should(be.DeepEqual(got, tt.want))
+
+			// Args keep their form when interpolated.
+			err = marshal.Interpolate(&got, map[string]string{})
+			g.NoError(err)
+			g.Should(be.DeepEqual(got, tt.want))
+		})
+	}
+}
@@ -1,21 +1,52 @@
 package runner
 
 import (
+	"bytes"
 	"errors"
 	"fmt"
 	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"time"
 
 	yaml "gopkg.in/yaml.v2"
 
 	"github.com/rliebz/tusk/marshal"
+	"github.com/rliebz/tusk/ui"
 )
 
-// executionState indicates whether a task is "running" or "finally".
+// timeSince allows overwriting during tests.
+var timeSince = time.Since
+
+// executionState indicates whether a task is "running", "on-failure", or
+// "finally".
 type executionState int
 
 const (
-	stateRunning executionState = iota
-	stateFinally executionState = iota
+	stateRunning   executionState = iota
+	stateOnFailure executionState = iota
+	stateFinally   executionState = iota
+)
+
+// failureVariable is replaced in on-failure commands by the error that caused
+// the task to fail.
+const failureVariable = "${.error}"
+
+// failureEnv is the environment variable that holds the error that caused the
+// task to fail while its on-failure clause runs.
+const failureEnv = "TUSK_ERROR"
+
+// resultPrefix starts the variables that are replaced in finally commands by
+// the result of each named item of the run list, such as "${.result.build}".
+const resultPrefix = ".result."
+
+// The results of a named item of the run list, as seen by the finally clause.
+const (
+	resultSucceeded = "succeeded"
+	resultFailed    = "failed"
+	resultSkipped   = "skipped"
+	resultNotRun    = "not-run"
 )
 
 // Task is a single task to be run by CLI.
@@ -23,19 +54,82 @@ type Task struct {
 	Args    Args    `yaml:"args,omitempty"`
 	Options Options `yaml:"options,omitempty"`
 
-	RunList     marshal.Slice[*Run] `yaml:"run"`
-	Finally     marshal.Slice[*Run] `yaml:"finally,omitempty"`
-	Usage       string              `yaml:"usage,omitempty"`
-	Description string              `yaml:"description,omitempty"`
-	Private     bool                `yaml:"private"`
-	Quiet       bool                `yaml:"quiet"`
+	RunList     marshal.Slice[*Run]   `yaml:"run"`
+	OnFailure   marshal.Slice[*Run]   `yaml:"on-failure,omitempty"`
+	Finally     marshal.Slice[*Run]   `yaml:"finally,omitempty"`
+	Usage       string                `yaml:"usage,omitempty"`
+	Description string                `yaml:"description,omitempty"`
+	Examples    []Example             `yaml:"examples,omitempty"`
+	Aliases     marshal.Slice[string] `yaml:"aliases,omitempty"`
+	Private     bool                  `yaml:"private"`
+	Quiet       bool                  `yaml:"quiet"`
+	Capture     bool                  `yaml:"capture"`
+	KeepGoing   bool                  `yaml:"keep-going"`
+	Interpreter string                `yaml:"interpreter,omitempty"`
+	Output      *Output               `yaml:"output,omitempty"`
+	Confirm     *Confirm              `yaml:"confirm,omitempty"`
 
 	Source marshal.Slice[string] `yaml:"source"`
 	Target marshal.Slice[string] `yaml:"target"`
+	Cache  *Cache                `yaml:"cache,omitempty"`
+
+	// Extends is the name of a task to inherit fields from.
+	Extends string `yaml:"extends,omitempty"`
+
+	// AppendRun is added to the end of the run list inherited from the task
+	// being extended.
+	AppendRun marshal.Slice[*Run] `yaml:"append-run,omitempty"`
+
+	// SkipCommonOptions leaves out the common options of the config file.
+	SkipCommonOptions bool `yaml:"skip-common-options"`
 
 	// Computed members not specified in yaml file
 	Name string            `yaml:"-"`
 	Vars map[string]string `yaml:"-"`
+
+	// UserConfig is the path of the user-level config file that defines the
+	// task, or empty if the task is defined by the project.
+	UserConfig string `yaml:"-"`
+
+	// Imported is the tool whose script the task was generated from, such as
+	// "npm", or empty if the task is defined by a config file.
+	Imported string `yaml:"-"`
+
+	// include is the path or URL of the file containing the task definition,
+	// which is loaded once the location of the config file is known.
+	include string
+
+	// includeChecksum is the expected sha256 checksum of the included file.
+	includeChecksum string
+
+	// overrides are the fields set alongside include, which take precedence
+	// over the included definition.
+	overrides includeOverrides
+}
+
+// includeOverrides are the fields that may be set alongside include. They only
+// affect how a task is presented, not what it does.
+type includeOverrides struct {
+	Usage       *string `yaml:"usage"`
+	Description *string `yaml:"description"`
+	Private     *bool   `yaml:"private"`
+	Quiet       *bool   `yaml:"quiet"`
+}
+
+// apply sets each overridden field on a task.
+func (o includeOverrides) apply(t *Task) {
+	if o.Usage != nil {
+		t.Usage = *o.Usage
+	}
+	if o.Description != nil {
+		t.Description = *o.Description
+	}
+	if o.Private != nil {
+		t.Private = *o.Private
+	}
+	if o.Quiet != nil {
+		t.Quiet = *o.Quiet
+	}
 }
 
 // UnmarshalYAML unmarshals and assigns names to options.
@@ -44,8 +138,10 @@ func (t *Task) UnmarshalYAML(unmarshal func(any) error) error {
 	includeCandidate := marshal.UnmarshalCandidate{
 		Unmarshal: func() error {
 			var def struct {
-				Include string            `yaml:"include"`
-				Else    map[string]string `yaml:",inline"`
+				Include   string           `yaml:"include"`
+				SHA256    string           `yaml:"sha256"`
+				Overrides includeOverrides `yaml:",inline"`
+				Else      map[string]any   `yaml:",inline"`
 			}
 
 			if err := unmarshal(&def); err != nil {
@@ -58,23 +154,20 @@ func (t *Task) UnmarshalYAML(unmarshal func(any) error) error {
 			}
 
 			if len(def.Else) != 0 {
-				return errors.New(`tasks using "include" may not specify other fields`)
+				return errors.New(
+					`tasks using "include" may only also specify usage, description, private, or quiet`,
+				)
 			}
 
-			f, err := os.Open(def.Include)
-			if err != nil {
-				return fmt.Errorf("opening included file: %w", err)
+			if err := validateChecksum(def.SHA256); err != nil {
+				return err
 			}
-			defer f.Close() //nolint:errcheck
 
-			decoder := yaml.NewDecoder(f)
-			decoder.SetStrict(true)
-
-			if err := decoder.Decode(&includeTarget); err != nil {
-				//nolint:errorlint // opaque the error to prevent unmarshal retries
-				return fmt.Errorf("decoding included file %q: %v", def.Include, err)
+			includeTarget = Task{
+				include:         def.Include,
+				includeChecksum: def.SHA256,
+				overrides:       def.Overrides,
 			}
-
 			return nil
 		},
 		Assign: func() { *t = includeTarget },
@@ -93,32 +186,280 @@ func (t *Task) UnmarshalYAML(unmarshal func(any) error) error {
 	return marshal.UnmarshalOneOf(includeCandidate, taskCandidate)
 }
 
-// isValid checks whether a given task definition is valid.
+// Tasks is a set of tasks by name, as defined under the tasks key of a config
+// file.
+type Tasks map[string]*Task
+
+// UnmarshalYAML unmarshals each task, adding the task's name and location to
+// any errors.
+func (t *Tasks) UnmarshalYAML(unmarshal func(any) error) error {
+	tasks := make(Tasks)
+	var typeErrs []string
+	var validationErrs ValidationErrors
+	assign := func(name string, unmarshal func(any) error) error {
+		var task Task
+		err := unmarshal(&task)
+
+		var typeErr *yaml.TypeError
+		var verrs ValidationErrors
+		switch {
+		case errors.As(err, &typeErr):
+			// Type errors already include line numbers, and are collected so that
+			// every one can be reported together.
+			for _, msg := range typeErr.Errors {
+				typeErrs = append(typeErrs, prefixTypeError(msg, fmt.Sprintf("task %q: ", name)))
+			}
+		case errors.As(err, &verrs):
+			// Problems with the definition of each task are also reported together.
+			for _, err := range verrs {
+				validationErrs = append(validationErrs, taskErrors(name, err))
+			}
+		case err != nil:
+			return entryError(err, "task", "tasks", name)
+		}
+
+		tasks[name] = &task
+		return nil
+	}
+
+	if err := marshal.UnmarshalOrderedMap(unmarshal, assign); err != nil {
+		return err
+	}
+
+	if len(typeErrs) > 0 {
+		return &yaml.TypeError{Errors: typeErrs}
+	}
+
+	if len(validationErrs) > 0 {
+		return validationErrs
+	}
+
+	*t = tasks
+	return nil
+}
+
+// loadInclude replaces an included task with the definition in its file. A
+// relative path is resolved from dir, which should be the directory containing
+// the file that included it.
+func (t *Task) loadInclude(dir string, r includeReader) error {
+	return t.loadIncludeFrom(dir, nil, r)
+}
+
+// loadIncludeFrom loads an included task, where the stack is the chain of files
+// already being included, so that cycles can be detected.
+func (t *Task) loadIncludeFrom(dir string, stack []string, r includeReader) error {
+	if t.include == "" {
+		return nil
+	}
+
+	location, err := resolveInclude(dir, t.include)
+	if err != nil {
+		return fmt.Errorf("include %q: %w", t.include, err)
+	}
+
+	if i := slices.Index(stack, location); i >= 0 {
+		return newIncludeCycleError(slices.Concat(stack[i:], []string{location}), filepath.Base)
+	}
+
+	data, err := r.read(location, t.includeChecksum)
+	if err != nil {
+		return err
+	}
+
+	decoder := yaml.NewDecoder(bytes.NewReader(data))
+	decoder.SetStrict(true)
+
+	var included Task
+	if err := decoder.Decode(&included); err != nil {
+		var typeErr *yaml.TypeError
+		if errors.As(err, &typeErr) {
+			return typeErrors(t.include, typeErr)
+		}
+		return eachError(err, func(err error) error {
+			var verr *ValidationError
+			if errors.As(err, &verr) {
+				verr.Task = t.Name
+			}
+			if line, ok := errorLine(data, err); ok {
+				return fmt.Errorf("%s:%d: %w", t.include, line, err)
+			}
+			return fmt.Errorf("decoding included file %q: %w", t.include, err)
+		})
+	}
+
+	err = included.loadIncludeFrom(includeDir(location), append(slices.Clip(stack), location), r)
+	if err != nil {
+		return err
+	}
+
+	name, overrides := t.Name, t.overrides
+	*t = included
+	t.Name = name
+	overrides.apply(t)
+
+	return nil
+}
+
+// isValid checks whether a given task definition is valid. Every problem found
+// is returned in [ValidationErrors], each as a [ValidationError].
 func (t *Task) isValid() error {
+	var errs ValidationErrors
+	invalid := func(field string, err error) {
+		errs = append(errs, newValidationError(field, err))
+	}
+
+	if len(t.AppendRun) > 0 && t.Extends == "" {
+		invalid("append-run", errors.New("`append-run` can only be used with `extends`"))
+	}
+
+	if len(t.AppendRun) > 0 && len(t.RunList) > 0 {
+		invalid("append-run", errors.New("`run` and `append-run` cannot be used together"))
+	}
+
 	if len(t.Source) > 0 && len(t.Target) == 0 {
-		return errors.New("task source cannot be defined without target")
+		invalid("source", errors.New("task source cannot be defined without target"))
 	}
 
 	if len(t.Target) > 0 && len(t.Source) == 0 {
-		return errors.New("task target cannot be defined without source")
+		invalid("target", errors.New("task target cannot be defined without source"))
+	}
+
+	if err := t.Cache.validate(t); err != nil {
+		invalid("cache", err)
+	}
+
+	if err := validateInterpreter(t.Interpreter); err != nil {
+		invalid("interpreter", err)
+	}
+
+	usesOutcome := func(r *Run) bool { return r.When.usesOutcome() }
+	if slices.ContainsFunc(t.RunList, usesOutcome) {
+		invalid("run", errOutcomeOutsideFinally)
+	}
+	if slices.ContainsFunc(t.OnFailure, usesOutcome) {
+		invalid("on-failure", errOutcomeOutsideFinally)
+	}
+
+	results := t.newResults()
+	for _, r := range t.Finally {
+		if err := r.When.validateResults(results); err != nil {
+			invalid("finally", err)
+		}
+	}
+
+	errs = append(errs, t.validateRunNames()...)
+
+	for _, err := range t.validateOptions() {
+		invalid("options", err)
+	}
+
+	if len(errs) > 0 {
+		return errs
+	}
+
+	return nil
+}
+
+// errOutcomeOutsideFinally is returned when a when clause that checks how the
+// task went is used before the task has finished.
+var errOutcomeOutsideFinally = errors.New(
+	"when clauses `failed`, `succeeded`, and `result` can only be used in finally",
+)
+
+// validateRunNames returns a problem for each run item that has the same name
+// as an earlier one.
+func (t *Task) validateRunNames() []error {
+	var errs []error
+	names := make(map[string]struct{})
+	clauses := []string{"run", "on-failure", "finally"}
+	for i, items := range []marshal.Slice[*Run]{t.RunList, t.OnFailure, t.Finally} {
+		for _, r := range items {
+			if r.Name == "" {
+				continue
+			}
+			if _, ok := names[r.Name]; ok {
+				errs = append(errs, newValidationError(clauses[i], fmt.Errorf(
+					"run item %q must have a unique name within a task", r.Name,
+				)))
+			}
+			names[r.Name] = struct{}{}
+		}
 	}
 
+	return errs
+}
+
+// validateOptions returns the problems found with the options of the task.
+func (t *Task) validateOptions() []error {
+	var errs []error
 	for _, o := range t.Options {
-		for _, a := range t.Args {
-			if o.Name == a.Name {
-				return fmt.Errorf(
-					"argument and option %q must have unique names within a task", o.Name,
-				)
+		if _, ok := t.Args.Lookup(o.Name); ok {
+			errs = append(errs, fmt.Errorf(
+				"argument and option %q must have unique names within a task", o.Name,
+			))
+		}
+
+		for _, name := range o.ExclusiveWith {
+			if name == o.Name {
+				errs = append(errs, fmt.Errorf("option %q cannot be exclusive with itself", o.Name))
+				continue
+			}
+			if _, ok := t.Options.Lookup(name); !ok {
+				errs = append(errs, fmt.Errorf(
+					"option %q cannot be exclusive with %q, which is not an option of the task",
+					o.Name, name,
+				))
+			}
+		}
+	}
+
+	return errs
+}
+
+// ConflictingOptions returns the sorted names of the options of the task that
+// cannot be passed along with the named option.
+func (t *Task) ConflictingOptions(name string) []string {
+	var names []string
+	for _, o := range t.Options {
+		switch {
+		case o.Name == name:
+			names = append(names, o.ExclusiveWith...)
+		case slices.Contains(o.ExclusiveWith, name):
+			names = append(names, o.Name)
+		}
+	}
+
+	slices.Sort(names)
+	return slices.Compact(names)
+}
+
+// checkConflicts returns an error if options that cannot be used together are
+// passed together. Default values are not considered.
+func (t *Task) checkConflicts(passed map[string]string) error {
+	for _, o := range t.Options {
+		if _, ok := passed[o.Name]; !ok {
+			continue
+		}
+
+		flags := []string{"--" + o.Name}
+		for _, name := range t.ConflictingOptions(o.Name) {
+			if _, ok := passed[name]; ok {
+				flags = append(flags, "--"+name)
 			}
 		}
+
+		if len(flags) > 1 {
+			return fmt.Errorf("options cannot be used together: %s", strings.Join(flags, ", "))
+		}
 	}
 
 	return nil
 }
 
-// AllRunItems returns all run items referenced, including `run` and `finally`.
+// AllRunItems returns all run items referenced, including `run`, `on-failure`,
+// and `finally`.
 func (t *Task) AllRunItems() marshal.Slice[*Run] {
-	return append(t.RunList, t.Finally...)
+	return slices.Concat(t.RunList, t.OnFailure, t.Finally)
 }
 
 // Dependencies returns a list of options that are required explicitly.
@@ -130,7 +471,7 @@ func (t *Task) Dependencies() []string {
 		options = append(options, opt.Dependencies()...)
 	}
 	for _, run := range t.AllRunItems() {
-		options = append(options, run.When.Dependencies()...)
+		options = append(options, run.Dependencies()...)
 	}
 
 	return options
@@ -138,7 +479,24 @@ func (t *Task) Dependencies() []string {
 
 // Execute runs the Run scripts in the task.
 func (t *Task) Execute(ctx Context) (err error) {
-	ctx = ctx.WithTask(t)
+	if ctx.explicitEnv == nil {
+		ctx.explicitEnv = newExplicitEnv()
+	}
+	ctx = ctx.WithTask(t).withInterpreter(t.Interpreter)
+
+	if err := ctx.Selection.validate(t); err != nil {
+		return err
+	}
+
+	if ctx.Completed.has(t) {
+		skipTask(ctx, t.Name, len(ctx.taskStack)-1, "already run")
+		return nil
+	}
+	defer func() {
+		if err == nil {
+			ctx.Completed.add(t)
+		}
+	}()
 
 	cachePath, err := t.taskInputCachePath(ctx)
 	if err != nil {
@@ -150,19 +508,65 @@ func (t *Task) Execute(ctx Context) (err error) {
 		return fmt.Errorf("checking cache: %w", err)
 	}
 	if isUpToDate {
-		ctx.Logger.PrintTaskSkipped(t.Name, "all targets up to date")
+		skipTask(ctx, t.Name, len(ctx.taskStack)-1, "all targets up to date")
 		return nil
 	}
 
+	confirmed, err := t.Confirm.ask(ctx)
+	if err != nil {
+		return err
+	}
+	if !confirmed {
+		skipTask(ctx, t.Name, len(ctx.taskStack)-1, "not confirmed")
+		return ErrNotConfirmed
+	}
+
 	ctx.Logger.PrintTask(t.Name)
 
-	defer ctx.Logger.PrintTaskCompleted(t.Name)
+	if err := ctx.Hooks.before(ctx); err != nil {
+		return err
+	}
+	defer ctx.Hooks.after(ctx, &err)
+
+	start := time.Now()
+	defer func() {
+		elapsed := timeSince(start)
+		ctx.Logger.RecordTiming(ui.Timing{
+			Task:    t.Name,
+			Depth:   len(ctx.taskStack) - 1,
+			Err:     err,
+			Start:   start,
+			Elapsed: elapsed,
+		})
+		ctx.Logger.PrintTaskCompleted(t.Name, elapsed)
+	}()
+
+	if t.Output != nil {
+		f, err := t.Output.open(ctx)
+		if err != nil {
+			return err
+		}
+		defer f.Close() //nolint:errcheck
+		ctx = ctx.withOutput(f)
+	}
+
+	ctx.background = new(backgroundProcesses)
+	defer ctx.background.stopAll(ctx)
+	ctx.results = t.newResults()
 	defer t.runFinally(ctx, &err)
 
-	for _, r := range t.RunList {
-		if err := t.run(ctx, r, stateRunning); err != nil {
-			return err
+	partial, err := t.runList(ctx)
+	if err != nil {
+		// Declining a sub-task aborts the run rather than failing it.
+		if !errors.Is(err, ErrNotConfirmed) {
+			err = t.runOnFailure(ctx, err)
 		}
+		return err
+	}
+
+	// A partial run does not produce up-to-date targets.
+	if partial {
+		return nil
 	}
 
 	if err := t.cache(ctx, cachePath); err != nil {
@@ -172,6 +576,65 @@ func (t *Task) Execute(ctx Context) (err error) {
 	return nil
 }
 
+// runList runs each item in the run list that is selected. Partial is true
+// when any item was excluded by the selection.
+//
+// When keeping going, the remaining items run after one fails, and the first
+// error is returned once every item has run.
+func (t *Task) runList(ctx Context) (partial bool, err error) {
+	keepGoing := ctx.KeepGoing || t.KeepGoing
+
+	var failures []ui.Failure
+	for _, r := range t.RunList {
+		if reason, ok := ctx.Selection.excludes(r); ok {
+			r.printSkipped(ctx, reason)
+			ctx.setResult(r, resultSkipped)
+			partial = true
+			continue
+		}
+
+		err := t.run(ctx, r, stateRunning)
+		switch {
+		case err == nil:
+			continue
+		case !keepGoing, errors.Is(err, ErrInterrupted), errors.Is(err, ErrNotConfirmed):
+			return partial, err
+		}
+
+		ctx.Logger.Debug("Continuing after failed run item")
+		failures = append(failures, newFailure(err))
+	}
+
+	if len(failures) > 0 {
+		ctx.Logger.PrintFailures(failures)
+		return partial, failures[0].Err
+	}
+
+	return partial, nil
+}
+
+// runOnFailure runs the on-failure clause after the run list has failed. The
+// original error is always returned first by the task, followed by the error
+// that stopped the on-failure clause, if any.
+func (t *Task) runOnFailure(ctx Context, err error) error {
+	if len(t.OnFailure) == 0 {
+		return err
+	}
+
+	ctx.Logger.PrintTaskOnFailure(t.Name)
+
+	ctx.cleanup = true
+	ctx.env = append(slices.Clip(ctx.env), envVar{failureEnv, err.Error()})
+
+	for _, r := range t.OnFailure {
+		if rerr := t.run(ctx, r.withFailure(err), stateOnFailure); rerr != nil {
+			return errors.Join(err, fmt.Errorf("on-failure: %w", rerr))
+		}
+	}
+
+	return err
+}
+
 func (t *Task) runFinally(ctx Context, err *error) {
 	if len(t.Finally) == 0 {
 		return
@@ -179,8 +642,11 @@ func (t *Task) runFinally(ctx Context, err *error) {
 
 	ctx.Logger.PrintTaskFinally(t.Name)
 
+	ctx.taskErr = err
+	ctx.cleanup = true
+
 	for _, r := range t.Finally {
-		if rerr := t.run(ctx, r, stateFinally); rerr != nil {
+		if rerr := t.run(ctx, r.withResults(ctx.results), stateFinally); rerr != nil {
 			// Do not overwrite existing errors
 			if *err == nil {
 				*err = rerr
@@ -192,30 +658,99 @@ func (t *Task) runFinally(ctx Context, err *error) {
 
 // run executes a Run struct.
 func (t *Task) run(ctx Context, r *Run, s executionState) error {
+	if err := ctx.interrupted(); err != nil {
+		return err
+	}
+
 	if ok, err := r.shouldRun(ctx, t.Vars); !ok || err != nil {
+		if err == nil && s == stateRunning {
+			ctx.setResult(r, resultSkipped)
+		}
 		return err
 	}
 
+	if r.Name != "" {
+		ctx.step = r.Name
+		if !inQuietTask(ctx) {
+			ctx.Logger.PrintStep(r.Name, ctx.namespaces()...)
+		}
+		defer t.recordStep(ctx, time.Now())
+	}
+
 	runFuncs := []func() error{
 		func() error { return t.runCommands(ctx, r, s) },
 		func() error { return t.runSubTasks(ctx, r) },
 		func() error { return t.runEnvironment(ctx, r) },
+		func() error { return t.runWait(ctx, r, s) },
 	}
 
 	for _, f := range runFuncs {
 		if err := f(); err != nil {
+			if s == stateRunning {
+				ctx.setResult(r, resultFailed)
+			}
 			return err
 		}
 	}
 
+	if s == stateRunning {
+		ctx.setResult(r, resultSucceeded)
+	}
+
 	return nil
 }
 
-// shouldBeQuiet checks if the command or any of the tasks in the stack are quiet.
+// skipTask prints that a task was skipped and records it for the run summary.
+// The depth is the number of tasks it would have run within.
+func skipTask(ctx Context, name string, depth int, reason string) {
+	ctx.Logger.PrintTaskSkipped(name, reason)
+	recordSkip(ctx, name, depth, reason)
+}
+
+// recordSkip records that a task was skipped for the run summary.
+func recordSkip(ctx Context, name string, depth int, reason string) {
+	ctx.Logger.RecordSkip(ui.Skip{
+		Task:   name,
+		Reason: reason,
+		Depth:  depth,
+		Start:  time.Now(),
+	})
+}
+
+// recordStep records the time taken by a named run item.
+func (t *Task) recordStep(ctx Context, start time.Time) {
+	ctx.Logger.RecordTiming(ui.Timing{
+		Task:    t.Name,
+		Step:    ctx.step,
+		Depth:   len(ctx.taskStack),
+		Start:   start,
+		Elapsed: timeSince(start),
+	})
+}
+
+// newResults returns the results of the named items of the run list before any
+// of them have run.
+func (t *Task) newResults() map[string]string {
+	results := make(map[string]string)
+	for _, r := range t.RunList {
+		if r.Name != "" {
+			results[r.Name] = resultNotRun
+		}
+	}
+	return results
+}
+
+// shouldBeQuiet checks if the command is quiet. A command that does not set
+// quiet itself is quiet if any of the tasks in the stack are quiet.
 func shouldBeQuiet(cmd *Command, ctx Context) bool {
-	if cmd.Quiet {
-		return true
+	if cmd.Quiet != nil {
+		return *cmd.Quiet
 	}
+	return inQuietTask(ctx)
+}
+
+// inQuietTask checks if any of the tasks in the stack are quiet.
+func inQuietTask(ctx Context) bool {
 	for _, t := range ctx.taskStack {
 		if t.Quiet {
 			return true
@@ -224,27 +759,144 @@ func shouldBeQuiet(cmd *Command, ctx Context) bool {
 	return false
 }
 
+// shouldCapture checks if the command or any of the tasks in the stack only
+// print output on failure.
+func shouldCapture(cmd *Command, ctx Context) bool {
+	if cmd.Capture {
+		return true
+	}
+	for _, t := range ctx.taskStack {
+		if t.Capture {
+			return true
+		}
+	}
+	return false
+}
+
 func (t *Task) runCommands(ctx Context, r *Run, s executionState) error {
+	if len(r.Matrix) != 0 {
+		return t.runMatrix(ctx, r, s)
+	}
+
+	if r.Pipe {
+		return t.runPipeline(ctx, r, s)
+	}
+
+	var firstErr error
 	for _, command := range r.Command {
-		if !shouldBeQuiet(command, ctx) {
-			switch s {
-			case stateFinally:
-				ctx.Logger.PrintCommandWithParenthetical(command.Print, "finally", ctx.TaskNames()...)
-			default:
-				ctx.Logger.PrintCommand(command.Print, ctx.TaskNames()...)
-			}
+		err := t.runCommand(ctx, command, s)
+		if err == nil {
+			continue
 		}
 
-		if err := command.exec(ctx); err != nil {
+		if r.IgnoreErrors == ignoreErrorsNever {
+			return err
+		}
+
+		ctx.Logger.Debug("Continuing after failed command:", command.Print)
+		if firstErr == nil {
+			firstErr = err
+		}
+	}
+
+	return r.ignoredError(firstErr)
+}
+
+// runCommand runs a single command, or starts it if it runs in the background.
+func (t *Task) runCommand(ctx Context, command *Command, s executionState) error {
+	printCommand(ctx, command, s)
+
+	if command.Background {
+		if err := ctx.background.start(ctx, command); err != nil {
 			ctx.Logger.PrintCommandError(err)
 			return err
 		}
+		return nil
+	}
+
+	start := time.Now()
+	err := command.Retry.run(ctx, command.Print, func() error { return command.exec(ctx) })
+	quiet := shouldBeQuiet(command, ctx)
+	return t.finishCommand(ctx, command.Print, quiet, start, err)
+}
+
+// runPipeline runs the commands of a run item as a single pipeline, with the
+// output of each command passed as the input of the next.
+func (t *Task) runPipeline(ctx Context, r *Run, s executionState) error {
+	prints := make([]string, 0, len(r.Command))
+	quiet := true
+	for _, command := range r.Command {
+		printCommand(ctx, command, s)
+		prints = append(prints, command.Print)
+		quiet = quiet && shouldBeQuiet(command, ctx)
+	}
+
+	start := time.Now()
+	err := execPipeline(ctx, r.Command)
+	return r.ignoredError(t.finishCommand(ctx, strings.Join(prints, " | "), quiet, start, err))
+}
+
+// printCommand prints a command that is about to run unless it is quiet.
+func printCommand(ctx Context, command *Command, s executionState) {
+	if shouldBeQuiet(command, ctx) {
+		return
+	}
+
+	printStep(ctx, command.Print, s)
+}
+
+// printStep prints a step of a run item that is about to run.
+func printStep(ctx Context, step string, s executionState) {
+	switch s {
+	case stateOnFailure:
+		ctx.Logger.PrintCommandWithParenthetical(step, "on-failure", ctx.namespaces()...)
+	case stateFinally:
+		ctx.Logger.PrintCommandWithParenthetical(step, "finally", ctx.namespaces()...)
+	default:
+		ctx.Logger.PrintCommand(step, ctx.namespaces()...)
+	}
+}
+
+// finishCommand records the time taken by a command that has finished running
+// and prints the result, returning the command's error.
+func (t *Task) finishCommand(
+	ctx Context,
+	command string,
+	quiet bool,
+	start time.Time,
+	err error,
+) error {
+	depth := len(ctx.taskStack)
+	if ctx.step != "" {
+		depth++
+	}
+
+	elapsed := timeSince(start)
+	ctx.Logger.RecordTiming(ui.Timing{
+		Task:    t.Name,
+		Step:    ctx.step,
+		Command: command,
+		Depth:   depth,
+		Err:     err,
+		Start:   start,
+		Elapsed: elapsed,
+	})
+	if err != nil {
+		ctx.Logger.PrintCommandFailed(command, err, elapsed)
+		return &commandError{command: command, err: err}
+	}
+
+	if !quiet {
+		ctx.Logger.PrintCommandCompleted(command, elapsed)
 	}
 
 	return nil
 }
 
 func (t *Task) runSubTasks(ctx Context, r *Run) error {
+	ctx.Selection = Selection{}
+	ctx.step = ""
+	ctx.taskErr = nil
 	for i := range r.Tasks {
 		if err := r.Tasks[i].Execute(ctx); err != nil {
 			return err
@@ -254,9 +906,27 @@ func (t *Task) runSubTasks(ctx Context, r *Run) error {
 	return nil
 }
 
+func (t *Task) runWait(ctx Context, r *Run, s executionState) error {
+	if r.Wait == nil {
+		return nil
+	}
+
+	step := r.Wait.String()
+	quiet := inQuietTask(ctx)
+	if !quiet {
+		printStep(ctx, step, s)
+	}
+
+	start := time.Now()
+	err := r.Wait.run(ctx)
+	return t.finishCommand(ctx, step, quiet, start, err)
+}
+
 func (t *Task) runEnvironment(ctx Context, r *Run) error {
 	ctx.Logger.PrintEnvironment(r.SetEnvironment)
 	for key, value := range r.SetEnvironment {
+		ctx.explicitEnv.add(key)
+
 		if value == nil {
 			if err := os.Unsetenv(key); err != nil {
 				return err
@@ -2,6 +2,7 @@ package runner
 
 import (
 	"bytes"
+	"encoding/json"
 	"errors"
 	"fmt"
 	"io"
@@ -10,6 +11,7 @@ import (
 	"runtime"
 	"strings"
 	"testing"
+	"time"
 
 	"github.com/rliebz/ghost"
 	"github.com/rliebz/ghost/be"
@@ -30,6 +32,9 @@ func TestTask_UnmarshalYAML(t *testing.T) {
 		return filepath.Join(wd, "testdata", filename)
 	}
 
+	overriddenUsage := "Overridden"
+	overriddenPrivate := true
+
 	tests := []struct {
 		name    string
 		input   string
@@ -72,28 +77,26 @@ args: { three: {}, four: {} }
 		{
 			name:  "include",
 			input: fmt.Sprintf(`{include: %q}`, testdata("included.yml")),
+			want:  Task{include: testdata("included.yml")},
+		},
+		{
+			name: "include-overrides",
+			input: fmt.Sprintf(
+				`{include: %q, usage: "Overridden", private: true}`,
+				testdata("included.yml"),
+			),
 			want: Task{
-				Usage: "A valid example of an included task",
-				RunList: marshal.Slice[*Run]{{Command: marshal.Slice[*Command]{{
-					Exec:  `echo "We're in!"`,
-					Print: `echo "We're in!"`,
-				}}}},
+				include: testdata("included.yml"),
+				overrides: includeOverrides{
+					Usage:   &overriddenUsage,
+					Private: &overriddenPrivate,
+				},
 			},
 		},
 		{
 			name:    "include-extra",
-			input:   fmt.Sprintf(`{include: %q, usage: "This is incorrect"}`, testdata("included.yml")),
-			wantErr: `tasks using "include" may not specify other fields`,
-		},
-		{
-			name:    "include invalid",
-			input:   fmt.Sprintf(`{include: %q}`, testdata("included-invalid.yml")),
-			wantErr: "decoding included file",
-		},
-		{
-			name:    "include missing",
-			input:   fmt.Sprintf(`{include: %q}`, testdata("not-a-real-file.yml")),
-			wantErr: "opening included file",
+			input:   fmt.Sprintf(`{include: %q, run: "echo incorrect"}`, testdata("included.yml")),
+			wantErr: `tasks using "include" may only also specify usage, description, private, or quiet`,
 		},
 		{
 			name:    "invalid",
@@ -108,6 +111,82 @@ args: { foo: {} }
 `,
 			wantErr: `argument and option "foo" must have unique names within a task`,
 		},
+		{
+			name: "run items share name",
+			input: `
+run:
+  - { name: foo, command: echo one }
+  - { name: foo, command: echo two }
+`,
+			wantErr: `run item "foo" must have a unique name within a task`,
+		},
+		{
+			name: "outcome outside finally",
+			input: `
+run:
+  - { when: { failed: true }, command: echo one }
+`,
+			wantErr: "when clauses `failed`, `succeeded`, and `result` can only be used in finally",
+		},
+		{
+			name: "outcome in on-failure",
+			input: `
+run: exit 1
+on-failure:
+  - { when: { succeeded: false }, command: echo one }
+`,
+			wantErr: "when clauses `failed`, `succeeded`, and `result` can only be used in finally",
+		},
+		{
+			name: "option exclusive with itself",
+			input: `
+options: { fast: { exclusive-with: fast } }
+`,
+			wantErr: `option "fast" cannot be exclusive with itself`,
+		},
+		{
+			name: "option exclusive with undefined option",
+			input: `
+options: { fast: { exclusive-with: slow } }
+`,
+			wantErr: `option "fast" cannot be exclusive with "slow", which is not an option of the task`,
+		},
+		{
+			name: "nested outcome outside finally",
+			input: `
+run:
+  - { when: { any-of: [{ not: { failed: true } }] }, command: echo one }
+`,
+			wantErr: "when clauses `failed`, `succeeded`, and `result` can only be used in finally",
+		},
+		{
+			name: "result outside finally",
+			input: `
+run:
+  - { name: build, command: echo one }
+  - { when: { result: { build: failed } }, command: echo two }
+`,
+			wantErr: "when clauses `failed`, `succeeded`, and `result` can only be used in finally",
+		},
+		{
+			name: "result of undefined run item",
+			input: `
+run: { name: build, command: echo one }
+finally:
+  - { when: { not: { result: { test: failed } } }, command: echo two }
+`,
+			wantErr: "when clause `result` refers to \"test\", which is not a named run item",
+		},
+		{
+			name: "result with unknown value",
+			input: `
+run: { name: build, command: echo one }
+finally:
+  - { when: { result: { build: passed } }, command: echo two }
+`,
+			wantErr: "when clause `result` for \"build\" must be one of " +
+				`succeeded, failed, skipped, not-run, got "passed"`,
+		},
 	}
 
 	for _, tt := range tests {
@@ -127,6 +206,25 @@ args: { foo: {} }
 	}
 }
 
+func TestTask_ConflictingOptions(t *testing.T) {
+	g := ghost.New(t)
+
+	var task Task
+	err := yaml.UnmarshalStrict([]byte(`
+options:
+  canary: {}
+  full: {exclusive-with: [canary, partial]}
+  partial: {exclusive-with: canary}
+  region: {}
+`), &task)
+	g.NoError(err)
+
+	g.Should(be.DeepEqual(task.ConflictingOptions("canary"), []string{"full", "partial"}))
+	g.Should(be.DeepEqual(task.ConflictingOptions("full"), []string{"canary", "partial"}))
+	g.Should(be.DeepEqual(task.ConflictingOptions("partial"), []string{"canary", "full"}))
+	g.Should(be.SliceLen(task.ConflictingOptions("region"), 0))
+}
+
 func TestTask_Execute_errors_returned(t *testing.T) {
 	tests := []struct {
 		name    string
@@ -170,162 +268,709 @@ func TestTask_Execute_errors_returned(t *testing.T) {
 	}
 }
 
-func TestTask_Execute_cache(t *testing.T) {
+func TestTask_Execute_selection(t *testing.T) {
 	tests := []struct {
-		name          string
-		skipWindows   bool
-		source        marshal.Slice[string]
-		target        marshal.Slice[string]
-		mutate        func(t *testing.T)
-		wantRunCount  int
-		wantFirstErr  string
-		wantSecondErr string
+		name      string
+		selection Selection
+		wantErr   string
 	}{
 		{
-			name: "one source one target",
-			source: marshal.Slice[string]{
-				"a1.txt",
-			},
-			target: marshal.Slice[string]{
-				"b1.txt",
-			},
-			wantRunCount: 1,
+			name:    "no selection",
+			wantErr: "exit status 1",
 		},
 		{
-			name: "multi source multi target",
-			source: marshal.Slice[string]{
-				"a1.txt",
-				"b1.txt",
-			},
-			target: marshal.Slice[string]{
-				"c2.txt",
-				"d2.txt",
-			},
-			wantRunCount: 1,
+			name:      "only",
+			selection: Selection{Only: []string{"pass"}},
 		},
 		{
-			name: "removed source",
-			source: marshal.Slice[string]{
-				"a1.txt",
-				"b1.txt",
-			},
-			target: marshal.Slice[string]{
-				"c2.txt",
-				"d2.txt",
-			},
-			mutate: func(t *testing.T) {
-				g := ghost.New(t)
-				err := os.Remove("a1.txt")
-				g.NoError(err)
-			},
-			wantSecondErr: "no source files found matching pattern: a1.txt",
+			name:      "only failure",
+			selection: Selection{Only: []string{"fail"}},
+			wantErr:   "exit status 1",
 		},
 		{
-			name: "removed target",
-			source: marshal.Slice[string]{
-				"a1.txt",
-				"b1.txt",
-			},
-			target: marshal.Slice[string]{
-				"c2.txt",
-				"d2.txt",
-			},
-			mutate: func(t *testing.T) {
-				g := ghost.New(t)
-				err := os.Remove("c2.txt")
-				g.NoError(err)
-			},
-			wantRunCount: 2,
+			name:      "skip runs unnamed",
+			selection: Selection{Skip: []string{"fail"}},
+			wantErr:   "exit status 2",
 		},
 		{
-			name: "modified source",
-			source: marshal.Slice[string]{
-				"a1.txt",
-				"b1.txt",
-			},
-			target: marshal.Slice[string]{
-				"c2.txt",
-				"d2.txt",
-			},
-			mutate: func(t *testing.T) {
-				g := ghost.New(t)
-				err := os.WriteFile("a1.txt", []byte("different data"), 0o600)
-				g.NoError(err)
-			},
-			wantRunCount: 2,
+			name:      "only excludes unnamed",
+			selection: Selection{Only: []string{"pass"}, Skip: []string{"fail"}},
 		},
 		{
-			name: "modified target",
-			source: marshal.Slice[string]{
-				"a1.txt",
-				"b1.txt",
-			},
-			target: marshal.Slice[string]{
-				"c2.txt",
-				"d2.txt",
-			},
-			mutate: func(t *testing.T) {
-				g := ghost.New(t)
-				err := os.WriteFile("c2.txt", []byte("different data"), 0o600)
-				g.NoError(err)
-			},
-			wantRunCount: 2,
+			name:      "unknown name",
+			selection: Selection{Skip: []string{"fake"}},
+			wantErr:   `task "foo" has no run item named "fake" (one of: pass, fail)`,
 		},
 		{
-			name: "glob no change",
-			source: marshal.Slice[string]{
-				"*1.txt",
-			},
-			target: marshal.Slice[string]{
-				"*2.txt",
-			},
-			wantRunCount: 1,
+			name:      "sub-task name",
+			selection: Selection{Only: []string{"bar:pass"}},
+			wantErr: `task "foo" has no run item named "bar:pass" (one of: pass, fail); ` +
+				"run items of sub-tasks cannot be selected",
 		},
-		{
-			name: "glob modified source",
-			source: marshal.Slice[string]{
-				"*1.txt",
-			},
-			target: marshal.Slice[string]{
-				"*2.txt",
-			},
-			mutate: func(t *testing.T) {
-				g := ghost.New(t)
-				err := os.WriteFile("a1.txt", []byte("different data"), 0o600)
-				g.NoError(err)
-			},
-			wantRunCount: 2,
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			g := ghost.New(t)
+
+			task := Task{
+				Name: "foo",
+				RunList: marshal.Slice[*Run]{
+					{Name: "pass", Command: marshal.Slice[*Command]{{Exec: "exit 0"}}},
+					{Name: "fail", Command: marshal.Slice[*Command]{{Exec: "exit 1"}}},
+					{Command: marshal.Slice[*Command]{{Exec: "exit 2"}}},
+				},
+			}
+
+			err := task.Execute(Context{Logger: ui.Noop(), Selection: tt.selection})
+			if tt.wantErr != "" {
+				g.Should(be.ErrorEqual(err, tt.wantErr))
+				return
+			}
+			g.NoError(err)
+		})
+	}
+}
+
+func TestTask_Execute_selection_finally(t *testing.T) {
+	g := ghost.New(t)
+
+	task := Task{
+		RunList: marshal.Slice[*Run]{
+			{Name: "skipped", Command: marshal.Slice[*Command]{{Exec: "exit 0"}}},
 		},
-		{
-			name: "glob modified target",
-			source: marshal.Slice[string]{
-				"*1.txt",
-			},
-			target: marshal.Slice[string]{
-				"*2.txt",
-			},
-			mutate: func(t *testing.T) {
-				g := ghost.New(t)
-				err := os.WriteFile("c2.txt", []byte("different data"), 0o600)
-				g.NoError(err)
-			},
-			wantRunCount: 2,
+		Finally: marshal.Slice[*Run]{
+			{Command: marshal.Slice[*Command]{{Exec: "exit 1"}}},
 		},
+	}
+
+	err := task.Execute(Context{
+		Logger:    ui.Noop(),
+		Selection: Selection{Skip: []string{"skipped"}},
+	})
+	g.Should(be.ErrorEqual(err, "exit status 1"))
+}
+
+func TestTask_Execute_steps(t *testing.T) {
+	g := ghost.New(t)
+
+	var runList marshal.Slice[*Run]
+	err := yaml.UnmarshalStrict([]byte(`
+- { name: build, command: echo built }
+- { name: deploy, when: { os: fake }, command: echo deployed }
+- exit 0
+`), &runList)
+	g.NoError(err)
+
+	stdout := new(bytes.Buffer)
+	stderr := new(bytes.Buffer)
+	logger := ui.New(ui.Config{Stdout: stdout, Stderr: stderr})
+
+	task := Task{Name: "foo", RunList: runList}
+	err = task.Execute(Context{Logger: logger})
+	g.NoError(err)
+
+	g.Should(be.Equal(stdout.String(), "built\n"))
+	g.Should(be.Equal(stderr.String(), `foo > build
+foo $ echo built
+foo > skipped: deploy
+ => current OS (`+runtime.GOOS+`) not listed in [fake]
+foo $ exit 0
+`))
+
+	var steps []string
+	for _, timing := range logger.Timings() {
+		if timing.Step != "" {
+			steps = append(steps, timing.Step+": "+timing.Command)
+		}
+	}
+	g.Should(be.DeepEqual(steps, []string{"build: ", "build: echo built"}))
+}
+
+func TestTask_Execute_steps_skipped_verbose(t *testing.T) {
+	g := ghost.New(t)
+
+	var runList marshal.Slice[*Run]
+	err := yaml.UnmarshalStrict([]byte(`
+- { name: deploy, when: { os: fake }, command: [echo one, echo two] }
+- { when: { os: fake }, command: echo three }
+`), &runList)
+	g.NoError(err)
+
+	stderr := new(bytes.Buffer)
+	logger := ui.New(ui.Config{
+		Stdout:    io.Discard,
+		Stderr:    stderr,
+		Verbosity: ui.LevelVerbose,
+	})
+
+	task := Task{Name: "foo", RunList: runList}
+	err = task.Execute(Context{Logger: logger})
+	g.NoError(err)
+
+	// Each skipped item is logged once, before the task completes.
+	got, _, _ := strings.Cut(stderr.String(), "Task Completed")
+	reason := " => current OS (" + runtime.GOOS + ") not listed in [fake]\n"
+	g.Should(be.Equal(got, "Task Started: foo\n"+
+		"foo > skipped: deploy\n"+reason+
+		"Skipping Command: echo three\n"+reason,
+	))
+}
+
+func TestTask_Execute_keep_going(t *testing.T) {
+	tests := []struct {
+		name      string
+		keepGoing bool
+		ctx       Context
+	}{
+		{name: "flag", ctx: Context{KeepGoing: true}},
+		{name: "task", keepGoing: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			g := ghost.New(t)
+
+			var task Task
+			err := yaml.UnmarshalStrict([]byte(`
+run:
+  - exit 1
+  - echo two
+  - { when: { os: fake }, command: echo skipped }
+  - exit 3
+finally: echo finally
+`), &task)
+			g.NoError(err)
+			task.Name = "foo"
+			task.KeepGoing = tt.keepGoing
+
+			stdout := new(bytes.Buffer)
+			stderr := new(bytes.Buffer)
+			ctx := tt.ctx
+			ctx.Logger = ui.New(ui.Config{Stdout: stdout, Stderr: stderr})
+
+			err = task.Execute(ctx)
+			g.Should(be.ErrorEqual(err, "exit status 1"))
+
+			g.Should(be.Equal(stdout.String(), "two\nfinally\n"))
+			g.Should(be.Equal(stderr.String(), `foo $ exit 1
+exit status 1
+foo $ echo two
+foo $ exit 3
+exit status 3
+Failures
+ => $ exit 1 (exit status 1)
+ => $ exit 3 (exit status 3)
+foo (finally) $ echo finally
+`))
+		})
+	}
+}
+
+func TestTask_Execute_quiet(t *testing.T) {
+	tests := []struct {
+		name       string
+		taskQuiet  bool
+		quiet      *bool
+		wantStderr string
+	}{
+		{name: "loud", wantStderr: "foo $ echo hello\n"},
+		{name: "quiet task", taskQuiet: true},
+		{name: "quiet command", quiet: ptr(true)},
 		{
-			name: "glob new source",
-			source: marshal.Slice[string]{
-				"*1.txt",
-			},
-			target: marshal.Slice[string]{
-				"*2.txt",
-			},
-			mutate: func(t *testing.T) {
-				g := ghost.New(t)
+			name:       "loud command in quiet task",
+			taskQuiet:  true,
+			quiet:      ptr(false),
+			wantStderr: "foo $ echo hello\n",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			g := ghost.New(t)
+
+			stdout := new(bytes.Buffer)
+			stderr := new(bytes.Buffer)
+			logger := ui.New(ui.Config{Stdout: stdout, Stderr: stderr})
+
+			task := Task{
+				Name:  "foo",
+				Quiet: tt.taskQuiet,
+				RunList: marshal.Slice[*Run]{{Command: marshal.Slice[*Command]{{
+					Exec: "echo hello", Print: "echo hello", Quiet: tt.quiet,
+				}}}},
+			}
+
+			err := task.Execute(Context{Logger: logger})
+			g.NoError(err)
+
+			g.Should(be.Equal(stdout.String(), "hello\n"))
+			g.Should(be.Equal(stderr.String(), tt.wantStderr))
+		})
+	}
+}
+
+func TestTask_Execute_completed(t *testing.T) {
+	g := ghost.New(t)
+
+	out := filepath.Join(t.TempDir(), "out.txt")
+	newTask := func(value string) *Task {
+		return &Task{
+			Name: "foo",
+			Vars: map[string]string{"value": value},
+			RunList: marshal.Slice[*Run]{
+				{Command: marshal.Slice[*Command]{{Exec: "echo " + value + " >> " + out}}},
+			},
+		}
+	}
+
+	ctx := Context{Logger: ui.Noop(), Completed: NewTaskRecord()}
+	for _, value := range []string{"a", "a", "b", "a"} {
+		g.NoError(ExecuteTask(ctx, &Config{}, newTask(value)))
+	}
+
+	got, err := os.ReadFile(out)
+	g.NoError(err)
+	g.Should(be.Equal(string(got), "a\nb\n"))
+}
+
+func TestTask_Execute_completed_sub_tasks(t *testing.T) {
+	g := ghost.New(t)
+
+	out := filepath.Join(t.TempDir(), "out.txt")
+	echo := func(text string) marshal.Slice[*Command] {
+		return marshal.Slice[*Command]{{Exec: "echo " + text + " >> " + out}}
+	}
+	clean := Task{Name: "clean", RunList: marshal.Slice[*Run]{{Command: echo("clean")}}}
+	build := &Task{
+		Name: "build",
+		RunList: marshal.Slice[*Run]{
+			{Tasks: []Task{clean}},
+			{Command: echo("build")},
+			{Tasks: []Task{clean}},
+		},
+	}
+
+	// A sub-task run twice by one task runs both times, but is skipped by a
+	// later task named on the command line.
+	ctx := Context{Logger: ui.Noop(), Completed: NewTaskRecord()}
+	g.NoError(ExecuteTask(ctx, &Config{}, build))
+	g.NoError(ExecuteTask(ctx, &Config{}, &clean))
+
+	got, err := os.ReadFile(out)
+	g.NoError(err)
+	g.Should(be.Equal(string(got), "clean\nbuild\nclean\n"))
+}
+
+func TestTask_Execute_completed_failure(t *testing.T) {
+	g := ghost.New(t)
+
+	task := Task{
+		Name: "foo",
+		RunList: marshal.Slice[*Run]{
+			{Command: marshal.Slice[*Command]{{Exec: "exit 1"}}},
+		},
+	}
+
+	ctx := Context{Logger: ui.Noop(), Completed: NewTaskRecord()}
+	g.Should(be.ErrorEqual(task.Execute(ctx), "exit status 1"))
+	g.Should(be.ErrorEqual(task.Execute(ctx), "exit status 1"))
+}
+
+func TestTask_Execute_timings(t *testing.T) {
+	g := ghost.New(t)
+
+	sub := Task{
+		Name: "sub",
+		RunList: marshal.Slice[*Run]{
+			{Command: marshal.Slice[*Command]{{Exec: "exit 0", Print: "sub command"}}},
+		},
+	}
+	task := Task{
+		Name: "parent",
+		RunList: marshal.Slice[*Run]{
+			{Command: marshal.Slice[*Command]{{Exec: "exit 0", Print: "parent command"}}},
+			{Tasks: []Task{sub}},
+		},
+	}
+
+	logger := ui.Noop()
+	err := task.Execute(Context{Logger: logger})
+	g.NoError(err)
+
+	type timing struct {
+		Task    string
+		Command string
+		Depth   int
+	}
+
+	var got []timing
+	for _, tt := range logger.Timings() {
+		got = append(got, timing{Task: tt.Task, Command: tt.Command, Depth: tt.Depth})
+	}
+
+	g.Should(be.DeepEqual(got, []timing{
+		{Task: "parent"},
+		{Task: "parent", Command: "parent command", Depth: 1},
+		{Task: "sub", Depth: 1},
+		{Task: "sub", Command: "sub command", Depth: 2},
+	}))
+}
+
+func TestTask_Execute_json(t *testing.T) {
+	g := ghost.New(t)
+
+	stdout := new(bytes.Buffer)
+	logger := ui.New(ui.Config{Stdout: stdout, Format: ui.FormatJSON})
+
+	task := Task{
+		Name: "foo",
+		RunList: marshal.Slice[*Run]{
+			{Command: marshal.Slice[*Command]{
+				{Exec: "echo hello"},
+				{Exec: "echo oops >&2"},
+			}},
+		},
+	}
+
+	err := task.Execute(Context{Logger: logger})
+	g.NoError(err)
+
+	type event struct {
+		Event  string `json:"event"`
+		Stream string `json:"stream"`
+		Line   string `json:"line"`
+	}
+
+	var got []event
+	decoder := json.NewDecoder(stdout)
+	for decoder.More() {
+		var e event
+		g.NoError(decoder.Decode(&e))
+		got = append(got, e)
+	}
+
+	g.Should(be.DeepEqual(got, []event{
+		{Event: "task_started"},
+		{Event: "command_started"},
+		{Event: "command_output", Stream: "stdout", Line: "hello"},
+		{Event: "command_finished"},
+		{Event: "command_started"},
+		{Event: "command_output", Stream: "stderr", Line: "oops"},
+		{Event: "command_finished"},
+		{Event: "task_completed"},
+	}))
+}
+
+func TestTask_Execute_pipe(t *testing.T) {
+	tests := []struct {
+		name       string
+		commands   []string
+		wantStdout string
+		wantErr    string
+	}{
+		{
+			name:       "output passed to the next command",
+			commands:   []string{`printf 'b\na\n'`, "sort", "tr a-z A-Z"},
+			wantStdout: "A\nB\n",
+		},
+		{
+			name:     "failure in the middle",
+			commands: []string{"echo hello", "exit 3", "cat"},
+			wantErr:  "exit status 3",
+		},
+		{
+			name:       "failure at the end",
+			commands:   []string{"echo hello", "cat; exit 4"},
+			wantStdout: "hello\n",
+			wantErr:    "exit status 4",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			g := ghost.New(t)
+
+			commands := make(marshal.Slice[*Command], 0, len(tt.commands))
+			for _, command := range tt.commands {
+				commands = append(commands, &Command{Exec: command, Print: command})
+			}
+
+			stdout := new(bytes.Buffer)
+			logger := ui.New(ui.Config{Stdout: stdout, Stderr: io.Discard})
+
+			task := Task{
+				Name:    "foo",
+				RunList: marshal.Slice[*Run]{{Command: commands, Pipe: true}},
+			}
+
+			err := task.Execute(Context{Logger: logger})
+			if tt.wantErr != "" {
+				g.Should(be.ErrorEqual(err, tt.wantErr))
+			} else {
+				g.NoError(err)
+			}
+
+			g.Should(be.Equal(stdout.String(), tt.wantStdout))
+
+			timings := logger.Timings()
+			g.Must(be.SliceLen(timings, 2))
+			g.Should(be.Equal(timings[1].Command, strings.Join(tt.commands, " | ")))
+		})
+	}
+}
+
+func TestTask_Execute_capture(t *testing.T) {
+	tests := []struct {
+		name       string
+		task       Task
+		wantStdout string
+		wantStderr string
+		wantErr    string
+	}{
+		{
+			name: "command success",
+			task: Task{RunList: marshal.Slice[*Run]{{Command: marshal.Slice[*Command]{{
+				Exec: "echo out; echo err >&2", Print: "cmd", Capture: true,
+			}}}}},
+			wantStderr: "foo $ cmd\n",
+		},
+		{
+			name: "command failure",
+			task: Task{RunList: marshal.Slice[*Run]{{Command: marshal.Slice[*Command]{{
+				Exec: "echo out; echo err >&2; exit 1", Print: "cmd", Capture: true,
+			}}}}},
+			wantStderr: "foo $ cmd\nout\nerr\nexit status 1\n",
+			wantErr:    "exit status 1",
+		},
+		{
+			name: "task success",
+			task: Task{Capture: true, RunList: marshal.Slice[*Run]{{Command: marshal.Slice[*Command]{{
+				Exec: "echo out", Print: "cmd",
+			}}}}},
+			wantStderr: "foo $ cmd\n",
+		},
+		{
+			name: "not captured",
+			task: Task{RunList: marshal.Slice[*Run]{{Command: marshal.Slice[*Command]{{
+				Exec: "echo out", Print: "cmd",
+			}}}}},
+			wantStdout: "out\n",
+			wantStderr: "foo $ cmd\n",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			g := ghost.New(t)
+
+			stdout := new(bytes.Buffer)
+			stderr := new(bytes.Buffer)
+			logger := ui.New(ui.Config{Stdout: stdout, Stderr: stderr})
+
+			tt.task.Name = "foo"
+			err := tt.task.Execute(Context{Logger: logger})
+			if tt.wantErr != "" {
+				g.Should(be.ErrorEqual(err, tt.wantErr))
+			} else {
+				g.NoError(err)
+			}
+
+			g.Should(be.Equal(stdout.String(), tt.wantStdout))
+			g.Should(be.Equal(stderr.String(), tt.wantStderr))
+		})
+	}
+}
+
+func TestTask_Execute_cache(t *testing.T) {
+	tests := []struct {
+		name          string
+		skipWindows   bool
+		source        marshal.Slice[string]
+		target        marshal.Slice[string]
+		mutate        func(t *testing.T)
+		wantRunCount  int
+		wantFirstErr  string
+		wantSecondErr string
+	}{
+		{
+			name: "one source one target",
+			source: marshal.Slice[string]{
+				"a1.txt",
+			},
+			target: marshal.Slice[string]{
+				"b1.txt",
+			},
+			wantRunCount: 1,
+		},
+		{
+			name: "multi source multi target",
+			source: marshal.Slice[string]{
+				"a1.txt",
+				"b1.txt",
+			},
+			target: marshal.Slice[string]{
+				"c2.txt",
+				"d2.txt",
+			},
+			wantRunCount: 1,
+		},
+		{
+			name: "removed source",
+			source: marshal.Slice[string]{
+				"a1.txt",
+				"b1.txt",
+			},
+			target: marshal.Slice[string]{
+				"c2.txt",
+				"d2.txt",
+			},
+			mutate: func(t *testing.T) {
+				g := ghost.New(t)
+				err := os.Remove("a1.txt")
+				g.NoError(err)
+			},
+			wantSecondErr: "no source files found matching pattern: a1.txt",
+		},
+		{
+			name: "removed target",
+			source: marshal.Slice[string]{
+				"a1.txt",
+				"b1.txt",
+			},
+			target: marshal.Slice[string]{
+				"c2.txt",
+				"d2.txt",
+			},
+			mutate: func(t *testing.T) {
+				g := ghost.New(t)
+				err := os.Remove("c2.txt")
+				g.NoError(err)
+			},
+			wantRunCount: 2,
+		},
+		{
+			name: "modified source",
+			source: marshal.Slice[string]{
+				"a1.txt",
+				"b1.txt",
+			},
+			target: marshal.Slice[string]{
+				"c2.txt",
+				"d2.txt",
+			},
+			mutate: func(t *testing.T) {
+				g := ghost.New(t)
+				err := os.WriteFile("a1.txt", []byte("different data"), 0o600)
+				g.NoError(err)
+			},
+			wantRunCount: 2,
+		},
+		{
+			name: "modified target",
+			source: marshal.Slice[string]{
+				"a1.txt",
+				"b1.txt",
+			},
+			target: marshal.Slice[string]{
+				"c2.txt",
+				"d2.txt",
+			},
+			mutate: func(t *testing.T) {
+				g := ghost.New(t)
+				err := os.WriteFile("c2.txt", []byte("different data"), 0o600)
+				g.NoError(err)
+			},
+			wantRunCount: 2,
+		},
+		{
+			name: "glob no change",
+			source: marshal.Slice[string]{
+				"*1.txt",
+			},
+			target: marshal.Slice[string]{
+				"*2.txt",
+			},
+			wantRunCount: 1,
+		},
+		{
+			name: "glob modified source",
+			source: marshal.Slice[string]{
+				"*1.txt",
+			},
+			target: marshal.Slice[string]{
+				"*2.txt",
+			},
+			mutate: func(t *testing.T) {
+				g := ghost.New(t)
+				err := os.WriteFile("a1.txt", []byte("different data"), 0o600)
+				g.NoError(err)
+			},
+			wantRunCount: 2,
+		},
+		{
+			name: "glob modified target",
+			source: marshal.Slice[string]{
+				"*1.txt",
+			},
+			target: marshal.Slice[string]{
+				"*2.txt",
+			},
+			mutate: func(t *testing.T) {
+				g := ghost.New(t)
+				err := os.WriteFile("c2.txt", []byte("different data"), 0o600)
+				g.NoError(err)
+			},
+			wantRunCount: 2,
+		},
+		{
+			name: "glob new source",
+			source: marshal.Slice[string]{
+				"*1.txt",
+			},
+			target: marshal.Slice[string]{
+				"*2.txt",
+			},
+			mutate: func(t *testing.T) {
+				g := ghost.New(t)
 				err := os.WriteFile("x1.txt", []byte("different data"), 0o600)
 				g.NoError(err)
 			},
 			wantRunCount: 2,
 		},
+		{
+			name: "excluded modified source",
+			source: marshal.Slice[string]{
+				"*1.txt",
+				"!a1.txt",
+			},
+			target: marshal.Slice[string]{
+				"*2.txt",
+			},
+			mutate: func(t *testing.T) {
+				g := ghost.New(t)
+				err := os.WriteFile("a1.txt", []byte("different data"), 0o600)
+				g.NoError(err)
+			},
+			wantRunCount: 1,
+		},
+		{
+			name: "reincluded modified source",
+			source: marshal.Slice[string]{
+				"*1.txt",
+				"!*.txt",
+				"a1.txt",
+			},
+			target: marshal.Slice[string]{
+				"*2.txt",
+			},
+			mutate: func(t *testing.T) {
+				g := ghost.New(t)
+				err := os.WriteFile("a1.txt", []byte("different data"), 0o600)
+				g.NoError(err)
+			},
+			wantRunCount: 2,
+		},
 		{
 			name: "glob new target",
 			source: marshal.Slice[string]{
@@ -576,103 +1221,366 @@ func TestTask_Execute_cache(t *testing.T) {
 			},
 		}
 
-		cachePath, err := task.taskInputCachePath(ctx)
-		g.NoError(err)
+		cachePath, err := task.taskInputCachePath(ctx)
+		g.NoError(err)
+
+		err = os.MkdirAll(filepath.Dir(cachePath), 0o700)
+		g.NoError(err)
+
+		err = os.WriteFile(cachePath, []byte("data readonly"), 0o400)
+		g.NoError(err)
+
+		permissionDenied := "permission denied"
+		if runtime.GOOS == "windows" {
+			permissionDenied = "Access is denied."
+		}
+
+		err = task.Execute(ctx)
+		g.Should(be.ErrorEqual(
+			err,
+			fmt.Sprintf("caching task: open %s: %s", cachePath, permissionDenied),
+		))
+
+		runCount := strings.Count(buf.String(), "exit 0")
+		if !g.Should(be.Equal(runCount, 1)) {
+			t.Log(buf.String())
+		}
+	})
+
+	t.Run("writeonly cache path", func(t *testing.T) {
+		if runtime.GOOS == "windows" {
+			t.Skip("test is for unix file permissions")
+		}
+
+		g := ghost.New(t)
+
+		wd := xtesting.UseTempDir(t)
+		cfgPath := filepath.Join(wd, "tusk.yml")
+
+		err := os.WriteFile("input.txt", []byte("data a"), 0o600)
+		g.NoError(err)
+
+		err = os.WriteFile("output.txt", []byte("data b"), 0o600)
+		g.NoError(err)
+
+		cacheHome := t.TempDir()
+		t.Setenv("XDG_CACHE_HOME", cacheHome)
+
+		var buf bytes.Buffer
+
+		logger := ui.New(ui.Config{
+			Stdout: io.Discard,
+			Stderr: &buf,
+		})
+
+		ctx := Context{
+			CfgPath: cfgPath,
+			Logger:  logger,
+		}
+
+		task := Task{
+			Name: "my-task",
+			Source: marshal.Slice[string]{
+				"input.txt",
+			},
+			Target: marshal.Slice[string]{
+				"output.txt",
+			},
+			RunList: marshal.Slice[*Run]{
+				{
+					Command: marshal.Slice[*Command]{
+						{
+							Print: "exit 0",
+							Exec:  "exit 0",
+						},
+					},
+				},
+			},
+		}
+
+		cachePath, err := task.taskInputCachePath(ctx)
+		g.NoError(err)
+
+		err = os.MkdirAll(filepath.Dir(cachePath), 0o700)
+		g.NoError(err)
+
+		err = os.WriteFile(cachePath, []byte("data writeonly"), 0o200)
+		g.NoError(err)
+
+		err = task.Execute(ctx)
+		g.Should(be.ErrorEqual(
+			err,
+			fmt.Sprintf("checking cache: open %s: permission denied", cachePath),
+		))
+
+		runCount := strings.Count(buf.String(), "exit 0")
+		if !g.Should(be.Equal(runCount, 0)) {
+			t.Log(buf.String())
+		}
+	})
+}
+
+func TestTask_Execute_cache_force(t *testing.T) {
+	g := ghost.New(t)
+
+	wd := xtesting.UseTempDir(t)
+	t.Setenv("XDG_CACHE_HOME", t.TempDir())
+
+	err := os.WriteFile("input.txt", []byte("data a"), 0o600)
+	g.NoError(err)
+
+	err = os.WriteFile("output.txt", []byte("data b"), 0o600)
+	g.NoError(err)
+
+	var buf bytes.Buffer
+	ctx := Context{
+		CfgPath: filepath.Join(wd, "tusk.yml"),
+		Logger: ui.New(ui.Config{
+			Stdout:    io.Discard,
+			Stderr:    &buf,
+			Verbosity: ui.LevelVerbose,
+		}),
+	}
+
+	sub := Task{
+		Name:   "sub",
+		Source: marshal.Slice[string]{"input.txt"},
+		Target: marshal.Slice[string]{"output.txt"},
+		RunList: marshal.Slice[*Run]{
+			{Command: marshal.Slice[*Command]{{Exec: "echo run >> runs.txt"}}},
+		},
+	}
+	task := Task{
+		Name:    "my-task",
+		RunList: marshal.Slice[*Run]{{Tasks: []Task{sub}}},
+	}
+
+	runCount := func() int {
+		t.Helper()
+		data, err := os.ReadFile("runs.txt")
+		g.NoError(err)
+		return strings.Count(string(data), "run")
+	}
+
+	g.NoError(task.Execute(ctx))
+	g.Should(be.Equal(runCount(), 1))
+
+	forced := ctx
+	forced.Force = true
+	g.NoError(task.Execute(forced))
+	g.Should(be.Equal(runCount(), 2))
+	g.Should(be.Equal(strings.Count(buf.String(), "all targets up to date"), 0))
+
+	// The forced run still refreshes the cache for later runs.
+	g.NoError(task.Execute(ctx))
+	g.Should(be.Equal(runCount(), 2))
+	g.Should(be.Equal(strings.Count(buf.String(), "all targets up to date"), 1))
+}
+
+func TestTask_Execute_cache_inputs(t *testing.T) {
+	g := ghost.New(t)
+
+	wd := xtesting.UseTempDir(t)
+	t.Setenv("XDG_CACHE_HOME", t.TempDir())
+
+	err := os.WriteFile("input.txt", []byte("data a"), 0o600)
+	g.NoError(err)
+
+	err = os.WriteFile("output.txt", []byte("data b"), 0o600)
+	g.NoError(err)
+
+	ctx := Context{
+		CfgPath: filepath.Join(wd, "tusk.yml"),
+		Logger:  ui.Noop(),
+	}
+
+	newTask := func(command string, vars map[string]string) Task {
+		return Task{
+			Name:   "my-task",
+			Source: marshal.Slice[string]{"input.txt"},
+			Target: marshal.Slice[string]{"output.txt"},
+			RunList: marshal.Slice[*Run]{
+				{Command: marshal.Slice[*Command]{{Exec: command + " >> runs.txt"}}},
+			},
+			Vars: vars,
+		}
+	}
+
+	runCount := func() int {
+		t.Helper()
+		data, err := os.ReadFile("runs.txt")
+		g.NoError(err)
+		return strings.Count(string(data), "run")
+	}
+
+	task := newTask("echo run", map[string]string{"foo": "a"})
+	g.NoError(task.Execute(ctx))
+	g.NoError(task.Execute(ctx))
+	g.Should(be.Equal(runCount(), 1))
+
+	task = newTask("echo run", map[string]string{"foo": "b"})
+	g.NoError(task.Execute(ctx))
+	g.Should(be.Equal(runCount(), 2))
+
+	task = newTask("echo run again", map[string]string{"foo": "b"})
+	g.NoError(task.Execute(ctx))
+	g.Should(be.Equal(runCount(), 3))
+
+	// Each set of inputs has its own cache entry, which is still up to date.
+	task = newTask("echo run", map[string]string{"foo": "a"})
+	g.NoError(task.Execute(ctx))
+	g.Should(be.Equal(runCount(), 3))
+}
+
+func TestTask_Execute_cache_env(t *testing.T) {
+	g := ghost.New(t)
+
+	wd := xtesting.UseTempDir(t)
+	t.Setenv("XDG_CACHE_HOME", t.TempDir())
+	t.Setenv("BUILD_MODE", "debug")
+
+	err := os.WriteFile("input.txt", []byte("data a"), 0o600)
+	g.NoError(err)
+
+	err = os.WriteFile("output.txt", []byte("data b"), 0o600)
+	g.NoError(err)
+
+	ctx := Context{
+		CfgPath: filepath.Join(wd, "tusk.yml"),
+		Logger:  ui.Noop(),
+	}
+
+	task := Task{
+		Name:   "my-task",
+		Source: marshal.Slice[string]{"input.txt"},
+		Target: marshal.Slice[string]{"output.txt"},
+		Cache:  &Cache{Inputs: marshal.Slice[string]{"BUILD_MODE"}},
+		RunList: marshal.Slice[*Run]{
+			{Command: marshal.Slice[*Command]{{Exec: "echo run >> runs.txt"}}},
+		},
+	}
 
-		err = os.MkdirAll(filepath.Dir(cachePath), 0o700)
+	runCount := func() int {
+		t.Helper()
+		data, err := os.ReadFile("runs.txt")
 		g.NoError(err)
+		return strings.Count(string(data), "run")
+	}
 
-		err = os.WriteFile(cachePath, []byte("data readonly"), 0o400)
-		g.NoError(err)
+	g.NoError(task.Execute(ctx))
+	g.NoError(task.Execute(ctx))
+	g.Should(be.Equal(runCount(), 1))
 
-		permissionDenied := "permission denied"
-		if runtime.GOOS == "windows" {
-			permissionDenied = "Access is denied."
-		}
+	t.Setenv("BUILD_MODE", "release")
+	g.NoError(task.Execute(ctx))
+	g.Should(be.Equal(runCount(), 2))
 
-		err = task.Execute(ctx)
-		g.Should(be.ErrorEqual(
-			err,
-			fmt.Sprintf("caching task: open %s: %s", cachePath, permissionDenied),
-		))
+	t.Setenv("BUILD_MODE", "")
+	g.NoError(task.Execute(ctx))
+	g.Should(be.Equal(runCount(), 3))
 
-		runCount := strings.Count(buf.String(), "exit 0")
-		if !g.Should(be.Equal(runCount, 1)) {
-			t.Log(buf.String())
-		}
-	})
+	// An unset variable is not the same as an empty one, but is consistent.
+	g.NoError(os.Unsetenv("BUILD_MODE"))
+	g.NoError(task.Execute(ctx))
+	g.NoError(task.Execute(ctx))
+	g.Should(be.Equal(runCount(), 4))
+}
 
-	t.Run("writeonly cache path", func(t *testing.T) {
-		if runtime.GOOS == "windows" {
-			t.Skip("test is for unix file permissions")
-		}
+func TestTask_Execute_cache_directory_target(t *testing.T) {
+	g := ghost.New(t)
 
-		g := ghost.New(t)
+	wd := xtesting.UseTempDir(t)
+	t.Setenv("XDG_CACHE_HOME", t.TempDir())
 
-		wd := xtesting.UseTempDir(t)
-		cfgPath := filepath.Join(wd, "tusk.yml")
+	err := os.WriteFile("input.txt", []byte("data a"), 0o600)
+	g.NoError(err)
 
-		err := os.WriteFile("input.txt", []byte("data a"), 0o600)
-		g.NoError(err)
+	ctx := Context{
+		CfgPath: filepath.Join(wd, "tusk.yml"),
+		Logger:  ui.Noop(),
+	}
 
-		err = os.WriteFile("output.txt", []byte("data b"), 0o600)
+	task := Task{
+		Name:   "my-task",
+		Source: marshal.Slice[string]{"input.txt"},
+		Target: marshal.Slice[string]{"dist/"},
+		RunList: marshal.Slice[*Run]{{Command: marshal.Slice[*Command]{{
+			Exec: "echo run >> runs.txt && mkdir -p dist/sub && echo out > dist/sub/out.txt",
+		}}}},
+	}
+
+	runCount := func() int {
+		t.Helper()
+		data, err := os.ReadFile("runs.txt")
 		g.NoError(err)
+		return strings.Count(string(data), "run")
+	}
 
-		cacheHome := t.TempDir()
-		t.Setenv("XDG_CACHE_HOME", cacheHome)
+	g.NoError(task.Execute(ctx))
+	g.NoError(task.Execute(ctx))
+	g.Should(be.Equal(runCount(), 1))
+
+	// Symlinks are not followed, even when they point nowhere or form a loop.
+	if runtime.GOOS != "windows" {
+		g.NoError(os.Symlink("missing.txt", "dist/missing.txt"))
+		g.NoError(os.Symlink("..", "dist/sub/parent"))
+		g.NoError(task.Execute(ctx))
+		g.NoError(task.Execute(ctx))
+		g.Should(be.Equal(runCount(), 2))
+	}
 
-		var buf bytes.Buffer
+	runs := runCount()
+	err = os.WriteFile("dist/sub/out.txt", []byte("changed"), 0o600)
+	g.NoError(err)
+	g.NoError(task.Execute(ctx))
+	g.Should(be.Equal(runCount(), runs+1))
 
-		logger := ui.New(ui.Config{
-			Stdout: io.Discard,
-			Stderr: &buf,
-		})
+	g.NoError(os.RemoveAll("dist"))
+	g.NoError(task.Execute(ctx))
+	g.Should(be.Equal(runCount(), runs+2))
 
-		ctx := Context{
-			CfgPath: cfgPath,
-			Logger:  logger,
-		}
+	g.NoError(os.RemoveAll("dist"))
+	g.NoError(os.Mkdir("dist", 0o700))
+	g.NoError(task.Execute(ctx))
+	g.Should(be.Equal(runCount(), runs+3))
+}
 
-		task := Task{
-			Name: "my-task",
-			Source: marshal.Slice[string]{
-				"input.txt",
-			},
-			Target: marshal.Slice[string]{
-				"output.txt",
-			},
-			RunList: marshal.Slice[*Run]{
-				{
-					Command: marshal.Slice[*Command]{
-						{
-							Print: "exit 0",
-							Exec:  "exit 0",
-						},
-					},
-				},
-			},
-		}
+func TestTask_Execute_cache_dir(t *testing.T) {
+	g := ghost.New(t)
 
-		cachePath, err := task.taskInputCachePath(ctx)
-		g.NoError(err)
+	wd := xtesting.UseTempDir(t)
+	t.Setenv("XDG_CACHE_HOME", t.TempDir())
 
-		err = os.MkdirAll(filepath.Dir(cachePath), 0o700)
-		g.NoError(err)
+	err := os.WriteFile("input.txt", []byte("data a"), 0o600)
+	g.NoError(err)
 
-		err = os.WriteFile(cachePath, []byte("data writeonly"), 0o200)
-		g.NoError(err)
+	err = os.WriteFile("output.txt", []byte("data b"), 0o600)
+	g.NoError(err)
 
-		err = task.Execute(ctx)
-		g.Should(be.ErrorEqual(
-			err,
-			fmt.Sprintf("checking cache: open %s: permission denied", cachePath),
-		))
+	cacheDir := filepath.Join(wd, ".cache", "tusk")
+	ctx := Context{
+		CfgPath:  filepath.Join(wd, "tusk.yml"),
+		CacheDir: cacheDir,
+		Logger:   ui.Noop(),
+	}
 
-		runCount := strings.Count(buf.String(), "exit 0")
-		if !g.Should(be.Equal(runCount, 0)) {
-			t.Log(buf.String())
-		}
-	})
+	task := Task{
+		Name:   "my-task",
+		Source: marshal.Slice[string]{"input.txt"},
+		Target: marshal.Slice[string]{"output.txt"},
+	}
+	g.NoError(task.Execute(ctx))
+
+	entries, err := os.ReadDir(cacheDir)
+	g.NoError(err)
+	g.Should(be.SliceLen(entries, 1))
+
+	// A cache directory that cannot be used is reported with its path.
+	err = os.WriteFile(filepath.Join(wd, "file"), nil, 0o600)
+	g.NoError(err)
+	ctx.CacheDir = filepath.Join(wd, "file", "cache")
+	err = task.Execute(ctx)
+	g.Should(be.ErrorContaining(err, ctx.CacheDir))
 }
 
 func TestTask_run_commands(t *testing.T) {
@@ -698,6 +1606,68 @@ func TestTask_run_commands(t *testing.T) {
 	g.Should(be.ErrorEqual(err, "exit status 1"))
 }
 
+func TestTask_run_commands_ignoreErrors(t *testing.T) {
+	tests := []struct {
+		name         string
+		ignoreErrors IgnoreErrors
+		pipe         bool
+		wantErr      string
+		wantRan      bool
+	}{
+		{
+			name:         "never",
+			ignoreErrors: ignoreErrorsNever,
+			wantErr:      "exit status 2",
+		},
+		{
+			name:         "always",
+			ignoreErrors: ignoreErrorsAlways,
+			wantRan:      true,
+		},
+		{
+			name:         "at end",
+			ignoreErrors: ignoreErrorsUntilEnd,
+			wantErr:      "exit status 2",
+			wantRan:      true,
+		},
+		{
+			name:         "always with pipe",
+			ignoreErrors: ignoreErrorsAlways,
+			pipe:         true,
+			wantRan:      true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			g := ghost.New(t)
+
+			dir := t.TempDir()
+			task := Task{Name: "foo"}
+			r := &Run{
+				Command: marshal.Slice[*Command]{
+					{Exec: "exit 2"},
+					{Exec: "exit 3"},
+					{Exec: "echo ran > ran.txt"},
+				},
+				Pipe:         tt.pipe,
+				IgnoreErrors: tt.ignoreErrors,
+			}
+
+			ctx := Context{CfgPath: filepath.Join(dir, "tusk.yml"), Logger: ui.Noop()}
+			err := task.run(ctx, r, stateRunning)
+			if tt.wantErr != "" {
+				g.Should(be.ErrorEqual(err, tt.wantErr))
+			} else {
+				g.NoError(err)
+			}
+
+			_, err = os.Stat(filepath.Join(dir, "ran.txt"))
+			g.Should(be.Equal(err == nil, tt.wantRan))
+		})
+	}
+}
+
 func TestTask_run_sub_tasks(t *testing.T) {
 	g := ghost.New(t)
 
@@ -730,6 +1700,248 @@ func TestTask_run_sub_tasks(t *testing.T) {
 	g.Should(be.ErrorEqual(err, "exit status 1"))
 }
 
+func TestTask_Execute_interpreter(t *testing.T) {
+	tests := []struct {
+		name        string
+		interpreter string
+		want        string
+	}{
+		{"inherited", "", "parent sub\n"},
+		{"overridden", "echo sub", "sub sub\n"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			g := ghost.New(t)
+
+			stdout := new(bytes.Buffer)
+			logger := ui.New(ui.Config{Stdout: stdout, Stderr: new(bytes.Buffer)})
+
+			task := Task{
+				Name:        "parent",
+				Interpreter: "echo parent",
+				RunList: marshal.Slice[*Run]{{Tasks: []Task{{
+					Name:        "sub",
+					Interpreter: tt.interpreter,
+					RunList: marshal.Slice[*Run]{{Command: marshal.Slice[*Command]{{
+						Exec: "sub", Quiet: ptr(true),
+					}}}},
+				}}}},
+			}
+
+			err := task.Execute(Context{Logger: logger, Interpreter: []string{"false"}})
+			g.NoError(err)
+
+			g.Should(be.Equal(stdout.String(), tt.want))
+		})
+	}
+}
+
+func TestTask_Execute_finally_outcome(t *testing.T) {
+	yes := true
+
+	tests := []struct {
+		name    string
+		exec    string
+		want    string
+		wantErr string
+	}{
+		{"success", "exit 0", "always\nsucceeded\n", ""},
+		{"failure", "exit 1", "always\nfailed\n", "exit status 1"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			g := ghost.New(t)
+
+			stdout := new(bytes.Buffer)
+			logger := ui.New(ui.Config{Stdout: stdout, Stderr: new(bytes.Buffer)})
+
+			task := Task{
+				Name: "foo",
+				RunList: marshal.Slice[*Run]{{Command: marshal.Slice[*Command]{{
+					Exec: tt.exec,
+				}}}},
+				Finally: marshal.Slice[*Run]{
+					{Command: marshal.Slice[*Command]{{Exec: "echo always"}}},
+					{
+						When:    WhenList{{Failed: &yes}},
+						Command: marshal.Slice[*Command]{{Exec: "echo failed"}},
+					},
+					{
+						When:    WhenList{{Succeeded: &yes}},
+						Command: marshal.Slice[*Command]{{Exec: "echo succeeded"}},
+					},
+				},
+			}
+
+			err := task.Execute(Context{Logger: logger})
+			if tt.wantErr != "" {
+				g.Should(be.ErrorEqual(err, tt.wantErr))
+			} else {
+				g.NoError(err)
+			}
+
+			g.Should(be.Equal(stdout.String(), tt.want))
+		})
+	}
+}
+
+func TestTask_Execute_finally_results(t *testing.T) {
+	tests := []struct {
+		name    string
+		exec    string
+		want    string
+		wantErr string
+	}{
+		{
+			name: "success",
+			exec: "exit 0",
+			want: "lint=skipped build=succeeded test=succeeded\n",
+		},
+		{
+			name:    "failure",
+			exec:    "exit 1",
+			want:    "lint=skipped build=failed test=not-run\ncleanup\n",
+			wantErr: "exit status 1",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			g := ghost.New(t)
+
+			stdout := new(bytes.Buffer)
+			logger := ui.New(ui.Config{Stdout: stdout, Stderr: new(bytes.Buffer)})
+
+			task := Task{
+				Name: "foo",
+				RunList: marshal.Slice[*Run]{
+					{
+						Name:    "lint",
+						When:    WhenList{{Equal: map[string]marshal.Slice[string]{"never": {"true"}}}},
+						Command: marshal.Slice[*Command]{{Exec: "echo lint"}},
+					},
+					{Name: "build", Command: marshal.Slice[*Command]{{Exec: tt.exec}}},
+					{Name: "test", Command: marshal.Slice[*Command]{{Exec: "exit 0"}}},
+				},
+				Finally: marshal.Slice[*Run]{
+					{Command: marshal.Slice[*Command]{{
+						Exec: "echo lint=${.result.lint} build=${.result.build} test=${.result.test}",
+					}}},
+					{
+						When:    WhenList{{Result: map[string]marshal.Slice[string]{"build": {"failed"}}}},
+						Command: marshal.Slice[*Command]{{Exec: "echo cleanup"}},
+					},
+				},
+			}
+
+			err := task.Execute(Context{Logger: logger})
+			if tt.wantErr != "" {
+				g.Should(be.ErrorEqual(err, tt.wantErr))
+			} else {
+				g.NoError(err)
+			}
+
+			g.Should(be.Equal(stdout.String(), tt.want))
+		})
+	}
+}
+
+func TestTask_Execute_onFailure(t *testing.T) {
+	tests := []struct {
+		name          string
+		exec          string
+		onFailureExit string
+		want          string
+		wantErr       string
+	}{
+		{
+			name:          "success",
+			exec:          "exit 0",
+			onFailureExit: "0",
+			want:          "finally\n",
+		},
+		{
+			name:          "failure",
+			exec:          "exit 1",
+			onFailureExit: "0",
+			want:          "error: exit status 1\nenv: exit status 1\nafter\nfinally\n",
+			wantErr:       "exit status 1",
+		},
+		{
+			name:          "on-failure failure",
+			exec:          "exit 1",
+			onFailureExit: "2",
+			want:          "error: exit status 1\nenv: exit status 1\nfinally\n",
+			wantErr:       "exit status 1\non-failure: exit status 2",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			g := ghost.New(t)
+
+			stdout := new(bytes.Buffer)
+			logger := ui.New(ui.Config{Stdout: stdout, Stderr: new(bytes.Buffer)})
+
+			errText := "${.error}"
+			task := Task{
+				Name: "foo",
+				RunList: marshal.Slice[*Run]{{Command: marshal.Slice[*Command]{{
+					Exec: tt.exec,
+				}}}},
+				OnFailure: marshal.Slice[*Run]{
+					{Command: marshal.Slice[*Command]{{Exec: `echo "error: ${.error}"`}}},
+					{Command: marshal.Slice[*Command]{{
+						Exec: `echo "env: $ERR"; exit ` + tt.onFailureExit,
+						Env:  map[string]*string{"ERR": &errText},
+					}}},
+					{Command: marshal.Slice[*Command]{{Exec: "echo after"}}},
+				},
+				Finally: marshal.Slice[*Run]{
+					{Command: marshal.Slice[*Command]{{Exec: "echo finally"}}},
+				},
+			}
+
+			err := task.Execute(Context{Logger: logger})
+			if tt.wantErr != "" {
+				g.Should(be.ErrorEqual(err, tt.wantErr))
+			} else {
+				g.NoError(err)
+			}
+
+			g.Should(be.Equal(stdout.String(), tt.want))
+			g.Should(be.Equal(errText, "${.error}"))
+		})
+	}
+}
+
+func TestTask_runOnFailure_message(t *testing.T) {
+	g := ghost.New(t)
+
+	stdout := new(bytes.Buffer)
+	logger := ui.New(ui.Config{Stdout: stdout, Stderr: new(bytes.Buffer)})
+
+	task := Task{
+		Name: "foo",
+		OnFailure: marshal.Slice[*Run]{
+			{Command: marshal.Slice[*Command]{{Exec: `echo "error: ${.error}"`}}},
+			{Command: marshal.Slice[*Command]{{Exec: `echo "env: $TUSK_ERROR"`}}},
+			{Command: marshal.Slice[*Command]{{Argv: []string{"echo", "argv: ${.error}"}}}},
+		},
+	}
+
+	message := `it's "quoted" $(echo injected) ${HOME}`
+	err := task.runOnFailure(Context{Logger: logger}, errors.New(message))
+	g.Should(be.ErrorEqual(err, message))
+
+	g.Should(be.Equal(
+		stdout.String(),
+		"error: "+message+"\nenv: "+message+"\nargv: "+message+"\n",
+	))
+}
+
 func TestTask_run_environment(t *testing.T) {
 	g := ghost.New(t)
 
@@ -792,6 +2004,9 @@ func TestTask_run_finally_error(t *testing.T) {
 func TestTask_run_finally_ui(t *testing.T) {
 	g := ghost.New(t)
 
+	t.Cleanup(func() { timeSince = time.Since })
+	timeSince = func(time.Time) time.Duration { return time.Second }
+
 	taskName := "foo"
 	command := "exit 0"
 
@@ -803,6 +2018,7 @@ func TestTask_run_finally_ui(t *testing.T) {
 
 	wantLogger.PrintTaskFinally(taskName)
 	wantLogger.PrintCommandWithParenthetical(command, "finally", taskName)
+	wantLogger.PrintCommandCompleted(command, time.Second)
 
 	got := new(bytes.Buffer)
 	gotLogger := ui.New(ui.Config{
@@ -0,0 +1,57 @@
+package runner
+
+import (
+	"fmt"
+	"slices"
+)
+
+// mergeUserConfig adds the tasks and shared options of the user-level config
+// file to the config. The project takes precedence, so a user task is left out
+// if its name or any of its aliases is already used by the project.
+//
+// The user config is parsed on its own, so its includes are resolved relative
+// to the user config file.
+func (c *Config) mergeUserConfig(meta *ParseConfig) error {
+	if meta.UserCfgPath == "" {
+		return nil
+	}
+
+	user, err := Parse(&ParseConfig{
+		CfgPath: meta.UserCfgPath,
+		CfgText: meta.UserCfgText,
+		Offline: meta.Offline,
+	})
+	if err != nil {
+		return fmt.Errorf("user config %q: %w", meta.UserCfgPath, err)
+	}
+
+	taken := make(map[string]bool, len(c.Tasks))
+	for name, t := range c.Tasks {
+		taken[name] = true
+		for _, alias := range t.Aliases {
+			taken[alias] = true
+		}
+	}
+
+	if c.Tasks == nil {
+		c.Tasks = make(map[string]*Task, len(user.Tasks))
+	}
+
+	isTaken := func(name string) bool { return taken[name] }
+	for name, t := range user.Tasks {
+		if taken[name] || slices.ContainsFunc(t.Aliases, isTaken) {
+			continue
+		}
+
+		t.UserConfig = meta.UserCfgPath
+		c.Tasks[name] = t
+	}
+
+	for _, opt := range user.Options {
+		if _, ok := c.Options.Lookup(opt.Name); !ok {
+			c.Options = append(c.Options, opt)
+		}
+	}
+
+	return nil
+}
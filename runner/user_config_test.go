@@ -0,0 +1,112 @@
+package runner
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/rliebz/ghost"
+	"github.com/rliebz/ghost/be"
+)
+
+func TestParse_userConfig(t *testing.T) {
+	g := ghost.New(t)
+
+	cfgText := `
+options:
+  shared: {default: project}
+tasks:
+  build:
+    aliases: [b]
+    run: echo project build
+`
+
+	userCfgText := `
+options:
+  shared: {default: user}
+  editor: {default: vi}
+tasks:
+  build:
+    run: echo user build
+  bundle:
+    aliases: [b]
+    run: echo user bundle
+  scratch:
+    run: echo scratch
+`
+
+	cfg, err := Parse(&ParseConfig{
+		CfgPath:     "tusk.yml",
+		CfgText:     []byte(cfgText),
+		UserCfgPath: filepath.Join("home", "tusk.yml"),
+		UserCfgText: []byte(userCfgText),
+	})
+	g.NoError(err)
+
+	g.Should(be.Equal(cfg.Tasks["build"].RunList[0].Command[0].Exec, "echo project build"))
+	g.Should(be.Equal(cfg.Tasks["build"].UserConfig, ""))
+
+	_, ok := cfg.Tasks["bundle"]
+	g.Should(be.False(ok))
+
+	g.Should(be.Equal(cfg.Tasks["scratch"].UserConfig, filepath.Join("home", "tusk.yml")))
+
+	g.Should(be.Equal(len(cfg.Options), 2))
+	_, ok = cfg.Options.Lookup("editor")
+	g.Should(be.True(ok))
+
+	shared, _ := cfg.Options.Lookup("shared")
+	value, _ := shared.StaticDefault()
+	g.Should(be.Equal(value, "project"))
+}
+
+func TestParse_userConfig_includes(t *testing.T) {
+	g := ghost.New(t)
+
+	dir := t.TempDir()
+	g.NoError(os.WriteFile(filepath.Join(dir, "scratch.yml"), []byte("run: echo scratch"), 0o600))
+
+	cfg, err := Parse(&ParseConfig{
+		CfgPath:     "tusk.yml",
+		UserCfgPath: filepath.Join(dir, "tusk.yml"),
+		UserCfgText: []byte("tasks: { scratch: { include: scratch.yml } }"),
+	})
+	g.NoError(err)
+
+	g.Should(be.Equal(cfg.Tasks["scratch"].RunList[0].Command[0].Exec, "echo scratch"))
+}
+
+func TestParse_userConfig_error(t *testing.T) {
+	g := ghost.New(t)
+
+	_, err := Parse(&ParseConfig{
+		CfgPath:     "tusk.yml",
+		UserCfgPath: "user.yml",
+		UserCfgText: []byte("tasks: { foo: { extends: bar } }"),
+	})
+	g.Should(be.ErrorEqual(err, `user config "user.yml": task "foo": extends undefined task "bar"`))
+}
+
+func TestParseComplete_userConfig_builtinVars(t *testing.T) {
+	g := ghost.New(t)
+
+	dir := t.TempDir()
+	cfgText := "tasks: { project: { run: 'echo ${tusk.dir}' } }"
+	userCfgText := "tasks: { scratch: { run: [{task: project}, 'echo ${tusk.dir}'] } }"
+
+	cfg, err := ParseComplete(&ParseConfig{
+		CfgPath:     filepath.Join(dir, "project", "tusk.yml"),
+		CfgText:     []byte(cfgText),
+		TaskName:    "scratch",
+		UserCfgPath: filepath.Join(dir, "home", "tusk.yml"),
+		UserCfgText: []byte(userCfgText),
+	})
+	g.NoError(err)
+
+	task := cfg.Tasks["scratch"]
+	g.Should(be.Equal(
+		task.RunList[0].Tasks[0].RunList[0].Command[0].Exec,
+		"echo "+filepath.Join(dir, "project"),
+	))
+	g.Should(be.Equal(task.RunList[1].Command[0].Exec, "echo "+filepath.Join(dir, "home")))
+}
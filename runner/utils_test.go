@@ -9,6 +9,11 @@ import (
 	"github.com/rliebz/tusk/marshal"
 )
 
+// ptr returns a pointer to a value.
+func ptr[T any](v T) *T {
+	return &v
+}
+
 // createOption creates a custom option for testing purposes.
 func createOption(operators ...func(o *Option)) *Option {
 	o := Option{}
@@ -90,6 +95,20 @@ func withWhenExists(filename string) func(w *When) {
 	}
 }
 
+// withWhenExistsDir returns an operator that requires a directory to exist.
+func withWhenExistsDir(filename string) func(w *When) {
+	return func(w *When) {
+		w.ExistsDir = append(w.ExistsDir, filename)
+	}
+}
+
+// withWhenExistsFile returns an operator that requires a regular file to exist.
+func withWhenExistsFile(filename string) func(w *When) {
+	return func(w *When) {
+		w.ExistsFile = append(w.ExistsFile, filename)
+	}
+}
+
 // withWhenNotExists returns an operator that requires a file to not exist.
 func withWhenNotExists(filename string) func(w *When) {
 	return func(w *When) {
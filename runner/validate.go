@@ -0,0 +1,477 @@
+package runner
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"maps"
+	"slices"
+	"strings"
+
+	yaml "gopkg.in/yaml.v2"
+
+	"github.com/rliebz/tusk/marshal"
+)
+
+// ValidationErrors is the list of problems found while validating a config.
+type ValidationErrors []error
+
+// Error returns every problem found, one per line.
+func (e ValidationErrors) Error() string {
+	messages := make([]string, 0, len(e))
+	for _, err := range e {
+		messages = append(messages, err.Error())
+	}
+
+	return strings.Join(messages, "\n")
+}
+
+// Unwrap returns the individual problems found.
+func (e ValidationErrors) Unwrap() []error {
+	return e
+}
+
+// ValidationError is a problem with the definition of a task.
+type ValidationError struct {
+	// Task is the name of the task, if it is known.
+	Task string
+
+	// Field is the key of the task the problem was found in, such as "source".
+	Field string
+
+	// Message describes the problem.
+	Message string
+}
+
+// newValidationError returns a problem found in a field of a task, recording
+// the field so that the line it is on can be found.
+func newValidationError(field string, err error) error {
+	return withKeys(&ValidationError{Field: field, Message: err.Error()}, field)
+}
+
+// Error returns the message describing the problem. The name of the task is
+// added by the caller, along with the location of the field if it is known.
+func (e *ValidationError) Error() string {
+	return e.Message
+}
+
+// taskErrors adds the name and key path of a task to each problem found with
+// it, recording the name of the task in each [ValidationError].
+func taskErrors(name string, err error) error {
+	return eachError(err, func(err error) error {
+		var verr *ValidationError
+		if errors.As(err, &verr) {
+			verr.Task = name
+		}
+		return entryError(err, "task", "tasks", name)
+	})
+}
+
+// eachError applies f to each problem in [ValidationErrors], or to err itself if
+// it is a single problem.
+func eachError(err error, f func(error) error) error {
+	errs, ok := err.(ValidationErrors) //nolint:errorlint // Only a list itself is split.
+	if !ok {
+		return f(err)
+	}
+
+	mapped := make(ValidationErrors, 0, len(errs))
+	for _, err := range errs {
+		mapped = append(mapped, f(err))
+	}
+
+	return mapped
+}
+
+// Validate checks a config file for problems without running anything.
+//
+// Includes are resolved, every task is validated, interpolations are checked
+// against the args and options that are in scope, sub-task references are
+// resolved, and option dependencies are checked for cycles. All problems found
+// are returned together as [ValidationErrors].
+func Validate(meta *ParseConfig) error {
+	if meta.CfgPath == "" {
+		return errors.New("no config file found")
+	}
+
+	cfg, err := Parse(meta)
+	var parseErrs ValidationErrors
+	switch {
+	case errors.As(err, &parseErrs):
+		return parseErrs
+	case err != nil:
+		return ValidationErrors{err}
+	}
+
+	var errs ValidationErrors
+
+	cycles, err := findOptionCycles(cfg.Options)
+	if err != nil {
+		return ValidationErrors{err}
+	}
+	for _, cycle := range cycles {
+		errs = append(errs, newOptionCycleError(cycle))
+	}
+
+	locate := func(err error, keys ...string) error {
+		return locateError(meta.CfgPath, meta.CfgText, withKeys(err, keys...))
+	}
+
+	for _, opt := range cfg.Options {
+		item := []any{opt, comparisons([]*Option{opt})}
+		for _, err := range validateReferences(item, cfg.Options.names()) {
+			errs = append(errs, locate(fmt.Errorf("option %q: %w", opt.Name, err), "options", opt.Name))
+		}
+	}
+
+	for _, name := range slices.Sorted(maps.Keys(cfg.Tasks)) {
+		for _, err := range cfg.Tasks[name].validate(cfg) {
+			errs = append(errs, locateError(meta.CfgPath, meta.CfgText, taskErrors(name, err)))
+		}
+	}
+
+	for _, name := range slices.Sorted(maps.Keys(cfg.Profiles)) {
+		if _, err := cfg.profileOverrides(name); err != nil {
+			errs = append(errs, err)
+		}
+	}
+
+	if len(errs) > 0 {
+		return ValidationErrors(uniqueErrors(errs))
+	}
+
+	return nil
+}
+
+// validate returns all problems found with a task in the context of a config.
+func (t *Task) validate(cfg *Config) []error {
+	var errs []error
+
+	var verrs ValidationErrors
+	if err := t.isValid(); errors.As(err, &verrs) {
+		errs = append(errs, verrs...)
+	}
+
+	scope := t.optionScope(cfg)
+	errs = append(errs, t.validateInterpolation(scope)...)
+	for _, r := range t.AllRunItems() {
+		errs = append(errs, r.validateSubTasks(cfg, t.Name, scope)...)
+	}
+
+	return errs
+}
+
+// validateInterpolation returns a problem for every cycle of options in scope
+// whose values depend on each other, and for every interpolation in a task that
+// does not refer to one of its args, an option in scope, or a matrix variable
+// of its run item.
+func (t *Task) validateInterpolation(scope map[string]*Option) []error {
+	var errs []error
+
+	cycles, err := findOptionCycles(slices.Collect(maps.Values(scope)))
+	if err != nil {
+		errs = append(errs, err)
+	}
+	for _, cycle := range cycles {
+		// Cycles of shared options alone are reported once for the config.
+		if slices.ContainsFunc(cycle, func(name string) bool {
+			_, ok := t.Options.Lookup(name)
+			return ok
+		}) {
+			errs = append(errs, newOptionCycleError(cycle))
+		}
+	}
+
+	declared := make(map[string]struct{}, len(scope)+len(t.Args))
+	for name := range scope {
+		declared[name] = struct{}{}
+	}
+	for _, arg := range t.Args {
+		declared[arg.Name] = struct{}{}
+	}
+
+	// The on-failure clause can also refer to the error that failed the task.
+	onFailure := maps.Clone(declared)
+	onFailure[strings.Trim(failureVariable, "${}")] = struct{}{}
+
+	// The finally clause can also refer to the result of each named run item.
+	finally := maps.Clone(declared)
+	for name := range t.newResults() {
+		finally[resultPrefix+name] = struct{}{}
+	}
+
+	errs = append(errs, validateReferences(
+		[]any{
+			t.Options,
+			comparisons(t.Options),
+			withoutMatrix(t.RunList),
+			comparisons(withoutMatrix(t.RunList)),
+			t.Interpreter,
+			t.Source,
+			t.Target,
+		},
+		declared,
+	)...)
+	errs = append(errs, validateReferences(
+		[]any{withoutMatrix(t.OnFailure), comparisons(withoutMatrix(t.OnFailure))},
+		onFailure,
+	)...)
+	errs = append(errs, validateReferences(
+		[]any{withoutMatrix(t.Finally), comparisons(withoutMatrix(t.Finally))},
+		finally,
+	)...)
+	for _, r := range t.RunList {
+		errs = append(errs, r.validateMatrix(declared)...)
+	}
+	for _, r := range t.OnFailure {
+		errs = append(errs, r.validateMatrix(onFailure)...)
+	}
+	for _, r := range t.Finally {
+		errs = append(errs, r.validateMatrix(finally)...)
+	}
+
+	return uniqueErrors(errs)
+}
+
+// comparisons returns the args and options compared by the when clauses of
+// each item, such as the keys of `equal`, written as interpolations so that
+// they can be checked the same way.
+func comparisons[T interface{ Dependencies() []string }](items []T) []string {
+	var names []string
+	for _, item := range items {
+		names = append(names, item.Dependencies()...)
+	}
+	slices.Sort(names)
+
+	refs := make([]string, 0, len(names))
+	for _, name := range slices.Compact(names) {
+		refs = append(refs, "${"+name+"}")
+	}
+	return refs
+}
+
+// uniqueErrors removes errors with the same message as an earlier error, which
+// happens when the same reference is found in more than one clause, or the
+// same problem is found in more than one place.
+func uniqueErrors(errs []error) []error {
+	seen := make(map[string]struct{}, len(errs))
+	return slices.DeleteFunc(errs, func(err error) bool {
+		if _, ok := seen[err.Error()]; ok {
+			return true
+		}
+		seen[err.Error()] = struct{}{}
+		return false
+	})
+}
+
+// checkInterpolation checks the interpolations of a task and every sub-task it
+// may run, so that a cycle of options is reported before anything runs rather
+// than when it is reached.
+//
+// A reference to an undefined arg or option is only added to the warnings of
+// the config, since text such as ${HOME} may be meant for the shell. It is an
+// error when validating.
+func checkInterpolation(meta *ParseConfig, cfg *Config, t *Task) error {
+	var errs ValidationErrors
+
+	cycles, err := findOptionCycles(cfg.Options)
+	if err != nil {
+		return err
+	}
+	for _, cycle := range cycles {
+		errs = append(errs, newOptionCycleError(cycle))
+	}
+
+	visited := make(map[string]bool)
+
+	var check func(t *Task)
+	check = func(t *Task) {
+		if visited[t.Name] {
+			return
+		}
+		visited[t.Name] = true
+
+		for _, err := range t.validateInterpolation(t.optionScope(cfg)) {
+			var undefined *undefinedReferenceError
+			isUndefined := errors.As(err, &undefined)
+
+			err = withKeys(fmt.Errorf("task %q: %w", t.Name, err), "tasks", t.Name)
+			err = locateError(meta.CfgPath, meta.CfgText, err)
+			if isUndefined {
+				cfg.Warnings = append(cfg.Warnings, err.Error())
+				continue
+			}
+			errs = append(errs, err)
+		}
+
+		for _, r := range t.AllRunItems() {
+			for _, desc := range r.SubTaskList {
+				if sub, ok := cfg.Tasks[desc.Name]; ok {
+					check(sub)
+				}
+			}
+		}
+	}
+	check(t)
+
+	if len(errs) > 0 {
+		return ValidationErrors(uniqueErrors(errs))
+	}
+
+	return nil
+}
+
+// optionScope returns the options available to a task by name. Task options
+// take priority over shared options, and args hide shared options entirely.
+func (t *Task) optionScope(cfg *Config) map[string]*Option {
+	scope := make(map[string]*Option, len(cfg.Options)+len(t.Options))
+	for _, opt := range cfg.Options {
+		if _, ok := t.Args.Lookup(opt.Name); ok {
+			continue
+		}
+		scope[opt.Name] = opt
+	}
+	for _, opt := range t.Options {
+		scope[opt.Name] = opt
+	}
+
+	return scope
+}
+
+// withoutMatrix returns the run items that do not have a matrix, which can
+// only refer to args and options.
+func withoutMatrix(runs marshal.Slice[*Run]) marshal.Slice[*Run] {
+	var output marshal.Slice[*Run]
+	for _, r := range runs {
+		if len(r.Matrix) == 0 {
+			output = append(output, r)
+		}
+	}
+	return output
+}
+
+// validateMatrix checks that the matrix variables of a run item do not hide an
+// arg or option, and that its commands only refer to matrix variables, args,
+// and options.
+func (r *Run) validateMatrix(declared map[string]struct{}) []error {
+	if len(r.Matrix) == 0 {
+		return nil
+	}
+
+	var errs []error
+	withMatrix := maps.Clone(declared)
+	for _, name := range r.Matrix.names() {
+		if _, ok := declared[name]; ok {
+			errs = append(errs, fmt.Errorf("matrix variable %q is already an arg or option", name))
+		}
+		withMatrix[name] = struct{}{}
+	}
+
+	return append(errs, validateReferences(r, withMatrix)...)
+}
+
+// validateSubTasks checks that every sub-task referenced exists and accepts
+// the values passed to it, including options passed through from the parent
+// task's scope.
+func (r *Run) validateSubTasks(cfg *Config, parent string, scope map[string]*Option) []error {
+	var errs []error
+	for _, desc := range r.SubTaskList {
+		sub, ok := cfg.Tasks[desc.Name]
+		if !ok {
+			errs = append(errs, fmt.Errorf("sub-task %q is not defined", desc.Name))
+			continue
+		}
+
+		args, err := desc.Args.values(sub)
+		switch {
+		case err != nil:
+			errs = append(errs, err)
+		case !sub.Args.accepts(len(args)):
+			errs = append(errs, subTaskArgCountError(sub, len(args)))
+		}
+
+		for _, optName := range slices.Sorted(maps.Keys(desc.Options)) {
+			if _, ok := sub.Options.Lookup(optName); !ok {
+				errs = append(errs, fmt.Errorf(
+					"option %q cannot be passed to task %q",
+					optName, sub.Name,
+				))
+			}
+		}
+
+		for _, optName := range desc.PassOptions {
+			if optName == passAllOptions {
+				continue
+			}
+
+			if _, ok := scope[optName]; !ok {
+				errs = append(errs, fmt.Errorf(
+					"option %q cannot be passed from task %q",
+					optName, parent,
+				))
+			}
+
+			if _, ok := sub.Options.Lookup(optName); !ok {
+				errs = append(errs, fmt.Errorf(
+					"option %q cannot be passed to task %q",
+					optName, sub.Name,
+				))
+			}
+		}
+	}
+
+	return errs
+}
+
+// validateReferences checks that every interpolation within an item refers to
+// a declared name or a built-in variable. Escaped interpolations such as
+// $${foo} are ignored.
+func validateReferences(item any, declared map[string]struct{}) []error {
+	text, err := yaml.Marshal(item)
+	if err != nil {
+		return []error{err}
+	}
+	text = bytes.ReplaceAll(text, []byte("$$"), nil)
+
+	var errs []error
+	if err := marshal.CheckFunctions(text); err != nil {
+		errs = append(errs, err)
+	}
+	if err := marshal.CheckEnv(text); err != nil {
+		errs = append(errs, err)
+	}
+
+	seen := make(map[string]struct{})
+	for _, name := range marshal.FindPotentialVariables(text) {
+		if _, ok := seen[name]; ok {
+			continue
+		}
+		seen[name] = struct{}{}
+
+		if _, ok := declared[name]; !ok && !slices.Contains(builtinVarNames, name) {
+			errs = append(errs, &undefinedReferenceError{name: name})
+		}
+	}
+
+	return errs
+}
+
+// undefinedReferenceError is an interpolation that does not refer to an arg,
+// option, or built-in variable.
+type undefinedReferenceError struct {
+	name string
+}
+
+// Error describes the reference.
+func (e *undefinedReferenceError) Error() string {
+	return fmt.Sprintf("${%s} does not refer to an arg or option", e.name)
+}
+
+// names returns the set of option names.
+func (o Options) names() map[string]struct{} {
+	names := make(map[string]struct{}, len(o))
+	for _, opt := range o {
+		names[opt.Name] = struct{}{}
+	}
+	return names
+}
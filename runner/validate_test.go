@@ -0,0 +1,453 @@
+package runner
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/rliebz/ghost"
+	"github.com/rliebz/ghost/be"
+)
+
+func TestValidate(t *testing.T) {
+	tests := []struct {
+		name     string
+		input    string
+		wantErrs []string
+	}{
+		{
+			name: "valid",
+			input: `
+options:
+  name:
+    default: World
+  greeting:
+    default: Hello, ${name}
+tasks:
+  greet:
+    args:
+      target: {}
+    options:
+      loud:
+        type: bool
+    run:
+      - when: loud
+        command: echo "${greeting}, ${target}!"
+      - echo "$${HOME}"
+      - task:
+          name: other
+          options: {excited: true}
+  other:
+    options:
+      excited:
+        type: bool
+    run: echo ${excited}
+`,
+		},
+		{
+			name: "invalid profiles",
+			input: `
+profiles:
+  prod:
+    count: many
+    missing: value
+tasks:
+  one:
+    options:
+      count:
+        type: int
+    run: echo ${count}
+`,
+			wantErrs: []string{
+				`profile "prod": value "many" for option "count" is not of type "int"`,
+			},
+		},
+		{
+			name:     "invalid yaml",
+			input:    `}{`,
+			wantErrs: []string{"yaml: did not find expected node content"},
+		},
+		{
+			name: "unknown keys",
+			input: `
+unknown: true
+tasks:
+  one:
+    desciption: One
+    run: echo one
+  two:
+    options:
+      count:
+        typ: int
+    run: echo ${count}
+`,
+			wantErrs: []string{
+				`tusk.yml:2: field unknown not found in type runner.configType`,
+				`tusk.yml:5: task "one": field desciption not found in type runner.taskType`,
+				`tusk.yml:10: task "two": field typ not found in type runner.optionType`,
+			},
+		},
+		{
+			name: "undefined references",
+			input: `
+tasks:
+  one:
+    options:
+      foo:
+        default: ${bar}
+    run:
+      - echo ${foo} ${baz}
+      - set-environment: { FOO: "${qux}" }
+    finally:
+      - when:
+          equal: { foo: "${baz}" }
+        command: echo
+  two:
+    run: echo ${foo}
+`,
+			wantErrs: []string{
+				`tusk.yml:3: task "one": ${bar} does not refer to an arg or option`,
+				`tusk.yml:3: task "one": ${baz} does not refer to an arg or option`,
+				`tusk.yml:3: task "one": ${qux} does not refer to an arg or option`,
+				`tusk.yml:14: task "two": ${foo} does not refer to an arg or option`,
+			},
+		},
+		{
+			name: "built-in variables",
+			input: `
+tasks:
+  one:
+    run: echo ${tusk.dir} ${upper(tusk.config)} ${tusk.other}
+`,
+			wantErrs: []string{
+				`tusk.yml:3: task "one": ${tusk.other} does not refer to an arg or option`,
+			},
+		},
+		{
+			name: "failure variable",
+			input: `
+tasks:
+  one:
+    run: echo ${.error}
+    on-failure:
+      - echo ${.error}
+      - matrix: { os: [linux] }
+        command: echo ${os} ${.error}
+    finally: echo ${.error}
+`,
+			wantErrs: []string{
+				`tusk.yml:3: task "one": ${.error} does not refer to an arg or option`,
+			},
+		},
+		{
+			name: "result variables",
+			input: `
+tasks:
+  one:
+    run:
+      - name: build
+        command: echo ${.result.build}
+    finally:
+      - echo ${.result.build}
+      - echo ${.result.test}
+`,
+			wantErrs: []string{
+				`tusk.yml:3: task "one": ${.result.build} does not refer to an arg or option`,
+				`tusk.yml:3: task "one": ${.result.test} does not refer to an arg or option`,
+			},
+		},
+		{
+			name: "matrix references",
+			input: `
+tasks:
+  one:
+    options:
+      os:
+        default: linux
+    run:
+      - matrix: { go: ["1.22", "1.23"] }
+        command: echo ${go} ${os} ${arch}
+      - echo ${go}
+      - matrix: { os: [linux] }
+        command: echo ${os}
+`,
+			wantErrs: []string{
+				`tusk.yml:3: task "one": ${go} does not refer to an arg or option`,
+				`tusk.yml:3: task "one": ${arch} does not refer to an arg or option`,
+				`tusk.yml:3: task "one": matrix variable "os" is already an arg or option`,
+			},
+		},
+		{
+			name: "undefined shared option reference",
+			input: `
+options:
+  foo:
+    default: ${bar}
+tasks:
+  one:
+    run: echo ${foo}
+`,
+			wantErrs: []string{
+				`tusk.yml:3: option "foo": ${bar} does not refer to an arg or option`,
+			},
+		},
+		{
+			name: "sub-task problems",
+			input: `
+tasks:
+  one:
+    args:
+      foo: {}
+    run: echo ${foo}
+  two:
+    run:
+      - task: fake
+      - task:
+          name: one
+          options: {wrong: value}
+          pass-options: [missing, all]
+`,
+			wantErrs: []string{
+				`tusk.yml:7: task "two": sub-task "fake" is not defined`,
+				`tusk.yml:7: task "two": subtask "one" requires 1 args but got 0`,
+				`tusk.yml:7: task "two": option "wrong" cannot be passed to task "one"`,
+				`tusk.yml:7: task "two": option "missing" cannot be passed from task "two"`,
+				`tusk.yml:7: task "two": option "missing" cannot be passed to task "one"`,
+			},
+		},
+		{
+			name: "sub-task named args",
+			input: `
+tasks:
+  one:
+    args:
+      foo: {}
+      bar: {}
+    run: echo ${foo} ${bar}
+  two:
+    run:
+      - task: {name: one, args: {baz: value}}
+      - task: {name: one, args: {bar: value}}
+      - task: {name: one, args: {foo: value}}
+`,
+			wantErrs: []string{
+				`tusk.yml:8: task "two": arg "baz" cannot be passed to task "one"`,
+				`tusk.yml:8: task "two": arg "foo" must be passed to task "one" ` +
+					`along with the args after it`,
+				`tusk.yml:8: task "two": subtask "one" requires 2 args but got 1`,
+			},
+		},
+		{
+			name: "interpolation functions",
+			input: `
+tasks:
+  one:
+    options:
+      name: {}
+    run:
+      - echo ${upper(name)} ${default(name, "x")}
+      - echo ${reverse(name)} ${lower(missing)}
+`,
+			wantErrs: []string{
+				`tusk.yml:3: task "one": unknown function "reverse" in ${reverse(name)}`,
+				`tusk.yml:3: task "one": ${missing} does not refer to an arg or option`,
+			},
+		},
+		{
+			name: "environment variables",
+			input: `
+tasks:
+  one:
+    run:
+      - echo ${env:HOME} ${env:PORT:-8080}
+      - echo ${env:PORT:8080}
+`,
+			wantErrs: []string{
+				`tusk.yml:3: task "one": invalid environment variable reference ${env:PORT:8080}: ` +
+					`must be ${env:NAME} or ${env:NAME:-default}`,
+			},
+		},
+		{
+			name: "when comparisons",
+			input: `
+options:
+  a:
+    default:
+      - when: { equal: { missing: foo } }
+        value: foo
+tasks:
+  one:
+    args: {b: {}}
+    options:
+      c:
+        default:
+          - when: { not-equal: { b: foo, undefined: bar } }
+            value: foo
+    run:
+      - when: { equal: { a: foo, c: foo, other: foo } }
+        command: echo ${a} ${c}
+`,
+			wantErrs: []string{
+				`tusk.yml:3: option "a": ${missing} does not refer to an arg or option`,
+				`tusk.yml:8: task "one": ${undefined} does not refer to an arg or option`,
+				`tusk.yml:8: task "one": ${other} does not refer to an arg or option`,
+			},
+		},
+		{
+			name: "task option cycle",
+			input: `
+tasks:
+  one:
+    options:
+      a:
+        default: ${b}
+      b:
+        default:
+          - when: { equal: { a: foo } }
+            value: foo
+    run: echo ${a}
+`,
+			wantErrs: []string{
+				`tusk.yml:3: task "one": options form a dependency cycle: option "a" -> "b" -> "a"`,
+			},
+		},
+		{
+			name: "shared option cycle",
+			input: `
+options:
+  a:
+    default: ${b}
+  b:
+    default: ${a}
+tasks:
+  one:
+    run: echo ${a}
+  two:
+    options:
+      c:
+        default: ${a}
+    run: echo ${c}
+`,
+			wantErrs: []string{
+				`options form a dependency cycle: option "a" -> "b" -> "a"`,
+			},
+		},
+		{
+			name: "shared and task option cycle",
+			input: `
+options:
+  a:
+    default: ${b}
+tasks:
+  one:
+    options:
+      b:
+        default: ${c}
+      c:
+        default: ${a}
+    run: echo ${a}
+`,
+			wantErrs: []string{
+				`tusk.yml:3: option "a": ${b} does not refer to an arg or option`,
+				`tusk.yml:6: task "one": ` +
+					`options form a dependency cycle: option "a" -> "b" -> "c" -> "a"`,
+			},
+		},
+		{
+			name: "task definitions",
+			input: `
+tasks:
+  one:
+    source: src/**
+    interpreter: " "
+    run: echo one
+  two:
+    args: {env: {}}
+    options: {env: {}}
+    run:
+      - { name: build, command: echo two }
+      - { name: build, command: echo three }
+`,
+			wantErrs: []string{
+				`tusk.yml:4: task "one": task source cannot be defined without target`,
+				`tusk.yml:5: task "one": interpreter must name an executable`,
+				`tusk.yml:10: task "two": run item "build" must have a unique name within a task`,
+				`tusk.yml:9: task "two": ` +
+					`argument and option "env" must have unique names within a task`,
+			},
+		},
+		{
+			name: "self references",
+			input: `
+tasks:
+  one:
+    options:
+      a:
+        default: ${a}-suffix
+      b:
+        default: ${c}
+      c:
+        default: ${b}
+      d:
+        default:
+          - when: { equal: { d: foo } }
+            value: bar
+    run: echo ${a} ${b} ${d}
+`,
+			wantErrs: []string{
+				`tusk.yml:3: task "one": option "a" depends on itself`,
+				`tusk.yml:3: task "one": options form a dependency cycle: option "b" -> "c" -> "b"`,
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			g := ghost.New(t)
+
+			err := Validate(&ParseConfig{CfgPath: "tusk.yml", CfgText: []byte(tt.input)})
+			if len(tt.wantErrs) == 0 {
+				g.NoError(err)
+				return
+			}
+
+			var verrs ValidationErrors
+			g.Assert(errors.As(err, &verrs))
+
+			got := make([]string, 0, len(verrs))
+			for _, verr := range verrs {
+				got = append(got, verr.Error())
+			}
+			g.Should(be.DeepEqual(got, tt.wantErrs))
+		})
+	}
+}
+
+func TestValidationError(t *testing.T) {
+	g := ghost.New(t)
+
+	_, err := Parse(&ParseConfig{CfgPath: "tusk.yml", CfgText: []byte(`
+tasks:
+  build:
+    target: bin/tusk
+    run: go build
+`)})
+
+	var verr *ValidationError
+	g.Assert(errors.As(err, &verr))
+	g.Should(be.DeepEqual(*verr, ValidationError{
+		Task:    "build",
+		Field:   "target",
+		Message: "task target cannot be defined without source",
+	}))
+	g.Should(be.ErrorEqual(
+		err,
+		`tusk.yml:4: task "build": task target cannot be defined without source`,
+	))
+}
+
+func TestValidate_no_config(t *testing.T) {
+	g := ghost.New(t)
+
+	err := Validate(&ParseConfig{})
+	g.Should(be.ErrorEqual(err, "no config file found"))
+}
@@ -1,8 +1,10 @@
 package runner
 
 import (
+	"errors"
 	"fmt"
-	"strings"
+	"os/exec"
+	"sync"
 
 	"github.com/rliebz/tusk/marshal"
 )
@@ -13,24 +15,110 @@ type Value struct {
 	When    WhenList
 	Command string
 	Value   string
+
+	// CommandSucceeds is a command whose exit status is used as the value,
+	// which is "true" when the command succeeds and "false" otherwise.
+	CommandSucceeds string `yaml:"command-succeeds"`
+
+	// Cache determines whether the output of the command is reused for the
+	// rest of the run. If nil, it is reused.
+	Cache *bool `yaml:"cache"`
 }
 
 // commandValueOrDefault validates a content definition, then gets the value.
-func (v *Value) commandValueOrDefault(ctx Context) (string, error) {
+//
+// Commands are run the same way as the commands of a task, using the
+// interpreter of the context. Unless caching is disabled, a command is run
+// once per option for each run, and its output is reused after that.
+func (v *Value) commandValueOrDefault(ctx Context, option string) (string, error) {
+	if v.Command == "" && v.CommandSucceeds == "" {
+		return v.Value, nil
+	}
+
+	if v.Cache != nil && !*v.Cache {
+		return v.commandValue(ctx)
+	}
+
+	key := defaultKey{option: option, command: v.Command, succeeds: v.CommandSucceeds}
+	return ctx.defaults.get(key, func() (string, error) { return v.commandValue(ctx) })
+}
+
+func (v *Value) commandValue(ctx Context) (string, error) {
+	if v.CommandSucceeds != "" {
+		return commandSucceeds(ctx, v.CommandSucceeds)
+	}
+
 	if v.Command != "" {
-		cmd := newCmd(ctx, v.Command)
+		command := Command{Exec: v.Command, Print: v.Command}
 
-		out, err := cmd.Output()
+		out, err := command.output(ctx)
 		if err != nil {
-			return "", err
+			return "", fmt.Errorf("running %q: %w", v.Command, err)
 		}
 
-		return strings.TrimSpace(string(out)), nil
+		return out, nil
 	}
 
 	return v.Value, nil
 }
 
+// defaultCache holds the values computed by the commands of option defaults
+// during a run.
+type defaultCache struct {
+	mu     sync.Mutex
+	values map[defaultKey]string
+}
+
+type defaultKey struct {
+	option   string
+	command  string
+	succeeds string
+}
+
+func newDefaultCache() *defaultCache {
+	return &defaultCache{values: make(map[defaultKey]string)}
+}
+
+// get returns the cached value for a key, computing it if it has not been
+// computed yet. Errors are not cached. A nil cache computes every time.
+func (c *defaultCache) get(key defaultKey, compute func() (string, error)) (string, error) {
+	if c == nil {
+		return compute()
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if value, ok := c.values[key]; ok {
+		return value, nil
+	}
+
+	value, err := compute()
+	if err != nil {
+		return "", err
+	}
+
+	c.values[key] = value
+	return value, nil
+}
+
+// commandSucceeds runs a command, returning "true" if it exits successfully
+// and "false" if it exits with a non-zero status. Output is discarded, the same
+// as a command in a when clause.
+func commandSucceeds(ctx Context, command string) (string, error) {
+	err := testCommand(ctx, command)
+
+	var exitErr *exec.ExitError
+	switch {
+	case err == nil:
+		return "true", nil
+	case errors.As(err, &exitErr):
+		return "false", nil
+	default:
+		return "", fmt.Errorf("running %q: %w", command, err)
+	}
+}
+
 // UnmarshalYAML allows plain strings to represent a full struct. The value of
 // the string is used as the Default field.
 func (v *Value) UnmarshalYAML(unmarshal func(any) error) error {
@@ -53,6 +141,17 @@ func (v *Value) UnmarshalYAML(unmarshal func(any) error) error {
 				)
 			}
 
+			if valueItem.CommandSucceeds != "" && (valueItem.Value != "" || valueItem.Command != "") {
+				return fmt.Errorf(
+					"command-succeeds (%s) cannot be defined with value or command",
+					valueItem.CommandSucceeds,
+				)
+			}
+
+			if valueItem.Cache != nil && valueItem.Command == "" && valueItem.CommandSucceeds == "" {
+				return errors.New("cache can only be set with command or command-succeeds")
+			}
+
 			return nil
 		},
 	}
@@ -1,11 +1,16 @@
 package runner
 
 import (
+	"os"
+	"path/filepath"
+	"strings"
 	"testing"
 
 	"github.com/rliebz/ghost"
 	"github.com/rliebz/ghost/be"
 	yaml "gopkg.in/yaml.v2"
+
+	"github.com/rliebz/tusk/internal/xtesting"
 )
 
 func TestValue_UnmarshalYAML(t *testing.T) {
@@ -30,3 +35,71 @@ func TestValue_UnmarshalYAML_value_and_command(t *testing.T) {
 	err := yaml.UnmarshalStrict([]byte(`{value: "example", command: "echo hello"}`), &v)
 	g.Should(be.ErrorEqual(err, "value (example) and command (echo hello) are both defined"))
 }
+
+func TestValue_UnmarshalYAML_commandSucceeds_and_command(t *testing.T) {
+	g := ghost.New(t)
+
+	var v Value
+	err := yaml.UnmarshalStrict(
+		[]byte(`{command-succeeds: "docker info", command: "echo hello"}`),
+		&v,
+	)
+	g.Should(be.ErrorEqual(
+		err,
+		"command-succeeds (docker info) cannot be defined with value or command",
+	))
+}
+
+func TestValue_UnmarshalYAML_cache_without_command(t *testing.T) {
+	g := ghost.New(t)
+
+	var v Value
+	err := yaml.UnmarshalStrict([]byte(`{value: "example", cache: false}`), &v)
+	g.Should(be.ErrorEqual(err, "cache can only be set with command or command-succeeds"))
+}
+
+func TestParseComplete_caches_command_defaults(t *testing.T) {
+	tests := []struct {
+		name  string
+		cache string
+		want  int
+	}{
+		{"cached by default", "", 1},
+		{"cache enabled", "cache: true", 1},
+		{"cache disabled", "cache: false", 2},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			g := ghost.New(t)
+
+			dir := xtesting.UseTempDir(t)
+
+			cfgText := `
+tasks:
+  inner:
+    options:
+      revision:
+        default:
+          command: echo run >> count.txt && echo abc123
+          ` + tt.cache + `
+    run: echo ${revision}
+  outer:
+    run:
+      - task: inner
+      - task: inner
+`
+
+			_, err := ParseComplete(&ParseConfig{
+				CfgPath:  filepath.Join(dir, "tusk.yml"),
+				CfgText:  []byte(cfgText),
+				TaskName: "outer",
+			})
+			g.NoError(err)
+
+			out, err := os.ReadFile("count.txt")
+			g.NoError(err)
+			g.Should(be.Equal(strings.Count(string(out), "run"), tt.want))
+		})
+	}
+}
@@ -0,0 +1,267 @@
+package runner
+
+import (
+	"cmp"
+	"errors"
+	"fmt"
+	"regexp"
+	"slices"
+	"strconv"
+	"strings"
+)
+
+// Version is a condition that passes when a version satisfies a constraint.
+// The version is either the output of a command or a value.
+type Version struct {
+	// Command is run to find the version, using its standard output.
+	Command string `yaml:"command,omitempty"`
+
+	// Value is the version to check.
+	Value *string `yaml:"value,omitempty"`
+
+	// Strip is a regular expression for text to remove from the version before
+	// it is parsed, such as a tool name prefix.
+	Strip string `yaml:"strip,omitempty"`
+
+	// Constraint is the set of comparisons the version must satisfy.
+	Constraint string `yaml:"constraint"`
+}
+
+// UnmarshalYAML ensures the condition is valid.
+func (v *Version) UnmarshalYAML(unmarshal func(any) error) error {
+	type versionType Version // Use new type to avoid recursion
+	if err := unmarshal((*versionType)(v)); err != nil {
+		return err
+	}
+
+	if (v.Command == "") == (v.Value == nil) {
+		return errors.New("version must specify exactly one of command or value")
+	}
+
+	if strings.TrimSpace(v.Constraint) == "" {
+		return errors.New("version must specify a constraint")
+	}
+
+	if _, err := regexp.Compile(v.Strip); err != nil {
+		return fmt.Errorf("version strip pattern: %w", err)
+	}
+
+	return nil
+}
+
+func (w *When) validateVersion(ctx Context) error {
+	if w.Version == nil {
+		return newUnspecifiedError("version")
+	}
+
+	v := w.Version
+	text, err := v.text(ctx)
+	if err != nil {
+		return err
+	}
+
+	actual, err := parseSemver(text)
+	if err != nil {
+		return fmt.Errorf("version %q: %w", text, err)
+	}
+
+	constraint, err := parseVersionConstraint(v.Constraint)
+	if err != nil {
+		return fmt.Errorf("version constraint %q: %w", v.Constraint, err)
+	}
+
+	if !constraint.allows(actual) {
+		return newCondFailErrorf("version %s does not satisfy: %s", text, v.Constraint)
+	}
+
+	return nil
+}
+
+// text returns the version to check, with the strip pattern removed.
+func (v *Version) text(ctx Context) (string, error) {
+	var text string
+	if v.Value != nil {
+		text = *v.Value
+	} else {
+		command := Command{Exec: v.Command, Print: v.Command}
+
+		out, err := command.output(ctx)
+		if err != nil {
+			return "", fmt.Errorf("version command %q: %w", v.Command, err)
+		}
+		text = out
+	}
+
+	if v.Strip != "" {
+		re, err := regexp.Compile(v.Strip)
+		if err != nil {
+			return "", fmt.Errorf("version strip pattern: %w", err)
+		}
+		text = re.ReplaceAllString(text, "")
+	}
+
+	return strings.TrimSpace(text), nil
+}
+
+// semverPattern matches a semantic version, where the minor and patch versions
+// are optional. Any leading letters, such as "v" or "go", are allowed.
+var semverPattern = regexp.MustCompile(
+	`^[A-Za-z]*(\d+)(?:\.(\d+))?(?:\.(\d+))?(?:-([0-9A-Za-z.-]+))?(?:\+[0-9A-Za-z.-]+)?$`,
+)
+
+// semver is a parsed semantic version. Build metadata is ignored.
+type semver struct {
+	release    [3]uint64
+	prerelease []string
+}
+
+func parseSemver(s string) (semver, error) {
+	match := semverPattern.FindStringSubmatch(s)
+	if match == nil {
+		return semver{}, errors.New("not a valid semantic version")
+	}
+
+	var v semver
+	for i, part := range match[1:4] {
+		if part == "" {
+			continue
+		}
+
+		n, err := strconv.ParseUint(part, 10, 64)
+		if err != nil {
+			return semver{}, err
+		}
+		v.release[i] = n
+	}
+
+	if match[4] != "" {
+		v.prerelease = strings.Split(match[4], ".")
+	}
+
+	return v, nil
+}
+
+// compare returns -1, 0, or 1 depending on whether v is lower than, equal to,
+// or greater than other, following semantic versioning precedence.
+func (v semver) compare(other semver) int {
+	if c := slices.Compare(v.release[:], other.release[:]); c != 0 {
+		return c
+	}
+
+	// A version without a prerelease has higher precedence.
+	switch {
+	case len(v.prerelease) == 0 && len(other.prerelease) == 0:
+		return 0
+	case len(v.prerelease) == 0:
+		return 1
+	case len(other.prerelease) == 0:
+		return -1
+	}
+
+	for i := range min(len(v.prerelease), len(other.prerelease)) {
+		if c := comparePrerelease(v.prerelease[i], other.prerelease[i]); c != 0 {
+			return c
+		}
+	}
+
+	return cmp.Compare(len(v.prerelease), len(other.prerelease))
+}
+
+// comparePrerelease compares prerelease identifiers. Numeric identifiers are
+// compared numerically and have lower precedence than alphanumeric ones.
+func comparePrerelease(a, b string) int {
+	aNum, aErr := strconv.ParseUint(a, 10, 64)
+	bNum, bErr := strconv.ParseUint(b, 10, 64)
+	switch {
+	case aErr == nil && bErr == nil:
+		return cmp.Compare(aNum, bNum)
+	case aErr == nil:
+		return -1
+	case bErr == nil:
+		return 1
+	default:
+		return strings.Compare(a, b)
+	}
+}
+
+// versionConstraint is a set of alternatives, of which at least one must be
+// satisfied. Each alternative is a set of comparisons that must all pass.
+type versionConstraint [][]versionComparison
+
+type versionComparison struct {
+	operator string
+	version  semver
+}
+
+func parseVersionConstraint(s string) (versionConstraint, error) {
+	var constraint versionConstraint
+	for _, alternative := range strings.Split(s, "||") {
+		comparisons, err := parseVersionComparisons(strings.Fields(alternative))
+		if err != nil {
+			return nil, err
+		}
+		constraint = append(constraint, comparisons)
+	}
+
+	return constraint, nil
+}
+
+// parseVersionComparisons parses comparisons such as ">=1.2" or ">= 1.2".
+func parseVersionComparisons(fields []string) ([]versionComparison, error) {
+	if len(fields) == 0 {
+		return nil, errors.New("empty comparison")
+	}
+
+	var comparisons []versionComparison
+	for i := 0; i < len(fields); i++ {
+		text := strings.TrimLeft(fields[i], "<>=!")
+		operator := fields[i][:len(fields[i])-len(text)]
+		if text == "" && i+1 < len(fields) {
+			i++
+			text = fields[i]
+		}
+
+		if !slices.Contains([]string{"", "=", "==", "!=", "<", "<=", ">", ">="}, operator) {
+			return nil, fmt.Errorf("unknown operator %q", operator)
+		}
+
+		version, err := parseSemver(text)
+		if err != nil {
+			return nil, fmt.Errorf("%q: %w", text, err)
+		}
+
+		comparisons = append(comparisons, versionComparison{operator, version})
+	}
+
+	return comparisons, nil
+}
+
+// allows returns whether a version satisfies the constraint.
+func (c versionConstraint) allows(v semver) bool {
+	return slices.ContainsFunc(c, func(comparisons []versionComparison) bool {
+		for _, comparison := range comparisons {
+			if !comparison.allows(v) {
+				return false
+			}
+		}
+		return true
+	})
+}
+
+func (c versionComparison) allows(v semver) bool {
+	result := v.compare(c.version)
+	switch c.operator {
+	case "!=":
+		return result != 0
+	case "<":
+		return result < 0
+	case "<=":
+		return result <= 0
+	case ">":
+		return result > 0
+	case ">=":
+		return result >= 0
+	default:
+		return result == 0
+	}
+}
@@ -0,0 +1,186 @@
+package runner
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/rliebz/ghost"
+	"github.com/rliebz/ghost/be"
+	yaml "gopkg.in/yaml.v2"
+
+	"github.com/rliebz/tusk/ui"
+)
+
+func TestVersion_UnmarshalYAML(t *testing.T) {
+	value := "v1.2.3"
+
+	tests := []struct {
+		name    string
+		input   string
+		want    Version
+		wantErr string
+	}{
+		{
+			name:  "command",
+			input: `{command: go env GOVERSION, strip: ^go, constraint: ">= 1.22"}`,
+			want: Version{
+				Command:    "go env GOVERSION",
+				Strip:      "^go",
+				Constraint: ">= 1.22",
+			},
+		},
+		{
+			name:  "value",
+			input: `{value: v1.2.3, constraint: ">=1"}`,
+			want:  Version{Value: &value, Constraint: ">=1"},
+		},
+		{
+			name:    "command and value",
+			input:   `{command: echo 1, value: "1", constraint: ">1"}`,
+			wantErr: "version must specify exactly one of command or value",
+		},
+		{
+			name:    "neither command nor value",
+			input:   `{constraint: ">1"}`,
+			wantErr: "version must specify exactly one of command or value",
+		},
+		{
+			name:    "no constraint",
+			input:   `{value: "1"}`,
+			wantErr: "version must specify a constraint",
+		},
+		{
+			name:    "invalid strip pattern",
+			input:   `{value: "1", strip: "(", constraint: ">1"}`,
+			wantErr: "version strip pattern: error parsing regexp: missing closing ): `(`",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			g := ghost.New(t)
+
+			var got Version
+			err := yaml.UnmarshalStrict([]byte(tt.input), &got)
+			if tt.wantErr != "" {
+				g.Should(be.ErrorEqual(err, tt.wantErr))
+				return
+			}
+			g.NoError(err)
+
+			g.Should(be.DeepEqual(got, tt.want))
+		})
+	}
+}
+
+func TestWhen_Validate_version(t *testing.T) {
+	tests := []struct {
+		name       string
+		value      string
+		strip      string
+		constraint string
+		shouldRun  bool
+	}{
+		{"equal", "1.2.3", "", "1.2.3", true},
+		{"explicit equal", "1.2.3", "", "= 1.2.3", true},
+		{"not equal", "1.2.3", "", "!=1.2.3", false},
+		{"leading v", "v1.2.3", "", "== v1.2.3", true},
+		{"missing patch", "1.22", "", ">= 1.22.0", true},
+		{"greater", "1.22.1", "", ">1.22", true},
+		{"not greater", "1.22.0", "", ">1.22", false},
+		{"less", "1.9.0", "", "<1.10", true},
+		{"range", "2.5.0", "", ">=2.1.0 <3", true},
+		{"outside range", "3.0.0", "", ">=2.1.0 <3", false},
+		{"alternative", "1.5.0", "", "<1 || >=1.5", true},
+		{"prerelease before release", "1.0.0-rc.1", "", "<1.0.0", true},
+		{"numeric prerelease", "1.0.0-rc.2", "", "<1.0.0-rc.10", true},
+		{"numeric before alphanumeric prerelease", "1.0.0-1", "", "<1.0.0-alpha", true},
+		{"shorter prerelease first", "1.0.0-alpha", "", "<1.0.0-alpha.1", true},
+		{"build metadata ignored", "1.0.0+abc", "", "=1.0.0", true},
+		{"strip prefix", "go1.22.3", "^go", ">= 1.22", true},
+		{"letter prefix", "go1.22.3", "", ">= go1.22", true},
+		{"letter prefix too low", "go1.21.9", "", ">= go1.22", false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			g := ghost.New(t)
+
+			w := When{Version: &Version{
+				Value:      &tt.value,
+				Strip:      tt.strip,
+				Constraint: tt.constraint,
+			}}
+			err := w.Validate(Context{}, nil)
+			if tt.shouldRun {
+				g.NoError(err)
+				return
+			}
+			g.Should(be.True(IsFailedCondition(err)))
+		})
+	}
+}
+
+func TestWhen_Validate_version_command(t *testing.T) {
+	g := ghost.New(t)
+
+	stderr := new(bytes.Buffer)
+	logger := ui.New(ui.Config{Stdout: new(bytes.Buffer), Stderr: stderr})
+
+	w := When{Version: &Version{
+		Command:    "echo tool version 2.4.1; echo checking >&2",
+		Strip:      "^tool version ",
+		Constraint: ">=2 <3",
+	}}
+	err := w.Validate(Context{Logger: logger}, nil)
+	g.NoError(err)
+
+	g.Should(be.Equal(stderr.String(), "checking\n"))
+}
+
+func TestWhen_Validate_version_errors(t *testing.T) {
+	value := "1.2.3"
+	invalid := "latest"
+
+	tests := []struct {
+		name    string
+		version Version
+		wantErr string
+	}{
+		{
+			name:    "invalid version",
+			version: Version{Value: &invalid, Constraint: ">1"},
+			wantErr: `version "latest": not a valid semantic version`,
+		},
+		{
+			name:    "invalid constraint version",
+			version: Version{Value: &value, Constraint: ">= 1.x"},
+			wantErr: `version constraint ">= 1.x": "1.x": not a valid semantic version`,
+		},
+		{
+			name:    "unknown operator",
+			version: Version{Value: &value, Constraint: "=> 1.0"},
+			wantErr: `version constraint "=> 1.0": unknown operator "=>"`,
+		},
+		{
+			name:    "empty alternative",
+			version: Version{Value: &value, Constraint: ">1 ||"},
+			wantErr: `version constraint ">1 ||": empty comparison`,
+		},
+		{
+			name:    "failed command",
+			version: Version{Command: "exit 1", Constraint: ">1"},
+			wantErr: `version command "exit 1": exit status 1`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			g := ghost.New(t)
+
+			w := When{Version: &tt.version}
+			err := w.Validate(Context{Logger: ui.Noop()}, nil)
+			g.Should(be.ErrorEqual(err, tt.wantErr))
+		})
+	}
+}
@@ -0,0 +1,116 @@
+package runner
+
+import (
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/rliebz/tusk/marshal"
+)
+
+// waitProgressInterval is how often progress is printed during a long wait.
+// It can be overwritten during tests.
+var waitProgressInterval = 5 * time.Second
+
+// Wait is a run item that pauses for a fixed duration or until a probe
+// succeeds, without depending on the tools available on the host.
+type Wait struct {
+	// Duration is how long to pause for.
+	Duration time.Duration `yaml:"duration,omitempty"`
+
+	// WaitFor is the probe that must succeed.
+	WaitFor WaitFor `yaml:",inline"`
+}
+
+// UnmarshalYAML allows a duration to be used as a short form.
+func (w *Wait) UnmarshalYAML(unmarshal func(any) error) error {
+	var duration time.Duration
+	durationCandidate := marshal.UnmarshalCandidate{
+		Unmarshal: func() error { return unmarshal(&duration) },
+		Validate:  func() error { return validateWaitDuration(duration) },
+		Assign:    func() { *w = Wait{Duration: duration} },
+	}
+
+	var waitItem struct {
+		Duration time.Duration `yaml:"duration,omitempty"`
+		Address  string        `yaml:"address,omitempty"`
+		URL      string        `yaml:"url,omitempty"`
+		Timeout  time.Duration `yaml:"timeout,omitempty"`
+		Interval time.Duration `yaml:"interval,omitempty"`
+	}
+	waitCandidate := marshal.UnmarshalCandidate{
+		Unmarshal: func() error { return unmarshal(&waitItem) },
+		Validate: func() error {
+			probe := waitItem.Address != "" || waitItem.URL != ""
+			switch {
+			case waitItem.Duration != 0 && probe:
+				return errors.New("wait cannot specify both a duration and an address or url")
+			case waitItem.Duration != 0:
+				if waitItem.Timeout != 0 || waitItem.Interval != 0 {
+					return errors.New("wait timeout and interval require an address or url")
+				}
+				return validateWaitDuration(waitItem.Duration)
+			case waitItem.Address != "" && waitItem.URL != "":
+				return errors.New("wait must specify only one of address or url")
+			case !probe:
+				return errors.New("wait must specify a duration, address, or url")
+			case waitItem.Timeout < 0 || waitItem.Interval < 0:
+				return errors.New("wait timeout and interval cannot be negative")
+			default:
+				return nil
+			}
+		},
+		Assign: func() {
+			*w = Wait{
+				Duration: waitItem.Duration,
+				WaitFor: WaitFor{
+					Address:  waitItem.Address,
+					URL:      waitItem.URL,
+					Timeout:  waitItem.Timeout,
+					Interval: waitItem.Interval,
+				},
+			}
+		},
+	}
+
+	return marshal.UnmarshalOneOf(durationCandidate, waitCandidate)
+}
+
+func validateWaitDuration(d time.Duration) error {
+	if d <= 0 {
+		return errors.New("wait duration must be positive")
+	}
+	return nil
+}
+
+// String describes the wait for output.
+func (w *Wait) String() string {
+	if w.Duration != 0 {
+		return fmt.Sprintf("wait %s", w.Duration)
+	}
+	return fmt.Sprintf("wait for %s", w.WaitFor.target())
+}
+
+// run pauses until the wait has finished.
+func (w *Wait) run(ctx Context) error {
+	if w.Duration == 0 {
+		return w.WaitFor.wait(ctx, nil)
+	}
+
+	start := time.Now()
+	done := time.After(w.Duration)
+	progress := time.NewTicker(waitProgressInterval)
+	defer progress.Stop()
+
+	for {
+		select {
+		case <-done:
+			return nil
+		case <-progress.C:
+			remaining := w.Duration - time.Since(start)
+			ctx.Logger.Info(fmt.Sprintf(
+				"Waiting %s (%s remaining)", w.Duration, remaining.Round(time.Second),
+			))
+		}
+	}
+}
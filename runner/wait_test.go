@@ -0,0 +1,189 @@
+package runner
+
+import (
+	"bytes"
+	"net"
+	"testing"
+	"time"
+
+	"github.com/rliebz/ghost"
+	"github.com/rliebz/ghost/be"
+	yaml "gopkg.in/yaml.v2"
+
+	"github.com/rliebz/tusk/marshal"
+	"github.com/rliebz/tusk/ui"
+)
+
+func TestWait_UnmarshalYAML(t *testing.T) {
+	tests := []struct {
+		name  string
+		input string
+		want  Wait
+	}{
+		{
+			name:  "short form",
+			input: `5s`,
+			want:  Wait{Duration: 5 * time.Second},
+		},
+		{
+			name:  "duration",
+			input: `{duration: 1m}`,
+			want:  Wait{Duration: time.Minute},
+		},
+		{
+			name:  "address",
+			input: `{address: "localhost:5432", timeout: 30s, interval: 1s}`,
+			want: Wait{WaitFor: WaitFor{
+				Address:  "localhost:5432",
+				Timeout:  30 * time.Second,
+				Interval: time.Second,
+			}},
+		},
+		{
+			name:  "url",
+			input: `{url: "http://localhost:8080/health"}`,
+			want:  Wait{WaitFor: WaitFor{URL: "http://localhost:8080/health"}},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			g := ghost.New(t)
+
+			var got Wait
+			err := yaml.UnmarshalStrict([]byte(tt.input), &got)
+			g.NoError(err)
+
+			g.Should(be.Equal(got, tt.want))
+		})
+	}
+}
+
+func TestWait_UnmarshalYAML_invalid(t *testing.T) {
+	tests := []struct {
+		name    string
+		input   string
+		wantErr string
+	}{
+		{
+			name:    "empty",
+			input:   `{}`,
+			wantErr: "wait must specify a duration, address, or url",
+		},
+		{
+			name:    "zero duration",
+			input:   `0s`,
+			wantErr: "wait duration must be positive",
+		},
+		{
+			name:    "duration and address",
+			input:   `{duration: 5s, address: "localhost:5432"}`,
+			wantErr: "wait cannot specify both a duration and an address or url",
+		},
+		{
+			name:    "duration and timeout",
+			input:   `{duration: 5s, timeout: 10s}`,
+			wantErr: "wait timeout and interval require an address or url",
+		},
+		{
+			name:    "address and url",
+			input:   `{address: "localhost:5432", url: "http://localhost"}`,
+			wantErr: "wait must specify only one of address or url",
+		},
+		{
+			name:    "negative interval",
+			input:   `{address: "localhost:5432", interval: -1s}`,
+			wantErr: "wait timeout and interval cannot be negative",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			g := ghost.New(t)
+
+			var got Wait
+			err := yaml.UnmarshalStrict([]byte(tt.input), &got)
+			g.Should(be.ErrorEqual(err, tt.wantErr))
+		})
+	}
+}
+
+func TestTask_Execute_wait(t *testing.T) {
+	var lc net.ListenConfig
+	listener, err := lc.Listen(t.Context(), "tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { listener.Close() }) //nolint:errcheck
+	open := listener.Addr().String()
+
+	unused, err := lc.Listen(t.Context(), "tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	closed := unused.Addr().String()
+	unused.Close() //nolint:errcheck
+
+	tests := []struct {
+		name    string
+		wait    Wait
+		wantErr string
+	}{
+		{
+			name: "duration",
+			wait: Wait{Duration: 10 * time.Millisecond},
+		},
+		{
+			name: "ready",
+			wait: Wait{WaitFor: WaitFor{Address: open}},
+		},
+		{
+			name: "timeout",
+			wait: Wait{WaitFor: WaitFor{
+				Address:  closed,
+				Timeout:  200 * time.Millisecond,
+				Interval: 50 * time.Millisecond,
+			}},
+			wantErr: "timed out after 200ms waiting for " + closed,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			g := ghost.New(t)
+
+			task := Task{
+				Name:    "foo",
+				RunList: marshal.Slice[*Run]{{Wait: &tt.wait}},
+			}
+
+			err := task.Execute(Context{Logger: ui.Noop()})
+			if tt.wantErr != "" {
+				g.Should(be.ErrorEqual(err, tt.wantErr))
+				return
+			}
+			g.NoError(err)
+		})
+	}
+}
+
+func TestTask_Execute_wait_progress(t *testing.T) {
+	g := ghost.New(t)
+
+	t.Cleanup(func() { waitProgressInterval = 5 * time.Second })
+	waitProgressInterval = 20 * time.Millisecond
+
+	stderr := new(bytes.Buffer)
+	logger := ui.New(ui.Config{Stderr: stderr})
+
+	task := Task{
+		Name:    "foo",
+		RunList: marshal.Slice[*Run]{{Wait: &Wait{Duration: 100 * time.Millisecond}}},
+	}
+
+	err := task.Execute(Context{Logger: logger})
+	g.NoError(err)
+
+	g.Should(be.StringContaining(stderr.String(), "foo $ wait 100ms\n"))
+	g.Should(be.StringContaining(stderr.String(), "Waiting 100ms ("))
+}
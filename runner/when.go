@@ -1,11 +1,16 @@
 package runner
 
 import (
+	"cmp"
 	"errors"
 	"fmt"
+	"maps"
 	"os"
 	"path/filepath"
+	"regexp"
 	"runtime"
+	"slices"
+	"strconv"
 	"strings"
 
 	yaml "gopkg.in/yaml.v2"
@@ -15,14 +20,43 @@ import (
 
 // When defines the conditions for running a task.
 type When struct {
-	Command   marshal.Slice[string] `yaml:",omitempty"`
-	Exists    marshal.Slice[string] `yaml:",omitempty"`
-	NotExists marshal.Slice[string] `yaml:"not-exists,omitempty"`
-	OS        marshal.Slice[string] `yaml:",omitempty"`
+	Command    marshal.Slice[string] `yaml:",omitempty"`
+	Exists     marshal.Slice[string] `yaml:",omitempty"`
+	ExistsDir  marshal.Slice[string] `yaml:"exists-dir,omitempty"`
+	ExistsFile marshal.Slice[string] `yaml:"exists-file,omitempty"`
+	NotExists  marshal.Slice[string] `yaml:"not-exists,omitempty"`
+	OS         marshal.Slice[string] `yaml:",omitempty"`
 
 	Environment map[string]marshal.Slice[*string] `yaml:",omitempty"`
+	EnvMatches  map[string]marshal.Slice[string]  `yaml:"env-matches,omitempty"`
 	Equal       map[string]marshal.Slice[string]  `yaml:",omitempty"`
 	NotEqual    map[string]marshal.Slice[string]  `yaml:"not-equal,omitempty"`
+
+	// Numeric comparisons parse both the option and the values as numbers.
+	GreaterThan    map[string]marshal.Slice[string] `yaml:"greater-than,omitempty"`
+	GreaterOrEqual map[string]marshal.Slice[string] `yaml:"greater-or-equal,omitempty"`
+	LessThan       map[string]marshal.Slice[string] `yaml:"less-than,omitempty"`
+	LessOrEqual    map[string]marshal.Slice[string] `yaml:"less-or-equal,omitempty"`
+
+	ChangedFiles *ChangedFiles `yaml:"changed-files,omitempty"`
+	FileContains *FileContains `yaml:"file-contains,omitempty"`
+	Version      *Version      `yaml:"version,omitempty"`
+
+	// Failed and Succeeded check the outcome of the task, and can only be used
+	// within a finally clause.
+	Failed    *bool `yaml:",omitempty"`
+	Succeeded *bool `yaml:",omitempty"`
+
+	// Result checks the result of named items of the run list, which is one of
+	// succeeded, failed, skipped, or not-run. It can only be used within a
+	// finally clause.
+	Result map[string]marshal.Slice[string] `yaml:",omitempty"`
+
+	// AllOf, AnyOf, and Not combine nested when items, which passes when every
+	// item passes, when any item passes, or when the item fails.
+	AllOf WhenList `yaml:"all-of,omitempty"`
+	AnyOf WhenList `yaml:"any-of,omitempty"`
+	Not   *When    `yaml:",omitempty"`
 }
 
 // UnmarshalYAML warns about deprecated features.
@@ -54,6 +88,7 @@ func (w *When) UnmarshalYAML(unmarshal func(any) error) error {
 
 			return nil
 		},
+		Validate: func() error { return validateEnvPatterns(whenItem.EnvMatches) },
 		Assign: func() {
 			*w = When(whenItem)
 			fixNilEnvironment(w, ms)
@@ -82,6 +117,20 @@ func fixNilEnvironment(w *When, ms yaml.MapSlice) {
 	}
 }
 
+// validateEnvPatterns ensures that every env-matches pattern is a valid regular
+// expression, so that mistakes are found before anything runs.
+func validateEnvPatterns(envMatches map[string]marshal.Slice[string]) error {
+	for _, name := range slices.Sorted(maps.Keys(envMatches)) {
+		for _, pattern := range envMatches[name] {
+			if _, err := regexp.Compile(pattern); err != nil {
+				return fmt.Errorf("env-matches pattern for %q: %w", name, err)
+			}
+		}
+	}
+
+	return nil
+}
+
 // Dependencies returns a list of options that are required explicitly.
 // This does not include interpolations.
 func (w *When) Dependencies() []string {
@@ -92,10 +141,24 @@ func (w *When) Dependencies() []string {
 	// Use a map to prevent duplicates
 	references := make(map[string]struct{})
 
-	for opt := range w.Equal {
-		references[opt] = struct{}{}
+	for _, cases := range []map[string]marshal.Slice[string]{
+		w.Equal,
+		w.NotEqual,
+		w.GreaterThan,
+		w.GreaterOrEqual,
+		w.LessThan,
+		w.LessOrEqual,
+	} {
+		for opt := range cases {
+			references[opt] = struct{}{}
+		}
 	}
-	for opt := range w.NotEqual {
+
+	for _, opt := range slices.Concat(
+		w.AllOf.Dependencies(),
+		w.AnyOf.Dependencies(),
+		w.Not.Dependencies(),
+	) {
 		references[opt] = struct{}{}
 	}
 
@@ -113,15 +176,53 @@ func (w *When) Validate(ctx Context, vars map[string]string) error {
 		return nil
 	}
 
-	return validateAny(
-		w.validateOS(),
-		w.validateEqual(vars),
-		w.validateNotEqual(vars),
-		w.validateEnv(),
-		w.validateExists(ctx),
-		w.validateNotExists(ctx),
-		w.validateCommand(ctx),
-	)
+	clauses := w.check(ctx, vars)
+	errs := make([]error, 0, len(clauses))
+	for _, c := range clauses {
+		errs = append(errs, c.err)
+	}
+
+	return validateAny(errs...)
+}
+
+// whenClause is the outcome of checking a single clause of a when item.
+type whenClause struct {
+	name string
+	err  error
+}
+
+// check checks every clause of the when item, leaving out the clauses that
+// are not specified.
+func (w *When) check(ctx Context, vars map[string]string) []whenClause {
+	clauses := []whenClause{
+		{"os", w.validateOS()},
+		{"equal", w.validateEqual(vars)},
+		{"not-equal", w.validateNotEqual(vars)},
+		{"greater-than", w.validateGreaterThan(vars)},
+		{"greater-or-equal", w.validateGreaterOrEqual(vars)},
+		{"less-than", w.validateLessThan(vars)},
+		{"less-or-equal", w.validateLessOrEqual(vars)},
+		{"environment", w.validateEnv()},
+		{"env-matches", w.validateEnvMatches()},
+		{"exists", w.validateExists(ctx)},
+		{"exists-dir", w.validateExistsDir(ctx)},
+		{"exists-file", w.validateExistsFile(ctx)},
+		{"not-exists", w.validateNotExists(ctx)},
+		{"command", w.validateCommand(ctx)},
+		{"changed-files", w.validateChangedFiles(ctx)},
+		{"file-contains", w.validateFileContains(ctx)},
+		{"version", w.validateVersion(ctx)},
+		{"failed", w.validateFailed(ctx)},
+		{"succeeded", w.validateSucceeded(ctx)},
+		{"result", w.validateResult(ctx)},
+		{"all-of", w.validateAllOf(ctx, vars)},
+		{"any-of", w.validateAnyOf(ctx, vars)},
+		{"not", w.validateNot(ctx, vars)},
+	}
+
+	return slices.DeleteFunc(clauses, func(c whenClause) bool {
+		return IsUnspecifiedClause(c.err)
+	})
 }
 
 // TODO: Should this be done in parallel?
@@ -140,6 +241,48 @@ func validateAny(errs ...error) error {
 	return errOutput
 }
 
+func (w *When) validateAllOf(ctx Context, vars map[string]string) error {
+	if len(w.AllOf) == 0 {
+		return newUnspecifiedError("all-of")
+	}
+
+	return w.AllOf.Validate(ctx, vars)
+}
+
+func (w *When) validateAnyOf(ctx Context, vars map[string]string) error {
+	if len(w.AnyOf) == 0 {
+		return newUnspecifiedError("any-of")
+	}
+
+	for _, item := range w.AnyOf {
+		err := item.Validate(ctx, vars)
+		if err == nil {
+			return nil
+		}
+		if !IsFailedCondition(err) {
+			return err
+		}
+	}
+
+	return newCondFailError("no conditions in any-of passed")
+}
+
+func (w *When) validateNot(ctx Context, vars map[string]string) error {
+	if w.Not == nil {
+		return newUnspecifiedError("not")
+	}
+
+	err := w.Not.Validate(ctx, vars)
+	switch {
+	case err == nil:
+		return newCondFailError("condition in not passed")
+	case IsFailedCondition(err):
+		return nil
+	default:
+		return err
+	}
+}
+
 func (w *When) validateCommand(ctx Context) error {
 	if len(w.Command) == 0 {
 		return newUnspecifiedError("command")
@@ -160,34 +303,146 @@ func (w *When) validateExists(ctx Context) error {
 	}
 
 	for _, f := range w.Exists {
-		if _, err := os.Stat(filepath.Join(ctx.Dir(), f)); err != nil {
-			if !errors.Is(err, os.ErrNotExist) {
-				return err
-			}
-			continue
+		info, err := statPath(ctx, f)
+		if err != nil {
+			return err
 		}
 
-		return nil
+		if info != nil {
+			return nil
+		}
 	}
 
 	return newCondFailErrorf("no required file exists: %s", w.Exists)
 }
 
+func (w *When) validateExistsDir(ctx Context) error {
+	if len(w.ExistsDir) == 0 {
+		return newUnspecifiedError("exists-dir")
+	}
+
+	for _, f := range w.ExistsDir {
+		info, err := statPath(ctx, f)
+		if err != nil {
+			return err
+		}
+
+		if info == nil || !info.IsDir() {
+			return newCondFailErrorf("required directory does not exist: %s", f)
+		}
+	}
+
+	return nil
+}
+
+func (w *When) validateExistsFile(ctx Context) error {
+	if len(w.ExistsFile) == 0 {
+		return newUnspecifiedError("exists-file")
+	}
+
+	for _, f := range w.ExistsFile {
+		info, err := statPath(ctx, f)
+		if err != nil {
+			return err
+		}
+
+		if info == nil || !info.Mode().IsRegular() {
+			return newCondFailErrorf("required regular file does not exist: %s", f)
+		}
+	}
+
+	return nil
+}
+
 func (w *When) validateNotExists(ctx Context) error {
 	if len(w.NotExists) == 0 {
 		return newUnspecifiedError("not-exists")
 	}
 
 	for _, f := range w.NotExists {
-		if _, err := os.Stat(filepath.Join(ctx.Dir(), f)); err != nil {
-			if errors.Is(err, os.ErrNotExist) {
-				return nil
-			}
+		info, err := statPath(ctx, f)
+		if err != nil {
 			return err
 		}
+
+		if info != nil {
+			return newCondFailErrorf("file exists: %s", f)
+		}
 	}
 
-	return newCondFailErrorf("all files exist: %s", w.NotExists)
+	return nil
+}
+
+// statPath returns information about a path relative to the config file. If
+// the path does not exist, the result is nil. Other problems, such as a lack
+// of permission, are returned as errors rather than treated as missing paths.
+func statPath(ctx Context, path string) (os.FileInfo, error) {
+	info, err := os.Stat(filepath.Join(ctx.Dir(), path))
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("could not check whether %s exists: %w", path, err)
+	}
+
+	return info, nil
+}
+
+func (w *When) validateFailed(ctx Context) error {
+	if w.Failed == nil {
+		return newUnspecifiedError("failed")
+	}
+
+	return validateOutcome(ctx, "failed", *w.Failed)
+}
+
+func (w *When) validateSucceeded(ctx Context) error {
+	if w.Succeeded == nil {
+		return newUnspecifiedError("succeeded")
+	}
+
+	return validateOutcome(ctx, "succeeded", !*w.Succeeded)
+}
+
+func (w *When) validateResult(ctx Context) error {
+	if len(w.Result) == 0 {
+		return newUnspecifiedError("result")
+	}
+
+	if ctx.taskErr == nil {
+		return errors.New("when clause `result` can only be used in finally")
+	}
+
+	for _, name := range slices.Sorted(maps.Keys(w.Result)) {
+		if err := validateOneOf(
+			fmt.Sprintf("run item %q", name),
+			ctx.results[name],
+			w.Result[name],
+			func(a, b string) bool { return a == b },
+		); err == nil {
+			return nil
+		}
+	}
+
+	return newCondFailError("no run item results matched")
+}
+
+// validateOutcome checks whether the task whose finally clause is running has
+// failed as expected.
+func validateOutcome(ctx Context, clause string, wantFailed bool) error {
+	if ctx.taskErr == nil {
+		return fmt.Errorf("when clause `%s` can only be used in finally", clause)
+	}
+
+	failed := *ctx.taskErr != nil
+	switch {
+	case failed == wantFailed:
+		return nil
+	case failed:
+		return newCondFailError("task failed")
+	default:
+		return newCondFailError("task did not fail")
+	}
 }
 
 func (w *When) validateOS() error {
@@ -217,6 +472,33 @@ func (w *When) validateEnv() error {
 	return newCondFailError("no environment variables matched")
 }
 
+func (w *When) validateEnvMatches() error {
+	if len(w.EnvMatches) == 0 {
+		return newUnspecifiedError("env-matches")
+	}
+
+	for _, name := range slices.Sorted(maps.Keys(w.EnvMatches)) {
+		// Unset variables never match, even if a pattern matches empty strings.
+		value, ok := os.LookupEnv(name)
+		if !ok {
+			continue
+		}
+
+		for _, pattern := range w.EnvMatches[name] {
+			re, err := regexp.Compile(pattern)
+			if err != nil {
+				return fmt.Errorf("env-matches pattern for %q: %w", name, err)
+			}
+
+			if re.MatchString(value) {
+				return nil
+			}
+		}
+	}
+
+	return newCondFailError("no environment variables matched a pattern")
+}
+
 func (w *When) isEnvVarValid(varName string, values marshal.Slice[*string]) bool {
 	stringValues := make([]string, 0, len(values))
 	for _, value := range values {
@@ -261,6 +543,92 @@ func (w *When) validateNotEqual(vars map[string]string) error {
 	})
 }
 
+func (w *When) validateGreaterThan(vars map[string]string) error {
+	if len(w.GreaterThan) == 0 {
+		return newUnspecifiedError("greater-than")
+	}
+
+	return validateComparison(vars, "greater-than", w.GreaterThan, func(c int) bool {
+		return c > 0
+	})
+}
+
+func (w *When) validateGreaterOrEqual(vars map[string]string) error {
+	if len(w.GreaterOrEqual) == 0 {
+		return newUnspecifiedError("greater-or-equal")
+	}
+
+	return validateComparison(vars, "greater-or-equal", w.GreaterOrEqual, func(c int) bool {
+		return c >= 0
+	})
+}
+
+func (w *When) validateLessThan(vars map[string]string) error {
+	if len(w.LessThan) == 0 {
+		return newUnspecifiedError("less-than")
+	}
+
+	return validateComparison(vars, "less-than", w.LessThan, func(c int) bool {
+		return c < 0
+	})
+}
+
+func (w *When) validateLessOrEqual(vars map[string]string) error {
+	if len(w.LessOrEqual) == 0 {
+		return newUnspecifiedError("less-or-equal")
+	}
+
+	return validateComparison(vars, "less-or-equal", w.LessOrEqual, func(c int) bool {
+		return c <= 0
+	})
+}
+
+// validateComparison checks whether any option compares numerically to any of
+// its values as required. The compare function receives the result of comparing
+// the option's value to the expected value. Values that are not numbers are an
+// error rather than a failed condition.
+func validateComparison(
+	options map[string]string,
+	clause string,
+	cases map[string]marshal.Slice[string],
+	compare func(int) bool,
+) error {
+	for _, optionName := range slices.Sorted(maps.Keys(cases)) {
+		actual, ok := options[optionName]
+		if !ok {
+			continue
+		}
+
+		a, err := parseNumber(actual)
+		if err != nil {
+			return fmt.Errorf("%s: option %q: %w", clause, optionName, err)
+		}
+
+		for _, expected := range cases[optionName] {
+			b, err := parseNumber(expected)
+			if err != nil {
+				return fmt.Errorf("%s: value for option %q: %w", clause, optionName, err)
+			}
+
+			if compare(cmp.Compare(a, b)) {
+				return nil
+			}
+		}
+	}
+
+	return newCondFailErrorf("no options matched %s", clause)
+}
+
+// parseNumber parses an integer or floating point number.
+func parseNumber(s string) (float64, error) {
+	n, err := strconv.ParseFloat(strings.TrimSpace(s), 64)
+	if err != nil {
+		return 0, fmt.Errorf("%q is not a number", s)
+	}
+
+	return n, nil
+}
+
 func validateOneOf(
 	desc, value string, required []string, compare func(string, string) bool,
 ) error {
@@ -344,6 +712,80 @@ func (l *WhenList) Validate(ctx Context, vars map[string]string) error {
 	return nil
 }
 
+// usesOutcome checks whether any when item checks the outcome of the task.
+func (l *WhenList) usesOutcome() bool {
+	if l == nil {
+		return false
+	}
+
+	return slices.ContainsFunc(*l, func(w When) bool {
+		return w.usesOutcome()
+	})
+}
+
+// usesOutcome checks whether the when item or any nested item checks the
+// outcome of the task.
+func (w *When) usesOutcome() bool {
+	if w == nil {
+		return false
+	}
+
+	return w.Failed != nil || w.Succeeded != nil || len(w.Result) != 0 ||
+		w.AllOf.usesOutcome() || w.AnyOf.usesOutcome() || w.Not.usesOutcome()
+}
+
+// validateResults checks that every result clause refers to a named item of the
+// run list and to a result it can have.
+func (l *WhenList) validateResults(names map[string]string) error {
+	if l == nil {
+		return nil
+	}
+
+	for _, w := range *l {
+		if err := w.validateResults(names); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// validateResults checks that every result clause of the when item or any
+// nested item refers to a named item of the run list and to a result it can
+// have.
+func (w *When) validateResults(names map[string]string) error {
+	if w == nil {
+		return nil
+	}
+
+	valid := []string{resultSucceeded, resultFailed, resultSkipped, resultNotRun}
+	for _, name := range slices.Sorted(maps.Keys(w.Result)) {
+		if _, ok := names[name]; !ok {
+			return fmt.Errorf("when clause `result` refers to %q, which is not a named run item", name)
+		}
+		for _, result := range w.Result[name] {
+			if !slices.Contains(valid, result) {
+				return fmt.Errorf(
+					"when clause `result` for %q must be one of %s, got %q",
+					name, strings.Join(valid, ", "), result,
+				)
+			}
+		}
+	}
+
+	for _, err := range []error{
+		w.AllOf.validateResults(names),
+		w.AnyOf.validateResults(names),
+		w.Not.validateResults(names),
+	} {
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
 // Dependencies returns a list of options that are required explicitly.
 // This does not include interpolations.
 func (l *WhenList) Dependencies() []string {
@@ -1,6 +1,7 @@
 package runner
 
 import (
+	"errors"
 	"fmt"
 	"path/filepath"
 	"runtime"
@@ -10,9 +11,13 @@ import (
 	"github.com/rliebz/ghost"
 	"github.com/rliebz/ghost/be"
 	yaml "gopkg.in/yaml.v2"
+
+	"github.com/rliebz/tusk/marshal"
 )
 
 func TestWhen_UnmarshalYAML(t *testing.T) {
+	yes := true
+
 	tests := []struct {
 		name  string
 		input string
@@ -42,6 +47,42 @@ func TestWhen_UnmarshalYAML(t *testing.T) {
 			`not-exists: file.txt`,
 			createWhen(withWhenNotExists("file.txt")),
 		},
+		{
+			"exists-dir",
+			`exists-dir: [.venv, venv]`,
+			createWhen(withWhenExistsDir(".venv"), withWhenExistsDir("venv")),
+		},
+		{
+			"exists-file",
+			`exists-file: go.mod`,
+			createWhen(withWhenExistsFile("go.mod")),
+		},
+		{
+			"greater-than",
+			`greater-than: {parallelism: 1}`,
+			When{GreaterThan: map[string]marshal.Slice[string]{"parallelism": {"1"}}},
+		},
+		{
+			"failed",
+			`failed: true`,
+			When{Failed: &yes},
+		},
+		{
+			"env-matches",
+			`env-matches: {CI: '^(true|1)$'}`,
+			When{EnvMatches: map[string]marshal.Slice[string]{"CI": {"^(true|1)$"}}},
+		},
+		{
+			"combinators",
+			`{any-of: [{all-of: [foo, {not: bar}]}, baz]}`,
+			When{AnyOf: WhenList{
+				When{AllOf: WhenList{
+					createWhen(withWhenEqual("foo", "true")),
+					When{Not: &When{Equal: map[string]marshal.Slice[string]{"bar": {"true"}}}},
+				}},
+				createWhen(withWhenEqual("baz", "true")),
+			}},
+		},
 		{
 			"null environment",
 			`environment: {foo: null}`,
@@ -112,6 +153,25 @@ func TestWhen_Dependencies(t *testing.T) {
 			when: createWhen(withWhenEqual("foo", "true"), withWhenNotEqual("bar", "true")),
 			want: []string{"foo", "bar"},
 		},
+		{
+			name: "combinators",
+			when: When{
+				AllOf: WhenList{createWhen(withWhenEqual("foo", "true"))},
+				AnyOf: WhenList{createWhen(withWhenNotEqual("bar", "true"))},
+				Not:   &When{LessThan: map[string]marshal.Slice[string]{"baz": {"1"}}},
+			},
+			want: []string{"foo", "bar", "baz"},
+		},
+		{
+			name: "numeric comparisons",
+			when: When{
+				GreaterThan:    map[string]marshal.Slice[string]{"foo": {"1"}},
+				GreaterOrEqual: map[string]marshal.Slice[string]{"bar": {"1"}},
+				LessThan:       map[string]marshal.Slice[string]{"baz": {"1"}},
+				LessOrEqual:    map[string]marshal.Slice[string]{"foo": {"1"}},
+			},
+			want: []string{"foo", "bar", "baz"},
+		},
 	}
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
@@ -146,11 +206,27 @@ var whenValidateTests = []struct {
 	{createWhen(withWhenExists("when_test.go"), withWhenExists("fakefile")), nil, false},
 	{createWhen(withWhenExists("fakefile"), withWhenExists("fakefile2")), nil, true},
 
+	// Exist Dir Clauses
+	{createWhen(withWhenExistsDir("testdata")), nil, false},
+	{createWhen(withWhenExistsDir("when_test.go")), nil, true},
+	{createWhen(withWhenExistsDir("fakefile")), nil, true},
+	{createWhen(withWhenExistsDir("fakefile"), withWhenExistsDir("testdata")), nil, true},
+	{createWhen(withWhenExistsDir("testdata"), withWhenExistsDir("when_test.go")), nil, true},
+	{createWhen(withWhenExistsDir("testdata"), withWhenExistsDir("testdata/include")), nil, false},
+
+	// Exist File Clauses
+	{createWhen(withWhenExistsFile("when_test.go")), nil, false},
+	{createWhen(withWhenExistsFile("testdata")), nil, true},
+	{createWhen(withWhenExistsFile("fakefile")), nil, true},
+	{createWhen(withWhenExistsFile("testdata"), withWhenExistsFile("when_test.go")), nil, true},
+	{createWhen(withWhenExistsFile("when_test.go"), withWhenExistsFile("fakefile")), nil, true},
+	{createWhen(withWhenExistsFile("when.go"), withWhenExistsFile("when_test.go")), nil, false},
+
 	// Not Exist Clauses
 	{createWhen(withWhenNotExists("when_test.go")), nil, true},
 	{createWhen(withWhenNotExists("fakefile")), nil, false},
-	{createWhen(withWhenNotExists("fakefile"), withWhenNotExists("when_test.go")), nil, false},
-	{createWhen(withWhenNotExists("when_test.go"), withWhenNotExists("fakefile")), nil, false},
+	{createWhen(withWhenNotExists("fakefile"), withWhenNotExists("when_test.go")), nil, true},
+	{createWhen(withWhenNotExists("when_test.go"), withWhenNotExists("fakefile")), nil, true},
 	{createWhen(withWhenNotExists("fakefile"), withWhenNotExists("fakefile2")), nil, false},
 	{createWhen(withWhenNotExists("when.go"), withWhenNotExists("when_test.go")), nil, true},
 
@@ -388,6 +464,12 @@ func TestWhen_Validate(t *testing.T) {
 				withWhenNotExists("when_test.go"), // file in working dir, not in testdata
 			),
 		},
+		{
+			name: "directory exists-dir",
+			when: createWhen(
+				withWhenExistsDir("include"), // directory in testdata
+			),
+		},
 		{
 			name: "directory command",
 			when: createWhen(
@@ -409,6 +491,285 @@ func TestWhen_Validate(t *testing.T) {
 	}
 }
 
+func TestWhen_Validate_outcome(t *testing.T) {
+	yes, no := true, false
+	taskErr := errors.New("task failed")
+
+	tests := []struct {
+		name      string
+		when      When
+		taskErr   error
+		shouldRun bool
+	}{
+		{"failed after failure", When{Failed: &yes}, taskErr, true},
+		{"failed after success", When{Failed: &yes}, nil, false},
+		{"not failed after success", When{Failed: &no}, nil, true},
+		{"succeeded after success", When{Succeeded: &yes}, nil, true},
+		{"succeeded after failure", When{Succeeded: &yes}, taskErr, false},
+		{"not succeeded after failure", When{Succeeded: &no}, taskErr, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			g := ghost.New(t)
+
+			err := tt.when.Validate(Context{taskErr: &tt.taskErr}, nil)
+			if tt.shouldRun {
+				g.NoError(err)
+				return
+			}
+
+			g.Should(be.True(IsFailedCondition(err)))
+		})
+	}
+}
+
+func TestWhen_Validate_comparison(t *testing.T) {
+	tests := []struct {
+		name      string
+		when      When
+		value     string
+		shouldRun bool
+	}{
+		{"greater than", When{GreaterThan: comparisonCase("1")}, "2", true},
+		{"not greater than", When{GreaterThan: comparisonCase("2")}, "2", false},
+		{"greater than float", When{GreaterThan: comparisonCase("1.5")}, "2", true},
+		{"greater or equal", When{GreaterOrEqual: comparisonCase("2")}, "2", true},
+		{"not greater or equal", When{GreaterOrEqual: comparisonCase("2.1")}, "2", false},
+		{"less than", When{LessThan: comparisonCase("10")}, "9", true},
+		{"not less than", When{LessThan: comparisonCase("9")}, "10", false},
+		{"less or equal", When{LessOrEqual: comparisonCase("-1")}, "-1.0", true},
+		{"not less or equal", When{LessOrEqual: comparisonCase("-2")}, "-1", false},
+		{"any value", When{GreaterThan: comparisonCase("5", "1")}, "2", true},
+		{
+			"any clause",
+			When{
+				Equal:       map[string]marshal.Slice[string]{"count": {"0"}},
+				GreaterThan: comparisonCase("1"),
+			},
+			"0",
+			true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			g := ghost.New(t)
+
+			err := tt.when.Validate(Context{}, map[string]string{"count": tt.value})
+			if tt.shouldRun {
+				g.NoError(err)
+				return
+			}
+
+			g.Should(be.True(IsFailedCondition(err)))
+		})
+	}
+}
+
+func TestWhen_Validate_comparison_invalid(t *testing.T) {
+	tests := []struct {
+		name  string
+		when  When
+		value string
+		want  string
+	}{
+		{
+			"option",
+			When{GreaterThan: comparisonCase("1")},
+			"many",
+			`greater-than: option "count": "many" is not a number`,
+		},
+		{
+			"value",
+			When{LessOrEqual: comparisonCase("1x")},
+			"1",
+			`less-or-equal: value for option "count": "1x" is not a number`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			g := ghost.New(t)
+
+			err := tt.when.Validate(Context{}, map[string]string{"count": tt.value})
+			g.Should(be.ErrorEqual(err, tt.want))
+		})
+	}
+}
+
+func TestWhen_Validate_combinators(t *testing.T) {
+	pass := createWhen(withWhenEqual("foo", "true"))
+	fail := createWhen(withWhenEqual("foo", "false"))
+
+	tests := []struct {
+		name      string
+		when      When
+		shouldRun bool
+	}{
+		{"all-of passes", When{AllOf: WhenList{pass, pass}}, true},
+		{"all-of fails", When{AllOf: WhenList{pass, fail}}, false},
+		{"any-of passes", When{AnyOf: WhenList{fail, pass}}, true},
+		{"any-of fails", When{AnyOf: WhenList{fail, fail}}, false},
+		{"not passes", When{Not: &fail}, true},
+		{"not fails", When{Not: &pass}, false},
+		{
+			"nested",
+			When{AnyOf: WhenList{
+				When{AllOf: WhenList{pass, When{Not: &pass}}},
+				When{Not: &When{AnyOf: WhenList{fail}}},
+			}},
+			true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			g := ghost.New(t)
+
+			err := tt.when.Validate(Context{}, map[string]string{"foo": "true"})
+			if tt.shouldRun {
+				g.NoError(err)
+				return
+			}
+
+			g.Should(be.True(IsFailedCondition(err)))
+		})
+	}
+}
+
+func TestWhen_Validate_combinators_error(t *testing.T) {
+	g := ghost.New(t)
+
+	when := When{Not: &When{AnyOf: WhenList{{GreaterThan: comparisonCase("1")}}}}
+
+	err := when.Validate(Context{}, map[string]string{"count": "many"})
+	g.Should(be.ErrorEqual(err, `greater-than: option "count": "many" is not a number`))
+}
+
+// comparisonCase returns the values to compare the count option against.
+func comparisonCase(values ...string) map[string]marshal.Slice[string] {
+	return map[string]marshal.Slice[string]{"count": values}
+}
+
+func TestWhen_Validate_outcome_outside_finally(t *testing.T) {
+	g := ghost.New(t)
+
+	yes := true
+	when := When{Failed: &yes}
+
+	err := when.Validate(Context{}, nil)
+	g.Should(be.ErrorEqual(err, "when clause `failed` can only be used in finally"))
+}
+
+func TestWhen_Validate_result(t *testing.T) {
+	var taskErr error
+	ctx := Context{
+		taskErr: &taskErr,
+		results: map[string]string{"build": "failed", "test": "not-run"},
+	}
+
+	tests := []struct {
+		name      string
+		result    map[string]marshal.Slice[string]
+		shouldRun bool
+	}{
+		{
+			name:      "matching result",
+			result:    map[string]marshal.Slice[string]{"build": {"failed"}},
+			shouldRun: true,
+		},
+		{
+			name:      "any matching result",
+			result:    map[string]marshal.Slice[string]{"test": {"skipped", "not-run"}},
+			shouldRun: true,
+		},
+		{
+			name:      "any matching run item",
+			result:    map[string]marshal.Slice[string]{"build": {"succeeded"}, "test": {"not-run"}},
+			shouldRun: true,
+		},
+		{
+			name:   "no matching result",
+			result: map[string]marshal.Slice[string]{"build": {"succeeded"}},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			g := ghost.New(t)
+
+			when := When{Result: tt.result}
+			err := when.Validate(ctx, nil)
+			if tt.shouldRun {
+				g.NoError(err)
+				return
+			}
+			g.Should(be.True(IsFailedCondition(err)))
+		})
+	}
+}
+
+func TestWhen_Validate_envMatches(t *testing.T) {
+	t.Setenv("TUSK_TEST_CI", "1")
+	t.Setenv("TUSK_TEST_EMPTY", "")
+
+	tests := []struct {
+		name      string
+		patterns  map[string]marshal.Slice[string]
+		shouldRun bool
+	}{
+		{
+			name:      "matching pattern",
+			patterns:  map[string]marshal.Slice[string]{"TUSK_TEST_CI": {`^(true|1)$`}},
+			shouldRun: true,
+		},
+		{
+			name:      "any matching pattern",
+			patterns:  map[string]marshal.Slice[string]{"TUSK_TEST_CI": {`^true$`, `^1$`}},
+			shouldRun: true,
+		},
+		{
+			name:     "no matching pattern",
+			patterns: map[string]marshal.Slice[string]{"TUSK_TEST_CI": {`^true$`}},
+		},
+		{
+			name:      "empty value",
+			patterns:  map[string]marshal.Slice[string]{"TUSK_TEST_EMPTY": {`^$`}},
+			shouldRun: true,
+		},
+		{
+			name:     "unset variable",
+			patterns: map[string]marshal.Slice[string]{"TUSK_TEST_UNSET": {`^$`}},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			g := ghost.New(t)
+
+			when := When{EnvMatches: tt.patterns}
+			err := when.Validate(Context{}, nil)
+			if tt.shouldRun {
+				g.NoError(err)
+				return
+			}
+			g.Should(be.True(IsFailedCondition(err)))
+		})
+	}
+}
+
+func TestWhen_UnmarshalYAML_invalid_envMatches(t *testing.T) {
+	g := ghost.New(t)
+
+	var w When
+	err := yaml.UnmarshalStrict([]byte(`env-matches: {CI: "(true"}`), &w)
+	g.Should(be.ErrorEqual(
+		err,
+		`env-matches pattern for "CI": error parsing regexp: missing closing ): `+"`(true`",
+	))
+}
+
 func TestNormalizeOS(t *testing.T) {
 	tests := []struct {
 		input string
@@ -8,8 +8,11 @@ import (
 	"testing"
 
 	"github.com/rliebz/ghost"
+	"github.com/rliebz/ghost/be"
 	"github.com/santhosh-tekuri/jsonschema/v5"
 	"gopkg.in/yaml.v3"
+
+	configschema "github.com/rliebz/tusk/internal/schema"
 )
 
 func TestJSONSchema(t *testing.T) {
@@ -17,15 +20,7 @@ func TestJSONSchema(t *testing.T) {
 
 	compiler := jsonschema.NewCompiler()
 
-	schemaFile, err := os.Open("tusk.schema.yaml")
-	g.NoError(err)
-	t.Cleanup(func() { g.NoError(schemaFile.Close()) })
-
-	var schemaData any
-	err = yaml.NewDecoder(schemaFile).Decode(&schemaData)
-	g.NoError(err)
-
-	jsonSchema, err := json.Marshal(schemaData)
+	jsonSchema, err := os.ReadFile("tusk.schema.json")
 	g.NoError(err)
 
 	err = compiler.AddResource("tusk.schema.json", bytes.NewReader(jsonSchema))
@@ -51,3 +46,15 @@ func TestJSONSchema(t *testing.T) {
 		t.Fatal(string(b))
 	}
 }
+
+func TestJSONSchema_generated(t *testing.T) {
+	g := ghost.New(t)
+
+	want, err := configschema.Generate()
+	g.NoError(err)
+
+	got, err := os.ReadFile("tusk.schema.json")
+	g.NoError(err)
+
+	g.Should(be.Equal(string(got), string(want)))
+}
@@ -1,11 +1,10 @@
 package main
 
 import (
-	"encoding/json"
 	"log"
 	"os"
 
-	"gopkg.in/yaml.v3"
+	"github.com/rliebz/tusk/internal/schema"
 )
 
 func main() {
@@ -15,31 +14,10 @@ func main() {
 }
 
 func run() error {
-	schemaFile, err := os.Open("./tusk.schema.yaml")
+	data, err := schema.Generate()
 	if err != nil {
 		return err
 	}
-	defer schemaFile.Close() //nolint:errcheck
 
-	var schemaData any
-	err = yaml.NewDecoder(schemaFile).Decode(&schemaData)
-	if err != nil {
-		return err
-	}
-
-	outfile, err := os.Create("./tusk.schema.json")
-	if err != nil {
-		return err
-	}
-	defer outfile.Close() //nolint:errcheck
-
-	enc := json.NewEncoder(outfile)
-	enc.SetIndent("", "\t")
-
-	err = enc.Encode(schemaData)
-	if err != nil {
-		return err
-	}
-
-	return outfile.Close()
+	return os.WriteFile("./tusk.schema.json", data, 0o644)
 }
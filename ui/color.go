@@ -0,0 +1,73 @@
+package ui
+
+import (
+	"cmp"
+	"fmt"
+	"io"
+	"os"
+)
+
+// ColorMode controls whether output is colored.
+type ColorMode int
+
+const (
+	// ColorAuto colors output when stderr is a terminal and NO_COLOR is unset.
+	ColorAuto ColorMode = iota
+	// ColorAlways colors output regardless of where it is written.
+	ColorAlways
+	// ColorNever never colors output.
+	ColorNever
+)
+
+// ParseColorMode returns the color mode with a given name.
+func ParseColorMode(name string) (ColorMode, error) {
+	switch name {
+	case "", "auto":
+		return ColorAuto, nil
+	case "always":
+		return ColorAlways, nil
+	case "never":
+		return ColorNever, nil
+	default:
+		return ColorAuto, fmt.Errorf("color mode %q must be one of [auto, always, never]", name)
+	}
+}
+
+// colorEnabled reports whether output should be colored. An explicit mode
+// always wins; otherwise colors are used only for a terminal, and only when
+// neither NO_COLOR nor a dumb terminal asks otherwise.
+func (l *Logger) colorEnabled() bool {
+	switch l.color {
+	case ColorAlways:
+		return true
+	case ColorNever:
+		return false
+	}
+
+	if os.Getenv("NO_COLOR") != "" || os.Getenv("TERM") == "dumb" {
+		return false
+	}
+
+	return isTerminal(cmp.Or[io.Writer](l.stderr, os.Stderr))
+}
+
+// colors returns the formatters to use for the logger's output.
+func (l *Logger) colors() palette {
+	return newPalette(l.colorEnabled())
+}
+
+// isTerminal reports whether a writer is a character device such as a
+// terminal.
+func isTerminal(w io.Writer) bool {
+	f, ok := w.(*os.File)
+	if !ok {
+		return false
+	}
+
+	info, err := f.Stat()
+	if err != nil {
+		return false
+	}
+
+	return info.Mode()&os.ModeCharDevice != 0
+}
@@ -0,0 +1,80 @@
+package ui
+
+import (
+	"bytes"
+	"fmt"
+	"testing"
+
+	"github.com/rliebz/ghost"
+	"github.com/rliebz/ghost/be"
+)
+
+func TestParseColorMode(t *testing.T) {
+	tests := []struct {
+		name    string
+		want    ColorMode
+		wantErr string
+	}{
+		{name: "", want: ColorAuto},
+		{name: "auto", want: ColorAuto},
+		{name: "always", want: ColorAlways},
+		{name: "never", want: ColorNever},
+		{name: "sometimes", wantErr: `color mode "sometimes" must be one of [auto, always, never]`},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			g := ghost.New(t)
+
+			got, err := ParseColorMode(tt.name)
+			if tt.wantErr != "" {
+				g.Should(be.ErrorEqual(err, tt.wantErr))
+				return
+			}
+			g.NoError(err)
+
+			g.Should(be.Equal(got, tt.want))
+		})
+	}
+}
+
+func TestLogger_colorEnabled(t *testing.T) {
+	tests := []struct {
+		name    string
+		mode    ColorMode
+		noColor string
+		want    bool
+	}{
+		{name: "auto without terminal", mode: ColorAuto, want: false},
+		{name: "always", mode: ColorAlways, want: true},
+		{name: "always with NO_COLOR", mode: ColorAlways, noColor: "1", want: true},
+		{name: "never", mode: ColorNever, want: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			g := ghost.New(t)
+
+			t.Setenv("NO_COLOR", tt.noColor)
+
+			logger := New(Config{Stderr: new(bytes.Buffer), Color: tt.mode})
+			g.Should(be.Equal(logger.colorEnabled(), tt.want))
+		})
+	}
+}
+
+func TestLogger_SetColor(t *testing.T) {
+	g := ghost.New(t)
+
+	stderr := new(bytes.Buffer)
+	logger := New(Config{Stderr: stderr})
+
+	logger.Info("foo")
+	g.Should(be.Equal(stderr.String(), fmt.Sprintf(logFormat, "Info:", "foo")))
+
+	stderr.Reset()
+	logger.SetColor(ColorAlways)
+
+	logger.Info("foo")
+	g.Should(be.Equal(stderr.String(), fmt.Sprintf(logFormat, "\x1b[34mInfo\x1b[0m", "foo")))
+}
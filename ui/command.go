@@ -4,17 +4,23 @@ import (
 	"fmt"
 	"sort"
 	"strings"
+	"time"
 )
 
 const (
 	namespaceSeparator = " > "
 	promptCharacter    = "$"
 
+	commandString        = "Command"
 	completedString      = "Completed"
+	confirmString        = "Confirm"
 	environmentString    = "Setting Environment"
 	finallyString        = "Finally"
+	onFailureString      = "On Failure"
+	runningString        = "Running"
 	startedString        = "Started"
 	skippedCommandString = "Skipping Command"
+	skippedStepString    = "skipped:"
 	skippedTaskString    = "Skipping Task"
 	taskString           = "Task"
 
@@ -24,43 +30,67 @@ const (
 
 // PrintCommand prints the command to be executed.
 func (l Logger) PrintCommand(command string, namespaces ...string) {
+	if l.isJSON() {
+		l.emit(event{Event: "command_started", Command: command, Tasks: namespaces})
+		return
+	}
+
 	if l.level <= LevelQuiet {
 		return
 	}
 
+	c := l.colors()
+
 	for i, ns := range namespaces {
-		namespaces[i] = green(ns)
+		namespaces[i] = c.green(ns)
 	}
 
-	s := strings.Join(namespaces, bold(blue(namespaceSeparator)))
+	s := strings.Join(namespaces, c.bold(c.blue(namespaceSeparator)))
 
-	fmt.Fprintf(l.Stderr(), "%s %s %s\n", s, bold(blue(promptCharacter)), bold(command))
+	fmt.Fprintf(l.Stderr(), "%s %s %s\n", s, c.bold(c.blue(promptCharacter)), c.bold(command))
 }
 
 // PrintCommandWithParenthetical prints a command with additional information.
 func (l Logger) PrintCommandWithParenthetical(command, parenthetical string, namespaces ...string) {
+	if l.isJSON() {
+		l.emit(event{
+			Event:   "command_started",
+			Command: command,
+			Label:   parenthetical,
+			Tasks:   namespaces,
+		})
+		return
+	}
+
 	if l.level <= LevelQuiet {
 		return
 	}
 
+	c := l.colors()
+
 	for i, ns := range namespaces {
-		namespaces[i] = green(ns)
+		namespaces[i] = c.green(ns)
 	}
 
-	s := strings.Join(namespaces, bold(blue(namespaceSeparator)))
+	s := strings.Join(namespaces, c.bold(c.blue(namespaceSeparator)))
 
 	fmt.Fprintf(
 		l.Stderr(),
 		"%s (%s) %s %s\n",
 		s,
-		yellow(parenthetical),
-		bold(blue(promptCharacter)),
-		bold(command),
+		c.yellow(parenthetical),
+		c.bold(c.blue(promptCharacter)),
+		c.bold(command),
 	)
 }
 
 // PrintEnvironment prints when environment variables are set.
 func (l Logger) PrintEnvironment(variables map[string]*string) {
+	if l.isJSON() {
+		l.emitEnvironment(variables)
+		return
+	}
+
 	if l.level <= LevelQuiet {
 		return
 	}
@@ -69,7 +99,8 @@ func (l Logger) PrintEnvironment(variables map[string]*string) {
 		return
 	}
 
-	f := blue
+	c := l.colors()
+	f := c.blue
 
 	fmt.Fprintln(l.Stderr(), f(environmentString))
 
@@ -91,7 +122,7 @@ func (l Logger) PrintEnvironment(variables map[string]*string) {
 			"%s%s %s=%s\n",
 			f(outputPrefix),
 			setEnvironmentString,
-			bold(key),
+			c.bold(key),
 			*value,
 		)
 	}
@@ -107,24 +138,77 @@ func (l Logger) PrintEnvironment(variables map[string]*string) {
 			"%s%s %s\n",
 			f(outputPrefix),
 			unsetEnvironmentString,
-			bold(key),
+			c.bold(key),
 		)
 	}
 }
 
+// PrintStep prints when a named run item has begun, as a header for the
+// commands it runs.
+func (l Logger) PrintStep(step string, namespaces ...string) {
+	if l.isJSON() {
+		l.emit(event{Event: "step_started", Step: step, Tasks: namespaces})
+		return
+	}
+
+	if l.level <= LevelQuiet {
+		return
+	}
+
+	c := l.colors()
+
+	names := make([]string, 0, len(namespaces)+1)
+	for _, ns := range namespaces {
+		names = append(names, c.green(ns))
+	}
+	names = append(names, c.bold(step))
+
+	fmt.Fprintln(l.Stderr(), strings.Join(names, c.bold(c.blue(namespaceSeparator))))
+}
+
+// PrintStepSkipped prints the named run item skipped and the reason.
+func (l Logger) PrintStepSkipped(step, reason string, namespaces ...string) {
+	if l.isJSON() {
+		l.emit(event{Event: "step_skipped", Step: step, Tasks: namespaces, Reason: reason})
+		return
+	}
+
+	if l.level <= LevelQuiet {
+		return
+	}
+
+	c := l.colors()
+	f := c.cyan
+
+	names := make([]string, 0, len(namespaces)+1)
+	for _, ns := range namespaces {
+		names = append(names, c.green(ns))
+	}
+	names = append(names, f(skippedStepString)+" "+c.bold(step))
+
+	fmt.Fprintln(l.Stderr(), strings.Join(names, c.bold(c.blue(namespaceSeparator))))
+	fmt.Fprintf(l.Stderr(), "%s%s\n", f(outputPrefix), reason)
+}
+
 // PrintCommandSkipped prints the command skipped and the reason.
 func (l Logger) PrintCommandSkipped(command, reason string) {
+	if l.isJSON() {
+		l.emit(event{Event: "command_skipped", Command: command, Reason: reason})
+		return
+	}
+
 	if l.Level() < LevelVerbose {
 		return
 	}
 
-	f := cyan
+	c := l.colors()
+	f := c.cyan
 
 	fmt.Fprintf(
 		l.Stderr(),
 		logFormat,
-		tag(skippedCommandString, f),
-		bold(command),
+		c.tag(skippedCommandString, f),
+		c.bold(command),
 	)
 
 	fmt.Fprintf(
@@ -137,17 +221,23 @@ func (l Logger) PrintCommandSkipped(command, reason string) {
 
 // PrintTaskSkipped prints the task skipped and the reason.
 func (l Logger) PrintTaskSkipped(task, reason string) {
+	if l.isJSON() {
+		l.emit(event{Event: "task_skipped", Task: task, Reason: reason})
+		return
+	}
+
 	if l.Level() < LevelVerbose {
 		return
 	}
 
-	f := cyan
+	c := l.colors()
+	f := c.cyan
 
 	fmt.Fprintf(
 		l.Stderr(),
 		logFormat,
-		tag(skippedTaskString, f),
-		bold(task),
+		c.tag(skippedTaskString, f),
+		c.bold(task),
 	)
 
 	fmt.Fprintf(
@@ -158,63 +248,217 @@ func (l Logger) PrintTaskSkipped(task, reason string) {
 	)
 }
 
+// PrintConfirm prints a question that must be answered before a task runs.
+// Since the answer is required, it is printed regardless of the log level.
+func (l Logger) PrintConfirm(prompt string) {
+	c := l.colors()
+
+	fmt.Fprintf(
+		l.Stderr(),
+		"%s %s %s ",
+		c.tag(confirmString, c.yellow),
+		c.bold(prompt),
+		"[y/N]",
+	)
+}
+
 // PrintTask prints when a task has begun.
 func (l Logger) PrintTask(taskName string) {
+	if l.isJSON() {
+		l.emit(event{Event: "task_started", Task: taskName})
+		return
+	}
+
 	if l.level <= LevelNormal {
 		return
 	}
 
 	s := fmt.Sprintf("%s %s", taskString, startedString)
 
+	c := l.colors()
+
 	fmt.Fprintf(
 		l.Stderr(),
 		logFormat,
-		tag(s, blue),
-		bold(taskName),
+		c.tag(s, c.blue),
+		c.bold(taskName),
+	)
+}
+
+// PrintInvocation prints the name of a task invoked from the command line,
+// which separates the output of each task when several are run together.
+func (l Logger) PrintInvocation(taskName string) {
+	if l.isJSON() {
+		l.emit(event{Event: "invocation_started", Task: taskName})
+		return
+	}
+
+	if l.level <= LevelQuiet {
+		return
+	}
+
+	c := l.colors()
+
+	fmt.Fprintf(
+		l.Stderr(),
+		logFormat,
+		c.tag(runningString, c.blue),
+		c.bold(taskName),
 	)
 }
 
 // PrintTaskFinally prints when a task's finally clause has begun.
 func (l Logger) PrintTaskFinally(taskName string) {
+	if l.isJSON() {
+		l.emit(event{Event: "task_finally", Task: taskName})
+		return
+	}
+
 	if l.level <= LevelNormal {
 		return
 	}
 
 	s := fmt.Sprintf("%s %s", taskString, finallyString)
 
+	c := l.colors()
+
 	fmt.Fprintf(
 		l.Stderr(),
 		logFormat,
-		tag(s, blue),
-		bold(taskName),
+		c.tag(s, c.blue),
+		c.bold(taskName),
 	)
 }
 
-// PrintTaskCompleted prints when a task has completed.
-func (l Logger) PrintTaskCompleted(taskName string) {
+// PrintTaskOnFailure prints when a task's on-failure clause has begun.
+func (l Logger) PrintTaskOnFailure(taskName string) {
+	if l.isJSON() {
+		l.emit(event{Event: "task_on_failure", Task: taskName})
+		return
+	}
+
 	if l.level <= LevelNormal {
 		return
 	}
 
-	s := fmt.Sprintf("%s %s", taskString, completedString)
+	s := fmt.Sprintf("%s %s", taskString, onFailureString)
+
+	c := l.colors()
 
 	fmt.Fprintf(
 		l.Stderr(),
 		logFormat,
-		tag(s, blue),
-		bold(taskName),
+		c.tag(s, c.blue),
+		c.bold(taskName),
+	)
+}
+
+// PrintTaskCompleted prints when a task has completed along with the time it
+// took.
+func (l Logger) PrintTaskCompleted(taskName string, elapsed time.Duration) {
+	if l.isJSON() {
+		l.emit(event{Event: "task_completed", Task: taskName, Duration: seconds(elapsed)})
+		return
+	}
+
+	if l.level <= LevelNormal {
+		return
+	}
+
+	s := fmt.Sprintf("%s %s", taskString, completedString)
+
+	c := l.colors()
+
+	fmt.Fprintf(
+		l.Stderr(),
+		"%s %s (%s)\n",
+		c.tag(s, c.blue),
+		c.bold(taskName),
+		formatDuration(elapsed),
+	)
+}
+
+// PrintCommandCompleted prints when a command has completed along with the
+// time it took.
+func (l Logger) PrintCommandCompleted(command string, elapsed time.Duration) {
+	if l.isJSON() {
+		l.emit(event{
+			Event:    "command_finished",
+			Command:  command,
+			ExitCode: exitCode(nil),
+			Duration: seconds(elapsed),
+		})
+		return
+	}
+
+	if l.level <= LevelNormal {
+		return
+	}
+
+	s := fmt.Sprintf("%s %s", commandString, completedString)
+
+	c := l.colors()
+
+	fmt.Fprintf(
+		l.Stderr(),
+		"%s %s (%s)\n",
+		c.tag(s, c.blue),
+		c.bold(command),
+		formatDuration(elapsed),
 	)
 }
 
 // PrintCommandError prints an error from a running command.
 func (l Logger) PrintCommandError(err error) {
+	if l.isJSON() {
+		l.emit(event{Event: "command_failed", ExitCode: exitCode(err), Error: err.Error()})
+		return
+	}
+
 	if l.level <= LevelQuiet {
 		return
 	}
 
+	c := l.colors()
+
 	fmt.Fprintf(
 		l.Stderr(),
 		"%s\n",
-		red(err.Error()),
+		c.red(err.Error()),
 	)
 }
+
+// PrintCommandFailed prints when a command has failed along with the time it
+// took.
+func (l Logger) PrintCommandFailed(command string, err error, elapsed time.Duration) {
+	if l.isJSON() {
+		l.emit(event{
+			Event:    "command_finished",
+			Command:  command,
+			ExitCode: exitCode(err),
+			Error:    err.Error(),
+			Duration: seconds(elapsed),
+		})
+		return
+	}
+
+	l.PrintCommandError(err)
+}
+
+func (l Logger) emitEnvironment(variables map[string]*string) {
+	if len(variables) == 0 {
+		return
+	}
+
+	e := event{Event: "environment", Set: make(map[string]string)}
+	for key, value := range variables {
+		if value == nil {
+			e.Unset = append(e.Unset, key)
+			continue
+		}
+		e.Set[key] = *value
+	}
+	sort.Strings(e.Unset)
+
+	l.emit(e)
+}
@@ -1,9 +1,14 @@
 package ui
 
 import (
+	"bytes"
 	"errors"
 	"fmt"
 	"testing"
+	"time"
+
+	"github.com/rliebz/ghost"
+	"github.com/rliebz/ghost/be"
 )
 
 var commandTests = []printTestCase{
@@ -23,6 +28,22 @@ var commandTests = []printTestCase{
 		LevelNormal,
 		"foo > bar (paren) $ echo hello\n",
 	},
+	{
+		`PrintStep("build", "foo", "bar")`,
+		withStderr,
+		func(l *Logger) { l.PrintStep("build", "foo", "bar") },
+		LevelQuiet,
+		LevelNormal,
+		"foo > bar > build\n",
+	},
+	{
+		`PrintStepSkipped("build", "oops", "foo")`,
+		withStderr,
+		func(l *Logger) { l.PrintStepSkipped("build", "oops", "foo") },
+		LevelQuiet,
+		LevelNormal,
+		fmt.Sprintf("foo > skipped: build\n%soops\n", outputPrefix),
+	},
 	{
 		`PrintEnvironment()`,
 		withStderr,
@@ -61,7 +82,7 @@ var commandTests = []printTestCase{
 		LevelVerbose,
 		fmt.Sprintf(
 			"%s %s\n%s%s\n",
-			tag(skippedCommandString, yellow),
+			plain.tag(skippedCommandString, plain.yellow),
 			"echo hello",
 			outputPrefix,
 			"oops",
@@ -75,12 +96,20 @@ var commandTests = []printTestCase{
 		LevelVerbose,
 		fmt.Sprintf(
 			"%s %s\n%s%s\n",
-			tag(skippedTaskString, yellow),
+			plain.tag(skippedTaskString, plain.yellow),
 			"my-task",
 			outputPrefix,
 			"oops",
 		),
 	},
+	{
+		`PrintInvocation("foo")`,
+		withStderr,
+		func(l *Logger) { l.PrintInvocation("foo") },
+		LevelQuiet,
+		LevelNormal,
+		"Running: foo\n",
+	},
 	{
 		`PrintTask("foo")`,
 		withStderr,
@@ -98,12 +127,44 @@ var commandTests = []printTestCase{
 		"Task Finally: foo\n",
 	},
 	{
-		`PrintTaskCompleted("foo")`,
+		`PrintTaskOnFailure("foo")`,
+		withStderr,
+		func(l *Logger) { l.PrintTaskOnFailure("foo") },
+		LevelNormal,
+		LevelVerbose,
+		"Task On Failure: foo\n",
+	},
+	{
+		`PrintTaskCompleted("foo", 1500*time.Millisecond)`,
 		withStderr,
-		func(l *Logger) { l.PrintTaskCompleted("foo") },
+		func(l *Logger) { l.PrintTaskCompleted("foo", 1500*time.Millisecond) },
 		LevelNormal,
 		LevelVerbose,
-		"Task Completed: foo\n",
+		"Task Completed: foo (1.5s)\n",
+	},
+	{
+		`PrintCommandCompleted("echo hello", 12*time.Millisecond)`,
+		withStderr,
+		func(l *Logger) { l.PrintCommandCompleted("echo hello", 12*time.Millisecond) },
+		LevelNormal,
+		LevelVerbose,
+		"Command Completed: echo hello (12ms)\n",
+	},
+	{
+		`PrintFailures(...)`,
+		withStderr,
+		func(l *Logger) {
+			l.PrintFailures([]Failure{
+				{Command: "go vet", Err: errors.New("exit status 1")},
+				{Err: errors.New("oops")},
+			})
+		},
+		LevelQuiet,
+		LevelNormal,
+		fmt.Sprintf(
+			"Failures\n%s$ go vet (exit status 1)\n%soops\n",
+			outputPrefix, outputPrefix,
+		),
 	},
 	{
 		`PrintCommandError(errors.New("oops"))`,
@@ -122,3 +183,14 @@ func TestCommandPrintFunctions(t *testing.T) {
 		})
 	}
 }
+
+func TestLogger_PrintConfirm(t *testing.T) {
+	g := ghost.New(t)
+
+	stderr := new(bytes.Buffer)
+	logger := New(Config{Stderr: stderr, Verbosity: LevelSilent})
+
+	logger.PrintConfirm("Are you sure?")
+
+	g.Should(be.Equal(stderr.String(), "Confirm: Are you sure? [y/N] "))
+}
@@ -0,0 +1,56 @@
+package ui
+
+import (
+	"fmt"
+)
+
+const failuresString = "Failures"
+
+// Failure is a run item that failed while its task kept going.
+type Failure struct {
+	// Command is the command that failed, or empty if the run item failed for
+	// another reason.
+	Command string
+	Err     error
+}
+
+// PrintFailures prints every run item that failed after a task kept going,
+// along with the command that failed and its error.
+func (l Logger) PrintFailures(failures []Failure) {
+	if l.isJSON() {
+		events := make([]failureEvent, 0, len(failures))
+		for _, failure := range failures {
+			events = append(events, failureEvent{
+				Command:  failure.Command,
+				ExitCode: exitCode(failure.Err),
+				Error:    failure.Err.Error(),
+			})
+		}
+		l.emit(event{Event: "failures", Failures: events})
+		return
+	}
+
+	if l.level <= LevelQuiet {
+		return
+	}
+
+	c := l.colors()
+	f := c.red
+
+	fmt.Fprintln(l.Stderr(), f(failuresString))
+	for _, failure := range failures {
+		if failure.Command == "" {
+			fmt.Fprintf(l.Stderr(), "%s%s\n", f(outputPrefix), failure.Err)
+			continue
+		}
+
+		fmt.Fprintf(
+			l.Stderr(),
+			"%s%s %s (%s)\n",
+			f(outputPrefix),
+			c.bold(promptCharacter),
+			c.bold(failure.Command),
+			failure.Err,
+		)
+	}
+}
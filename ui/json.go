@@ -0,0 +1,191 @@
+package ui
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os/exec"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Format is the format of the logger's output.
+type Format int
+
+const (
+	// FormatHuman prints output formatted for people to read.
+	FormatHuman Format = iota
+	// FormatJSON prints one JSON object per line for each event.
+	FormatJSON
+)
+
+// ParseFormat returns the output format with a given name.
+func ParseFormat(name string) (Format, error) {
+	switch name {
+	case "", "human":
+		return FormatHuman, nil
+	case "json":
+		return FormatJSON, nil
+	default:
+		return FormatHuman, fmt.Errorf("output format %q must be one of [human, json]", name)
+	}
+}
+
+// event is a single JSON log entry.
+type event struct {
+	Event    string            `json:"event"`
+	Level    string            `json:"level,omitempty"`
+	Message  string            `json:"message,omitempty"`
+	Task     string            `json:"task,omitempty"`
+	Tasks    []string          `json:"tasks,omitempty"`
+	Step     string            `json:"step,omitempty"`
+	Command  string            `json:"command,omitempty"`
+	Label    string            `json:"label,omitempty"`
+	Stream   string            `json:"stream,omitempty"`
+	Line     *string           `json:"line,omitempty"`
+	Reason   string            `json:"reason,omitempty"`
+	ExitCode *int              `json:"exit_code,omitempty"`
+	Error    string            `json:"error,omitempty"`
+	Duration *float64          `json:"duration_seconds,omitempty"`
+	Set      map[string]string `json:"set,omitempty"`
+	Unset    []string          `json:"unset,omitempty"`
+	Timings  []timingEvent     `json:"timings,omitempty"`
+	Failures []failureEvent    `json:"failures,omitempty"`
+}
+
+// timingEvent is the JSON representation of a [Timing].
+type timingEvent struct {
+	Task     string  `json:"task"`
+	Step     string  `json:"step,omitempty"`
+	Command  string  `json:"command,omitempty"`
+	Depth    int     `json:"depth"`
+	Duration float64 `json:"duration_seconds"`
+}
+
+// failureEvent is the JSON representation of a [Failure].
+type failureEvent struct {
+	Command  string `json:"command,omitempty"`
+	ExitCode *int   `json:"exit_code"`
+	Error    string `json:"error"`
+}
+
+// isJSON returns whether the logger prints JSON events.
+func (l Logger) isJSON() bool {
+	return l.format == FormatJSON
+}
+
+// emit writes an event to stdout. Events are written at every level of
+// verbosity other than silent.
+func (l Logger) emit(e event) {
+	if l.level <= LevelSilent {
+		return
+	}
+
+	b, err := json.Marshal(e)
+	if err != nil {
+		// Events are built only from types that can always be marshaled.
+		panic(err)
+	}
+
+	fmt.Fprintf(l.Stdout(), "%s\n", b)
+}
+
+func seconds(d time.Duration) *float64 {
+	s := d.Seconds()
+	return &s
+}
+
+func exitCode(err error) *int {
+	code := 0
+	if err != nil {
+		code = -1
+		var exitErr *exec.ExitError
+		if errors.As(err, &exitErr) {
+			code = exitErr.ExitCode()
+		}
+	}
+
+	return &code
+}
+
+func messageOf(a ...any) string {
+	messages := make([]string, 0, len(a))
+	for _, message := range a {
+		messages = append(messages, fmt.Sprint(message))
+	}
+
+	return strings.Join(messages, "\n")
+}
+
+// CommandOutput returns the writers that a command's stdout and stderr should
+// be written to. In JSON mode, each line of output is wrapped in an event, and
+// when prefixing output, each line is prefixed with the task namespaces. The
+// returned flush function must be called once the command has exited to write
+// any final line without a trailing newline.
+func (l *Logger) CommandOutput(
+	command string,
+	namespaces ...string,
+) (stdout, stderr io.Writer, flush func()) {
+	if !l.isJSON() {
+		if l.prefixOutput && len(namespaces) > 0 {
+			return l.prefixedOutput(namespaces)
+		}
+		return l.Stdout(), l.Stderr(), func() {}
+	}
+
+	var mu sync.Mutex
+	outWriter := &lineWriter{logger: l, mu: &mu, command: command, stream: "stdout"}
+	errWriter := &lineWriter{logger: l, mu: &mu, command: command, stream: "stderr"}
+
+	return outWriter, errWriter, func() {
+		outWriter.flush()
+		errWriter.flush()
+	}
+}
+
+// lineWriter emits an event for each line written to it.
+type lineWriter struct {
+	logger  *Logger
+	mu      *sync.Mutex
+	command string
+	stream  string
+	buf     bytes.Buffer
+}
+
+func (w *lineWriter) Write(p []byte) (int, error) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	w.buf.Write(p)
+	for {
+		i := bytes.IndexByte(w.buf.Bytes(), '\n')
+		if i < 0 {
+			return len(p), nil
+		}
+
+		line := strings.TrimSuffix(string(w.buf.Next(i + 1)[:i]), "\r")
+		w.emitLine(line)
+	}
+}
+
+func (w *lineWriter) flush() {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	if w.buf.Len() > 0 {
+		w.emitLine(w.buf.String())
+		w.buf.Reset()
+	}
+}
+
+func (w *lineWriter) emitLine(line string) {
+	w.logger.emit(event{
+		Event:   "command_output",
+		Command: w.command,
+		Stream:  w.stream,
+		Line:    &line,
+	})
+}
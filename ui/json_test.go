@@ -0,0 +1,232 @@
+package ui
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"os/exec"
+	"testing"
+	"time"
+
+	"github.com/rliebz/ghost"
+	"github.com/rliebz/ghost/be"
+)
+
+func TestParseFormat(t *testing.T) {
+	tests := []struct {
+		name    string
+		want    Format
+		wantErr string
+	}{
+		{name: "", want: FormatHuman},
+		{name: "human", want: FormatHuman},
+		{name: "json", want: FormatJSON},
+		{name: "xml", wantErr: `output format "xml" must be one of [human, json]`},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			g := ghost.New(t)
+
+			got, err := ParseFormat(tt.name)
+			if tt.wantErr != "" {
+				g.Should(be.ErrorEqual(err, tt.wantErr))
+				return
+			}
+			g.NoError(err)
+
+			g.Should(be.Equal(got, tt.want))
+		})
+	}
+}
+
+func TestLogger_json(t *testing.T) {
+	exitErr := exec.Command("sh", "-c", "exit 3").Run()
+
+	tests := []struct {
+		name      string
+		printFunc func(l *Logger)
+		want      string
+	}{
+		{
+			name:      "PrintCommand",
+			printFunc: func(l *Logger) { l.PrintCommand("echo hello", "foo", "bar") },
+			want:      `{"event":"command_started","tasks":["foo","bar"],"command":"echo hello"}`,
+		},
+		{
+			name: "PrintCommandWithParenthetical",
+			printFunc: func(l *Logger) {
+				l.PrintCommandWithParenthetical("echo hello", "finally", "foo")
+			},
+			want: `{"event":"command_started","tasks":["foo"],"command":"echo hello","label":"finally"}`,
+		},
+		{
+			name: "PrintEnvironment",
+			printFunc: func(l *Logger) {
+				a := "one"
+				l.PrintEnvironment(map[string]*string{"A": &a, "C": nil, "B": nil})
+			},
+			want: `{"event":"environment","set":{"A":"one"},"unset":["B","C"]}`,
+		},
+		{
+			name:      "PrintStep",
+			printFunc: func(l *Logger) { l.PrintStep("build", "foo") },
+			want:      `{"event":"step_started","tasks":["foo"],"step":"build"}`,
+		},
+		{
+			name:      "PrintStepSkipped",
+			printFunc: func(l *Logger) { l.PrintStepSkipped("build", "oops", "foo") },
+			want:      `{"event":"step_skipped","tasks":["foo"],"step":"build","reason":"oops"}`,
+		},
+		{
+			name:      "PrintCommandSkipped",
+			printFunc: func(l *Logger) { l.PrintCommandSkipped("echo hello", "oops") },
+			want:      `{"event":"command_skipped","command":"echo hello","reason":"oops"}`,
+		},
+		{
+			name:      "PrintTaskSkipped",
+			printFunc: func(l *Logger) { l.PrintTaskSkipped("foo", "oops") },
+			want:      `{"event":"task_skipped","task":"foo","reason":"oops"}`,
+		},
+		{
+			name:      "PrintInvocation",
+			printFunc: func(l *Logger) { l.PrintInvocation("foo") },
+			want:      `{"event":"invocation_started","task":"foo"}`,
+		},
+		{
+			name:      "PrintTask",
+			printFunc: func(l *Logger) { l.PrintTask("foo") },
+			want:      `{"event":"task_started","task":"foo"}`,
+		},
+		{
+			name:      "PrintTaskFinally",
+			printFunc: func(l *Logger) { l.PrintTaskFinally("foo") },
+			want:      `{"event":"task_finally","task":"foo"}`,
+		},
+		{
+			name:      "PrintTaskOnFailure",
+			printFunc: func(l *Logger) { l.PrintTaskOnFailure("foo") },
+			want:      `{"event":"task_on_failure","task":"foo"}`,
+		},
+		{
+			name:      "PrintTaskCompleted",
+			printFunc: func(l *Logger) { l.PrintTaskCompleted("foo", 1500*time.Millisecond) },
+			want:      `{"event":"task_completed","task":"foo","duration_seconds":1.5}`,
+		},
+		{
+			name: "PrintCommandCompleted",
+			printFunc: func(l *Logger) {
+				l.PrintCommandCompleted("echo hello", 250*time.Millisecond)
+			},
+			want: `{"event":"command_finished","command":"echo hello","exit_code":0,` +
+				`"duration_seconds":0.25}`,
+		},
+		{
+			name: "PrintCommandFailed",
+			printFunc: func(l *Logger) {
+				l.PrintCommandFailed("exit 3", exitErr, 2*time.Second)
+			},
+			want: `{"event":"command_finished","command":"exit 3","exit_code":3,` +
+				`"error":"exit status 3","duration_seconds":2}`,
+		},
+		{
+			name:      "PrintCommandError",
+			printFunc: func(l *Logger) { l.PrintCommandError(errors.New("oops")) },
+			want:      `{"event":"command_failed","exit_code":-1,"error":"oops"}`,
+		},
+		{
+			name: "PrintFailures",
+			printFunc: func(l *Logger) {
+				l.PrintFailures([]Failure{
+					{Command: "sh -c 'exit 3'", Err: exitErr},
+					{Err: errors.New("oops")},
+				})
+			},
+			want: `{"event":"failures","failures":[` +
+				`{"command":"sh -c 'exit 3'","exit_code":3,"error":"exit status 3"},` +
+				`{"exit_code":-1,"error":"oops"}]}`,
+		},
+		{
+			name:      "Warn",
+			printFunc: func(l *Logger) { l.Warn("foo", "bar") },
+			want:      `{"event":"log","level":"warning","message":"foo\nbar"}`,
+		},
+		{
+			name: "PrintTimingSummary",
+			printFunc: func(l *Logger) {
+				l.RecordTiming(Timing{
+					Task:    "foo",
+					Step:    "build",
+					Command: "echo",
+					Depth:   1,
+					Elapsed: time.Second,
+				})
+				l.PrintTimingSummary()
+			},
+			want: `{"event":"timings","timings":[` +
+				`{"task":"foo","step":"build","command":"echo","depth":1,"duration_seconds":1}]}`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			g := ghost.New(t)
+
+			stdout := new(bytes.Buffer)
+			stderr := new(bytes.Buffer)
+			logger := New(Config{
+				Stdout:    stdout,
+				Stderr:    stderr,
+				Verbosity: LevelNormal,
+				Format:    FormatJSON,
+			})
+
+			tt.printFunc(logger)
+			g.Should(be.Equal(stdout.String(), tt.want+"\n"))
+			g.Should(be.Zero(stderr.String()))
+
+			stdout.Reset()
+			logger.SetLevel(LevelSilent)
+			tt.printFunc(logger)
+			g.Should(be.Zero(stdout.String()))
+		})
+	}
+}
+
+func TestLogger_CommandOutput(t *testing.T) {
+	g := ghost.New(t)
+
+	buf := new(bytes.Buffer)
+	logger := New(Config{Stdout: buf, Format: FormatJSON})
+
+	stdout, stderr, flush := logger.CommandOutput("echo hello")
+
+	_, err := fmt.Fprint(stdout, "one\ntw")
+	g.NoError(err)
+	_, err = fmt.Fprint(stderr, "oops\r\n")
+	g.NoError(err)
+	_, err = fmt.Fprint(stdout, "o\n\nthree")
+	g.NoError(err)
+	flush()
+
+	//nolint:lll
+	g.Should(be.Equal(buf.String(), `{"event":"command_output","command":"echo hello","stream":"stdout","line":"one"}
+{"event":"command_output","command":"echo hello","stream":"stderr","line":"oops"}
+{"event":"command_output","command":"echo hello","stream":"stdout","line":"two"}
+{"event":"command_output","command":"echo hello","stream":"stdout","line":""}
+{"event":"command_output","command":"echo hello","stream":"stdout","line":"three"}
+`))
+}
+
+func TestLogger_CommandOutput_human(t *testing.T) {
+	g := ghost.New(t)
+
+	stdoutBuf := new(bytes.Buffer)
+	stderrBuf := new(bytes.Buffer)
+	logger := New(Config{Stdout: stdoutBuf, Stderr: stderrBuf})
+
+	stdout, stderr, flush := logger.CommandOutput("echo hello")
+	g.Should(be.Equal(stdout, logger.Stdout()))
+	g.Should(be.Equal(stderr, logger.Stderr()))
+	flush()
+}
@@ -0,0 +1,58 @@
+package ui
+
+import (
+	"bytes"
+	"io"
+	"regexp"
+	"sync"
+)
+
+// ansiEscape matches the escape sequences used to color terminal output.
+var ansiEscape = regexp.MustCompile(`\x1b\[[0-9;]*[A-Za-z]`)
+
+// partialEscape matches the start of an escape sequence at the end of a write,
+// which is completed by the next write.
+var partialEscape = regexp.MustCompile(`\x1b(\[[0-9;]*)?$`)
+
+// SetLogFile sets a writer that receives a copy of everything written to the
+// logger's standard output and error, including the output of commands.
+// Escape codes used for colors are removed before writing.
+func (l *Logger) SetLogFile(w io.Writer) {
+	l.logFile = &plainWriter{w: w}
+}
+
+// tee returns a writer that also writes to the log file, if one is set.
+func (l *Logger) tee(w io.Writer) io.Writer {
+	if l.logFile == nil {
+		return w
+	}
+
+	return io.MultiWriter(w, l.logFile)
+}
+
+// plainWriter writes text with ANSI escape codes removed. An escape code split
+// across writes is held back until the rest of it arrives.
+type plainWriter struct {
+	w io.Writer
+
+	mu      sync.Mutex
+	pending []byte
+}
+
+func (p *plainWriter) Write(b []byte) (int, error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	text := append(p.pending, b...)
+	p.pending = nil
+	if loc := partialEscape.FindIndex(text); loc != nil {
+		p.pending = bytes.Clone(text[loc[0]:])
+		text = text[:loc[0]]
+	}
+
+	if _, err := p.w.Write(ansiEscape.ReplaceAll(text, nil)); err != nil {
+		return 0, err
+	}
+
+	return len(b), nil
+}
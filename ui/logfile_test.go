@@ -0,0 +1,47 @@
+package ui
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"testing"
+
+	"github.com/rliebz/ghost"
+	"github.com/rliebz/ghost/be"
+)
+
+func TestLogger_SetLogFile(t *testing.T) {
+	g := ghost.New(t)
+
+	stdout := new(bytes.Buffer)
+	stderr := new(bytes.Buffer)
+	logFile := new(bytes.Buffer)
+
+	logger := New(Config{Stdout: stdout, Stderr: stderr, Color: ColorAlways})
+	logger.SetLogFile(logFile)
+
+	logger.Println("foo")
+	logger.Info("bar")
+
+	g.Should(be.Equal(stdout.String(), "foo\n"))
+	g.Should(be.Equal(stderr.String(), fmt.Sprintf(logFormat, "\x1b[34mInfo\x1b[0m", "bar")))
+	g.Should(be.Equal(logFile.String(), "foo\nInfo bar\n"))
+}
+
+func TestLogger_SetLogFile_split_escape(t *testing.T) {
+	g := ghost.New(t)
+
+	logFile := new(bytes.Buffer)
+
+	logger := Noop()
+	logger.SetLogFile(logFile)
+
+	w := logger.tee(io.Discard)
+	for _, chunk := range []string{"foo \x1b", "[3", "4mbar\x1b[0", "m baz\n"} {
+		n, err := w.Write([]byte(chunk))
+		g.NoError(err)
+		g.Should(be.Equal(n, len(chunk)))
+	}
+
+	g.Should(be.Equal(logFile.String(), "foo bar baz\n"))
+}
@@ -24,8 +24,14 @@ const (
 type Logger struct {
 	stdout, stderr io.Writer
 	level          Level
+	format         Format
+	color          ColorMode
+	logFile        io.Writer
+	prefixOutput   bool
 
 	deprecations []string
+	timings      []Timing
+	skips        []Skip
 }
 
 // Config provides the configuration options for a [Logger].
@@ -33,6 +39,8 @@ type Config struct {
 	Stdout    io.Writer
 	Stderr    io.Writer
 	Verbosity Level
+	Format    Format
+	Color     ColorMode
 }
 
 // New returns a new logger with the default settings.
@@ -41,6 +49,8 @@ func New(cfg Config) *Logger {
 		stdout: cfg.Stdout,
 		stderr: cfg.Stderr,
 		level:  cfg.Verbosity,
+		format: cfg.Format,
+		color:  cfg.Color,
 	}
 }
 
@@ -55,12 +65,12 @@ func Noop() *Logger {
 
 // Stdout returns the logger's standard output.
 func (l *Logger) Stdout() io.Writer {
-	return cmp.Or[io.Writer](l.stdout, os.Stdout)
+	return l.tee(cmp.Or[io.Writer](l.stdout, os.Stdout))
 }
 
 // Stderr returns the logger's error output.
 func (l *Logger) Stderr() io.Writer {
-	return cmp.Or[io.Writer](l.stderr, os.Stderr)
+	return l.tee(cmp.Or[io.Writer](l.stderr, os.Stderr))
 }
 
 // Level returns the logger's verbosity level.
@@ -73,6 +83,16 @@ func (l *Logger) SetLevel(level Level) {
 	l.level = level
 }
 
+// SetFormat sets the logger's output format.
+func (l *Logger) SetFormat(format Format) {
+	l.format = format
+}
+
+// SetColor sets whether the logger's output is colored.
+func (l *Logger) SetColor(mode ColorMode) {
+	l.color = mode
+}
+
 // Println prints a line directly.
 func (l *Logger) Println(a ...any) {
 	if l.level <= LevelSilent {
@@ -88,7 +108,7 @@ func (l *Logger) Debug(a ...any) {
 		return
 	}
 
-	l.logInStyle(debugString, cyan, a...)
+	l.logInStyle(debugString, l.colors().cyan, a...)
 }
 
 // Info prints normal application information.
@@ -97,7 +117,7 @@ func (l *Logger) Info(a ...any) {
 		return
 	}
 
-	l.logInStyle(infoString, blue, a...)
+	l.logInStyle(infoString, l.colors().blue, a...)
 }
 
 // Warn prints at the warning level.
@@ -106,7 +126,7 @@ func (l *Logger) Warn(a ...any) {
 		return
 	}
 
-	l.logInStyle(warningString, yellow, a...)
+	l.logInStyle(warningString, l.colors().yellow, a...)
 }
 
 // Error prints application errors.
@@ -115,7 +135,7 @@ func (l *Logger) Error(a ...any) {
 		return
 	}
 
-	l.logInStyle(errorString, red, a...)
+	l.logInStyle(errorString, l.colors().red, a...)
 }
 
 // Deprecate prints deprecation warnings no more than once.
@@ -132,16 +152,27 @@ func (l *Logger) Deprecate(a ...any) {
 		l.deprecations = append(l.deprecations, message)
 	}
 
-	l.logInStyle(deprecatedString, yellow, a...)
-	fmt.Fprintln(l.Stderr())
+	l.logInStyle(deprecatedString, l.colors().yellow, a...)
+	if !l.isJSON() {
+		fmt.Fprintln(l.Stderr())
+	}
 }
 
 func (l *Logger) logInStyle(title string, f formatter, a ...any) {
+	if l.isJSON() {
+		l.emit(event{
+			Event:   "log",
+			Level:   strings.ToLower(title),
+			Message: messageOf(a...),
+		})
+		return
+	}
+
 	messages := make([]string, 0, len(a))
 	for _, message := range a {
 		messages = append(messages, fmt.Sprint(message))
 	}
 	message := strings.Join(messages, "\n"+f(outputPrefix))
 
-	fmt.Fprintf(l.Stderr(), logFormat, tag(title, f), message)
+	fmt.Fprintf(l.Stderr(), logFormat, l.colors().tag(title, f), message)
 }
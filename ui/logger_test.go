@@ -20,7 +20,7 @@ var outputTests = []printTestCase{
 		func(l *Logger) { l.Debug("foo") },
 		LevelNormal,
 		LevelVerbose,
-		fmt.Sprintf(logFormat, tag(debugString, cyan), "foo"),
+		fmt.Sprintf(logFormat, plain.tag(debugString, plain.cyan), "foo"),
 	},
 	{
 		`Debug("foo", "bar", "baz")`,
@@ -30,9 +30,9 @@ var outputTests = []printTestCase{
 		LevelVerbose,
 		fmt.Sprintf(
 			"%s %s\n%s%s\n%s%s\n",
-			tag(debugString, cyan), "foo",
-			cyan(outputPrefix), "bar",
-			cyan(outputPrefix), "baz",
+			plain.tag(debugString, plain.cyan), "foo",
+			plain.cyan(outputPrefix), "bar",
+			plain.cyan(outputPrefix), "baz",
 		),
 	},
 	{
@@ -41,7 +41,7 @@ var outputTests = []printTestCase{
 		func(l *Logger) { l.Info("foo") },
 		LevelQuiet,
 		LevelNormal,
-		fmt.Sprintf(logFormat, tag(infoString, blue), "foo"),
+		fmt.Sprintf(logFormat, plain.tag(infoString, plain.blue), "foo"),
 	},
 	{
 		`Warn("foo")`,
@@ -49,7 +49,7 @@ var outputTests = []printTestCase{
 		func(l *Logger) { l.Warn("foo") },
 		LevelQuiet,
 		LevelNormal,
-		fmt.Sprintf(logFormat, tag(warningString, yellow), "foo"),
+		fmt.Sprintf(logFormat, plain.tag(warningString, plain.yellow), "foo"),
 	},
 	{
 		`Error("foo")`,
@@ -57,7 +57,7 @@ var outputTests = []printTestCase{
 		func(l *Logger) { l.Error("foo") },
 		LevelSilent,
 		LevelQuiet,
-		fmt.Sprintf(logFormat, tag(errorString, red), "foo"),
+		fmt.Sprintf(logFormat, plain.tag(errorString, plain.red), "foo"),
 	},
 	{
 		`Deprecate("foo") once`,
@@ -65,7 +65,7 @@ var outputTests = []printTestCase{
 		func(l *Logger) { l.Deprecate("foo") },
 		LevelQuiet,
 		LevelNormal,
-		fmt.Sprintf(logFormat, tag(deprecatedString, yellow), "foo\n"),
+		fmt.Sprintf(logFormat, plain.tag(deprecatedString, plain.yellow), "foo\n"),
 	},
 	{
 		`Deprecate("foo") twice`,
@@ -76,7 +76,7 @@ var outputTests = []printTestCase{
 		},
 		LevelQuiet,
 		LevelNormal,
-		fmt.Sprintf(logFormat, tag(deprecatedString, yellow), "foo\n"),
+		fmt.Sprintf(logFormat, plain.tag(deprecatedString, plain.yellow), "foo\n"),
 	},
 	{
 		`Deprecate("foo", "bar")`,
@@ -86,8 +86,8 @@ var outputTests = []printTestCase{
 		LevelNormal,
 		fmt.Sprintf(
 			"%s %s\n%s%s\n\n",
-			tag(deprecatedString, yellow), "foo",
-			yellow(outputPrefix), "bar",
+			plain.tag(deprecatedString, plain.yellow), "foo",
+			plain.yellow(outputPrefix), "bar",
 		),
 	},
 }
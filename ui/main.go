@@ -35,25 +35,46 @@ func (v Level) String() string {
 	}
 }
 
-var (
-	bold   = newFormatter(color.Bold)
-	blue   = newFormatter(color.FgBlue)
-	cyan   = newFormatter(color.FgCyan)
-	green  = newFormatter(color.FgGreen)
-	red    = newFormatter(color.FgRed)
-	yellow = newFormatter(color.FgYellow)
-)
+// palette is the set of formatters used to style output.
+type palette struct {
+	enabled bool
+
+	bold   formatter
+	blue   formatter
+	cyan   formatter
+	green  formatter
+	red    formatter
+	yellow formatter
+}
+
+func newPalette(enabled bool) palette {
+	return palette{
+		enabled: enabled,
+		bold:    newFormatter(enabled, color.Bold),
+		blue:    newFormatter(enabled, color.FgBlue),
+		cyan:    newFormatter(enabled, color.FgCyan),
+		green:   newFormatter(enabled, color.FgGreen),
+		red:     newFormatter(enabled, color.FgRed),
+		yellow:  newFormatter(enabled, color.FgYellow),
+	}
+}
 
 type formatter func(a ...any) string
 
-func newFormatter(value ...color.Attribute) formatter {
+func newFormatter(enabled bool, value ...color.Attribute) formatter {
 	return func(a ...any) string {
-		return color.New(value...).SprintFunc()(a...)
+		c := color.New(value...)
+		if enabled {
+			c.EnableColor()
+		} else {
+			c.DisableColor()
+		}
+		return c.Sprint(a...)
 	}
 }
 
-func tag(name string, f formatter) string {
-	if color.NoColor {
+func (p palette) tag(name string, f formatter) string {
+	if !p.enabled {
 		return name + ":"
 	}
 
@@ -9,6 +9,9 @@ import (
 	"github.com/rliebz/ghost/be"
 )
 
+// plain formats output the way a logger writing to a buffer does.
+var plain = newPalette(false)
+
 func withStdout(l *Logger, out io.Writer) {
 	l.stdout = out
 }
@@ -0,0 +1,115 @@
+package ui
+
+import (
+	"bytes"
+	"hash/fnv"
+	"io"
+	"strings"
+	"sync"
+
+	"github.com/fatih/color"
+)
+
+// maxPrefixedLine is the most output held back while waiting for the end of
+// a line. Longer lines are written in pieces without repeating the prefix.
+const maxPrefixedLine = 64 * 1024
+
+// labelColors are the colors used to tell task labels apart.
+var labelColors = []color.Attribute{
+	color.FgCyan,
+	color.FgGreen,
+	color.FgMagenta,
+	color.FgYellow,
+	color.FgBlue,
+}
+
+// SetPrefixOutput sets whether each line of command output is prefixed with
+// the names of the tasks that ran the command.
+func (l *Logger) SetPrefixOutput(prefix bool) {
+	l.prefixOutput = prefix
+}
+
+// prefixedOutput returns writers that prefix each line written with a label
+// built from the task names, along with a function that writes any final
+// partial line.
+func (l *Logger) prefixedOutput(namespaces []string) (stdout, stderr io.Writer, flush func()) {
+	label := strings.Join(namespaces, namespaceSeparator)
+	prefix := l.colors().label(label)("["+label+"]") + " "
+
+	var mu sync.Mutex
+	outWriter := &prefixWriter{w: l.Stdout(), mu: &mu, prefix: prefix}
+	errWriter := &prefixWriter{w: l.Stderr(), mu: &mu, prefix: prefix}
+
+	return outWriter, errWriter, func() {
+		outWriter.flush()
+		errWriter.flush()
+	}
+}
+
+// label returns the formatter for a task label. The same label is always
+// given the same color.
+func (p palette) label(name string) formatter {
+	h := fnv.New32a()
+	h.Write([]byte(name)) //nolint:errcheck
+	return newFormatter(p.enabled, labelColors[h.Sum32()%uint32(len(labelColors))])
+}
+
+// prefixWriter writes output a line at a time, with a prefix before each line.
+//
+// Complete lines are written at once so that output from other writers sharing
+// the destination is not interleaved within a line.
+type prefixWriter struct {
+	w      io.Writer
+	mu     *sync.Mutex
+	prefix string
+	buf    bytes.Buffer
+
+	// midLine is set when part of a long line has already been written.
+	midLine bool
+}
+
+func (w *prefixWriter) Write(p []byte) (int, error) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	w.buf.Write(p)
+	for {
+		i := bytes.IndexByte(w.buf.Bytes(), '\n')
+		if i < 0 {
+			break
+		}
+
+		if err := w.writeLine(w.buf.Next(i + 1)); err != nil {
+			return 0, err
+		}
+		w.midLine = false
+	}
+
+	if w.buf.Len() >= maxPrefixedLine {
+		if err := w.writeLine(w.buf.Next(w.buf.Len())); err != nil {
+			return 0, err
+		}
+		w.midLine = true
+	}
+
+	return len(p), nil
+}
+
+func (w *prefixWriter) flush() {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	if w.buf.Len() > 0 || w.midLine {
+		w.writeLine(append(w.buf.Next(w.buf.Len()), '\n')) //nolint:errcheck
+		w.midLine = false
+	}
+}
+
+func (w *prefixWriter) writeLine(line []byte) error {
+	if !w.midLine {
+		line = append([]byte(w.prefix), line...)
+	}
+
+	_, err := w.w.Write(line)
+	return err
+}
@@ -0,0 +1,75 @@
+package ui
+
+import (
+	"bytes"
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/rliebz/ghost"
+	"github.com/rliebz/ghost/be"
+)
+
+func TestLogger_CommandOutput_prefix(t *testing.T) {
+	g := ghost.New(t)
+
+	stdoutBuf := new(bytes.Buffer)
+	stderrBuf := new(bytes.Buffer)
+	logger := New(Config{Stdout: stdoutBuf, Stderr: stderrBuf})
+	logger.SetPrefixOutput(true)
+
+	stdout, stderr, flush := logger.CommandOutput("echo hello", "parent", "child")
+
+	_, err := fmt.Fprint(stdout, "one\ntw")
+	g.NoError(err)
+	_, err = fmt.Fprint(stderr, "oops\n")
+	g.NoError(err)
+	_, err = fmt.Fprint(stdout, "o\n\nthree")
+	g.NoError(err)
+	flush()
+
+	g.Should(be.Equal(
+		stdoutBuf.String(),
+		"[parent > child] one\n[parent > child] two\n[parent > child] \n[parent > child] three\n",
+	))
+	g.Should(be.Equal(stderrBuf.String(), "[parent > child] oops\n"))
+}
+
+func TestLogger_CommandOutput_prefix_no_tasks(t *testing.T) {
+	g := ghost.New(t)
+
+	logger := New(Config{Stdout: new(bytes.Buffer), Stderr: new(bytes.Buffer)})
+	logger.SetPrefixOutput(true)
+
+	stdout, stderr, flush := logger.CommandOutput("echo hello")
+	g.Should(be.Equal(stdout, logger.Stdout()))
+	g.Should(be.Equal(stderr, logger.Stderr()))
+	flush()
+}
+
+func TestLogger_CommandOutput_prefix_long_line(t *testing.T) {
+	g := ghost.New(t)
+
+	buf := new(bytes.Buffer)
+	logger := New(Config{Stdout: buf})
+	logger.SetPrefixOutput(true)
+
+	stdout, _, flush := logger.CommandOutput("echo hello", "task")
+
+	long := strings.Repeat("x", maxPrefixedLine+1)
+	_, err := fmt.Fprint(stdout, long)
+	g.NoError(err)
+	_, err = fmt.Fprint(stdout, "y\nz")
+	g.NoError(err)
+	flush()
+
+	g.Should(be.Equal(buf.String(), "[task] "+long+"y\n[task] z\n"))
+}
+
+func TestPalette_label(t *testing.T) {
+	g := ghost.New(t)
+
+	c := newPalette(true)
+	g.Should(be.Equal(c.label("foo")("foo"), c.label("foo")("foo")))
+	g.Should(be.Equal(plain.label("foo")("foo"), "foo"))
+}
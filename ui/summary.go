@@ -0,0 +1,262 @@
+package ui
+
+import (
+	"cmp"
+	"encoding/json"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"slices"
+	"strings"
+	"time"
+)
+
+// SummaryFormat is the format of a run summary file.
+type SummaryFormat int
+
+const (
+	// SummaryJSON writes the summary as a JSON document.
+	SummaryJSON SummaryFormat = iota
+	// SummaryJUnit writes the summary as a JUnit XML report.
+	SummaryJUnit
+)
+
+// ParseSummaryFormat returns the summary format with a given name.
+func ParseSummaryFormat(name string) (SummaryFormat, error) {
+	switch name {
+	case "", "json":
+		return SummaryJSON, nil
+	case "junit":
+		return SummaryJUnit, nil
+	default:
+		return SummaryJSON, fmt.Errorf("summary format %q must be one of [json, junit]", name)
+	}
+}
+
+// Summary statuses of a task.
+const (
+	statusPassed  = "passed"
+	statusFailed  = "failed"
+	statusSkipped = "skipped"
+)
+
+// Skip is a task that was skipped rather than run.
+type Skip struct {
+	Task   string
+	Reason string
+	// Depth is the number of tasks the task would have run within.
+	Depth int
+	Start time.Time
+}
+
+// RecordSkip records a task that was skipped for the run summary.
+func (l *Logger) RecordSkip(skip Skip) {
+	l.skips = append(l.skips, skip)
+}
+
+// summary is the JSON representation of every task run.
+type summary struct {
+	Status int           `json:"exit_status"`
+	Tasks  []taskSummary `json:"tasks"`
+}
+
+// taskSummary is a task, and the commands it ran, within a [summary].
+type taskSummary struct {
+	Name     string           `json:"name"`
+	Depth    int              `json:"depth"`
+	Status   string           `json:"status"`
+	Reason   string           `json:"reason,omitempty"`
+	Error    string           `json:"error,omitempty"`
+	Duration float64          `json:"duration_seconds"`
+	Commands []commandSummary `json:"commands,omitempty"`
+
+	start time.Time
+}
+
+// commandSummary is a command within a [taskSummary].
+type commandSummary struct {
+	Command  string  `json:"command"`
+	Step     string  `json:"step,omitempty"`
+	ExitCode *int    `json:"exit_code"`
+	Duration float64 `json:"duration_seconds"`
+}
+
+// WriteSummary writes every task and command recorded, along with the exit
+// status of tusk, in the given format.
+func (l *Logger) WriteSummary(w io.Writer, format SummaryFormat, status int) error {
+	s := l.summary(status)
+
+	if format == SummaryJUnit {
+		return writeJUnit(w, s)
+	}
+
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	return enc.Encode(s)
+}
+
+// summary returns the tasks run and skipped in the order they were started,
+// each with the commands it ran.
+func (l *Logger) summary(status int) summary {
+	tasks := []taskSummary{}
+	for _, timing := range l.timings {
+		if timing.Command != "" || timing.Step != "" {
+			continue
+		}
+
+		task := taskSummary{
+			Name:     timing.Task,
+			Depth:    timing.Depth,
+			Status:   statusPassed,
+			Duration: timing.Elapsed.Seconds(),
+			start:    timing.Start,
+		}
+		if timing.Err != nil {
+			task.Status = statusFailed
+			task.Error = timing.Err.Error()
+		}
+		tasks = append(tasks, task)
+	}
+
+	for _, skip := range l.skips {
+		tasks = append(tasks, taskSummary{
+			Name:   skip.Task,
+			Depth:  skip.Depth,
+			Status: statusSkipped,
+			Reason: skip.Reason,
+			start:  skip.Start,
+		})
+	}
+
+	slices.SortStableFunc(tasks, func(a, b taskSummary) int {
+		return cmp.Or(a.start.Compare(b.start), cmp.Compare(a.Depth, b.Depth))
+	})
+
+	for _, timing := range l.Timings() {
+		if timing.Command == "" {
+			continue
+		}
+
+		task := ownerOf(tasks, timing)
+		if task == nil {
+			continue
+		}
+
+		task.Commands = append(task.Commands, commandSummary{
+			Command:  timing.Command,
+			Step:     timing.Step,
+			ExitCode: exitCode(timing.Err),
+			Duration: timing.Elapsed.Seconds(),
+		})
+	}
+
+	return summary{Status: status, Tasks: tasks}
+}
+
+// ownerOf returns the task that ran a command, which is the latest task with
+// the same name started before it, or nil if there is none.
+func ownerOf(tasks []taskSummary, timing Timing) *taskSummary {
+	for i := len(tasks) - 1; i >= 0; i-- {
+		task := &tasks[i]
+		if task.Name == timing.Task &&
+			task.Depth < timing.Depth &&
+			!task.start.After(timing.Start) {
+			return task
+		}
+	}
+
+	return nil
+}
+
+// junitSuites is the root element of a JUnit XML report.
+type junitSuites struct {
+	XMLName  xml.Name     `xml:"testsuites"`
+	Tests    int          `xml:"tests,attr"`
+	Failures int          `xml:"failures,attr"`
+	Skipped  int          `xml:"skipped,attr"`
+	Suite    []junitSuite `xml:"testsuite"`
+}
+
+// junitSuite holds a test case for each task run.
+type junitSuite struct {
+	Name     string      `xml:"name,attr"`
+	Tests    int         `xml:"tests,attr"`
+	Failures int         `xml:"failures,attr"`
+	Skipped  int         `xml:"skipped,attr"`
+	Cases    []junitCase `xml:"testcase"`
+}
+
+// junitCase is a task within a [junitSuite].
+type junitCase struct {
+	Name      string        `xml:"name,attr"`
+	ClassName string        `xml:"classname,attr"`
+	Time      string        `xml:"time,attr"`
+	Failure   *junitMessage `xml:"failure"`
+	Skipped   *junitMessage `xml:"skipped"`
+	SystemOut *junitOutput  `xml:"system-out"`
+}
+
+// junitMessage is the reason a [junitCase] failed or was skipped.
+type junitMessage struct {
+	Message string `xml:"message,attr"`
+}
+
+// junitOutput is the output of a [junitCase].
+type junitOutput struct {
+	Text string `xml:",cdata"`
+}
+
+// writeJUnit writes a summary as a JUnit XML report, with a test case for each
+// task and the commands it ran as its output.
+func writeJUnit(w io.Writer, s summary) error {
+	suite := junitSuite{Name: "tusk"}
+	for _, task := range s.Tasks {
+		tc := junitCase{
+			Name:      task.Name,
+			ClassName: "tusk",
+			Time:      fmt.Sprintf("%.3f", task.Duration),
+		}
+
+		switch task.Status {
+		case statusFailed:
+			tc.Failure = &junitMessage{Message: task.Error}
+			suite.Failures++
+		case statusSkipped:
+			tc.Skipped = &junitMessage{Message: task.Reason}
+			suite.Skipped++
+		}
+
+		var out strings.Builder
+		for _, command := range task.Commands {
+			fmt.Fprintf(
+				&out, "%s %s (exit status %d)\n", promptCharacter, command.Command, *command.ExitCode,
+			)
+		}
+		if out.Len() > 0 {
+			tc.SystemOut = &junitOutput{Text: out.String()}
+		}
+
+		suite.Cases = append(suite.Cases, tc)
+	}
+	suite.Tests = len(suite.Cases)
+
+	suites := junitSuites{
+		Tests:    suite.Tests,
+		Failures: suite.Failures,
+		Skipped:  suite.Skipped,
+		Suite:    []junitSuite{suite},
+	}
+
+	if _, err := io.WriteString(w, xml.Header); err != nil {
+		return err
+	}
+
+	enc := xml.NewEncoder(w)
+	enc.Indent("", "  ")
+	if err := enc.Encode(suites); err != nil {
+		return err
+	}
+
+	_, err := io.WriteString(w, "\n")
+	return err
+}
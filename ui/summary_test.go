@@ -0,0 +1,183 @@
+package ui
+
+import (
+	"bytes"
+	"os/exec"
+	"testing"
+	"time"
+
+	"github.com/rliebz/ghost"
+	"github.com/rliebz/ghost/be"
+)
+
+func TestParseSummaryFormat(t *testing.T) {
+	tests := []struct {
+		name    string
+		want    SummaryFormat
+		wantErr string
+	}{
+		{name: "", want: SummaryJSON},
+		{name: "json", want: SummaryJSON},
+		{name: "junit", want: SummaryJUnit},
+		{name: "xml", wantErr: `summary format "xml" must be one of [json, junit]`},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			g := ghost.New(t)
+
+			got, err := ParseSummaryFormat(tt.name)
+			if tt.wantErr != "" {
+				g.Should(be.ErrorEqual(err, tt.wantErr))
+				return
+			}
+			g.NoError(err)
+
+			g.Should(be.Equal(got, tt.want))
+		})
+	}
+}
+
+// summaryLogger returns a logger that has recorded a parent task with a
+// failing sub-task, a command of its own, and a skipped sub-task.
+func summaryLogger(t *testing.T) *Logger {
+	t.Helper()
+
+	exitErr := exec.Command("sh", "-c", "exit 3").Run()
+	ghost.New(t).Must(be.ErrorEqual(exitErr, "exit status 3"))
+
+	start := time.Now()
+	logger := Noop()
+
+	// Timings are recorded on completion, so children come before parents.
+	logger.RecordTiming(Timing{
+		Task:    "child",
+		Command: "exit 3",
+		Depth:   2,
+		Err:     exitErr,
+		Start:   start.Add(time.Second),
+		Elapsed: time.Second,
+	})
+	logger.RecordTiming(Timing{
+		Task:    "child",
+		Depth:   1,
+		Err:     exitErr,
+		Start:   start.Add(time.Second),
+		Elapsed: 1500 * time.Millisecond,
+	})
+	logger.RecordTiming(Timing{
+		Task:    "parent",
+		Step:    "build",
+		Command: "echo build",
+		Depth:   2,
+		Start:   start,
+		Elapsed: 500 * time.Millisecond,
+	})
+	logger.RecordTiming(Timing{
+		Task:    "parent",
+		Step:    "build",
+		Depth:   1,
+		Start:   start,
+		Elapsed: 500 * time.Millisecond,
+	})
+	logger.RecordSkip(Skip{
+		Task:   "cleanup",
+		Reason: "when condition not met",
+		Depth:  1,
+		Start:  start.Add(3 * time.Second),
+	})
+	logger.RecordTiming(Timing{
+		Task:    "parent",
+		Err:     exitErr,
+		Start:   start,
+		Elapsed: 3 * time.Second,
+	})
+
+	return logger
+}
+
+func TestLogger_WriteSummary_json(t *testing.T) {
+	g := ghost.New(t)
+
+	buf := new(bytes.Buffer)
+	g.NoError(summaryLogger(t).WriteSummary(buf, SummaryJSON, 3))
+
+	g.Should(be.Equal(buf.String(), `{
+  "exit_status": 3,
+  "tasks": [
+    {
+      "name": "parent",
+      "depth": 0,
+      "status": "failed",
+      "error": "exit status 3",
+      "duration_seconds": 3,
+      "commands": [
+        {
+          "command": "echo build",
+          "step": "build",
+          "exit_code": 0,
+          "duration_seconds": 0.5
+        }
+      ]
+    },
+    {
+      "name": "child",
+      "depth": 1,
+      "status": "failed",
+      "error": "exit status 3",
+      "duration_seconds": 1.5,
+      "commands": [
+        {
+          "command": "exit 3",
+          "exit_code": 3,
+          "duration_seconds": 1
+        }
+      ]
+    },
+    {
+      "name": "cleanup",
+      "depth": 1,
+      "status": "skipped",
+      "reason": "when condition not met",
+      "duration_seconds": 0
+    }
+  ]
+}
+`))
+}
+
+func TestLogger_WriteSummary_junit(t *testing.T) {
+	g := ghost.New(t)
+
+	buf := new(bytes.Buffer)
+	g.NoError(summaryLogger(t).WriteSummary(buf, SummaryJUnit, 3))
+
+	g.Should(be.Equal(buf.String(), `<?xml version="1.0" encoding="UTF-8"?>
+<testsuites tests="3" failures="2" skipped="1">
+  <testsuite name="tusk" tests="3" failures="2" skipped="1">
+    <testcase name="parent" classname="tusk" time="3.000">
+      <failure message="exit status 3"></failure>
+      <system-out><![CDATA[$ echo build (exit status 0)
+]]></system-out>
+    </testcase>
+    <testcase name="child" classname="tusk" time="1.500">
+      <failure message="exit status 3"></failure>
+      <system-out><![CDATA[$ exit 3 (exit status 3)
+]]></system-out>
+    </testcase>
+    <testcase name="cleanup" classname="tusk" time="0.000">
+      <skipped message="when condition not met"></skipped>
+    </testcase>
+  </testsuite>
+</testsuites>
+`))
+}
+
+func TestLogger_WriteSummary_empty(t *testing.T) {
+	g := ghost.New(t)
+
+	buf := new(bytes.Buffer)
+	g.NoError(Noop().WriteSummary(buf, SummaryJSON, 1))
+
+	g.Should(be.Equal(buf.String(), "{\n  \"exit_status\": 1,\n  \"tasks\": []\n}\n"))
+}
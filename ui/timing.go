@@ -0,0 +1,167 @@
+package ui
+
+import (
+	"cmp"
+	"fmt"
+	"slices"
+	"strings"
+	"time"
+)
+
+const (
+	profileString       = "Profile"
+	timingSummaryString = "Slowest Tasks"
+
+	// maxTimingSummary is the number of tasks shown in the timing summary.
+	maxTimingSummary = 5
+)
+
+// Timing is the wall-clock time taken to run a task or command.
+type Timing struct {
+	// Task is the name of the task, or the task running the command.
+	Task string
+	// Step is the name of the run item, or the run item running the command.
+	// It is empty for a task.
+	Step string
+	// Command is the command run, or empty for a task.
+	Command string
+	// Depth is the number of tasks the task or command is running within.
+	Depth int
+
+	// Err is the error the task or command failed with, if any.
+	Err error
+
+	Start   time.Time
+	Elapsed time.Duration
+}
+
+// RecordTiming records the time taken by a task or command for the timing
+// summary and profile.
+func (l *Logger) RecordTiming(timing Timing) {
+	l.timings = append(l.timings, timing)
+}
+
+// Timings returns every timing recorded, in the order they were started.
+func (l *Logger) Timings() []Timing {
+	return slices.SortedStableFunc(slices.Values(l.timings), func(a, b Timing) int {
+		// A sub-task started on the same clock tick must follow its parent.
+		return cmp.Or(a.Start.Compare(b.Start), cmp.Compare(a.Depth, b.Depth))
+	})
+}
+
+// PrintTimingSummary prints the slowest tasks and named run items completed.
+func (l *Logger) PrintTimingSummary() {
+	if l.isJSON() {
+		l.emitTimings()
+		return
+	}
+
+	if l.level <= LevelNormal {
+		return
+	}
+
+	var timings []Timing
+	for _, timing := range l.timings {
+		if timing.Command == "" {
+			timings = append(timings, timing)
+		}
+	}
+	if len(timings) == 0 {
+		return
+	}
+
+	slices.SortStableFunc(timings, func(a, b Timing) int {
+		return cmp.Compare(b.Elapsed, a.Elapsed)
+	})
+	timings = timings[:min(len(timings), maxTimingSummary)]
+
+	c := l.colors()
+
+	l.printTimings(timingSummaryString, timings, func(timing Timing) string {
+		return timingName(c, timing)
+	})
+}
+
+// PrintProfile prints the time taken by every task, sub-task, and command in
+// the order they were run.
+func (l *Logger) PrintProfile() {
+	if l.isJSON() {
+		l.emitTimings()
+		return
+	}
+
+	if l.level <= LevelSilent || len(l.timings) == 0 {
+		return
+	}
+
+	c := l.colors()
+
+	l.printTimings(profileString, l.Timings(), func(timing Timing) string {
+		indent := strings.Repeat("  ", timing.Depth)
+		if timing.Command != "" {
+			return fmt.Sprintf("%s%s %s", indent, c.blue(promptCharacter), timing.Command)
+		}
+
+		return indent + timingName(c, timing)
+	})
+}
+
+// timingName returns the name of a task, or of a named run item within its
+// task.
+func timingName(c palette, timing Timing) string {
+	if timing.Step != "" {
+		return c.bold(timing.Task) + namespaceSeparator + timing.Step
+	}
+
+	return c.bold(timing.Task)
+}
+
+func (l *Logger) emitTimings() {
+	if len(l.timings) == 0 {
+		return
+	}
+
+	timings := l.Timings()
+	events := make([]timingEvent, 0, len(timings))
+	for _, timing := range timings {
+		events = append(events, timingEvent{
+			Task:     timing.Task,
+			Step:     timing.Step,
+			Command:  timing.Command,
+			Depth:    timing.Depth,
+			Duration: timing.Elapsed.Seconds(),
+		})
+	}
+
+	l.emit(event{Event: "timings", Timings: events})
+}
+
+func (l *Logger) printTimings(title string, timings []Timing, label func(Timing) string) {
+	width := 0
+	for _, timing := range timings {
+		width = max(width, len(formatDuration(timing.Elapsed)))
+	}
+
+	f := l.colors().blue
+
+	fmt.Fprintln(l.Stderr(), f(title))
+	for _, timing := range timings {
+		fmt.Fprintf(
+			l.Stderr(),
+			"%s%-*s  %s\n",
+			f(outputPrefix),
+			width,
+			formatDuration(timing.Elapsed),
+			label(timing),
+		)
+	}
+}
+
+// formatDuration formats a duration for display.
+func formatDuration(d time.Duration) string {
+	if d < time.Millisecond {
+		return d.Round(time.Microsecond).String()
+	}
+
+	return d.Round(time.Millisecond).String()
+}
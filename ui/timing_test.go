@@ -0,0 +1,121 @@
+package ui
+
+import (
+	"bytes"
+	"testing"
+	"time"
+
+	"github.com/rliebz/ghost"
+	"github.com/rliebz/ghost/be"
+)
+
+func TestLogger_Timings(t *testing.T) {
+	g := ghost.New(t)
+
+	start := time.Now()
+	logger := Noop()
+
+	// Timings are recorded on completion, so children come before parents.
+	want := []Timing{
+		{Task: "parent", Start: start, Elapsed: 3 * time.Second},
+		{Task: "child", Depth: 1, Start: start, Elapsed: 2 * time.Second},
+		{Task: "child", Command: "echo one", Depth: 2, Start: start, Elapsed: time.Second},
+		{Task: "child", Command: "echo two", Depth: 2, Start: start.Add(time.Second)},
+	}
+	logger.RecordTiming(want[2])
+	logger.RecordTiming(want[3])
+	logger.RecordTiming(want[1])
+	logger.RecordTiming(want[0])
+
+	g.Should(be.DeepEqual(logger.Timings(), want))
+}
+
+func TestLogger_PrintTimingSummary(t *testing.T) {
+	g := ghost.New(t)
+
+	buf := new(bytes.Buffer)
+	logger := New(Config{Stderr: buf, Verbosity: LevelNormal})
+
+	durations := []time.Duration{
+		2 * time.Second,
+		300 * time.Millisecond,
+		1500 * time.Millisecond,
+		10 * time.Millisecond,
+		20 * time.Millisecond,
+		40 * time.Microsecond,
+	}
+	for i, d := range durations {
+		logger.RecordTiming(Timing{Task: string(rune('a' + i)), Elapsed: d})
+	}
+	logger.RecordTiming(Timing{Task: "a", Command: "sleep 5", Elapsed: 5 * time.Second})
+	logger.RecordTiming(Timing{Task: "a", Step: "build", Elapsed: 400 * time.Millisecond})
+
+	logger.PrintTimingSummary()
+	g.Should(be.Zero(buf.String()))
+
+	logger.SetLevel(LevelVerbose)
+	logger.PrintTimingSummary()
+	g.Should(be.Equal(buf.String(), `Slowest Tasks
+ => 2s     a
+ => 1.5s   c
+ => 400ms  a > build
+ => 300ms  b
+ => 20ms   e
+`))
+}
+
+func TestLogger_PrintTimingSummary_empty(t *testing.T) {
+	g := ghost.New(t)
+
+	buf := new(bytes.Buffer)
+	logger := New(Config{Stderr: buf, Verbosity: LevelVerbose})
+
+	logger.PrintTimingSummary()
+	g.Should(be.Zero(buf.String()))
+}
+
+func TestLogger_PrintProfile(t *testing.T) {
+	g := ghost.New(t)
+
+	buf := new(bytes.Buffer)
+	logger := New(Config{Stderr: buf, Verbosity: LevelSilent})
+
+	start := time.Now()
+	logger.RecordTiming(Timing{
+		Task:    "build",
+		Command: "go build",
+		Depth:   1,
+		Start:   start.Add(time.Millisecond),
+		Elapsed: 1200 * time.Millisecond,
+	})
+	logger.RecordTiming(Timing{
+		Task:    "test",
+		Command: "go test",
+		Depth:   2,
+		Start:   start.Add(2 * time.Second),
+		Elapsed: 800 * time.Millisecond,
+	})
+	logger.RecordTiming(Timing{
+		Task:    "test",
+		Depth:   1,
+		Start:   start.Add(2 * time.Second),
+		Elapsed: 801 * time.Millisecond,
+	})
+	logger.RecordTiming(Timing{
+		Task:    "build",
+		Start:   start,
+		Elapsed: 2 * time.Second,
+	})
+
+	logger.PrintProfile()
+	g.Should(be.Zero(buf.String()))
+
+	logger.SetLevel(LevelQuiet)
+	logger.PrintProfile()
+	g.Should(be.Equal(buf.String(), `Profile
+ => 2s     build
+ => 1.2s     $ go build
+ => 801ms    test
+ => 800ms      $ go test
+`))
+}